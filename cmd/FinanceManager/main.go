@@ -65,29 +65,55 @@ func respondError(w http.ResponseWriter, status int, message string, errors ...[
 }
 
 type Server struct {
-	router                      *http.ServeMux
-	authHandler                 *auth.Handler
-	userHandler                 *user.Handler
-	authService                 auth.Service
-	userService                 user.Service
-	instrumentHandler           instrument.Handler
-	investmentsHandler          *investments.InvestmentHandler
-	personalTransactionsHandler *interfaces.PersonalTransactionHandler
-	financeCategoriesHandler    *interfaces.CategoryHandler
-	financePaymentHandler       *interfaces.PaymentHandler
+	router                       *http.ServeMux
+	authHandler                  *auth.Handler
+	userHandler                  *user.Handler
+	authService                  auth.Service
+	userService                  user.Service
+	instrumentHandler            instrument.Handler
+	investmentsHandler           *investments.InvestmentHandler
+	personalTransactionsHandler  *interfaces.PersonalTransactionHandler
+	financeCategoriesHandler     *interfaces.CategoryHandler
+	financePaymentHandler        *interfaces.PaymentHandler
+	financeRecurringHandler      *interfaces.RecurringTransactionHandler
+	financeBudgetHandler         *interfaces.BudgetHandler
+	financeReconciliationHandler *interfaces.ReconciliationHandler
+	financeImportHandler         *interfaces.ImportHandler
+	financeExportHandler         *interfaces.ExportHandler
+	financeCategorizationHandler *interfaces.CategorizationHandler
+	financeTagHandler            *interfaces.TagHandler
+	financeAttachmentHandler     *interfaces.AttachmentHandler
+	financeForecastHandler       *interfaces.ForecastHandler
+	financeSavingsGoalHandler    *interfaces.SavingsGoalHandler
+	financeLoanHandler           *interfaces.LoanHandler
+	financeSettingsHandler       *interfaces.FinanceSettingsHandler
+	financeNetWorthHandler       *interfaces.NetWorthHandler
 }
 
-func NewServer(authHandler *auth.Handler, authService auth.Service, userHandler *user.Handler, investmentHandler *investments.InvestmentHandler, instrumentHandler instrument.Handler, personalTransactionsHandler *interfaces.PersonalTransactionHandler, financeCategoriesHandler *interfaces.CategoryHandler, financePaymentHandler *interfaces.PaymentHandler) *Server {
+func NewServer(authHandler *auth.Handler, authService auth.Service, userHandler *user.Handler, investmentHandler *investments.InvestmentHandler, instrumentHandler instrument.Handler, personalTransactionsHandler *interfaces.PersonalTransactionHandler, financeCategoriesHandler *interfaces.CategoryHandler, financePaymentHandler *interfaces.PaymentHandler, financeRecurringHandler *interfaces.RecurringTransactionHandler, financeBudgetHandler *interfaces.BudgetHandler, financeReconciliationHandler *interfaces.ReconciliationHandler, financeImportHandler *interfaces.ImportHandler, financeExportHandler *interfaces.ExportHandler, financeCategorizationHandler *interfaces.CategorizationHandler, financeTagHandler *interfaces.TagHandler, financeAttachmentHandler *interfaces.AttachmentHandler, financeForecastHandler *interfaces.ForecastHandler, financeSavingsGoalHandler *interfaces.SavingsGoalHandler, financeLoanHandler *interfaces.LoanHandler, financeSettingsHandler *interfaces.FinanceSettingsHandler, financeNetWorthHandler *interfaces.NetWorthHandler) *Server {
 	return &Server{
-		authHandler:                 authHandler,
-		userHandler:                 userHandler,
-		investmentsHandler:          investmentHandler,
-		authService:                 authService,
-		instrumentHandler:           instrumentHandler,
-		personalTransactionsHandler: personalTransactionsHandler,
-		financeCategoriesHandler:    financeCategoriesHandler,
-		financePaymentHandler:       financePaymentHandler,
-		router:                      http.NewServeMux(),
+		authHandler:                  authHandler,
+		userHandler:                  userHandler,
+		investmentsHandler:           investmentHandler,
+		authService:                  authService,
+		instrumentHandler:            instrumentHandler,
+		personalTransactionsHandler:  personalTransactionsHandler,
+		financeCategoriesHandler:     financeCategoriesHandler,
+		financePaymentHandler:        financePaymentHandler,
+		financeRecurringHandler:      financeRecurringHandler,
+		financeBudgetHandler:         financeBudgetHandler,
+		financeReconciliationHandler: financeReconciliationHandler,
+		financeImportHandler:         financeImportHandler,
+		financeExportHandler:         financeExportHandler,
+		financeCategorizationHandler: financeCategorizationHandler,
+		financeTagHandler:            financeTagHandler,
+		financeAttachmentHandler:     financeAttachmentHandler,
+		financeForecastHandler:       financeForecastHandler,
+		financeSavingsGoalHandler:    financeSavingsGoalHandler,
+		financeLoanHandler:           financeLoanHandler,
+		financeSettingsHandler:       financeSettingsHandler,
+		financeNetWorthHandler:       financeNetWorthHandler,
+		router:                       http.NewServeMux(),
 	}
 }
 
@@ -109,6 +135,10 @@ func checkConfiguration() error {
 	if os.Getenv("JWT_SECRET") == "" {
 		return errors.New("no JWT_SECRET Provided")
 	}
+
+	if os.Getenv("PAYMENT_DETAILS_KEY") == "" {
+		return errors.New("no PAYMENT_DETAILS_KEY Provided")
+	}
 	return nil
 }
 
@@ -220,20 +250,241 @@ func (s *Server) RegisterRoutes() {
 	protectedRoutes.Handle("GET /api/protected/finance/transactions/summary/categories",
 		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetTransactionSummaryByCategory)))
 
+	protectedRoutes.Handle("GET /api/protected/finance/transactions/summary/tags",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetTransactionSummaryByTag)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/transactions/summary/comparison",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetTransactionComparison)))
+
 	protectedRoutes.Handle("GET /api/protected/finance/transactions",
 		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetUserTransactions)))
 
+	protectedRoutes.Handle("GET /api/protected/finance/transactions/export",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeExportHandler.ExportTransactions)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/transactions/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetTransaction)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/transactions/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.UpdateTransaction)))
+
+	protectedRoutes.Handle("PATCH /api/protected/finance/transactions/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.PatchTransaction)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/transactions/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.DeleteTransaction)))
+
 	protectedRoutes.Handle("GET /api/protected/finance/categories/predefined",
 		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.GetPredefinedCategories)))
 
-	//protectedRoutes.Handle("GET /api/protected/finance/categories/user",
-	//	s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.GetUserCategories)))
+	protectedRoutes.Handle("GET /api/protected/finance/categories/user",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.GetUserCategories)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/categories/user",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.CreateUserCategory)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/categories/user/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.GetUserCategory)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/categories/user/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.UpdateUserCategory)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/categories/user/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategoriesHandler.DeleteUserCategory)))
 
 	protectedRoutes.Handle("GET /api/protected/finance/payment/methods",
 		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.GetPaymentMethods)))
 
-	//protectedRoutes.Handle("GET /api/protected/finance/payment/sources",
-	//	s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.GetUserPaymentSources)))
+	protectedRoutes.Handle("GET /api/protected/finance/payment/sources",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.GetUserPaymentSources)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/payment/sources",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.CreatePaymentSource)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/payment/sources/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.GetUserPaymentSource)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/payment/sources/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.UpdatePaymentSource)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/payment/sources/{id}/archive",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.ArchivePaymentSource)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/payment/sources/{id}/restore",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financePaymentHandler.RestorePaymentSource)))
+	protectedRoutes.Handle("GET /api/protected/finance/payment/sources/{id}/transactions",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.personalTransactionsHandler.GetPaymentSourceHistory)))
+	protectedRoutes.Handle("GET /api/protected/finance/payment/sources/{id}/reconciliation",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeReconciliationHandler.PreviewReconciliation)))
+	protectedRoutes.Handle("GET /api/protected/finance/payment/sources/{id}/reconciliations",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeReconciliationHandler.GetReconciliations)))
+	protectedRoutes.Handle("POST /api/protected/finance/payment/sources/{id}/reconciliations",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeReconciliationHandler.Reconcile)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/recurring",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.GetRules)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/recurring",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.CreateRule)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/recurring/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.GetRule)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/recurring/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.UpdateRule)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/recurring/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.DeleteRule)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/recurring/{id}/occurrences",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.GetOccurrences)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/recurring/{id}/occurrences/{date}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.UpdateOccurrence)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/recurring/{id}/occurrences/{date}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeRecurringHandler.DeleteOccurrenceException)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/budgets",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeBudgetHandler.GetBudgets)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/budgets",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeBudgetHandler.CreateBudget)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/budgets/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeBudgetHandler.GetBudget)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/budgets/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeBudgetHandler.UpdateBudget)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/budgets/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeBudgetHandler.DeleteBudget)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/tags",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeTagHandler.GetTags)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/tags",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeTagHandler.CreateTag)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/tags/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeTagHandler.GetTag)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/tags/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeTagHandler.UpdateTag)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/tags/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeTagHandler.DeleteTag)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/transactions/{id}/attachments",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeAttachmentHandler.GetAttachments)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/transactions/{id}/attachments",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeAttachmentHandler.UploadAttachment)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/attachments/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeAttachmentHandler.DownloadAttachment)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/attachments/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeAttachmentHandler.DeleteAttachment)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/forecast",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeForecastHandler.GetForecast)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/goals",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSavingsGoalHandler.GetGoals)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/goals",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSavingsGoalHandler.CreateGoal)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/goals/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSavingsGoalHandler.GetGoal)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/goals/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSavingsGoalHandler.UpdateGoal)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/goals/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSavingsGoalHandler.DeleteGoal)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/loans",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.GetLoans)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/loans",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.CreateLoan)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/loans/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.GetLoan)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/loans/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.UpdateLoan)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/loans/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.DeleteLoan)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/loans/{id}/schedule",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.GetSchedule)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/loans/{id}/simulation",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeLoanHandler.SimulateOverpayment)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/settings",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSettingsHandler.GetSettings)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/settings",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeSettingsHandler.UpdateSettings)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/net-worth",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeNetWorthHandler.GetNetWorth)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/net-worth/history",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeNetWorthHandler.GetNetWorthHistory)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/categorization/rules",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.GetRules)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/categorization/rules",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.CreateRule)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/categorization/rules/suggestions",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.GetSuggestions)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/categorization/rules/apply",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.ApplyRules)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/categorization/rules/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.GetRule)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/categorization/rules/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.UpdateRule)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/categorization/rules/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeCategorizationHandler.DeleteRule)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/imports/profiles",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.GetProfiles)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/imports/profiles",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.CreateProfile)))
+
+	protectedRoutes.Handle("GET /api/protected/finance/imports/profiles/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.GetProfile)))
+
+	protectedRoutes.Handle("PUT /api/protected/finance/imports/profiles/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.UpdateProfile)))
+
+	protectedRoutes.Handle("DELETE /api/protected/finance/imports/profiles/{id}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.DeleteProfile)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/imports/csv/preview",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.PreviewCSV)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/imports/csv",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.ImportCSV)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/imports/statements/{format}/preview",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.PreviewStatement)))
+
+	protectedRoutes.Handle("POST /api/protected/finance/imports/statements/{format}",
+		s.authService.JWTAccessTokenMiddleware()(http.HandlerFunc(s.financeImportHandler.ImportStatement)))
 
 	// Refresh token routes
 	refreshTokenRoutes := http.NewServeMux()
@@ -315,13 +566,79 @@ func main() {
 
 	financeCategoriesHandler := interfaces.NewCategoryHandler(categoryService, respondJSON, respondError)
 
-	financePaymentRepository := infrastructure.NewPaymentRepository(dbService.DB)
+	paymentDetailsCipher, err := infrastructure.NewDetailsCipherFromEnv()
+	if err != nil {
+		log.Fatalf("Could not initialize payment details encryption: %v", err)
+	}
+	financePaymentRepository := infrastructure.NewPaymentRepository(dbService.DB, paymentDetailsCipher)
 	financePaymentService := application.NewPaymentService(financePaymentRepository)
 	financePaymentHandler := interfaces.NewPaymentHandler(financePaymentService, respondJSON, respondError)
 
-	personalTransactionService := application.NewPersonalTransactionService(personalTransactionRepository, categoryService, financePaymentService)
+	tagRepository := infrastructure.NewTagRepository(dbService.DB)
+	tagService := application.NewTagService(tagRepository)
+	tagHandler := interfaces.NewTagHandler(tagService, respondJSON, respondError)
+
+	personalTransactionService := application.NewPersonalTransactionService(personalTransactionRepository, categoryService, financePaymentService, tagService)
 	personalTransactionHandler := interfaces.NewPersonalTransactionHandler(personalTransactionService, respondJSON, respondError)
-	server := NewServer(authHandler, authService, userHandler, investmentsHandler, instrumentHandler, personalTransactionHandler, financeCategoriesHandler, financePaymentHandler)
+
+	budgetRepository := infrastructure.NewBudgetRepository(dbService.DB)
+	budgetService := application.NewBudgetService(budgetRepository, personalTransactionService, categoryService, userService, newEmailService)
+	personalTransactionService.SetBudgetAlerter(budgetService)
+	budgetHandler := interfaces.NewBudgetHandler(budgetService, respondJSON, respondError)
+
+	reconciliationRepository := infrastructure.NewReconciliationRepository(dbService.DB)
+	reconciliationService := application.NewReconciliationService(reconciliationRepository, financePaymentService, personalTransactionService)
+	reconciliationHandler := interfaces.NewReconciliationHandler(reconciliationService, respondJSON, respondError)
+
+	categorizationRuleRepository := infrastructure.NewCategorizationRuleRepository(dbService.DB)
+	categorizationService := application.NewCategorizationService(categorizationRuleRepository, personalTransactionRepository, categoryService, financePaymentService, tagService)
+	personalTransactionService.SetCategorizer(categorizationService)
+	categorizationHandler := interfaces.NewCategorizationHandler(categorizationService, respondJSON, respondError)
+
+	importProfileRepository := infrastructure.NewImportProfileRepository(dbService.DB)
+	importService := application.NewImportService(importProfileRepository, categoryService, financePaymentService, personalTransactionService, categorizationService)
+	importHandler := interfaces.NewImportHandler(importService, respondJSON, respondError)
+
+	exportService := application.NewExportService(personalTransactionRepository, categoryService, financePaymentService, personalTransactionService)
+	exportHandler := interfaces.NewExportHandler(exportService, respondJSON, respondError)
+
+	recurringRuleRepository := infrastructure.NewRecurringRuleRepository(dbService.DB)
+	recurringTransactionService := application.NewRecurringTransactionService(recurringRuleRepository, personalTransactionService)
+	recurringTransactionService.SetBudgetAlerter(budgetService)
+	recurringTransactionHandler := interfaces.NewRecurringTransactionHandler(recurringTransactionService, respondJSON, respondError)
+
+	attachmentStorage, err := infrastructure.NewLocalBlobStorageFromEnv()
+	if err != nil {
+		log.Fatalf("Could not initialize attachment storage: %v", err)
+	}
+	attachmentRepository := infrastructure.NewAttachmentRepository(dbService.DB)
+	attachmentService := application.NewAttachmentService(attachmentRepository, personalTransactionRepository, attachmentStorage)
+	personalTransactionService.SetAttachmentRemover(attachmentService)
+	attachmentHandler := interfaces.NewAttachmentHandler(attachmentService, respondJSON, respondError)
+
+	forecastService := application.NewForecastService(personalTransactionService, recurringTransactionService, financePaymentService, categoryService)
+	forecastHandler := interfaces.NewForecastHandler(forecastService, respondJSON, respondError)
+
+	savingsGoalRepository := infrastructure.NewSavingsGoalRepository(dbService.DB)
+	portfolioValueProvider := infrastructure.NewPortfolioValueProvider(portfolioService, assetService)
+	savingsGoalService := application.NewSavingsGoalService(savingsGoalRepository, personalTransactionService, categoryService, financePaymentService, portfolioValueProvider, userService, newEmailService)
+	savingsGoalHandler := interfaces.NewSavingsGoalHandler(savingsGoalService, respondJSON, respondError)
+
+	loanRepository := infrastructure.NewLoanRepository(dbService.DB)
+	loanService := application.NewLoanService(loanRepository, personalTransactionRepository, categoryService, financePaymentService)
+	loanHandler := interfaces.NewLoanHandler(loanService, respondJSON, respondError)
+
+	financeSettingsRepository := infrastructure.NewFinanceSettingsRepository(dbService.DB)
+	financeSettingsService := application.NewFinanceSettingsService(financeSettingsRepository)
+	personalTransactionService.SetSettingsProvider(financeSettingsService)
+	financeSettingsHandler := interfaces.NewFinanceSettingsHandler(financeSettingsService, respondJSON, respondError)
+
+	netWorthRepository := infrastructure.NewNetWorthRepository(dbService.DB)
+	exchangeRateProvider := infrastructure.NewExchangeRateProvider(marketDataService)
+	netWorthService := application.NewNetWorthService(netWorthRepository, financeSettingsService, financePaymentService, loanRepository, portfolioValueProvider, exchangeRateProvider)
+	netWorthHandler := interfaces.NewNetWorthHandler(netWorthService, respondJSON, respondError)
+
+	server := NewServer(authHandler, authService, userHandler, investmentsHandler, instrumentHandler, personalTransactionHandler, financeCategoriesHandler, financePaymentHandler, recurringTransactionHandler, budgetHandler, reconciliationHandler, importHandler, exportHandler, categorizationHandler, tagHandler, attachmentHandler, forecastHandler, savingsGoalHandler, loanHandler, financeSettingsHandler, netWorthHandler)
 
 	server.RegisterRoutes()
 
@@ -348,6 +665,18 @@ func main() {
 	if err != nil {
 		log.Fatalf("Scheduler didn't start, stoping the app ...")
 	}
+	err = StartRecurringTransactionsScheduler(recurringTransactionService)
+	if err != nil {
+		log.Fatalf("Scheduler didn't start, stoping the app ...")
+	}
+	err = StartSavingsGoalProgressScheduler(savingsGoalService)
+	if err != nil {
+		log.Fatalf("Scheduler didn't start, stoping the app ...")
+	}
+	err = StartNetWorthSnapshotScheduler(netWorthService)
+	if err != nil {
+		log.Fatalf("Scheduler didn't start, stoping the app ...")
+	}
 	loggingMiddleware := loggingMiddleware(http.HandlerFunc(server.router.ServeHTTP))
 	httpServer := &http.Server{
 		Addr:         ":8080",
@@ -398,3 +727,57 @@ func StartScheduler(instrumentService instrument.Service) error {
 	c.Start()
 	return nil
 }
+
+func StartRecurringTransactionsScheduler(recurringTransactionService *application.RecurringTransactionService) error {
+	c := cron.New()
+	// Occurrences are booked per day, running every hour picks up the new day shortly after midnight
+	_, err := c.AddFunc("@every 1h", func() {
+		generated, err := recurringTransactionService.GenerateDueTransactions(time.Now())
+		if err != nil {
+			log.Printf("Error generating recurring transactions: %v", err)
+		} else {
+			log.Printf("Recurring transactions generated: %d", generated)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.Start()
+	return nil
+}
+
+func StartSavingsGoalProgressScheduler(savingsGoalService *application.SavingsGoalService) error {
+	c := cron.New()
+	// Each goal is emailed once a month, running every hour sends the emails shortly after the month starts
+	_, err := c.AddFunc("@every 1h", func() {
+		sent, err := savingsGoalService.SendMonthlyProgressEmails(time.Now())
+		if err != nil {
+			log.Printf("Error sending savings goals progress emails: %v", err)
+		} else if sent > 0 {
+			log.Printf("Savings goals progress emails queued: %d", sent)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.Start()
+	return nil
+}
+
+func StartNetWorthSnapshotScheduler(netWorthService *application.NetWorthService) error {
+	c := cron.New()
+	// One snapshot per user and day, running every hour takes it shortly after midnight and retries failed users
+	_, err := c.AddFunc("@every 1h", func() {
+		recorded, err := netWorthService.RecordDailySnapshots(time.Now())
+		if err != nil {
+			log.Printf("Error recording net worth snapshots: %v", err)
+		} else if recorded > 0 {
+			log.Printf("Net worth snapshots recorded: %d", recorded)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.Start()
+	return nil
+}
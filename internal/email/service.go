@@ -19,6 +19,11 @@ const (
 	templateResetPassword            = "reset_password.html"
 	subjectTwoFactorCode             = "Your 2FA code"
 	templateTwoFactorCode            = "two_factor_code.html"
+	subjectBudgetWarning             = "You are close to your budget limit"
+	subjectBudgetExceeded            = "You have exceeded your budget"
+	templateBudgetAlert              = "budget_alert.html"
+	subjectSavingsGoalProgress       = "Your savings goals this month"
+	templateSavingsGoalProgress      = "savings_goal_progress.html"
 )
 
 type EmailData interface {
@@ -69,6 +74,51 @@ func (r TwoFactorCodeData) Subject() string {
 	return subjectTwoFactorCode
 }
 
+type BudgetAlertData struct {
+	UserName     string
+	CategoryName string
+	Threshold    int
+	Spent        string
+	Limit        string
+	PeriodStart  string
+	PeriodEnd    string
+}
+
+func (r BudgetAlertData) TemplateFileName() string {
+	return templateBudgetAlert
+}
+
+func (r BudgetAlertData) Subject() string {
+	if r.Threshold >= 100 {
+		return subjectBudgetExceeded
+	}
+	return subjectBudgetWarning
+}
+
+type SavingsGoalProgressData struct {
+	UserName string
+	Month    string
+	Goals    []SavingsGoalProgressLine
+}
+
+type SavingsGoalProgressLine struct {
+	Name                        string
+	Saved                       string
+	TargetAmount                string
+	TargetDate                  string
+	PercentComplete             float64
+	RequiredMonthlyContribution string
+	Status                      string
+}
+
+func (r SavingsGoalProgressData) TemplateFileName() string {
+	return templateSavingsGoalProgress
+}
+
+func (r SavingsGoalProgressData) Subject() string {
+	return subjectSavingsGoalProgress
+}
+
 type EmailService struct {
 	from         string
 	password     string
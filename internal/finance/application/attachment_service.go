@@ -0,0 +1,168 @@
+package application
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+)
+
+type AttachmentService struct {
+	repo            domain.AttachmentRepository
+	transactionRepo domain.PersonalTransactionRepository
+	storage         domain.BlobStorage
+}
+
+func NewAttachmentService(repo domain.AttachmentRepository, transactionRepo domain.PersonalTransactionRepository, storage domain.BlobStorage) *AttachmentService {
+	return &AttachmentService{repo: repo, transactionRepo: transactionRepo, storage: storage}
+}
+
+func (s *AttachmentService) GetTransactionAttachments(transactionID, userID string) ([]domain.Attachment, error) {
+	if err := s.checkTransaction(transactionID, userID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.FindByTransaction(transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		return []domain.Attachment{}, nil
+	}
+	return attachments, nil
+}
+
+// UploadAttachment attaches the file to the transaction of the user. Its type is detected from the content, only
+// the types in domain.AttachmentContentTypes are accepted, and it's rejected once it grows over domain.MaxAttachmentSize.
+func (s *AttachmentService) UploadAttachment(transactionID, userID, fileName string, content io.Reader) (*domain.Attachment, error) {
+	if err := s.checkTransaction(transactionID, userID); err != nil {
+		return nil, err
+	}
+	// checked again when the attachment is saved, this only spares storing a file which would be rejected
+	count, err := s.repo.CountByTransaction(transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxTransactionAttachments {
+		return nil, financeErrors.ErrTooManyAttachments
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, financeErrors.ErrEmptyAttachment
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil || !domain.AttachmentContentTypes[contentType] {
+		return nil, financeErrors.ErrUnsupportedAttachmentType
+	}
+
+	attachment := &domain.Attachment{
+		ID:            uuid.NewString(),
+		TransactionID: transactionID,
+		UserID:        userID,
+		FileName:      domain.SanitizeAttachmentFileName(fileName),
+		ContentType:   contentType,
+	}
+	attachment.StorageKey = domain.AttachmentStorageKey(userID, attachment.ID)
+
+	limited := &sizeLimitedReader{reader: io.MultiReader(bytes.NewReader(head[:n]), content), limit: domain.MaxAttachmentSize}
+	if err := s.storage.Put(attachment.StorageKey, limited); err != nil {
+		return nil, err
+	}
+	attachment.Size = limited.read
+
+	if err := s.repo.Create(attachment); err != nil {
+		s.removeBlob(attachment.StorageKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// OpenAttachment returns the attachment together with its content, which the caller must close.
+func (s *AttachmentService) OpenAttachment(attachmentID, userID string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getAttachment(attachmentID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.storage.Get(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(attachmentID, userID string) error {
+	attachment, err := s.getAttachment(attachmentID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := s.repo.Delete(attachmentID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrAttachmentNotFound
+	}
+	s.removeBlob(attachment.StorageKey)
+	return nil
+}
+
+// RemoveAttachmentBlobs deletes the content of attachments whose metadata is already gone, together with their
+// transaction. Failures are only logged, the transaction can't be brought back anyway.
+func (s *AttachmentService) RemoveAttachmentBlobs(storageKeys []string) {
+	for _, key := range storageKeys {
+		s.removeBlob(key)
+	}
+}
+
+func (s *AttachmentService) removeBlob(key string) {
+	if err := s.storage.Delete(key); err != nil {
+		log.Printf("Error deleting attachment %s: %v", key, err)
+	}
+}
+
+func (s *AttachmentService) getAttachment(attachmentID, userID string) (*domain.Attachment, error) {
+	attachment, err := s.repo.FindByID(attachmentID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) checkTransaction(transactionID, userID string) error {
+	if _, err := s.transactionRepo.FindByID(transactionID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return financeErrors.ErrTransactionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// sizeLimitedReader fails with ErrAttachmentTooLarge instead of silently truncating the content like io.LimitReader.
+type sizeLimitedReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		return 0, financeErrors.ErrAttachmentTooLarge
+	}
+	return n, err
+}
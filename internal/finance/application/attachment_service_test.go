@@ -0,0 +1,87 @@
+package application
+
+import (
+	"bytes"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const transactionID = "5b0c1d3e-7a7e-4a4e-9a57-1f0e4a0cb001"
+
+func newAttachmentTestServices(t *testing.T) (*AttachmentService, *PersonalTransactionService, *infrastructure.MockTransactionRepository) {
+	storage, err := infrastructure.NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+	transactionRepo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{ID: transactionID, UserID: "user-id", Name: "Laptop", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("4999")},
+		},
+	}
+	attachmentService := NewAttachmentService(&infrastructure.MockAttachmentRepository{}, transactionRepo, storage)
+	transactionService := NewPersonalTransactionService(transactionRepo, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+	transactionService.SetAttachmentRemover(attachmentService)
+	return attachmentService, transactionService, transactionRepo
+}
+
+func TestAttachments_UploadedScopedToUserAndDownloaded(t *testing.T) {
+	service, _, _ := newAttachmentTestServices(t)
+	invoice := "%PDF-1.7\n" + strings.Repeat("invoice ", 100)
+
+	attachment, err := service.UploadAttachment(transactionID, "user-id", "../../Invoice 03.pdf", strings.NewReader(invoice))
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", attachment.ContentType)
+	assert.Equal(t, "Invoice 03.pdf", attachment.FileName)
+	assert.Equal(t, int64(len(invoice)), attachment.Size)
+
+	_, _, err = service.OpenAttachment(attachment.ID, "other-user")
+	assert.ErrorIs(t, err, financeErrors.ErrAttachmentNotFound)
+	_, err = service.UploadAttachment(transactionID, "other-user", "receipt.pdf", strings.NewReader(invoice))
+	assert.ErrorIs(t, err, financeErrors.ErrTransactionNotFound)
+
+	_, content, err := service.OpenAttachment(attachment.ID, "user-id")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(content)
+	assert.NoError(t, err)
+	assert.NoError(t, content.Close())
+	assert.Equal(t, invoice, string(data))
+
+	assert.NoError(t, service.DeleteAttachment(attachment.ID, "user-id"))
+	_, err = service.storage.Get(attachment.StorageKey)
+	assert.Error(t, err)
+}
+
+func TestAttachments_TypeAndSizeChecked(t *testing.T) {
+	service, _, _ := newAttachmentTestServices(t)
+
+	_, err := service.UploadAttachment(transactionID, "user-id", "receipt.pdf", strings.NewReader("<html><script>alert(1)</script></html>"))
+	assert.ErrorIs(t, err, financeErrors.ErrUnsupportedAttachmentType)
+
+	_, err = service.UploadAttachment(transactionID, "user-id", "receipt.pdf", strings.NewReader(""))
+	assert.ErrorIs(t, err, financeErrors.ErrEmptyAttachment)
+
+	tooLarge := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, domain.MaxAttachmentSize)...)
+	_, err = service.UploadAttachment(transactionID, "user-id", "scan.png", bytes.NewReader(tooLarge))
+	assert.ErrorIs(t, err, financeErrors.ErrAttachmentTooLarge)
+
+	attachments, err := service.GetTransactionAttachments(transactionID, "user-id")
+	assert.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestAttachments_RemovedWithTransaction(t *testing.T) {
+	service, transactionService, transactionRepo := newAttachmentTestServices(t)
+
+	attachment, err := service.UploadAttachment(transactionID, "user-id", "warranty.png", strings.NewReader("\x89PNG\r\n\x1a\nwarranty"))
+	assert.NoError(t, err)
+	transactionRepo.Transactions[0].Attachments = []domain.Attachment{*attachment}
+
+	assert.NoError(t, transactionService.DeleteTransaction(transactionID, "user-id"))
+	_, err = service.storage.Get(attachment.StorageKey)
+	assert.Error(t, err)
+}
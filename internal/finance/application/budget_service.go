@@ -0,0 +1,307 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	emailService "github.com/sebuszqo/FinanceManager/internal/email"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/sebuszqo/FinanceManager/internal/user"
+	"log"
+	"time"
+)
+
+// maxRolloverPeriods limits how many previous periods are looked at when carrying over unspent amounts.
+const maxRolloverPeriods = 12
+
+type CategorySummaryProvider interface {
+	GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error)
+}
+
+type UserProvider interface {
+	GetUserByID(userID string) (*user.User, error)
+}
+
+type BudgetService struct {
+	repo            domain.BudgetRepository
+	summaryProvider CategorySummaryProvider
+	categoryService CategoryServiceInterface
+	userProvider    UserProvider
+	emailSender     emailService.EmailSender
+	now             func() time.Time
+}
+
+func NewBudgetService(repo domain.BudgetRepository, summaryProvider CategorySummaryProvider, categoryService CategoryServiceInterface, userProvider UserProvider, emailSender emailService.EmailSender) *BudgetService {
+	return &BudgetService{
+		repo:            repo,
+		summaryProvider: summaryProvider,
+		categoryService: categoryService,
+		userProvider:    userProvider,
+		emailSender:     emailSender,
+		now:             time.Now,
+	}
+}
+
+func (s *BudgetService) GetBudget(budgetID int, userID string) (*domain.Budget, error) {
+	budget, err := s.repo.FindByID(budgetID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrBudgetNotFound
+		}
+		return nil, err
+	}
+	return budget, nil
+}
+
+func (s *BudgetService) CreateBudget(budget *domain.Budget) error {
+	budget.ID = 0
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+	if err := s.repo.Create(budget); err != nil {
+		return err
+	}
+	stored, err := s.GetBudget(budget.ID, budget.UserID)
+	if err != nil {
+		return err
+	}
+	*budget = *stored
+	return nil
+}
+
+func (s *BudgetService) UpdateBudget(budget *domain.Budget) error {
+	if _, err := s.GetBudget(budget.ID, budget.UserID); err != nil {
+		return err
+	}
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*budget)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrBudgetNotFound
+	}
+	stored, err := s.GetBudget(budget.ID, budget.UserID)
+	if err != nil {
+		return err
+	}
+	*budget = *stored
+	return nil
+}
+
+func (s *BudgetService) DeleteBudget(budgetID int, userID string) error {
+	affected, err := s.repo.Delete(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (s *BudgetService) validateBudget(budget *domain.Budget) error {
+	if budget.StartDate.IsZero() {
+		budget.StartDate = s.now()
+	}
+	budget.RoundToMinorUnits()
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	// budgets always start at the beginning of a period, so every period has the full limit
+	budget.StartDate, _ = budget.PeriodContaining(budget.StartDate)
+
+	if budget.PredefinedCategoryID != nil {
+		exists, err := s.categoryService.DoesPredefinedCategoryExist(*budget.PredefinedCategoryID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPredefinedCategory
+		}
+	}
+	if budget.UserCategoryID != nil {
+		exists, err := s.categoryService.DoesUserCategoryExist(*budget.UserCategoryID, budget.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidUserCategory
+		}
+	}
+
+	exists, err := s.repo.DoesBudgetExist(*budget)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return financeErrors.ErrBudgetAlreadyExists
+	}
+	return nil
+}
+
+// GetUserBudgetsProgress returns spent vs. limit of every budget of the user in the period containing date.
+func (s *BudgetService) GetUserBudgetsProgress(userID string, date time.Time) ([]domain.BudgetProgress, error) {
+	budgets, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]domain.BudgetProgress, 0, len(budgets))
+	for _, budget := range budgets {
+		budgetProgress, err := s.progress(budget, date)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, budgetProgress)
+	}
+	return progress, nil
+}
+
+func (s *BudgetService) GetBudgetProgress(budgetID int, userID string, date time.Time) (*domain.BudgetProgress, error) {
+	budget, err := s.GetBudget(budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.progress(*budget, date)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (s *BudgetService) progress(budget domain.Budget, date time.Time) (domain.BudgetProgress, error) {
+	periodStart, periodEnd := budget.PeriodContaining(date)
+
+	rolledOver := money.Zero
+	if budget.Rollover {
+		var err error
+		rolledOver, err = s.rolledOver(budget, periodStart)
+		if err != nil {
+			return domain.BudgetProgress{}, err
+		}
+	}
+
+	spent, err := s.spent(budget, periodStart, periodEnd)
+	if err != nil {
+		return domain.BudgetProgress{}, err
+	}
+	return domain.NewBudgetProgress(budget, periodStart, periodEnd, rolledOver, spent), nil
+}
+
+// rolledOver sums up what was left unspent in the periods before periodStart. Overspending a period uses up
+// the carried amount, but never reduces the limit of the next period below the budget amount.
+func (s *BudgetService) rolledOver(budget domain.Budget, periodStart time.Time) (money.Decimal, error) {
+	var periods [][2]time.Time
+	for end := periodStart.AddDate(0, 0, -1); !end.Before(budget.StartDate) && len(periods) < maxRolloverPeriods; {
+		start, _ := budget.PeriodContaining(end)
+		periods = append(periods, [2]time.Time{start, end})
+		end = start.AddDate(0, 0, -1)
+	}
+
+	carried := money.Zero
+	for i := len(periods) - 1; i >= 0; i-- {
+		spent, err := s.spent(budget, periods[i][0], periods[i][1])
+		if err != nil {
+			return money.Zero, err
+		}
+		if carried = budget.Amount.Add(carried).Sub(spent); carried.IsNegative() {
+			carried = money.Zero
+		}
+	}
+	return carried, nil
+}
+
+func (s *BudgetService) spent(budget domain.Budget, periodStart, periodEnd time.Time) (money.Decimal, error) {
+	summaries, err := s.summaryProvider.GetTransactionSummaryByCategory(budget.UserID, periodStart, periodEnd, string(domain.TransactionTypeExpense))
+	if err != nil {
+		return money.Zero, err
+	}
+	return budget.Spent(summaries), nil
+}
+
+// CheckBudgetAlerts queues an email for every budget whose current period crossed one of the alert thresholds
+// because of the given transactions. Each threshold is reported at most once per period, errors are only logged
+// as the transactions have already been saved.
+func (s *BudgetService) CheckBudgetAlerts(userID string, transactions []domain.PersonalTransaction) {
+	budgets, err := s.repo.FindByUser(userID)
+	if err != nil {
+		log.Printf("Error loading budgets of user %s: %v", userID, err)
+		return
+	}
+
+	today := s.now()
+	for _, budget := range budgets {
+		periodStart, periodEnd := budget.PeriodContaining(today)
+		if !affectsBudget(budget, transactions, periodStart, periodEnd) {
+			continue
+		}
+
+		progress, err := s.progress(budget, today)
+		if err != nil {
+			log.Printf("Error computing progress of budget %d: %v", budget.ID, err)
+			continue
+		}
+
+		crossed := 0
+		for _, threshold := range domain.BudgetAlertThresholds {
+			if progress.PercentUsed < float64(threshold) {
+				break
+			}
+			recorded, err := s.repo.RecordAlert(budget.ID, progress.PeriodStart, threshold)
+			if err != nil {
+				log.Printf("Error recording alert of budget %d: %v", budget.ID, err)
+				break
+			}
+			if recorded {
+				crossed = threshold
+			}
+		}
+		if crossed > 0 {
+			s.sendBudgetAlert(userID, progress, crossed)
+		}
+	}
+}
+
+func affectsBudget(budget domain.Budget, transactions []domain.PersonalTransaction, periodStart, periodEnd time.Time) bool {
+	for _, transaction := range transactions {
+		if transaction.Type != string(domain.TransactionTypeExpense) || transaction.Date.Before(periodStart) || !transaction.Date.Before(periodEnd.AddDate(0, 0, 1)) {
+			continue
+		}
+		for _, line := range transaction.Lines() {
+			if budget.UserCategoryID != nil {
+				if line.UserCategoryID != nil && *line.UserCategoryID == *budget.UserCategoryID {
+					return true
+				}
+				continue
+			}
+			// amounts of user categories are rolled up into their parent category, which may differ from the predefined one
+			if line.PredefinedCategoryID == *budget.PredefinedCategoryID || line.UserCategoryID != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *BudgetService) sendBudgetAlert(userID string, progress domain.BudgetProgress, threshold int) {
+	recipient, err := s.userProvider.GetUserByID(userID)
+	if err != nil {
+		log.Printf("Error loading user %s for budget alert: %v", userID, err)
+		return
+	}
+
+	s.emailSender.QueueEmail(recipient.Email, emailService.BudgetAlertData{
+		UserName:     recipient.Login,
+		CategoryName: progress.Budget.CategoryName,
+		Threshold:    threshold,
+		Spent:        progress.Spent.StringFixed(2),
+		Limit:        progress.Limit.StringFixed(2),
+		PeriodStart:  progress.PeriodStart.Format("2006-01-02"),
+		PeriodEnd:    progress.PeriodEnd.Format("2006-01-02"),
+	})
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	emailService "github.com/sebuszqo/FinanceManager/internal/email"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/sebuszqo/FinanceManager/internal/user"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type queuedEmails struct {
+	emails []emailService.EmailData
+}
+
+func (q *queuedEmails) QueueEmail(to string, data emailService.EmailData) {
+	q.emails = append(q.emails, data)
+}
+
+type staticUserProvider struct{}
+
+func (staticUserProvider) GetUserByID(userID string) (*user.User, error) {
+	return &user.User{ID: userID, Email: "user@example.com", Login: "user"}, nil
+}
+
+func newTestBudgetService(transactions *infrastructure.MockTransactionRepository, budgets *infrastructure.MockBudgetRepository, emails *queuedEmails, now time.Time) *BudgetService {
+	service := NewBudgetService(budgets, transactions, &MockCategoryService{}, staticUserProvider{}, emails)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestBudgetProgress_RollsOverUnspentAmount(t *testing.T) {
+	groceries := 9
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{UserID: "user-id", Type: "expense", PredefinedCategoryID: groceries, Amount: money.MustParse("300"), Date: time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)},
+		{UserID: "user-id", Type: "expense", PredefinedCategoryID: groceries, Amount: money.MustParse("600"), Date: time.Date(2024, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		{UserID: "user-id", Type: "expense", PredefinedCategoryID: groceries, Amount: money.MustParse("150"), Date: time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC)},
+	}}
+	budgets := &infrastructure.MockBudgetRepository{}
+	service := newTestBudgetService(transactions, budgets, &queuedEmails{}, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC))
+
+	budget := &domain.Budget{UserID: "user-id", PredefinedCategoryID: &groceries, Period: domain.BudgetPeriodMonthly, Amount: money.MustParse("500"), Rollover: true,
+		StartDate: time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, service.CreateBudget(budget))
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), budget.StartDate)
+
+	progress, err := service.GetBudgetProgress(budget.ID, "user-id", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	// January leaves 200, February overspends by 100 and uses it up to 100, so March starts with 600
+	assert.Equal(t, money.MustParse("100"), progress.RolledOver)
+	assert.Equal(t, money.MustParse("600"), progress.Limit)
+	assert.Equal(t, money.MustParse("150"), progress.Spent)
+	assert.Equal(t, money.MustParse("450"), progress.Remaining)
+	assert.Equal(t, 25.0, progress.PercentUsed)
+}
+
+func TestCheckBudgetAlerts_SendsEachThresholdOncePerPeriod(t *testing.T) {
+	eatingOut := 3
+	now := time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC)
+	transactions := &infrastructure.MockTransactionRepository{}
+	budgets := &infrastructure.MockBudgetRepository{Budgets: []domain.Budget{
+		{ID: 1, UserID: "user-id", PredefinedCategoryID: &eatingOut, CategoryName: "EatingOut", Period: domain.BudgetPeriodMonthly, Amount: money.MustParse("100"),
+			StartDate: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	emails := &queuedEmails{}
+	service := newTestBudgetService(transactions, budgets, emails, now)
+
+	spend := func(amount string) {
+		transaction := domain.PersonalTransaction{UserID: "user-id", Type: "expense", PredefinedCategoryID: eatingOut, Amount: money.MustParse(amount), Date: now}
+		transactions.Transactions = append(transactions.Transactions, transaction)
+		service.CheckBudgetAlerts("user-id", []domain.PersonalTransaction{transaction})
+	}
+
+	spend("50")
+	assert.Empty(t, emails.emails)
+
+	spend("35")
+	spend("5")
+	assert.Len(t, emails.emails, 1)
+	assert.Equal(t, 80, emails.emails[0].(emailService.BudgetAlertData).Threshold)
+
+	spend("20")
+	assert.Len(t, emails.emails, 2)
+	alert := emails.emails[1].(emailService.BudgetAlertData)
+	assert.Equal(t, 100, alert.Threshold)
+	assert.Equal(t, "110.00", alert.Spent)
+	assert.Equal(t, "100.00", alert.Limit)
+}
+
+func TestCheckBudgetAlerts_AttributesSplitLines(t *testing.T) {
+	groceries, household := 9, 12
+	now := time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC)
+	transaction := domain.PersonalTransaction{UserID: "user-id", Type: "expense", PredefinedCategoryID: groceries, Amount: money.MustParse("150"), Date: now,
+		Splits: []domain.TransactionSplit{
+			{PredefinedCategoryID: groceries, Amount: money.MustParse("60")},
+			{PredefinedCategoryID: household, Amount: money.MustParse("90")},
+		}}
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{transaction}}
+	budgets := &infrastructure.MockBudgetRepository{Budgets: []domain.Budget{
+		{ID: 1, UserID: "user-id", PredefinedCategoryID: &household, CategoryName: "Household", Period: domain.BudgetPeriodMonthly, Amount: money.MustParse("100"),
+			StartDate: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	emails := &queuedEmails{}
+	service := newTestBudgetService(transactions, budgets, emails, now)
+
+	service.CheckBudgetAlerts("user-id", []domain.PersonalTransaction{transaction})
+	assert.Len(t, emails.emails, 1)
+	alert := emails.emails[0].(emailService.BudgetAlertData)
+	assert.Equal(t, 80, alert.Threshold)
+	assert.Equal(t, "90.00", alert.Spent)
+}
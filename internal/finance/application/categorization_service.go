@@ -0,0 +1,226 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+// ruleSuggestionMinOccurrences is how many consistently categorized transactions with the same name make a suggestion.
+const ruleSuggestionMinOccurrences = 3
+
+// ruleSuggestionHistoryMonths is how far back the transactions are looked at when suggesting rules.
+const ruleSuggestionHistoryMonths = 12
+
+type CategorizationService struct {
+	repo            domain.CategorizationRuleRepository
+	transactionRepo domain.PersonalTransactionRepository
+	categoryService CategoryServiceInterface
+	paymentService  PaymentServiceInterface
+	tagProvider     TagProvider
+	now             func() time.Time
+}
+
+func NewCategorizationService(repo domain.CategorizationRuleRepository, transactionRepo domain.PersonalTransactionRepository, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface, tagProvider TagProvider) *CategorizationService {
+	return &CategorizationService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		categoryService: categoryService,
+		paymentService:  paymentService,
+		tagProvider:     tagProvider,
+		now:             time.Now,
+	}
+}
+
+// GetUserRules returns the rules of the user in the order they are evaluated in.
+func (s *CategorizationService) GetUserRules(userID string) ([]domain.CategorizationRule, error) {
+	rules, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return []domain.CategorizationRule{}, nil
+	}
+	return rules, nil
+}
+
+func (s *CategorizationService) GetRule(ruleID int, userID string) (*domain.CategorizationRule, error) {
+	rule, err := s.repo.FindByID(ruleID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrCategorizationRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *CategorizationService) CreateRule(rule *domain.CategorizationRule) error {
+	rule.ID = 0
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+	rules, err := s.repo.FindByUser(rule.UserID)
+	if err != nil {
+		return err
+	}
+	if len(rules) >= domain.MaxCategorizationRules {
+		return financeErrors.ErrTooManyCategorizationRules
+	}
+	return s.repo.Create(rule)
+}
+
+func (s *CategorizationService) UpdateRule(rule *domain.CategorizationRule) error {
+	if _, err := s.GetRule(rule.ID, rule.UserID); err != nil {
+		return err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*rule)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrCategorizationRuleNotFound
+	}
+	return nil
+}
+
+func (s *CategorizationService) DeleteRule(ruleID int, userID string) error {
+	affected, err := s.repo.Delete(ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrCategorizationRuleNotFound
+	}
+	return nil
+}
+
+func (s *CategorizationService) validateRule(rule *domain.CategorizationRule) error {
+	for _, amount := range []*money.Decimal{rule.MinAmount, rule.MaxAmount} {
+		if amount != nil {
+			*amount = amount.RoundToCurrency(money.DefaultCurrency)
+		}
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if rule.PredefinedCategoryID != nil {
+		exists, err := s.categoryService.DoesPredefinedCategoryExist(*rule.PredefinedCategoryID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPredefinedCategory
+		}
+	}
+	if rule.UserCategoryID != nil {
+		exists, err := s.categoryService.DoesUserCategoryExist(*rule.UserCategoryID, rule.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidUserCategory
+		}
+	}
+	if rule.PaymentMethodID != nil {
+		exists, err := s.paymentService.DoesPaymentMethodExistByID(*rule.PaymentMethodID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPaymentMethod
+		}
+	}
+	if len(rule.TagIDs) > 0 {
+		userTags, err := userTagIDs(s.tagProvider, rule.UserID)
+		if err != nil {
+			return err
+		}
+		if err := checkTags(rule.TagIDs, userTags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Categorize applies the first matching rule of the user to each of the transactions. The returned slice holds the
+// applied rule of every transaction, nil where no rule matched.
+func (s *CategorizationService) Categorize(userID string, transactions []*domain.PersonalTransaction) ([]*domain.CategorizationRule, error) {
+	rules, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]*domain.CategorizationRule, len(transactions))
+	if len(rules) == 0 {
+		return applied, nil
+	}
+	for i, transaction := range transactions {
+		applied[i] = domain.ApplyFirstMatchingRule(rules, transaction)
+	}
+	return applied, nil
+}
+
+// SuggestRules learns rules from the transactions of the last year which the current rules don't cover yet.
+func (s *CategorizationService) SuggestRules(userID string) ([]domain.RuleSuggestion, error) {
+	rules, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	history, err := s.transactionRepo.GetTransactionsInDateRange(userID, now.AddDate(0, -ruleSuggestionHistoryMonths, 0), now)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := domain.SuggestRules(history, rules, ruleSuggestionMinOccurrences)
+	if suggestions == nil {
+		return []domain.RuleSuggestion{}, nil
+	}
+	return suggestions, nil
+}
+
+// ReapplyRules runs the rules of the user over the transactions between startDate and endDate and returns how many
+// of them changed. Reconciled transactions are left as they are.
+func (s *CategorizationService) ReapplyRules(userID string, startDate, endDate time.Time) (int, error) {
+	rules, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+	transactions, err := s.transactionRepo.GetTransactionsInDateRange(userID, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, transaction := range transactions {
+		if transaction.IsReconciled() {
+			continue
+		}
+		for i := range rules {
+			if !rules[i].Matches(&transaction) {
+				continue
+			}
+			if rules[i].Apply(&transaction) {
+				affected, err := s.transactionRepo.Update(transaction)
+				if err != nil {
+					return updated, err
+				}
+				updated += int(affected)
+			}
+			break
+		}
+	}
+	return updated, nil
+}
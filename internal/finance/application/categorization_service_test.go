@@ -0,0 +1,53 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReapplyRules_SkipsReconciledAndUnchangedTransactions(t *testing.T) {
+	date := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	reconciliation := 1
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "uber", UserID: "user-id", Name: "UBER *TRIP", Date: date.AddDate(0, 0, 1), Type: "expense", Amount: money.MustParse("32"), PredefinedCategoryID: 1, PaymentMethodID: 1},
+		{ID: "uber-reconciled", UserID: "user-id", Name: "UBER *TRIP", Date: date.AddDate(0, 0, 2), Type: "expense", Amount: money.MustParse("18"), PredefinedCategoryID: 1, PaymentMethodID: 1, ReconciliationID: &reconciliation},
+		{ID: "uber-done", UserID: "user-id", Name: "Uber trip", Date: date.AddDate(0, 0, 3), Type: "expense", Amount: money.MustParse("25"), PredefinedCategoryID: 11, PaymentMethodID: 1},
+		{ID: "lunch", UserID: "user-id", Name: "Lunch", Date: date.AddDate(0, 0, 4), Type: "expense", Amount: money.MustParse("40"), PredefinedCategoryID: 1, PaymentMethodID: 1},
+	}}
+	service := NewCategorizationService(&infrastructure.MockCategorizationRuleRepository{}, transactions, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+
+	transport := 11
+	rule := &domain.CategorizationRule{UserID: "user-id", Name: "Taxi", MatchField: domain.RuleMatchName, MatchType: domain.RuleMatchRegex, Pattern: `(?i)\buber\b`, PredefinedCategoryID: &transport}
+	assert.NoError(t, service.CreateRule(rule))
+
+	updated, err := service.ReapplyRules("user-id", date, date.AddDate(0, 1, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, transport, transactions.Transactions[0].PredefinedCategoryID)
+	assert.Equal(t, 1, transactions.Transactions[1].PredefinedCategoryID)
+	assert.Equal(t, 1, transactions.Transactions[3].PredefinedCategoryID)
+}
+
+func TestCategorize_ReportsAppliedRules(t *testing.T) {
+	groceries, bills := 9, 17
+	repo := &infrastructure.MockCategorizationRuleRepository{Rules: []domain.CategorizationRule{
+		{ID: 1, UserID: "user-id", Name: "Energy", Priority: 2, MatchField: domain.RuleMatchName, MatchType: domain.RuleMatchContains, Pattern: "tauron", PredefinedCategoryID: &bills},
+		{ID: 2, UserID: "user-id", Name: "Shops", Priority: 1, MatchField: domain.RuleMatchName, MatchType: domain.RuleMatchContains, Pattern: "a", PredefinedCategoryID: &groceries},
+	}}
+	service := NewCategorizationService(repo, &infrastructure.MockTransactionRepository{}, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+
+	imported := []*domain.PersonalTransaction{
+		{Name: "Tauron Sprzedaz", Type: "expense", Amount: money.MustParse("120"), PredefinedCategoryID: 1},
+		{Name: "Kiosk", Type: "expense", Amount: money.MustParse("5"), PredefinedCategoryID: 1},
+	}
+	applied, err := service.Categorize("user-id", imported)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, applied[0].ID, "the rule with the lower priority is evaluated first")
+	assert.Nil(t, applied[1])
+	assert.Equal(t, groceries, imported[0].PredefinedCategoryID)
+	assert.Equal(t, 1, imported[1].PredefinedCategoryID)
+}
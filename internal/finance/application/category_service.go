@@ -1,6 +1,11 @@
 package application
 
-import "github.com/sebuszqo/FinanceManager/internal/finance/domain"
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
 
 type CategoryService struct {
 	repo domain.CategoryRepository
@@ -23,5 +28,88 @@ func (s *CategoryService) GetAllPredefinedCategories(categoryType string) ([]dom
 }
 
 func (s *CategoryService) GetAllUserCategories(userID string) ([]domain.UserCategory, error) {
-	return s.repo.FindUserCategories(userID)
+	categories, err := s.repo.FindUserCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		return []domain.UserCategory{}, nil
+	}
+	return categories, nil
+}
+
+func (s *CategoryService) GetUserCategory(categoryID int, userID string) (*domain.UserCategory, error) {
+	category, err := s.repo.FindUserCategoryByID(categoryID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrUserCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *CategoryService) CreateUserCategory(category *domain.UserCategory) error {
+	if err := s.validateUserCategory(category, 0); err != nil {
+		return err
+	}
+	return s.repo.CreateUserCategory(category)
+}
+
+func (s *CategoryService) UpdateUserCategory(category *domain.UserCategory) error {
+	if _, err := s.GetUserCategory(category.ID, category.UserID); err != nil {
+		return err
+	}
+	if err := s.validateUserCategory(category, category.ID); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.UpdateUserCategory(*category)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrUserCategoryNotFound
+	}
+	return nil
+}
+
+func (s *CategoryService) DeleteUserCategory(categoryID int, userID string) error {
+	affected, err := s.repo.DeleteUserCategory(categoryID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrUserCategoryNotFound
+	}
+	return nil
+}
+
+// validateUserCategory validates the fields, the parent category and name uniqueness (ignoring the category with excludedID).
+func (s *CategoryService) validateUserCategory(category *domain.UserCategory, excludedID int) error {
+	if err := category.Validate(); err != nil {
+		return err
+	}
+
+	if category.ParentCategoryID != nil {
+		parent, err := s.repo.FindPredefinedCategoryByID(*category.ParentCategoryID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return financeErrors.ErrInvalidPredefinedCategory
+			}
+			return err
+		}
+		if parent.Type != category.Type {
+			return financeErrors.ErrParentCategoryTypeMismatch
+		}
+	}
+
+	taken, err := s.repo.DoesUserCategoryNameExist(category.Name, category.UserID, excludedID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return financeErrors.ErrUserCategoryNameTaken
+	}
+	return nil
 }
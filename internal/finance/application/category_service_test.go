@@ -0,0 +1,97 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newCategoryRepository() *infrastructure.MockCategoryRepository {
+	return &infrastructure.MockCategoryRepository{
+		PredefinedCategories: []domain.PredefinedCategory{
+			{ID: 1, Name: "Salary", Type: "income"},
+			{ID: 9, Name: "Groceries", Type: "expense"},
+		},
+		UserCategories: []domain.UserCategory{
+			{ID: 1, Name: "Organic", UserID: "user-id", Type: "expense"},
+			{ID: 2, Name: "Discounter", UserID: "user-id", Type: "expense"},
+			{ID: 3, Name: "Bakery", UserID: "other-user-id", Type: "expense"},
+		},
+	}
+}
+
+func TestCategoryService_CreateUserCategory(t *testing.T) {
+	parent := func(id int) *int { return &id }
+
+	t.Run("Creates category under a parent of the same type", func(t *testing.T) {
+		repo := newCategoryRepository()
+		category := &domain.UserCategory{Name: "Bakery", UserID: "user-id", Type: "expense", ParentCategoryID: parent(9)}
+
+		assert.NoError(t, NewCategoryService(repo).CreateUserCategory(category))
+		assert.Equal(t, 4, category.ID)
+		assert.Len(t, repo.UserCategories, 4)
+	})
+
+	tests := []struct {
+		name     string
+		category domain.UserCategory
+		err      error
+	}{
+		{"Parent of the other type", domain.UserCategory{Name: "Bonus", Type: "expense", ParentCategoryID: parent(1)}, financeErrors.ErrParentCategoryTypeMismatch},
+		{"Unknown parent", domain.UserCategory{Name: "Bonus", Type: "expense", ParentCategoryID: parent(99)}, financeErrors.ErrInvalidPredefinedCategory},
+		{"Name already used by the user", domain.UserCategory{Name: "Organic", Type: "expense"}, financeErrors.ErrUserCategoryNameTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newCategoryRepository()
+			tt.category.UserID = "user-id"
+
+			assert.ErrorIs(t, NewCategoryService(repo).CreateUserCategory(&tt.category), tt.err)
+			assert.Len(t, repo.UserCategories, 3)
+		})
+	}
+
+	t.Run("Name used by another user", func(t *testing.T) {
+		category := &domain.UserCategory{Name: "Bakery", UserID: "user-id", Type: "expense"}
+		assert.NoError(t, NewCategoryService(newCategoryRepository()).CreateUserCategory(category))
+	})
+
+	t.Run("Invalid color", func(t *testing.T) {
+		color := "green"
+		category := &domain.UserCategory{Name: "Market", UserID: "user-id", Type: "expense", Color: &color}
+		err := NewCategoryService(newCategoryRepository()).CreateUserCategory(category)
+		assert.True(t, financeErrors.IsValidationError(err))
+	})
+}
+
+func TestCategoryService_UpdateUserCategory(t *testing.T) {
+	repo := newCategoryRepository()
+	service := NewCategoryService(repo)
+
+	// keeping its own name is not a conflict
+	assert.NoError(t, service.UpdateUserCategory(&domain.UserCategory{ID: 1, Name: "Organic", UserID: "user-id", Type: "expense"}))
+	assert.ErrorIs(t, service.UpdateUserCategory(&domain.UserCategory{ID: 1, Name: "Discounter", UserID: "user-id", Type: "expense"}), financeErrors.ErrUserCategoryNameTaken)
+	assert.ErrorIs(t, service.UpdateUserCategory(&domain.UserCategory{ID: 3, Name: "Rolls", UserID: "user-id", Type: "expense"}), financeErrors.ErrUserCategoryNotFound)
+	assert.Equal(t, "Bakery", repo.UserCategories[2].Name)
+}
+
+func TestCategoryService_DeleteAndGetUserCategories(t *testing.T) {
+	repo := newCategoryRepository()
+	service := NewCategoryService(repo)
+
+	assert.ErrorIs(t, service.DeleteUserCategory(3, "user-id"), financeErrors.ErrUserCategoryNotFound)
+	assert.NoError(t, service.DeleteUserCategory(1, "user-id"))
+
+	categories, err := service.GetAllUserCategories("user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.UserCategory{{ID: 2, Name: "Discounter", UserID: "user-id", Type: "expense"}}, categories)
+
+	_, err = service.GetUserCategory(1, "user-id")
+	assert.ErrorIs(t, err, financeErrors.ErrUserCategoryNotFound)
+
+	categories, err = service.GetAllUserCategories("new-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.UserCategory{}, categories)
+}
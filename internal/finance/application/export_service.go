@@ -0,0 +1,429 @@
+package application
+
+import (
+	"encoding/csv"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cashPaymentMethod = "Cash"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// PaymentSourceLister lists archived payment sources as well, older transactions still reference them.
+type PaymentSourceLister interface {
+	GetAllPaymentMethods() ([]domain.PaymentMethod, error)
+	ListUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error)
+}
+
+// exportedTransaction is a transaction with the names of the categories, payment method and source it references.
+type exportedTransaction struct {
+	domain.PersonalTransaction
+	CategoryName      string
+	UserCategoryName  string
+	PaymentMethodName string
+	PaymentSourceName string
+	// DestinationPaymentSourceName is only set for transfers.
+	DestinationPaymentSourceName string
+}
+
+func (t exportedTransaction) description() string {
+	if t.Description == nil {
+		return ""
+	}
+	return *t.Description
+}
+
+// exportAccount groups transactions by payment source, which desktop tools see as accounts. Transactions without
+// a payment source are grouped under the name of their payment method.
+type exportAccount struct {
+	Name         string
+	Cash         bool
+	Transactions []exportedTransaction
+}
+
+// ExportOptions selects the transactions to export.
+type ExportOptions struct {
+	Format domain.StatementFormat
+	UserID string
+	// Search holds the same filters as the transactions list, its sort and paging are ignored.
+	Search domain.TransactionSearch
+	// Currency is written to OFX and QFX statements, which require one.
+	Currency string
+	// IncludeSummary adds the year, month and week totals as a second sheet of XLSX exports.
+	IncludeSummary bool
+}
+
+type TransactionSummaryProvider interface {
+	GetFilteredTransactionSummary(userID string, search domain.TransactionSearch) (*domain.TransactionSummary, error)
+}
+
+type ExportService struct {
+	transactionRepo domain.PersonalTransactionRepository
+	categoryService CategoryServiceInterface
+	paymentService  PaymentSourceLister
+	summaryProvider TransactionSummaryProvider
+}
+
+func NewExportService(transactionRepo domain.PersonalTransactionRepository, categoryService CategoryServiceInterface, paymentService PaymentSourceLister, summaryProvider TransactionSummaryProvider) *ExportService {
+	return &ExportService{
+		transactionRepo: transactionRepo,
+		categoryService: categoryService,
+		paymentService:  paymentService,
+		summaryProvider: summaryProvider,
+	}
+}
+
+// IsSupportedExportFormat reports whether transactions can be exported in the format.
+func (s *ExportService) IsSupportedExportFormat(format domain.StatementFormat) bool {
+	switch format {
+	case domain.StatementFormatOFX, domain.StatementFormatQFX, domain.StatementFormatQIF, domain.StatementFormatCSV, domain.StatementFormatXLSX:
+		return true
+	}
+	return false
+}
+
+// Export writes the transactions selected by the options to w. Nothing is written to w if the options are invalid
+// or the names of categories and payment methods can't be loaded. CSV and XLSX rows are streamed from the database,
+// with a row per split line.
+func (s *ExportService) Export(w io.Writer, options ExportOptions) error {
+	if err := s.validateOptions(options); err != nil {
+		return err
+	}
+	names, err := s.loadNames(options.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch options.Format {
+	case domain.StatementFormatCSV:
+		return s.exportCSV(w, options, names)
+	case domain.StatementFormatXLSX:
+		return s.exportXLSX(w, options, names)
+	}
+
+	var exported []exportedTransaction
+	err = s.transactionRepo.StreamTransactions(options.UserID, options.Search, func(transaction domain.PersonalTransaction) error {
+		exported = append(exported, names.resolve(transaction))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	accounts, err := groupByAccount(exported)
+	if err != nil {
+		return err
+	}
+
+	if options.Format == domain.StatementFormatQIF {
+		return writeQIFStatement(w, accounts)
+	}
+	return writeOFXStatement(w, accounts, options.Currency, options.Search.StartDate, options.Search.EndDate)
+}
+
+func (s *ExportService) validateOptions(options ExportOptions) error {
+	if !s.IsSupportedExportFormat(options.Format) {
+		return financeErrors.ErrUnsupportedStatementFormat
+	}
+	if err := options.Search.Validate(); err != nil {
+		return err
+	}
+	if (options.Format == domain.StatementFormatOFX || options.Format == domain.StatementFormatQFX) && !currencyPattern.MatchString(options.Currency) {
+		return financeErrors.NewValidationError("Currency must be a three letter ISO 4217 code like PLN")
+	}
+	if options.IncludeSummary && options.Format != domain.StatementFormatXLSX {
+		return financeErrors.NewValidationError("Summary sheet is only available for xlsx exports")
+	}
+	if options.IncludeSummary && options.Search.Type == string(domain.TransactionTypeTransfer) {
+		return financeErrors.NewValidationError("Summary sheet only covers income and expenses")
+	}
+	return nil
+}
+
+// exportNames maps the IDs referenced by transactions to names, archived payment sources included.
+type exportNames struct {
+	categories     map[int]string
+	userCategories map[int]string
+	paymentMethods map[int]string
+	paymentSources map[int]string
+}
+
+func (s *ExportService) loadNames(userID string) (*exportNames, error) {
+	predefinedCategories, err := s.categoryService.GetAllPredefinedCategories("")
+	if err != nil {
+		return nil, err
+	}
+	userCategories, err := s.categoryService.GetAllUserCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	paymentMethods, err := s.paymentService.GetAllPaymentMethods()
+	if err != nil {
+		return nil, err
+	}
+	paymentSources, err := s.paymentService.ListUserPaymentSources(userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	names := &exportNames{
+		categories:     make(map[int]string, len(predefinedCategories)),
+		userCategories: make(map[int]string, len(userCategories)),
+		paymentMethods: make(map[int]string, len(paymentMethods)),
+		paymentSources: make(map[int]string, len(paymentSources)),
+	}
+	for _, category := range predefinedCategories {
+		names.categories[category.ID] = category.Name
+	}
+	for _, category := range userCategories {
+		names.userCategories[category.ID] = category.Name
+	}
+	for _, method := range paymentMethods {
+		names.paymentMethods[method.ID] = method.Name
+	}
+	for _, source := range paymentSources {
+		names.paymentSources[source.ID] = source.Name
+	}
+	return names, nil
+}
+
+func (n *exportNames) resolve(transaction domain.PersonalTransaction) exportedTransaction {
+	exported := exportedTransaction{
+		PersonalTransaction: transaction,
+		CategoryName:        n.categories[transaction.PredefinedCategoryID],
+		PaymentMethodName:   n.paymentMethods[transaction.PaymentMethodID],
+	}
+	if transaction.UserCategoryID != nil {
+		exported.UserCategoryName = n.userCategories[*transaction.UserCategoryID]
+	}
+	if transaction.PaymentSourceID != nil {
+		exported.PaymentSourceName = n.paymentSources[*transaction.PaymentSourceID]
+	}
+	if transaction.DestinationPaymentSourceID != nil {
+		exported.DestinationPaymentSourceName = n.paymentSources[*transaction.DestinationPaymentSourceID]
+	}
+	return exported
+}
+
+// escapeFormula prefixes text starting like a formula with an apostrophe, so that spreadsheets show it as text
+// instead of evaluating it (CSV injection).
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// resolveLines resolves every split line of the transaction as a row of its own, with the amount, categories and
+// description of the line. Lines without a description keep the one of the transaction.
+func (n *exportNames) resolveLines(transaction domain.PersonalTransaction) []exportedTransaction {
+	if len(transaction.Splits) == 0 {
+		return []exportedTransaction{n.resolve(transaction)}
+	}
+	lines := make([]exportedTransaction, len(transaction.Splits))
+	for i, split := range transaction.Splits {
+		line := transaction
+		line.Splits = nil
+		line.Amount = split.Amount
+		line.PredefinedCategoryID = split.PredefinedCategoryID
+		line.UserCategoryID = split.UserCategoryID
+		if split.Description != nil {
+			line.Description = split.Description
+		}
+		lines[i] = n.resolve(line)
+	}
+	return lines
+}
+
+var exportColumns = []string{"Date", "Name", "Type", "Amount", "Category", "User category", "Payment method", "Payment source", "Description"}
+
+func (s *ExportService) exportCSV(w io.Writer, options ExportOptions, names *exportNames) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(exportColumns); err != nil {
+		return err
+	}
+
+	err := s.transactionRepo.StreamTransactions(options.UserID, options.Search, func(transaction domain.PersonalTransaction) error {
+		for _, exported := range names.resolveLines(transaction) {
+			err := csvWriter.Write([]string{
+				exported.Date.Format("2006-01-02"),
+				escapeFormula(exported.Name),
+				exported.Type,
+				exported.Amount.StringFixed(2),
+				escapeFormula(exported.CategoryName),
+				escapeFormula(exported.UserCategoryName),
+				escapeFormula(exported.PaymentMethodName),
+				escapeFormula(exported.PaymentSourceName),
+				escapeFormula(exported.description()),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func (s *ExportService) exportXLSX(w io.Writer, options ExportOptions, names *exportNames) error {
+	// the summary is loaded before anything is written, so its failure doesn't leave a broken file behind
+	var summary *domain.TransactionSummary
+	sheetNames := []string{"Transactions"}
+	if options.IncludeSummary {
+		var err error
+		summary, err = s.summaryProvider.GetFilteredTransactionSummary(options.UserID, options.Search)
+		if err != nil {
+			return err
+		}
+		sheetNames = append(sheetNames, "Summary")
+	}
+
+	workbook, err := newXLSXWriter(w, sheetNames...)
+	if err != nil {
+		return err
+	}
+	if err := workbook.NextSheet(); err != nil {
+		return err
+	}
+	header := make([]xlsxCell, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = xlsxHeaderText(column)
+	}
+	if err := workbook.WriteRow(header...); err != nil {
+		return err
+	}
+
+	err = s.transactionRepo.StreamTransactions(options.UserID, options.Search, func(transaction domain.PersonalTransaction) error {
+		for _, exported := range names.resolveLines(transaction) {
+			err := workbook.WriteRow(
+				xlsxDate(exported.Date),
+				xlsxText(escapeFormula(exported.Name)),
+				xlsxText(exported.Type),
+				xlsxAmount(exported.Amount),
+				xlsxText(escapeFormula(exported.CategoryName)),
+				xlsxText(escapeFormula(exported.UserCategoryName)),
+				xlsxText(escapeFormula(exported.PaymentMethodName)),
+				xlsxText(escapeFormula(exported.PaymentSourceName)),
+				xlsxText(escapeFormula(exported.description())),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if options.IncludeSummary {
+		if err := writeSummarySheet(workbook, summary); err != nil {
+			return err
+		}
+	}
+	return workbook.Close()
+}
+
+// writeSummarySheet writes one row per year, followed by its months and the weeks of each month.
+func writeSummarySheet(workbook *xlsxWriter, summary *domain.TransactionSummary) error {
+	if err := workbook.NextSheet(); err != nil {
+		return err
+	}
+	if err := workbook.WriteRow(xlsxHeaderText("Year"), xlsxHeaderText("Month"), xlsxHeaderText("Week start"),
+		xlsxHeaderText("Income"), xlsxHeaderText("Expense"), xlsxHeaderText("Net")); err != nil {
+		return err
+	}
+
+	for _, year := range summary.Years {
+		if err := workbook.WriteRow(xlsxInt(year.Year), xlsxText(""), xlsxText(""), xlsxAmount(year.IncomeTotal),
+			xlsxAmount(year.ExpenseTotal), xlsxAmount(year.IncomeTotal.Sub(year.ExpenseTotal))); err != nil {
+			return err
+		}
+
+		for _, month := range year.Months {
+			monthName := time.Month(month.Month).String()
+			if err := workbook.WriteRow(xlsxInt(year.Year), xlsxText(monthName), xlsxText(""), xlsxAmount(month.IncomeTotal),
+				xlsxAmount(month.ExpenseTotal), xlsxAmount(month.IncomeTotal.Sub(month.ExpenseTotal))); err != nil {
+				return err
+			}
+
+			for _, week := range month.Weeks {
+				if err := workbook.WriteRow(xlsxInt(year.Year), xlsxText(monthName), xlsxDate(week.StartDate), xlsxAmount(week.IncomeTotal),
+					xlsxAmount(week.ExpenseTotal), xlsxAmount(week.IncomeTotal.Sub(week.ExpenseTotal))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// groupByAccount keeps the order of transactions within an account, accounts are sorted by name. A transfer is
+// written as an expense of its source account and an income of its destination account.
+func groupByAccount(transactions []exportedTransaction) ([]exportAccount, error) {
+	transactions, err := splitTransfers(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIndexes := map[string]int{}
+	var accounts []exportAccount
+	for _, transaction := range transactions {
+		name := transaction.PaymentSourceName
+		if name == "" && transaction.PaymentSourceID != nil {
+			name = "Payment source " + strconv.Itoa(*transaction.PaymentSourceID)
+		}
+		if name == "" {
+			name = transaction.PaymentMethodName
+		}
+
+		index, ok := accountIndexes[name]
+		if !ok {
+			index = len(accounts)
+			accountIndexes[name] = index
+			accounts = append(accounts, exportAccount{
+				Name: name,
+				Cash: transaction.PaymentSourceID == nil && transaction.PaymentMethodName == cashPaymentMethod,
+			})
+		}
+		accounts[index].Transactions = append(accounts[index].Transactions, transaction)
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts, nil
+}
+
+func splitTransfers(transactions []exportedTransaction) ([]exportedTransaction, error) {
+	result := make([]exportedTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if !transaction.IsTransfer() {
+			result = append(result, transaction)
+			continue
+		}
+		outgoing := transaction
+		outgoing.Type = string(domain.TransactionTypeExpense)
+		incoming := transaction
+		incoming.Type = string(domain.TransactionTypeIncome)
+		amount, err := transaction.DestinationAmount()
+		if err != nil {
+			return nil, err
+		}
+		incoming.Amount = amount
+		incoming.PaymentSourceID = transaction.DestinationPaymentSourceID
+		incoming.PaymentSourceName = transaction.DestinationPaymentSourceName
+		result = append(result, outgoing, incoming)
+	}
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package application
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+type staticPaymentSources struct{}
+
+func (staticPaymentSources) GetAllPaymentMethods() ([]domain.PaymentMethod, error) {
+	return []domain.PaymentMethod{{ID: 1, Name: "Payment Card"}, {ID: 5, Name: "Cash"}}, nil
+}
+
+func (staticPaymentSources) ListUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error) {
+	return []domain.PaymentSource{{ID: 3, Name: "Old card", Archived: true}}, nil
+}
+
+func newTestExportService() *ExportService {
+	description := "Weekly, \"big\" shopping"
+	repo := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "t1", UserID: "user-id", Name: "Market", Amount: money.MustParse("12.5"), Type: "expense", Date: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+			PredefinedCategoryID: 9, PaymentMethodID: 5, Description: &description},
+		{ID: "t2", UserID: "user-id", Name: "Salary", Amount: money.MustParse("5000"), Type: "income", Date: time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC),
+			PredefinedCategoryID: 29, PaymentMethodID: 1, PaymentSourceID: intPointer(3)},
+		{ID: "t3", UserID: "other-user", Name: "Hidden", Amount: money.MustParse("1"), Type: "expense", Date: time.Date(2023, time.March, 10, 0, 0, 0, 0, time.UTC),
+			PredefinedCategoryID: 9, PaymentMethodID: 1},
+	}}
+	categoryService := &MockCategoryService{}
+	return NewExportService(repo, categoryService, staticPaymentSources{}, NewPersonalTransactionService(repo, categoryService, nil, &MockTagService{}))
+}
+
+func exportOptions(format domain.StatementFormat) ExportOptions {
+	return ExportOptions{
+		Format: format,
+		UserID: "user-id",
+		Search: domain.TransactionSearch{
+			StartDate: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		Currency: "PLN",
+	}
+}
+
+func TestExportService_CSV(t *testing.T) {
+	service := newTestExportService()
+
+	var out bytes.Buffer
+	err := service.Export(&out, exportOptions(domain.StatementFormatCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, "Date,Name,Type,Amount,Category,User category,Payment method,Payment source,Description\n"+
+		"2024-03-05,Market,expense,12.50,,,Cash,,\"Weekly, \"\"big\"\" shopping\"\n"+
+		"2024-03-10,Salary,income,5000.00,,,Payment Card,Old card,\n", out.String())
+
+	options := exportOptions(domain.StatementFormatCSV)
+	options.Search.Type = "income"
+	out.Reset()
+	assert.NoError(t, service.Export(&out, options))
+	assert.NotContains(t, out.String(), "Market")
+
+	options = exportOptions(domain.StatementFormatCSV)
+	options.Search.Text = "market"
+	options.Search.PaymentMethodIDs = []int{5}
+	out.Reset()
+	assert.NoError(t, service.Export(&out, options))
+	assert.Contains(t, out.String(), "Market")
+	assert.NotContains(t, out.String(), "Salary")
+}
+
+func TestExportService_RowPerSplitLine(t *testing.T) {
+	service := newTestExportService()
+	repo := service.transactionRepo.(*infrastructure.MockTransactionRepository)
+	soap := "Soap"
+	repo.Transactions[0].Splits = []domain.TransactionSplit{
+		{PredefinedCategoryID: 9, Amount: money.MustParse("10")},
+		{PredefinedCategoryID: 12, Amount: money.MustParse("2.5"), Description: &soap},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, service.Export(&out, exportOptions(domain.StatementFormatCSV)))
+	assert.Equal(t, "Date,Name,Type,Amount,Category,User category,Payment method,Payment source,Description\n"+
+		"2024-03-05,Market,expense,10.00,,,Cash,,\"Weekly, \"\"big\"\" shopping\"\n"+
+		"2024-03-05,Market,expense,2.50,,,Cash,,Soap\n"+
+		"2024-03-10,Salary,income,5000.00,,,Payment Card,Old card,\n", out.String())
+}
+
+func TestExportService_EscapesFormulas(t *testing.T) {
+	service := newTestExportService()
+	repo := service.transactionRepo.(*infrastructure.MockTransactionRepository)
+	description := "@SUM(1+1)*cmd|' /C calc'!A0"
+	repo.Transactions[0].Name = "=HYPERLINK(\"http://example.com\")"
+	repo.Transactions[0].Description = &description
+	repo.Transactions[1].Name = "-5 refund"
+
+	var out bytes.Buffer
+	assert.NoError(t, service.Export(&out, exportOptions(domain.StatementFormatCSV)))
+	assert.Contains(t, out.String(), `"'=HYPERLINK(""http://example.com"")"`)
+	assert.Contains(t, out.String(), `'@SUM(1+1)*cmd|' /C calc'!A0`)
+	assert.Contains(t, out.String(), ",'-5 refund,income,5000.00,")
+
+	out.Reset()
+	assert.NoError(t, service.Export(&out, exportOptions(domain.StatementFormatXLSX)))
+	sheet := xlsxFile(t, out.Bytes(), "xl/worksheets/sheet1.xml")
+	assert.Contains(t, sheet, `&#39;=HYPERLINK(&#34;http://example.com&#34;)`)
+	assert.Contains(t, sheet, `&#39;-5 refund`)
+}
+
+func TestExportService_XLSXWithSummary(t *testing.T) {
+	service := newTestExportService()
+	options := exportOptions(domain.StatementFormatXLSX)
+	options.IncludeSummary = true
+
+	var out bytes.Buffer
+	assert.NoError(t, service.Export(&out, options))
+
+	assert.Contains(t, xlsxFile(t, out.Bytes(), "xl/workbook.xml"), `<sheet name="Transactions" sheetId="1" r:id="rId1"/><sheet name="Summary" sheetId="2" r:id="rId2"/>`)
+	transactions := xlsxFile(t, out.Bytes(), "xl/worksheets/sheet1.xml")
+	// 2024-03-05 is day 45356 of the 1900 date system
+	assert.Contains(t, transactions, `<c r="A2" s="1"><v>45356</v></c>`)
+	assert.Contains(t, transactions, `Weekly, &#34;big&#34; shopping`)
+	assert.Contains(t, transactions, `<c r="D3" s="2"><v>5000.00</v></c>`)
+	assert.NotContains(t, transactions, "Hidden")
+	summary := xlsxFile(t, out.Bytes(), "xl/worksheets/sheet2.xml")
+	assert.Contains(t, summary, `<row r="2"><c r="A2"><v>2024</v></c><c r="D2" s="2"><v>5000.00</v></c><c r="E2" s="2"><v>12.50</v></c><c r="F2" s="2"><v>4987.50</v></c></row>`)
+	assert.Contains(t, summary, `<t xml:space="preserve">March</t>`)
+
+	// the summary covers the same transactions as the first sheet
+	options.Search.Type = "expense"
+	out.Reset()
+	assert.NoError(t, service.Export(&out, options))
+	summary = xlsxFile(t, out.Bytes(), "xl/worksheets/sheet2.xml")
+	assert.Contains(t, summary, `<row r="2"><c r="A2"><v>2024</v></c><c r="D2" s="2"><v>0.00</v></c><c r="E2" s="2"><v>12.50</v></c><c r="F2" s="2"><v>-12.50</v></c></row>`)
+}
+
+func xlsxFile(t *testing.T, workbook []byte, name string) string {
+	archive, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	assert.NoError(t, err)
+	for _, file := range archive.File {
+		if file.Name == name {
+			reader, err := file.Open()
+			assert.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			return string(content)
+		}
+	}
+	t.Fatalf("%s not found in the workbook", name)
+	return ""
+}
+
+func TestExportService_InvalidOptions(t *testing.T) {
+	service := newTestExportService()
+
+	options := exportOptions(domain.StatementFormatCSV)
+	options.IncludeSummary = true
+	var out bytes.Buffer
+	assert.Error(t, service.Export(&out, options))
+
+	options = exportOptions(domain.StatementFormatOFX)
+	options.Currency = "zloty"
+	assert.Error(t, service.Export(&out, options))
+
+	options = exportOptions(domain.StatementFormatXLSX)
+	options.IncludeSummary = true
+	options.Search.Type = "transfer"
+	assert.Error(t, service.Export(&out, options))
+	assert.Zero(t, out.Len())
+}
+
+func TestXLSXColumn(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumn(0))
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AA", xlsxColumn(26))
+	assert.Equal(t, "AZ", xlsxColumn(51))
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type FinanceSettingsService struct {
+	repo domain.FinanceSettingsRepository
+}
+
+func NewFinanceSettingsService(repo domain.FinanceSettingsRepository) *FinanceSettingsService {
+	return &FinanceSettingsService{repo: repo}
+}
+
+// GetSettings returns the settings of the user, the defaults until they change them.
+func (s *FinanceSettingsService) GetSettings(userID string) (*domain.FinanceSettings, error) {
+	settings, err := s.repo.FindByUser(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			defaults := domain.DefaultFinanceSettings(userID)
+			return &defaults, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *FinanceSettingsService) UpdateSettings(settings *domain.FinanceSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Save(*settings)
+}
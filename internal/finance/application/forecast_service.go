@@ -0,0 +1,189 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"sort"
+	"time"
+)
+
+type ForecastTransactionProvider interface {
+	GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error)
+	GetPaymentSourceHistory(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PaymentSourceHistoryEntry, error)
+}
+
+type RecurringOccurrenceProvider interface {
+	GetUserRules(userID string) ([]domain.RecurringRule, error)
+	PreviewOccurrences(ruleID, userID string, from, to time.Time, limit int) ([]domain.RecurringOccurrence, error)
+}
+
+// ForecastService projects the balances of the payment sources from the transactions already booked in the future,
+// the upcoming occurrences of recurring rules and the average variable spend in the past months.
+type ForecastService struct {
+	transactions    ForecastTransactionProvider
+	recurring       RecurringOccurrenceProvider
+	paymentService  PaymentServiceInterface
+	categoryService CategoryServiceInterface
+	now             func() time.Time
+}
+
+func NewForecastService(transactions ForecastTransactionProvider, recurring RecurringOccurrenceProvider, paymentService PaymentServiceInterface, categoryService CategoryServiceInterface) *ForecastService {
+	return &ForecastService{
+		transactions:    transactions,
+		recurring:       recurring,
+		paymentService:  paymentService,
+		categoryService: categoryService,
+		now:             time.Now,
+	}
+}
+
+// GetForecast projects the balances of the active payment sources from tomorrow until request.Months from today.
+//
+// The variable spend is the expense history of the last request.HistoryMonths without the recurring items booked in
+// it, so that they aren't counted twice. Each payment source is charged the part of it which was paid from the source,
+// spread evenly over the days. Recurring items booked on an investment category are the scheduled investment
+// contributions.
+func (s *ForecastService) GetForecast(userID string, request domain.ForecastRequest) (*domain.CashFlowForecast, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	today := domain.TruncateToDate(s.now())
+	start, end := today.AddDate(0, 0, 1), today.AddDate(0, request.Months, 0)
+	historyStart := today.AddDate(0, -request.HistoryMonths, 0)
+	historyDays := int64(today.Sub(historyStart).Hours()/24) + 1
+
+	categories, err := s.categoryService.GetAllPredefinedCategories(string(domain.TransactionTypeExpense))
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[int]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	rules, err := s.recurring.GetUserRules(userID)
+	if err != nil {
+		return nil, err
+	}
+	// a rule has at most one occurrence a day
+	limit := int(end.Sub(historyStart).Hours()/24) + 1
+	recurringSpent := map[int]money.Decimal{}
+	items := map[int][]domain.ForecastItem{}
+	for _, rule := range rules {
+		occurrences, err := s.recurring.PreviewOccurrences(rule.ID, userID, historyStart, end, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, occurrence := range occurrences {
+			transaction := occurrence.Transaction
+			switch {
+			case occurrence.Skipped:
+			case !occurrence.Date.After(today):
+				if occurrence.Booked && transaction.Type == string(domain.TransactionTypeExpense) {
+					recurringSpent[transaction.PredefinedCategoryID] = recurringSpent[transaction.PredefinedCategoryID].Add(transaction.Amount)
+				}
+			case !occurrence.Booked && transaction.PaymentSourceID != nil:
+				// booked occurrences are in the history of the payment source already
+				kind := domain.ForecastItemRecurring
+				if transaction.Type == string(domain.TransactionTypeExpense) && domain.InvestmentContributionCategories[categoryNames[transaction.PredefinedCategoryID]] {
+					kind = domain.ForecastItemInvestment
+				}
+				sourceIDs := []int{*transaction.PaymentSourceID}
+				if transaction.IsTransfer() && transaction.DestinationPaymentSourceID != nil {
+					sourceIDs = append(sourceIDs, *transaction.DestinationPaymentSourceID)
+				}
+				for _, sourceID := range sourceIDs {
+					amount, err := transaction.BalanceChange(sourceID)
+					if err != nil {
+						return nil, err
+					}
+					items[sourceID] = append(items[sourceID], domain.ForecastItem{
+						Kind:            kind,
+						Date:            occurrence.Date,
+						Name:            transaction.Name,
+						Amount:          amount,
+						RecurringRuleID: rule.ID,
+					})
+				}
+			}
+		}
+	}
+
+	summaries, err := s.transactions.GetTransactionSummaryByCategory(userID, historyStart, today, string(domain.TransactionTypeExpense))
+	if err != nil {
+		return nil, err
+	}
+	// variableShare is the part of the spend in the category which didn't come from recurring items
+	variableShare := map[int]money.Decimal{}
+	forecast := &domain.CashFlowForecast{
+		StartDate:      start,
+		EndDate:        end,
+		HistoryMonths:  request.HistoryMonths,
+		VariableSpend:  []domain.CategorySpendAverage{},
+		PaymentSources: []domain.PaymentSourceForecast{},
+	}
+	for _, summary := range summaries {
+		if !summary.TotalAmount.IsPositive() {
+			continue
+		}
+		variable := summary.TotalAmount.Sub(recurringSpent[summary.CategoryID])
+		if !variable.IsPositive() {
+			continue
+		}
+		variableShare[summary.CategoryID] = variable.Div(summary.TotalAmount)
+		forecast.VariableSpend = append(forecast.VariableSpend, domain.CategorySpendAverage{
+			CategoryID:     summary.CategoryID,
+			CategoryName:   summary.CategoryName,
+			MonthlyAverage: variable.Div(money.FromInt(int64(request.HistoryMonths))).RoundToCurrency(money.DefaultCurrency),
+		})
+	}
+	sort.Slice(forecast.VariableSpend, func(i, j int) bool {
+		return forecast.VariableSpend[i].MonthlyAverage.GreaterThan(forecast.VariableSpend[j].MonthlyAverage)
+	})
+
+	sources, err := s.paymentService.GetUserPaymentSources(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		if source.Archived {
+			continue
+		}
+		history, err := s.transactions.GetPaymentSourceHistory(userID, source.ID, historyStart, end)
+		if err != nil {
+			return nil, err
+		}
+
+		// the balance of the source includes the transactions booked in the future, the projection starts from the
+		// running balance at the end of today instead
+		balance, started := source.Balance, false
+		variableSpent := money.Zero
+		for _, entry := range history {
+			transaction := entry.Transaction
+			if transaction.Date.After(today) {
+				if !started {
+					balance, started = entry.Balance.Sub(entry.Change), true
+				}
+				items[source.ID] = append(items[source.ID], domain.ForecastItem{
+					Kind:          domain.ForecastItemScheduled,
+					Date:          transaction.Date,
+					Name:          transaction.Name,
+					Amount:        entry.Change,
+					TransactionID: transaction.ID,
+				})
+				continue
+			}
+			balance = entry.Balance
+			if transaction.Type != string(domain.TransactionTypeExpense) {
+				continue
+			}
+			for _, line := range transaction.Lines() {
+				variableSpent = variableSpent.Add(line.Amount.Mul(variableShare[line.PredefinedCategoryID]))
+			}
+		}
+
+		dailyVariableSpend := variableSpent.Div(money.FromInt(historyDays)).RoundToCurrency(money.DefaultCurrency)
+		forecast.PaymentSources = append(forecast.PaymentSources, domain.ProjectPaymentSource(source, balance, dailyVariableSpend, items[source.ID], start, end))
+	}
+	return forecast, nil
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fundedPaymentSource struct {
+	ownedPaymentSources
+	source domain.PaymentSource
+}
+
+func (f fundedPaymentSource) GetUserPaymentSources(userID string) ([]domain.PaymentSource, error) {
+	return []domain.PaymentSource{f.source}, nil
+}
+
+func (f fundedPaymentSource) GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error) {
+	source := f.source
+	return &source, nil
+}
+
+type namedCategories struct {
+	MockCategoryService
+}
+
+func (n *namedCategories) GetAllPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error) {
+	return []domain.PredefinedCategory{{ID: 9, Name: "Groceries"}, {ID: 17, Name: "Housing"}, {ID: 21, Name: "Obligations"}, {ID: 30, Name: "Investments"}, {ID: 31, Name: "Savings"}}, nil
+}
+
+func TestGetForecast_CombinesRecurringScheduledAndVariableSpend(t *testing.T) {
+	groceries, housing, investments := 9, 17, 30
+	checking := 1
+	day := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC) }
+
+	rentBooked, contributionBooked, insuranceBooked := day(time.February, 20), day(time.March, 1), time.Date(2023, time.April, 10, 0, 0, 0, 0, time.UTC)
+	rules := &infrastructure.MockRecurringRuleRepository{Rules: []domain.RecurringRule{
+		{ID: "rent", UserID: "user-id", Frequency: domain.RecurrenceMonthly, Interval: 1, StartDate: day(time.January, 20), GeneratedUntil: &rentBooked,
+			Template: domain.RecurringTemplate{Name: "Rent", Amount: money.MustParse("1000"), Type: "expense", PredefinedCategoryID: housing, PaymentSourceID: &checking}},
+		{ID: "etf", UserID: "user-id", Frequency: domain.RecurrenceMonthly, Interval: 1, StartDate: day(time.January, 1), GeneratedUntil: &contributionBooked,
+			Template: domain.RecurringTemplate{Name: "ETF", Amount: money.MustParse("200"), Type: "expense", PredefinedCategoryID: investments, PaymentSourceID: &checking}},
+		{ID: "car-insurance", UserID: "user-id", Frequency: domain.RecurrenceYearly, Interval: 1, StartDate: insuranceBooked, GeneratedUntil: &insuranceBooked,
+			Template: domain.RecurringTemplate{Name: "Car insurance", Amount: money.MustParse("4000"), Type: "expense", PredefinedCategoryID: housing, PaymentSourceID: &checking}},
+	}}
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "1", UserID: "user-id", Name: "Rent", Type: "expense", Amount: money.MustParse("1000"), Date: rentBooked, PredefinedCategoryID: housing, PaymentSourceID: &checking},
+		{ID: "2", UserID: "user-id", Name: "ETF", Type: "expense", Amount: money.MustParse("200"), Date: contributionBooked, PredefinedCategoryID: investments, PaymentSourceID: &checking},
+		{ID: "3", UserID: "user-id", Name: "Supermarket", Type: "expense", Amount: money.MustParse("300"), Date: day(time.March, 5), PredefinedCategoryID: groceries, PaymentSourceID: &checking},
+		// paid in cash, it counts into the average but not into the spend of the checking account
+		{ID: "4", UserID: "user-id", Name: "Market", Type: "expense", Amount: money.MustParse("300"), Date: day(time.March, 7), PredefinedCategoryID: groceries},
+		{ID: "5", UserID: "user-id", Name: "Insurance", Type: "expense", Amount: money.MustParse("400"), Date: day(time.March, 20), PredefinedCategoryID: housing, PaymentSourceID: &checking},
+	}}
+	payments := fundedPaymentSource{source: domain.PaymentSource{ID: checking, Name: "Checking", OpeningBalance: money.MustParse("6900"), Balance: money.MustParse("5000")}}
+	transactionService := NewPersonalTransactionService(transactions, &namedCategories{}, payments, &MockTagService{})
+	recurringService := NewRecurringTransactionService(rules, transactionService)
+
+	service := NewForecastService(transactionService, recurringService, payments, &namedCategories{})
+	service.now = func() time.Time { return time.Date(2024, time.March, 15, 18, 30, 0, 0, time.UTC) }
+
+	forecast, err := service.GetForecast("user-id", domain.ForecastRequest{Months: 1, HistoryMonths: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, day(time.March, 16), forecast.StartDate)
+	assert.Equal(t, day(time.April, 15), forecast.EndDate)
+	// rent and the ETF contribution booked in the history are recurring, not variable spend
+	assert.Equal(t, []domain.CategorySpendAverage{{CategoryID: groceries, MonthlyAverage: money.MustParse("600")}}, forecast.VariableSpend)
+
+	assert.Len(t, forecast.PaymentSources, 1)
+	checkingForecast := forecast.PaymentSources[0]
+	// the insurance booked on the 20th of March is taken out of the current balance and projected on its date
+	assert.Equal(t, money.MustParse("5400"), checkingForecast.StartingBalance)
+	// 300 paid from the account in the 30 days of history
+	assert.Equal(t, money.MustParse("10"), checkingForecast.DailyVariableSpend)
+
+	var kinds []domain.ForecastItemKind
+	for _, forecastDay := range checkingForecast.Days {
+		for _, item := range forecastDay.Items {
+			kinds = append(kinds, item.Kind)
+		}
+	}
+	assert.Equal(t, []domain.ForecastItemKind{domain.ForecastItemRecurring, domain.ForecastItemScheduled, domain.ForecastItemInvestment, domain.ForecastItemRecurring}, kinds)
+
+	// the yearly car insurance on the 10th of April takes the account below zero
+	assert.Equal(t, []time.Time{day(time.April, 10)}, checkingForecast.NegativeDates)
+	assert.Equal(t, money.MustParse("-510"), checkingForecast.EndingBalance)
+	assert.Equal(t, money.MustParse("-510"), checkingForecast.LowestBalance)
+}
+
+func TestGetForecast_RecurringTransferMovesBetweenPaymentSources(t *testing.T) {
+	checking, savings := 1, 2
+	booked := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	rules := &infrastructure.MockRecurringRuleRepository{Rules: []domain.RecurringRule{
+		{ID: "savings", UserID: "user-id", Frequency: domain.RecurrenceMonthly, Interval: 1, StartDate: booked, GeneratedUntil: &booked,
+			Template: domain.RecurringTemplate{Name: "To savings", Amount: money.MustParse("500"), Type: "transfer", PaymentSourceID: &checking, DestinationPaymentSourceID: &savings}},
+	}}
+	payments := ownedPaymentSources{sources: []int{checking, savings}}
+	transactionService := NewPersonalTransactionService(&infrastructure.MockTransactionRepository{}, &namedCategories{}, payments, &MockTagService{})
+	recurringService := NewRecurringTransactionService(rules, transactionService)
+
+	service := NewForecastService(transactionService, recurringService, payments, &namedCategories{})
+	service.now = func() time.Time { return time.Date(2024, time.March, 15, 18, 30, 0, 0, time.UTC) }
+
+	forecast, err := service.GetForecast("user-id", domain.ForecastRequest{Months: 1, HistoryMonths: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, forecast.PaymentSources, 2) {
+		assert.Equal(t, money.MustParse("-500"), forecast.PaymentSources[0].EndingBalance)
+		assert.Equal(t, []time.Time{time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)}, forecast.PaymentSources[0].NegativeDates)
+		assert.Equal(t, money.MustParse("500"), forecast.PaymentSources[1].EndingBalance)
+		assert.Empty(t, forecast.PaymentSources[1].NegativeDates)
+	}
+}
@@ -0,0 +1,301 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"strings"
+)
+
+type BulkTransactionCreator interface {
+	CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string, options domain.BulkCreateOptions) (*domain.BulkCreateResult, error)
+}
+
+type TransactionCategorizer interface {
+	Categorize(userID string, transactions []*domain.PersonalTransaction) ([]*domain.CategorizationRule, error)
+}
+
+type statementParser func(r io.Reader, defaults domain.ImportDefaults) ([]domain.ImportedTransaction, error)
+
+// statementParsers are the bank statement formats which need no column mapping, only the import defaults.
+var statementParsers = map[domain.StatementFormat]statementParser{
+	domain.StatementFormatMT940:   parseMT940Statement,
+	domain.StatementFormatCAMT053: parseCAMT053Statement,
+	domain.StatementFormatOFX:     parseOFXStatement,
+	domain.StatementFormatQFX:     parseOFXStatement,
+	domain.StatementFormatQIF:     parseQIFStatement,
+}
+
+type ImportService struct {
+	profileRepo        domain.ImportProfileRepository
+	categoryService    CategoryServiceInterface
+	paymentService     PaymentServiceInterface
+	transactionCreator BulkTransactionCreator
+	categorizer        TransactionCategorizer
+}
+
+func NewImportService(profileRepo domain.ImportProfileRepository, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface, transactionCreator BulkTransactionCreator, categorizer TransactionCategorizer) *ImportService {
+	return &ImportService{
+		profileRepo:        profileRepo,
+		categoryService:    categoryService,
+		paymentService:     paymentService,
+		transactionCreator: transactionCreator,
+		categorizer:        categorizer,
+	}
+}
+
+// ImportResult reports how many transactions were created or overwritten and which rows were left out, either
+// because they were invalid (Skipped) or already imported (Details). The indexes of Details are statement rows.
+type ImportResult struct {
+	Imported int                          `json:"imported"`
+	Skipped  []domain.ImportedTransaction `json:"skipped"`
+	Details  *domain.BulkCreateResult     `json:"details"`
+}
+
+func (s *ImportService) GetUserProfiles(userID string) ([]domain.ImportProfile, error) {
+	profiles, err := s.profileRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if profiles == nil {
+		return []domain.ImportProfile{}, nil
+	}
+	return profiles, nil
+}
+
+func (s *ImportService) GetProfile(profileID int, userID string) (*domain.ImportProfile, error) {
+	profile, err := s.profileRepo.FindByID(profileID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrImportProfileNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *ImportService) CreateProfile(profile *domain.ImportProfile) error {
+	profile.ID = 0
+	if err := s.validateSavedProfile(profile); err != nil {
+		return err
+	}
+	return s.profileRepo.Create(profile)
+}
+
+func (s *ImportService) UpdateProfile(profile *domain.ImportProfile) error {
+	if _, err := s.GetProfile(profile.ID, profile.UserID); err != nil {
+		return err
+	}
+	if err := s.validateSavedProfile(profile); err != nil {
+		return err
+	}
+
+	affected, err := s.profileRepo.Update(*profile)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrImportProfileNotFound
+	}
+	return nil
+}
+
+func (s *ImportService) DeleteProfile(profileID int, userID string) error {
+	affected, err := s.profileRepo.Delete(profileID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrImportProfileNotFound
+	}
+	return nil
+}
+
+func (s *ImportService) validateSavedProfile(profile *domain.ImportProfile) error {
+	if err := s.ValidateProfile(profile); err != nil {
+		return err
+	}
+	exists, err := s.profileRepo.DoesNameExist(profile.Name, profile.UserID, profile.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return financeErrors.ErrImportProfileNameTaken
+	}
+	return nil
+}
+
+// ValidateProfile checks the mapping and the import defaults of the profile.
+// It is also used for profiles sent along with a single import without being saved.
+func (s *ImportService) ValidateProfile(profile *domain.ImportProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	return s.validateDefaultReferences(&profile.ImportDefaults)
+}
+
+func (s *ImportService) validateDefaultReferences(defaults *domain.ImportDefaults) error {
+	for _, categoryID := range []int{defaults.ExpenseCategoryID, defaults.IncomeCategoryID} {
+		exists, err := s.categoryService.DoesPredefinedCategoryExist(categoryID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPredefinedCategory
+		}
+	}
+
+	exists, err := s.paymentService.DoesPaymentMethodExistByID(defaults.PaymentMethodID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return financeErrors.ErrInvalidPaymentMethod
+	}
+	if defaults.PaymentSourceID != nil {
+		exists, err = s.paymentService.DoesUserPaymentSourceExistByID(*defaults.PaymentSourceID, defaults.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPaymentSource
+		}
+	}
+
+	return nil
+}
+
+// PreviewCSV parses the statement without saving anything, so the user can check the mapping row by row.
+func (s *ImportService) PreviewCSV(r io.Reader, profile domain.ImportProfile) (*domain.ImportPreview, error) {
+	rows, err := parseCSVStatement(r, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.categorize(profile.UserID, rows); err != nil {
+		return nil, err
+	}
+	return domain.NewImportPreview(rows), nil
+}
+
+// ImportCSV parses the statement and saves its transactions through the bulk path. Unless skipInvalid is set,
+// a single invalid row rejects the whole statement. Rows imported before are handled according to the options.
+func (s *ImportService) ImportCSV(r io.Reader, profile domain.ImportProfile, skipInvalid bool, options domain.BulkCreateOptions) (*ImportResult, error) {
+	preview, err := s.PreviewCSV(r, profile)
+	if err != nil {
+		return nil, err
+	}
+	return s.commitPreview(preview, profile.UserID, skipInvalid, options)
+}
+
+// ValidateDefaults checks that the default categories and payment method given to imported transactions exist.
+func (s *ImportService) ValidateDefaults(defaults *domain.ImportDefaults) error {
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	return s.validateDefaultReferences(defaults)
+}
+
+// IsSupportedStatementFormat reports whether statements in the format can be read with PreviewStatement and ImportStatement.
+func (s *ImportService) IsSupportedStatementFormat(format domain.StatementFormat) bool {
+	_, ok := statementParsers[format]
+	return ok
+}
+
+func (s *ImportService) PreviewStatement(format domain.StatementFormat, r io.Reader, defaults domain.ImportDefaults) (*domain.ImportPreview, error) {
+	parse, ok := statementParsers[format]
+	if !ok {
+		return nil, financeErrors.ErrUnsupportedStatementFormat
+	}
+	rows, err := parse(r, defaults)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.categorize(defaults.UserID, rows); err != nil {
+		return nil, err
+	}
+	return domain.NewImportPreview(rows), nil
+}
+
+// categorize applies the categorization rules of the user to the valid rows, overriding the import defaults.
+func (s *ImportService) categorize(userID string, rows []domain.ImportedTransaction) error {
+	if s.categorizer == nil {
+		return nil
+	}
+	var transactions []*domain.PersonalTransaction
+	var indexes []int
+	for i := range rows {
+		if len(rows[i].Errors) == 0 {
+			transactions = append(transactions, &rows[i].Transaction)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	applied, err := s.categorizer.Categorize(userID, transactions)
+	if err != nil {
+		return err
+	}
+	for i, rule := range applied {
+		if rule != nil {
+			ruleID := rule.ID
+			rows[indexes[i]].AppliedRuleID = &ruleID
+		}
+	}
+	return nil
+}
+
+func (s *ImportService) ImportStatement(format domain.StatementFormat, r io.Reader, defaults domain.ImportDefaults, skipInvalid bool, options domain.BulkCreateOptions) (*ImportResult, error) {
+	preview, err := s.PreviewStatement(format, r, defaults)
+	if err != nil {
+		return nil, err
+	}
+	return s.commitPreview(preview, defaults.UserID, skipInvalid, options)
+}
+
+func (s *ImportService) commitPreview(preview *domain.ImportPreview, userID string, skipInvalid bool, options domain.BulkCreateOptions) (*ImportResult, error) {
+	result := &ImportResult{Skipped: []domain.ImportedTransaction{}}
+	for _, row := range preview.Rows {
+		if len(row.Errors) > 0 {
+			result.Skipped = append(result.Skipped, row)
+		}
+	}
+
+	if len(result.Skipped) > 0 && !skipInvalid {
+		validationErrors := &financeErrors.ValidationErrors{}
+		for _, row := range result.Skipped {
+			validationErrors.Add(financeErrors.NewValidationError(rowErrorMessage(row)))
+		}
+		return nil, validationErrors
+	}
+
+	transactions := preview.ValidTransactions()
+	if len(transactions) == 0 {
+		return nil, financeErrors.ErrNothingToImport
+	}
+	details, err := s.transactionCreator.CreateTransactionsBulk(transactions, userID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var validRows []int
+	for _, row := range preview.Rows {
+		if len(row.Errors) == 0 {
+			validRows = append(validRows, row.Row)
+		}
+	}
+	for i := range details.Rows {
+		details.Rows[i].Index = validRows[details.Rows[i].Index-1]
+	}
+	result.Imported = details.Created + details.Overwritten
+	result.Details = details
+	return result, nil
+}
+
+func rowErrorMessage(row domain.ImportedTransaction) string {
+	return fmt.Sprintf("Validation error at row %d: %s", row.Row, strings.Join(row.Errors, "; "))
+}
@@ -0,0 +1,178 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"time"
+)
+
+type LoanService struct {
+	repo            domain.LoanRepository
+	transactionRepo domain.PersonalTransactionRepository
+	categoryService CategoryServiceInterface
+	paymentService  PaymentServiceInterface
+	now             func() time.Time
+}
+
+func NewLoanService(repo domain.LoanRepository, transactionRepo domain.PersonalTransactionRepository, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface) *LoanService {
+	return &LoanService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		categoryService: categoryService,
+		paymentService:  paymentService,
+		now:             time.Now,
+	}
+}
+
+func (s *LoanService) GetUserLoans(userID string) ([]domain.Loan, error) {
+	loans, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if loans == nil {
+		loans = []domain.Loan{}
+	}
+	return loans, nil
+}
+
+func (s *LoanService) GetLoan(loanID int, userID string) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(loanID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrLoanNotFound
+		}
+		return nil, err
+	}
+	return loan, nil
+}
+
+func (s *LoanService) CreateLoan(loan *domain.Loan) error {
+	loan.ID = 0
+	if err := s.validateLoan(loan); err != nil {
+		return err
+	}
+	return s.repo.Create(loan)
+}
+
+func (s *LoanService) UpdateLoan(loan *domain.Loan) error {
+	if err := s.validateLoan(loan); err != nil {
+		return err
+	}
+	affected, err := s.repo.Update(*loan)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrLoanNotFound
+	}
+	return nil
+}
+
+func (s *LoanService) DeleteLoan(loanID int, userID string) error {
+	affected, err := s.repo.Delete(loanID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrLoanNotFound
+	}
+	return nil
+}
+
+// validateLoan books the installments on the Obligations category unless the loan names another one.
+func (s *LoanService) validateLoan(loan *domain.Loan) error {
+	loan.Normalize()
+	if err := loan.Validate(); err != nil {
+		return err
+	}
+
+	if loan.PredefinedCategoryID == 0 {
+		categoryID, err := s.obligationsCategoryID()
+		if err != nil {
+			return err
+		}
+		loan.PredefinedCategoryID = categoryID
+	} else {
+		exists, err := s.categoryService.DoesPredefinedCategoryExist(loan.PredefinedCategoryID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPredefinedCategory
+		}
+	}
+
+	if loan.PaymentSourceID != nil {
+		exists, err := s.paymentService.DoesUserPaymentSourceExistByID(*loan.PaymentSourceID, loan.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPaymentSource
+		}
+	}
+	return nil
+}
+
+func (s *LoanService) obligationsCategoryID() (int, error) {
+	categories, err := s.categoryService.GetAllPredefinedCategories(string(domain.TransactionTypeExpense))
+	if err != nil {
+		return 0, err
+	}
+	for _, category := range categories {
+		if category.Name == domain.ObligationsCategoryName {
+			return category.ID, nil
+		}
+	}
+	return 0, errors.New("predefined category " + domain.ObligationsCategoryName + " not found")
+}
+
+// GetSchedule returns the full amortization schedule of the loan, with the expenses the installments due so far were
+// paid with.
+func (s *LoanService) GetSchedule(loanID int, userID string) (*domain.LoanSchedule, error) {
+	loan, err := s.GetLoan(loanID, userID)
+	if err != nil {
+		return nil, err
+	}
+	schedule := loan.Schedule(domain.LoanOverpaymentPlan{})
+
+	today := domain.TruncateToDate(s.now())
+	due := 0
+	for due < len(schedule.Installments) && !schedule.Installments[due].DueDate.After(today) {
+		due++
+	}
+	if due == 0 {
+		return &schedule, nil
+	}
+
+	var transactions []domain.PersonalTransaction
+	start := schedule.Installments[0].DueDate.AddDate(0, 0, -domain.LoanMatchDays)
+	end := schedule.Installments[due-1].DueDate.AddDate(0, 0, domain.LoanMatchDays)
+	search := domain.TransactionSearch{Type: string(domain.TransactionTypeExpense), StartDate: start, EndDate: end}
+	err = s.transactionRepo.StreamTransactions(userID, search, func(transaction domain.PersonalTransaction) error {
+		transactions = append(transactions, transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	loan.MatchInstallments(schedule.Installments[:due], transactions)
+	return &schedule, nil
+}
+
+// SimulateOverpayment compares the schedule of the loan with the overpayment plan against the one without it. The
+// monthly overpayment starts with the next installment.
+func (s *LoanService) SimulateOverpayment(loanID int, userID string, plan domain.LoanOverpaymentPlan) (*domain.LoanSimulation, error) {
+	loan, err := s.GetLoan(loanID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	plan.Since = domain.TruncateToDate(s.now())
+	simulation := loan.Simulate(plan)
+	return &simulation, nil
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetSchedule_MatchesPaidInstallments(t *testing.T) {
+	obligations, checking := 21, 1
+	day := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC) }
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "january", UserID: "user-id", Type: "expense", Amount: money.MustParse("1066.19"), Date: day(time.February, 2), PredefinedCategoryID: obligations, PaymentSourceID: &checking},
+		// paid from another account, it's not this loan
+		{ID: "other-loan", UserID: "user-id", Type: "expense", Amount: money.MustParse("1066.19"), Date: day(time.February, 27), PredefinedCategoryID: obligations},
+		{ID: "february", UserID: "user-id", Type: "expense", Amount: money.MustParse("1066.20"), Date: day(time.February, 28), PredefinedCategoryID: obligations, PaymentSourceID: &checking},
+		// booked too late for the March installment
+		{ID: "march", UserID: "user-id", Type: "expense", Amount: money.MustParse("1066.19"), Date: day(time.April, 10), PredefinedCategoryID: obligations, PaymentSourceID: &checking},
+		// a single transfer order paying the installment and the insurance of the car
+		{ID: "april", UserID: "user-id", Type: "expense", Amount: money.MustParse("1266.19"), Date: day(time.April, 30), PredefinedCategoryID: obligations, PaymentSourceID: &checking,
+			Splits: []domain.TransactionSplit{
+				{PredefinedCategoryID: 16, Amount: money.MustParse("200")},
+				{PredefinedCategoryID: obligations, Amount: money.MustParse("1066.19")},
+			}},
+	}}
+	loans := &infrastructure.MockLoanRepository{}
+	service := NewLoanService(loans, transactions, &namedCategories{}, ownedPaymentSources{sources: []int{checking}})
+	service.now = func() time.Time { return day(time.May, 2) }
+
+	loan := &domain.Loan{UserID: "user-id", Name: "Car loan", Principal: money.MustParse("12000"), AnnualRate: money.MustParse("12"),
+		RateType: domain.LoanRateFixed, TermMonths: 12, Method: domain.LoanInstallmentsEqual, FirstInstallmentDate: day(time.January, 31), PaymentSourceID: &checking}
+	assert.NoError(t, service.CreateLoan(loan))
+	// installments are expenses in the Obligations category unless the loan says otherwise
+	assert.Equal(t, obligations, loan.PredefinedCategoryID)
+
+	schedule, err := service.GetSchedule(loan.ID, "user-id")
+	assert.NoError(t, err)
+	assert.Len(t, schedule.Installments, 12)
+	var paidWith []*string
+	for _, installment := range schedule.Installments[:5] {
+		paidWith = append(paidWith, installment.TransactionID)
+	}
+	january, february, april := "january", "february", "april"
+	assert.Equal(t, []*string{&january, &february, nil, &april, nil}, paidWith)
+}
+
+func TestSimulateOverpayment_StartsWithNextInstallment(t *testing.T) {
+	loans := &infrastructure.MockLoanRepository{Loans: []domain.Loan{{ID: 1, UserID: "user-id", Name: "Mortgage", Principal: money.MustParse("12000"),
+		AnnualRate: money.MustParse("12"), RateType: domain.LoanRateFixed, TermMonths: 12, Method: domain.LoanInstallmentsDecreasing,
+		FirstInstallmentDate: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)}}}
+	service := NewLoanService(loans, &infrastructure.MockTransactionRepository{}, &namedCategories{}, ownedPaymentSources{})
+	service.now = func() time.Time { return time.Date(2026, time.June, 30, 9, 0, 0, 0, time.UTC) }
+
+	simulation, err := service.SimulateOverpayment(1, "user-id", domain.LoanOverpaymentPlan{MonthlyAmount: money.MustParse("1000")})
+	assert.NoError(t, err)
+	// the June installment is due today, 1000 is paid on top of the July, August and September ones which pay it off
+	assert.Equal(t, money.MustParse("3000"), simulation.Simulated.TotalOverpayment)
+	assert.Equal(t, 3, simulation.MonthsSaved)
+	assert.Equal(t, money.MustParse("90"), simulation.InterestSaved)
+
+	_, err = service.SimulateOverpayment(1, "user-id", domain.LoanOverpaymentPlan{})
+	assert.EqualError(t, err, "Either overpayments or a monthly overpayment is required")
+	_, err = service.SimulateOverpayment(2, "user-id", domain.LoanOverpaymentPlan{MonthlyAmount: money.MustParse("1000")})
+	assert.Error(t, err)
+}
@@ -5,8 +5,7 @@ import "github.com/sebuszqo/FinanceManager/internal/finance/domain"
 type MockCategoryService struct{}
 
 func (m *MockCategoryService) GetAllUserCategories(userID string) ([]domain.UserCategory, error) {
-	//TODO implement me
-	panic("implement me")
+	return []domain.UserCategory{}, nil
 }
 
 func (m *MockCategoryService) GetAllPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error) {
@@ -0,0 +1,17 @@
+package application
+
+import "github.com/sebuszqo/FinanceManager/internal/finance/domain"
+
+type MockTagService struct {
+	Tags []domain.Tag
+}
+
+func (m *MockTagService) GetUserTags(userID string) ([]domain.Tag, error) {
+	var tags []domain.Tag
+	for _, tag := range m.Tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"log"
+	"time"
+)
+
+type FinanceSettingsProvider interface {
+	GetSettings(userID string) (*domain.FinanceSettings, error)
+}
+
+type AssetHoldingProvider interface {
+	GetUserHoldings(userID string) ([]domain.NetWorthHolding, error)
+}
+
+type ExchangeRateProvider interface {
+	GetExchangeRate(from, to string) (money.Decimal, error)
+}
+
+type NetWorthService struct {
+	repo           domain.NetWorthRepository
+	settings       FinanceSettingsProvider
+	paymentService PaymentServiceInterface
+	loans          domain.LoanRepository
+	holdings       AssetHoldingProvider
+	rates          ExchangeRateProvider
+	now            func() time.Time
+}
+
+func NewNetWorthService(repo domain.NetWorthRepository, settings FinanceSettingsProvider, paymentService PaymentServiceInterface, loans domain.LoanRepository, holdings AssetHoldingProvider, rates ExchangeRateProvider) *NetWorthService {
+	return &NetWorthService{
+		repo:           repo,
+		settings:       settings,
+		paymentService: paymentService,
+		loans:          loans,
+		holdings:       holdings,
+		rates:          rates,
+		now:            time.Now,
+	}
+}
+
+// GetNetWorth computes the net worth of the user today, without storing it.
+func (s *NetWorthService) GetNetWorth(userID string) (*domain.NetWorthSnapshot, error) {
+	snapshot, err := s.snapshot(userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetNetWorthHistory returns the daily snapshots between the dates, the last DefaultNetWorthHistoryDays days when they
+// are zero.
+func (s *NetWorthService) GetNetWorthHistory(userID string, startDate, endDate time.Time) (*domain.NetWorthHistory, error) {
+	if endDate.IsZero() {
+		endDate = s.now()
+	}
+	endDate = domain.TruncateToDate(endDate)
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -domain.DefaultNetWorthHistoryDays)
+	}
+	startDate = domain.TruncateToDate(startDate)
+	if startDate.After(endDate) {
+		return nil, financeErrors.NewValidationError("Start date must not be after end date")
+	}
+	if endDate.Sub(startDate) > domain.MaxNetWorthHistoryDays*24*time.Hour {
+		return nil, financeErrors.NewValidationError(fmt.Sprintf("Net worth history can span at most %d days", domain.MaxNetWorthHistoryDays))
+	}
+
+	settings, err := s.settings.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.repo.FindInRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if snapshots == nil {
+		snapshots = []domain.NetWorthSnapshot{}
+	}
+	return &domain.NetWorthHistory{StartDate: startDate, EndDate: endDate, BaseCurrency: settings.BaseCurrency, Snapshots: snapshots}, nil
+}
+
+// RecordDailySnapshots stores today's net worth of every user who doesn't have it yet. It returns the number of
+// stored snapshots, errors of single users are logged and retried on the next run.
+func (s *NetWorthService) RecordDailySnapshots(now time.Time) (int, error) {
+	today := domain.TruncateToDate(now)
+	userIDs, err := s.repo.FindUsersDueForSnapshot(today)
+	if err != nil {
+		return 0, err
+	}
+
+	recorded := 0
+	for _, userID := range userIDs {
+		snapshot, err := s.snapshot(userID, today)
+		if err == nil {
+			err = s.repo.Save(userID, snapshot)
+		}
+		if err != nil {
+			log.Printf("Error recording net worth of user %s: %v", userID, err)
+			continue
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// snapshot sums the balances of the payment sources, the current value of the investment assets and the outstanding
+// principal of the loans, converted to the base currency of the user.
+func (s *NetWorthService) snapshot(userID string, date time.Time) (domain.NetWorthSnapshot, error) {
+	settings, err := s.settings.GetSettings(userID)
+	if err != nil {
+		return domain.NetWorthSnapshot{}, err
+	}
+
+	holdings, err := s.holdings.GetUserHoldings(userID)
+	if err != nil {
+		return domain.NetWorthSnapshot{}, err
+	}
+	sources, err := s.paymentService.GetUserPaymentSources(userID)
+	if err != nil {
+		return domain.NetWorthSnapshot{}, err
+	}
+	for _, source := range sources {
+		holdings = append(holdings, domain.NetWorthHolding{AssetClass: domain.AssetClassAccounts, Currency: source.Currency, Value: source.Balance})
+	}
+	loans, err := s.loans.FindByUser(userID)
+	if err != nil {
+		return domain.NetWorthSnapshot{}, err
+	}
+	for _, loan := range loans {
+		outstanding := loan.OutstandingPrincipal(domain.TruncateToDate(date))
+		holdings = append(holdings, domain.NetWorthHolding{AssetClass: domain.AssetClassLoans, Currency: loan.Currency, Value: outstanding.Neg()})
+	}
+
+	rates := map[string]money.Decimal{}
+	for i, holding := range holdings {
+		rate, ok := rates[holding.Currency]
+		if !ok {
+			rate, err = s.rates.GetExchangeRate(holding.Currency, settings.BaseCurrency)
+			if err != nil {
+				return domain.NetWorthSnapshot{}, fmt.Errorf("converting %s to %s: %w", holding.Currency, settings.BaseCurrency, err)
+			}
+			rates[holding.Currency] = rate
+		}
+		value, err := holding.Value.CheckedMul(rate)
+		if err != nil {
+			return domain.NetWorthSnapshot{}, fmt.Errorf("converting %s to %s: %w", holding.Currency, settings.BaseCurrency, err)
+		}
+		holdings[i].Value = value
+	}
+	return domain.NewNetWorthSnapshot(date, settings.BaseCurrency, holdings), nil
+}
@@ -0,0 +1,94 @@
+package application
+
+import (
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fixedHoldings []domain.NetWorthHolding
+
+func (f fixedHoldings) GetUserHoldings(userID string) ([]domain.NetWorthHolding, error) {
+	return append([]domain.NetWorthHolding(nil), f...), nil
+}
+
+type fixedExchangeRates map[string]string
+
+func (f fixedExchangeRates) GetExchangeRate(from, to string) (money.Decimal, error) {
+	if from == to {
+		return money.FromInt(1), nil
+	}
+	rate, ok := f[from+"/"+to]
+	if !ok {
+		return money.Zero, errors.New("rate not available")
+	}
+	return money.MustParse(rate), nil
+}
+
+func TestGetNetWorth_ConvertsToBaseCurrency(t *testing.T) {
+	settings := &infrastructure.MockFinanceSettingsRepository{}
+	euro := domain.DefaultFinanceSettings("user-id")
+	euro.BaseCurrency = "eur"
+	assert.NoError(t, NewFinanceSettingsService(settings).UpdateSettings(&euro))
+
+	payments := fundedPaymentSource{source: domain.PaymentSource{ID: 1, Name: "Checking", Currency: "PLN", Balance: money.MustParse("4300")}}
+	loans := &infrastructure.MockLoanRepository{Loans: []domain.Loan{{ID: 1, UserID: "user-id", Name: "Car loan", Principal: money.MustParse("12000"),
+		AnnualRate: money.MustParse("12"), TermMonths: 12, Method: domain.LoanInstallmentsDecreasing, Currency: "USD",
+		FirstInstallmentDate: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)}}}
+	holdings := fixedHoldings{
+		{AssetClass: "Stock", Currency: "USD", Value: money.MustParse("1000")},
+		{AssetClass: "ETF", Currency: "EUR", Value: money.MustParse("2500")},
+	}
+	rates := fixedExchangeRates{"PLN/EUR": "0.25", "USD/EUR": "0.9"}
+	service := NewNetWorthService(&infrastructure.MockNetWorthRepository{}, NewFinanceSettingsService(settings), payments, loans, holdings, rates)
+	service.now = func() time.Time { return time.Date(2026, time.April, 15, 10, 0, 0, 0, time.UTC) }
+
+	netWorth, err := service.GetNetWorth("user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", netWorth.BaseCurrency)
+	// 4300 PLN in the account, 9000 USD of the loan left after the March installment
+	assert.Equal(t, []domain.NetWorthComponent{
+		{AssetClass: "ETF", Value: money.MustParse("2500")},
+		{AssetClass: domain.AssetClassAccounts, Value: money.MustParse("1075")},
+		{AssetClass: "Stock", Value: money.MustParse("900")},
+		{AssetClass: domain.AssetClassLoans, Value: money.MustParse("-8100")},
+	}, netWorth.Breakdown)
+	assert.Equal(t, money.MustParse("-3625"), netWorth.NetWorth)
+
+	holdings[0].Currency = "CHF"
+	_, err = service.GetNetWorth("user-id")
+	assert.EqualError(t, err, "converting CHF to EUR: rate not available")
+}
+
+func TestRecordDailySnapshots_OncePerDay(t *testing.T) {
+	repo := &infrastructure.MockNetWorthRepository{Users: []string{"user-id"}}
+	payments := fundedPaymentSource{source: domain.PaymentSource{ID: 1, Name: "Checking", Currency: "PLN", Balance: money.MustParse("4300")}}
+	service := NewNetWorthService(repo, NewFinanceSettingsService(&infrastructure.MockFinanceSettingsRepository{}), payments,
+		&infrastructure.MockLoanRepository{}, fixedHoldings{}, fixedExchangeRates{})
+
+	for _, now := range []time.Time{
+		time.Date(2026, time.April, 14, 0, 30, 0, 0, time.UTC),
+		time.Date(2026, time.April, 14, 1, 30, 0, 0, time.UTC),
+		time.Date(2026, time.April, 15, 0, 30, 0, 0, time.UTC),
+	} {
+		_, err := service.RecordDailySnapshots(now)
+		assert.NoError(t, err)
+	}
+	service.now = func() time.Time { return time.Date(2026, time.April, 15, 12, 0, 0, 0, time.UTC) }
+
+	history, err := service.GetNetWorthHistory("user-id", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.April, 15, 0, 0, 0, 0, time.UTC), history.StartDate)
+	assert.Equal(t, "PLN", history.BaseCurrency)
+	if assert.Len(t, history.Snapshots, 2) {
+		assert.Equal(t, time.Date(2026, time.April, 14, 0, 0, 0, 0, time.UTC), history.Snapshots[0].Date)
+		assert.Equal(t, money.MustParse("4300"), history.Snapshots[1].NetWorth)
+	}
+
+	_, err = service.GetNetWorthHistory("user-id", time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	assert.EqualError(t, err, "Start date must not be after end date")
+}
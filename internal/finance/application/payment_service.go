@@ -1,6 +1,11 @@
 package application
 
-import "github.com/sebuszqo/FinanceManager/internal/finance/domain"
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
 
 type PaymentService struct {
 	repo domain.PaymentRepository
@@ -23,8 +28,104 @@ func (s *PaymentService) GetAllPaymentMethods() ([]domain.PaymentMethod, error)
 	return methods, nil
 }
 
+// GetUserPaymentSources returns the active (not archived) payment sources of the user.
 func (s *PaymentService) GetUserPaymentSources(userID string) ([]domain.PaymentSource, error) {
-	return s.repo.GetUserPaymentSources(userID)
+	return s.ListUserPaymentSources(userID, false)
+}
+
+func (s *PaymentService) ListUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error) {
+	sources, err := s.repo.GetUserPaymentSources(userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	if sources == nil {
+		return []domain.PaymentSource{}, nil
+	}
+	return sources, nil
+}
+
+func (s *PaymentService) GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error) {
+	source, err := s.repo.FindUserPaymentSourceByID(sourceID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrPaymentSourceNotFound
+		}
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *PaymentService) CreatePaymentSource(source *domain.PaymentSource) error {
+	if err := s.validatePaymentSource(source); err != nil {
+		return err
+	}
+	source.Archived = false
+	source.Balance = source.OpeningBalance
+	return s.repo.CreatePaymentSource(source)
+}
+
+func (s *PaymentService) UpdatePaymentSource(source *domain.PaymentSource) error {
+	existing, err := s.GetUserPaymentSource(source.ID, source.UserID)
+	if err != nil {
+		return err
+	}
+
+	// clients usually send back the masked values they received, those must not overwrite the stored ones
+	for key, value := range source.Details {
+		if stored, ok := existing.Details[key]; ok && domain.IsSensitivePaymentDetail(key) && value == domain.MaskPaymentDetail(stored) {
+			source.Details[key] = stored
+		}
+	}
+
+	if err := s.validatePaymentSource(source); err != nil {
+		return err
+	}
+	source.Archived = existing.Archived
+	source.Balance = existing.Balance.Sub(existing.OpeningBalance).Add(source.OpeningBalance)
+
+	affected, err := s.repo.UpdatePaymentSource(*source)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrPaymentSourceNotFound
+	}
+	return nil
+}
+
+func (s *PaymentService) ArchivePaymentSource(sourceID int, userID string) error {
+	return s.setArchived(sourceID, userID, true)
+}
+
+func (s *PaymentService) RestorePaymentSource(sourceID int, userID string) error {
+	return s.setArchived(sourceID, userID, false)
+}
+
+func (s *PaymentService) setArchived(sourceID int, userID string, archived bool) error {
+	affected, err := s.repo.SetPaymentSourceArchived(sourceID, userID, archived)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrPaymentSourceNotFound
+	}
+	return nil
+}
+
+func (s *PaymentService) validatePaymentSource(source *domain.PaymentSource) error {
+	source.Currency = domain.NormalizeCurrency(source.Currency)
+	source.OpeningBalance = source.OpeningBalance.RoundToCurrency(source.Currency)
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	exists, err := s.repo.PaymentMethodExists(source.PaymentMethodID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return financeErrors.ErrInvalidPaymentMethod
+	}
+	return nil
 }
 
 func (s *PaymentService) DoesPaymentMethodExistByID(methodID int) (bool, error) {
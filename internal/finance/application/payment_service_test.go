@@ -0,0 +1,103 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newPaymentRepository() *infrastructure.MockPaymentRepository {
+	return &infrastructure.MockPaymentRepository{
+		Methods: []domain.PaymentMethod{{ID: 1, Name: "Payment Card"}, {ID: 2, Name: "Cash"}},
+		Sources: []domain.PaymentSource{
+			{
+				ID: 1, UserID: "user-id", PaymentMethodID: 1, Name: "Main account",
+				Details:        map[string]string{"iban": "PL61 1090 1014 0000 0712 1981 2874", "bank": "Santander"},
+				OpeningBalance: money.MustParse("100"), Balance: money.MustParse("350"),
+			},
+			{ID: 2, UserID: "user-id", PaymentMethodID: 2, Name: "Wallet", Archived: true},
+			{ID: 3, UserID: "other-user-id", PaymentMethodID: 2, Name: "Wallet"},
+		},
+	}
+}
+
+func TestPaymentService_CreatePaymentSource(t *testing.T) {
+	repo := newPaymentRepository()
+	service := NewPaymentService(repo)
+
+	source := &domain.PaymentSource{UserID: "user-id", PaymentMethodID: 1, Name: "Savings", Archived: true, OpeningBalance: money.MustParse("10.005")}
+	assert.NoError(t, service.CreatePaymentSource(source))
+	assert.Equal(t, 4, source.ID)
+	assert.False(t, repo.Sources[3].Archived)
+	assert.Equal(t, money.MustParse("10.01"), repo.Sources[3].OpeningBalance)
+	assert.Equal(t, money.MustParse("10.01"), repo.Sources[3].Balance)
+	assert.Equal(t, "PLN", repo.Sources[3].Currency)
+
+	yen := &domain.PaymentSource{UserID: "user-id", PaymentMethodID: 2, Name: "Yen wallet", Currency: "jpy", OpeningBalance: money.MustParse("1000.4")}
+	assert.NoError(t, service.CreatePaymentSource(yen))
+	assert.Equal(t, "JPY", repo.Sources[4].Currency)
+	assert.Equal(t, money.MustParse("1000"), repo.Sources[4].OpeningBalance)
+
+	err := service.CreatePaymentSource(&domain.PaymentSource{UserID: "user-id", PaymentMethodID: 7, Name: "Unknown"})
+	assert.ErrorIs(t, err, financeErrors.ErrInvalidPaymentMethod)
+
+	err = service.CreatePaymentSource(&domain.PaymentSource{UserID: "user-id", PaymentMethodID: 1, Name: "Bad IBAN", Details: map[string]string{"iban": "PL00109010140000071219812874"}})
+	assert.True(t, financeErrors.IsValidationError(err))
+
+	err = service.CreatePaymentSource(&domain.PaymentSource{UserID: "user-id", PaymentMethodID: 1, Name: "Note", Details: map[string]string{"note": "enc:v1:abc"}})
+	assert.True(t, financeErrors.IsValidationError(err))
+
+	err = service.CreatePaymentSource(&domain.PaymentSource{UserID: "user-id", PaymentMethodID: 1, Name: "Overdraft", OpeningBalance: money.MustParse("-10000000000")})
+	assert.EqualError(t, err, "Opening balance must be between -10000000000 and 10000000000")
+	assert.Len(t, repo.Sources, 5)
+}
+
+func TestPaymentService_UpdatePaymentSource(t *testing.T) {
+	repo := newPaymentRepository()
+	service := NewPaymentService(repo)
+
+	// the client sends back the masked IBAN it received and a new opening balance
+	source := &domain.PaymentSource{
+		ID: 1, UserID: "user-id", PaymentMethodID: 1, Name: "Main account",
+		Details:        map[string]string{"iban": "**** 2874", "bank": "mBank"},
+		OpeningBalance: money.MustParse("150"),
+	}
+	assert.NoError(t, service.UpdatePaymentSource(source))
+
+	updated := repo.Sources[0]
+	assert.Equal(t, "PL61 1090 1014 0000 0712 1981 2874", updated.Details["iban"])
+	assert.Equal(t, "mBank", updated.Details["bank"])
+	// the balance moves with the opening balance, the transactions stay applied
+	assert.Equal(t, money.MustParse("400"), updated.Balance)
+
+	// archived state can only be changed by archiving and restoring
+	assert.NoError(t, service.UpdatePaymentSource(&domain.PaymentSource{ID: 2, UserID: "user-id", PaymentMethodID: 2, Name: "Old wallet"}))
+	assert.True(t, repo.Sources[1].Archived)
+
+	err := service.UpdatePaymentSource(&domain.PaymentSource{ID: 3, UserID: "user-id", PaymentMethodID: 2, Name: "Not mine"})
+	assert.ErrorIs(t, err, financeErrors.ErrPaymentSourceNotFound)
+	assert.Equal(t, "Wallet", repo.Sources[2].Name)
+}
+
+func TestPaymentService_ArchiveAndList(t *testing.T) {
+	repo := newPaymentRepository()
+	service := NewPaymentService(repo)
+
+	assert.NoError(t, service.ArchivePaymentSource(1, "user-id"))
+	assert.ErrorIs(t, service.ArchivePaymentSource(3, "user-id"), financeErrors.ErrPaymentSourceNotFound)
+
+	active, err := service.GetUserPaymentSources("user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.PaymentSource{}, active)
+
+	assert.NoError(t, service.RestorePaymentSource(2, "user-id"))
+	all, err := service.ListUserPaymentSources("user-id", true)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.True(t, all[0].Archived)
+		assert.False(t, all[1].Archived)
+	}
+}
@@ -3,7 +3,9 @@ package application
 import (
 	"fmt"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
 	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"github.com/stretchr/testify/assert"
 	"math"
 	"testing"
@@ -11,37 +13,37 @@ import (
 )
 
 // Helper function to compare floating-point values
-func areEqualRounded(a, b float64) bool {
-	return math.Abs(a-b) < 0.01
+func areEqualRounded(a money.Decimal, b float64) bool {
+	return math.Abs(a.Float64()-b) < 0.01
 }
 
 func TestGetTransactionSummary_MultipleYearsMonthsWeeks(t *testing.T) {
 	repo := &infrastructure.MockTransactionRepository{
 		Transactions: []domain.PersonalTransaction{
 			// 2023
-			{Date: time.Date(2023, time.January, 10, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 100.12},
-			{Date: time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 50.55},
-			{Date: time.Date(2023, time.March, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 300.45},
-			{Date: time.Date(2023, time.March, 10, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 100.12},
-			{Date: time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 75.55},
-			{Date: time.Date(2023, time.April, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 200.45},
+			{UserID: "test-user-id", Date: time.Date(2023, time.January, 10, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("100.12")},
+			{UserID: "test-user-id", Date: time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("50.55")},
+			{UserID: "test-user-id", Date: time.Date(2023, time.March, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("300.45")},
+			{UserID: "test-user-id", Date: time.Date(2023, time.March, 10, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("100.12")},
+			{UserID: "test-user-id", Date: time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("75.55")},
+			{UserID: "test-user-id", Date: time.Date(2023, time.April, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("200.45")},
 
 			// 2022
-			{Date: time.Date(2022, time.November, 20, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 150.12},
-			{Date: time.Date(2022, time.December, 10, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 60.55},
-			{Date: time.Date(2022, time.December, 25, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 120.45},
-			{Date: time.Date(2022, time.December, 30, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 45.55},
+			{UserID: "test-user-id", Date: time.Date(2022, time.November, 20, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("150.12")},
+			{UserID: "test-user-id", Date: time.Date(2022, time.December, 10, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("60.55")},
+			{UserID: "test-user-id", Date: time.Date(2022, time.December, 25, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("120.45")},
+			{UserID: "test-user-id", Date: time.Date(2022, time.December, 30, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("45.55")},
 
 			//  2021
-			{Date: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 80.45},
-			{Date: time.Date(2021, time.March, 20, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 30.55},
-			{Date: time.Date(2021, time.June, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: 50.12},
-			{Date: time.Date(2021, time.June, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: 20.55},
+			{UserID: "test-user-id", Date: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("80.45")},
+			{UserID: "test-user-id", Date: time.Date(2021, time.March, 20, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("30.55")},
+			{UserID: "test-user-id", Date: time.Date(2021, time.June, 5, 0, 0, 0, 0, time.UTC), Type: "income", Amount: money.MustParse("50.12")},
+			{UserID: "test-user-id", Date: time.Date(2021, time.June, 15, 0, 0, 0, 0, time.UTC), Type: "expense", Amount: money.MustParse("20.55")},
 		},
 	}
 	categoryService := &MockCategoryService{}
 	paymentService := &PaymentService{}
-	service := NewPersonalTransactionService(repo, categoryService, paymentService)
+	service := NewPersonalTransactionService(repo, categoryService, paymentService, &MockTagService{})
 
 	startDate, _ := time.Parse("2006-01-02", "2021-01-01")
 	endDate, _ := time.Parse("2006-01-02", "2023-12-31")
@@ -49,39 +51,386 @@ func TestGetTransactionSummary_MultipleYearsMonthsWeeks(t *testing.T) {
 	summary, err := service.GetTransactionSummary("test-user-id", startDate, endDate)
 	assert.NoError(t, err)
 
-	year2023 := summary[2023]
+	year2023 := summary.Year(2023)
 	assert.True(t, areEqualRounded(year2023.IncomeTotal, 701.14), fmt.Sprintf("Expected  income total for 2023 to be 700.70, got: %v", year2023.IncomeTotal))
 	assert.True(t, areEqualRounded(year2023.ExpenseTotal, 126.1), fmt.Sprintf("Expected  expense total for 2023 to be 126.11, got: %v ", year2023.ExpenseTotal))
 
-	january := year2023.Months["January"]
+	january := year2023.Month(time.January)
 	assert.True(t, areEqualRounded(january.IncomeTotal, 100.12), fmt.Sprintf("Expected  January 2023 income to be 100.12, got: %v", january.IncomeTotal))
 	assert.True(t, areEqualRounded(january.ExpenseTotal, 50.55), fmt.Sprintf("Expected  January 2023 expense to be 50.56, got: %v", january.ExpenseTotal))
 
-	march := year2023.Months["March"]
+	march := year2023.Month(time.March)
 	assert.True(t, areEqualRounded(march.IncomeTotal, 400.58), fmt.Sprintf("Expected  March 2023 income to be 400.58, got: %v", march.IncomeTotal))
 	assert.True(t, areEqualRounded(march.ExpenseTotal, 75.55), fmt.Sprintf("Expected  March 2023 expense to be 75.56, got: %v", march.ExpenseTotal))
 
-	april := year2023.Months["April"]
+	april := year2023.Month(time.April)
 	assert.True(t, areEqualRounded(april.IncomeTotal, 200.45), fmt.Sprintf("Expected  April 2023 income to be 200.46, got: %v", april.IncomeTotal))
 	assert.True(t, areEqualRounded(april.ExpenseTotal, 0), fmt.Sprintf("Expected  April 2023 expense to be 0, got: %v", april.ExpenseTotal))
 
-	year2022 := summary[2022]
+	year2022 := summary.Year(2022)
 	assert.True(t, areEqualRounded(year2022.IncomeTotal, 270.58), fmt.Sprintf("Expected  income total for 2022 to be 270.58, got: %v", year2022.IncomeTotal))
 	assert.True(t, areEqualRounded(year2022.ExpenseTotal, 106.1), fmt.Sprintf("Expected  expense total for 2022 to be 106.11, got: %v", year2022.ExpenseTotal))
 
-	december := year2022.Months["December"]
+	december := year2022.Month(time.December)
 	assert.True(t, areEqualRounded(december.IncomeTotal, 120.46), fmt.Sprintf("Expected  December 2022 income to be 120.46, got: %v", december.IncomeTotal))
 	assert.True(t, areEqualRounded(december.ExpenseTotal, 106.1), fmt.Sprintf("Expected  December 2022 expense to be 106.11, got: %v", december.ExpenseTotal))
 
-	year2021 := summary[2021]
+	year2021 := summary.Year(2021)
 	assert.True(t, areEqualRounded(year2021.IncomeTotal, 130.57), fmt.Sprintf("Expected  income total for 2021 to be 130.58, got: %v", year2021.IncomeTotal))
 	assert.True(t, areEqualRounded(year2021.ExpenseTotal, 51.11), fmt.Sprintf("Expected  expense total for 2021 to be 51.11, got: %v", year2021.ExpenseTotal))
 
-	march2021 := year2021.Months["March"]
+	march2021 := year2021.Month(time.March)
 	assert.True(t, areEqualRounded(march2021.IncomeTotal, 80.46), fmt.Sprintf("Expected  March 2021 income to be 80.46, got: %v", march2021.IncomeTotal))
 	assert.True(t, areEqualRounded(march2021.ExpenseTotal, 30.56), fmt.Sprintf("Expected  March 2021 expense to be 30.56, got: %v", march2021.ExpenseTotal))
 
-	june2021 := year2021.Months["June"]
+	june2021 := year2021.Month(time.June)
 	assert.True(t, areEqualRounded(june2021.IncomeTotal, 50.12), fmt.Sprintf("Expected  June 2021 income to be 50.12, got: %v", june2021.IncomeTotal))
 	assert.True(t, areEqualRounded(june2021.ExpenseTotal, 20.56), fmt.Sprintf(fmt.Sprintf("Expected  June 2021 expense to be 20.56, got: %v", june2021.ExpenseTotal)))
+
+	// years and months are ordered, weeks are keyed by the Monday they start on
+	var months []int
+	for _, year := range summary.Years {
+		months = append(months, year.Year*100)
+		for _, month := range year.Months {
+			months = append(months, month.Month)
+		}
+	}
+	assert.Equal(t, []int{202100, 3, 6, 202200, 11, 12, 202300, 1, 3, 4}, months)
+	var weeks []string
+	for _, week := range december.Weeks {
+		weeks = append(weeks, week.StartDate.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{"2022-12-05", "2022-12-19", "2022-12-26"}, weeks)
+}
+
+func TestUpdateAndDeleteTransaction_RequireOwnership(t *testing.T) {
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{ID: "tx-1", UserID: "owner-id", Name: "Lunch", Type: "expense", Amount: money.MustParse("25")},
+		},
+	}
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+
+	err := service.UpdateTransaction(&domain.PersonalTransaction{ID: "tx-1", UserID: "intruder-id", Name: "Lunch", Type: "expense", Amount: money.MustParse("30")})
+	assert.ErrorIs(t, err, financeErrors.ErrTransactionNotFound)
+
+	err = service.DeleteTransaction("tx-1", "intruder-id")
+	assert.ErrorIs(t, err, financeErrors.ErrTransactionNotFound)
+
+	_, err = service.GetTransaction("tx-1", "owner-id")
+	assert.NoError(t, err)
+
+	err = service.DeleteTransaction("tx-1", "owner-id")
+	assert.NoError(t, err)
+	assert.Empty(t, repo.Transactions)
+}
+
+type ownedPaymentSources struct {
+	PaymentServiceInterface
+	sources []int
+}
+
+func (o ownedPaymentSources) DoesUserPaymentSourceExistByID(sourceID int, userID string) (bool, error) {
+	for _, id := range o.sources {
+		if id == sourceID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (o ownedPaymentSources) DoesPaymentMethodExistByID(methodID int) (bool, error) {
+	return true, nil
+}
+
+func (o ownedPaymentSources) GetAllPaymentMethods() ([]domain.PaymentMethod, error) {
+	return []domain.PaymentMethod{{ID: 1, Name: "Card"}}, nil
+}
+
+func (o ownedPaymentSources) GetUserPaymentSources(userID string) ([]domain.PaymentSource, error) {
+	var sources []domain.PaymentSource
+	for _, id := range o.sources {
+		sources = append(sources, domain.PaymentSource{ID: id})
+	}
+	return sources, nil
+}
+
+func (o ownedPaymentSources) GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error) {
+	if exists, _ := o.DoesUserPaymentSourceExistByID(sourceID, userID); !exists {
+		return nil, financeErrors.ErrPaymentSourceNotFound
+	}
+	return &domain.PaymentSource{ID: sourceID, OpeningBalance: money.FromInt(int64(100 * sourceID))}, nil
+}
+
+func TestTransfers_ExcludedFromSummariesButInPaymentSourceHistory(t *testing.T) {
+	checking, savings := 1, 2
+	date := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{UserID: "user-id", Date: date, Type: "income", Amount: money.MustParse("1000"), PredefinedCategoryID: 29, PaymentSourceID: &checking},
+			{UserID: "user-id", Date: date.AddDate(0, 0, 1), Type: "transfer", Amount: money.MustParse("300"), PaymentSourceID: &checking,
+				DestinationPaymentSourceID: &savings},
+		},
+	}
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, ownedPaymentSources{sources: []int{checking, savings}}, &MockTagService{})
+	startDate, endDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	summary, err := service.GetTransactionSummary("user-id", startDate, endDate)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("1000"), summary.Year(2024).IncomeTotal)
+	assert.True(t, summary.Year(2024).ExpenseTotal.IsZero())
+
+	byCategory, err := service.GetTransactionSummaryByCategory("user-id", startDate, endDate, "")
+	assert.NoError(t, err)
+	assert.Len(t, byCategory, 1)
+
+	history, err := service.GetPaymentSourceHistory("user-id", checking, startDate, endDate)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, money.MustParse("-300"), history[1].Change)
+	// opening balance 100, +1000, -300
+	assert.Equal(t, money.MustParse("800"), history[1].Balance)
+
+	history, err = service.GetPaymentSourceHistory("user-id", savings, date.AddDate(0, 0, 1), endDate)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, money.MustParse("300"), history[0].Change)
+	assert.Equal(t, money.MustParse("500"), history[0].Balance)
+
+	_, err = service.GetPaymentSourceHistory("user-id", 7, startDate, endDate)
+	assert.ErrorIs(t, err, financeErrors.ErrInvalidPaymentSource)
+}
+
+func TestTags_ValidatedFilteredAndSummarized(t *testing.T) {
+	vacation, wedding, foreign := 1, 2, 3
+	tags := &MockTagService{Tags: []domain.Tag{
+		{ID: vacation, UserID: "user-id", Name: "vacation-2026"},
+		{ID: wedding, UserID: "user-id", Name: "wedding"},
+		{ID: foreign, UserID: "other-user-id", Name: "vacation-2026"},
+	}}
+	date := time.Date(2026, time.July, 10, 0, 0, 0, 0, time.UTC)
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{ID: "hotel", UserID: "user-id", Date: date, Type: "expense", Amount: money.MustParse("800"), PredefinedCategoryID: 20, TagIDs: []int{vacation}},
+			{ID: "suit", UserID: "user-id", Date: date.AddDate(0, 0, 1), Type: "expense", Amount: money.MustParse("1200"), PredefinedCategoryID: 7, TagIDs: []int{wedding}},
+			{ID: "flights", UserID: "user-id", Date: date.AddDate(0, 0, 2), Type: "expense", Amount: money.MustParse("600"), PredefinedCategoryID: 20, TagIDs: []int{vacation, wedding}},
+			{ID: "salary", UserID: "user-id", Date: date.AddDate(0, 0, 3), Type: "income", Amount: money.MustParse("5000"), PredefinedCategoryID: 29},
+		},
+	}
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, ownedPaymentSources{}, tags)
+
+	transaction := &domain.PersonalTransaction{UserID: "user-id", Name: "Souvenirs", Date: date, Type: "expense", Amount: money.MustParse("50"),
+		PredefinedCategoryID: 20, PaymentMethodID: 1, TagIDs: []int{foreign}}
+	assert.ErrorIs(t, service.validateTransactionReferences(transaction), financeErrors.ErrInvalidTag)
+	transaction.TagIDs = []int{vacation}
+	assert.NoError(t, service.validateTransactionReferences(transaction))
+
+	startDate, endDate := date.AddDate(0, -1, 0), date.AddDate(0, 1, 0)
+	tagged, err := service.GetUserTransactions("user-id", domain.TransactionSearch{TagIDs: []int{vacation}, StartDate: startDate, EndDate: endDate})
+	assert.NoError(t, err)
+	assert.Len(t, tagged.Transactions, 2)
+
+	summary, err := service.GetTransactionSummaryByTag("user-id", startDate, endDate, "expense")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.TransactionByTagSummary{
+		{TagID: vacation, TotalAmount: money.MustParse("1400"), TransactionCount: 2},
+		{TagID: wedding, TotalAmount: money.MustParse("1800"), TransactionCount: 2},
+	}, summary)
+}
+
+func TestSearchTransactions_SortedAndPagedWithCursor(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	checking, savings, foreign := 1, 2, 3
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{ID: "a", UserID: "user-id", Name: "Rent", Date: date, Type: "expense", Amount: money.MustParse("2500"), PaymentSourceID: &checking},
+			{ID: "b", UserID: "user-id", Name: "Groceries Lidl", Date: date.AddDate(0, 0, 1), Type: "expense", Amount: money.MustParse("120"), PaymentSourceID: &checking},
+			{ID: "c", UserID: "user-id", Name: "Groceries Biedronka", Date: date.AddDate(0, 0, 2), Type: "expense", Amount: money.MustParse("120"), PaymentSourceID: &savings},
+			{ID: "d", UserID: "user-id", Name: "Cinema", Date: date.AddDate(0, 0, 3), Type: "expense", Amount: money.MustParse("40"), PaymentSourceID: &checking},
+			{ID: "e", UserID: "other-user", Name: "Groceries", Date: date, Type: "expense", Amount: money.MustParse("10"), PaymentSourceID: &foreign},
+		},
+	}
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+
+	search := domain.TransactionSearch{StartDate: date, EndDate: date.AddDate(0, 1, 0), SortBy: domain.TransactionSortAmount, Ascending: true, Limit: 2}
+	var ids []string
+	for {
+		page, err := service.GetUserTransactions("user-id", search)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, page.TotalCount)
+		for _, transaction := range page.Transactions {
+			ids = append(ids, transaction.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		search.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"d", "b", "c", "a"}, ids)
+
+	minAmount := money.MustParse("100")
+	page, err := service.GetUserTransactions("user-id", domain.TransactionSearch{StartDate: date, EndDate: date.AddDate(0, 1, 0),
+		Text: "groceries", MinAmount: &minAmount, PaymentSourceIDs: []int{checking}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.TotalCount)
+	assert.Equal(t, "b", page.Transactions[0].ID)
+
+	_, err = service.GetUserTransactions("user-id", domain.TransactionSearch{StartDate: date, EndDate: date, Cursor: search.Cursor})
+	assert.True(t, financeErrors.IsValidationError(err))
+}
+
+func TestPlanBulkCreate_DuplicatePolicies(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	reconciliationID := 4
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			{ID: "rent", UserID: "user-id", Name: "Rent", Date: date, Type: "expense", Amount: money.MustParse("2500")},
+			{ID: "salary", UserID: "user-id", Name: "Salary", Date: date, Type: "income", Amount: money.MustParse("5000"), ReconciliationID: &reconciliationID},
+		},
+	}
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+	transactions := []*domain.PersonalTransaction{
+		{Name: "RENT", Date: date, Type: "expense", Amount: money.MustParse("2500")},
+		{Name: "Salary", Date: date, Type: "income", Amount: money.MustParse("5000")},
+		{Name: "Cinema", Date: date, Type: "expense", Amount: money.MustParse("40")},
+	}
+
+	actions := func(policy domain.DuplicatePolicy) []domain.BulkAction {
+		result, err := service.planBulkCreate(transactions, []int{0, 1, 2}, "user-id", domain.BulkCreateOptions{DuplicatePolicy: policy})
+		assert.NoError(t, err)
+		var actions []domain.BulkAction
+		for _, row := range result.Rows {
+			actions = append(actions, row.Action)
+		}
+		assert.Equal(t, []domain.DuplicateStatus{domain.DuplicateStatusDuplicate, domain.DuplicateStatusDuplicate, domain.DuplicateStatusNew},
+			[]domain.DuplicateStatus{result.Rows[0].Status, result.Rows[1].Status, result.Rows[2].Status})
+		assert.Equal(t, "rent", result.Rows[0].MatchedTransactionID)
+		return actions
+	}
+
+	assert.Equal(t, []domain.BulkAction{domain.BulkActionSkip, domain.BulkActionSkip, domain.BulkActionCreate}, actions(domain.DuplicatePolicySkip))
+	// reconciled transactions are never overwritten
+	assert.Equal(t, []domain.BulkAction{domain.BulkActionOverwrite, domain.BulkActionSkip, domain.BulkActionCreate}, actions(domain.DuplicatePolicyOverwrite))
+	assert.Equal(t, []domain.BulkAction{domain.BulkActionCreate, domain.BulkActionCreate, domain.BulkActionCreate}, actions(domain.DuplicatePolicyImport))
+}
+
+type knownCategories struct {
+	MockCategoryService
+}
+
+func (k knownCategories) GetAllPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error) {
+	return []domain.PredefinedCategory{{ID: 9}, {ID: 17}}, nil
+}
+
+func TestCreateTransactionsBulk_PartialDryRun(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &infrastructure.MockTransactionRepository{}
+	service := NewPersonalTransactionService(repo, &knownCategories{}, ownedPaymentSources{}, &MockTagService{})
+	transactions := func() []*domain.PersonalTransaction {
+		return []*domain.PersonalTransaction{
+			{Name: "Groceries", Date: date, Type: "expense", Amount: money.MustParse("87.20"), PredefinedCategoryID: 9, PaymentMethodID: 1},
+			{Name: "Rent", Date: date, Type: "expense", Amount: money.MustParse("-2500"), PredefinedCategoryID: 17, PaymentMethodID: 1},
+			{Name: "Cinema", Date: date, Type: "expense", Amount: money.MustParse("40"), PredefinedCategoryID: 99, PaymentMethodID: 1},
+		}
+	}
+
+	_, err := service.CreateTransactionsBulk(transactions(), "user-id", domain.BulkCreateOptions{DryRun: true})
+	assert.True(t, financeErrors.IsValidationErrors(err))
+
+	result, err := service.CreateTransactionsBulk(transactions(), "user-id", domain.BulkCreateOptions{DryRun: true, Partial: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Rejected)
+	assert.Equal(t, []domain.BulkRowResult{
+		{Index: 1, Status: domain.DuplicateStatusNew, Action: domain.BulkActionCreate},
+		{Index: 2, Action: domain.BulkActionReject, Error: "Validation error at transaction 2: Amount must be greater than zero"},
+		{Index: 3, Action: domain.BulkActionReject, Error: "Validation error at transaction 3: Invalid predefined category ID"},
+	}, result.Rows)
+}
+
+func TestGetTransactionComparison_DefaultsToMonthToDateOfUser(t *testing.T) {
+	transaction := func(userID string, date time.Time, transactionType string, categoryID, methodID int, amount string) domain.PersonalTransaction {
+		return domain.PersonalTransaction{UserID: userID, Date: date, Type: transactionType, PredefinedCategoryID: categoryID, PaymentMethodID: methodID, Amount: money.MustParse(amount)}
+	}
+	repo := &infrastructure.MockTransactionRepository{
+		Transactions: []domain.PersonalTransaction{
+			transaction("test-user-id", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC), "expense", 9, 1, "120"),
+			transaction("test-user-id", time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC), "expense", 9, 2, "80"),
+			transaction("test-user-id", time.Date(2026, time.September, 10, 0, 0, 0, 0, time.UTC), "expense", 9, 1, "150"),
+			transaction("test-user-id", time.Date(2026, time.September, 12, 0, 0, 0, 0, time.UTC), "income", 30, 1, "5000"),
+			transaction("test-user-id", time.Date(2026, time.September, 20, 0, 0, 0, 0, time.UTC), "transfer", 31, 1, "700"),
+			transaction("test-user-id", time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC), "expense", 17, 1, "1200"),
+			transaction("test-user-id", time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), "expense", 9, 1, "60"),
+			transaction("test-user-id", time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC), "expense", 9, 1, "999"),
+			transaction("other-user-id", time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC), "expense", 9, 1, "999"),
+		},
+	}
+	settings := &infrastructure.MockFinanceSettingsRepository{}
+	warsaw := domain.DefaultFinanceSettings("test-user-id")
+	warsaw.Timezone = "Europe/Warsaw"
+	settingsService := NewFinanceSettingsService(settings)
+	assert.NoError(t, settingsService.UpdateSettings(&warsaw))
+
+	service := NewPersonalTransactionService(repo, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+	service.SetSettingsProvider(settingsService)
+	// still the 19th in UTC, already the 20th in Warsaw
+	service.now = func() time.Time { return time.Date(2026, time.October, 19, 22, 30, 0, 0, time.UTC) }
+
+	comparison, err := service.GetTransactionComparison("test-user-id", domain.TransactionComparisonOptions{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.ComparisonPeriod{StartDate: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)}, comparison.Period)
+	assert.Equal(t, domain.ComparisonPeriod{StartDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.September, 20, 0, 0, 0, 0, time.UTC)}, comparison.ComparePeriod)
+	assert.Equal(t, money.MustParse("200"), comparison.Total.Amount)
+	assert.Equal(t, money.MustParse("150"), comparison.Total.CompareAmount)
+	// 150 + 1200 + 60 over 12 months
+	assert.Equal(t, money.MustParse("117.50"), comparison.Total.MonthlyAverage)
+
+	if assert.Len(t, comparison.Categories, 2) {
+		assert.Equal(t, 9, comparison.Categories[0].CategoryID)
+		assert.Equal(t, money.MustParse("50"), comparison.Categories[0].Change)
+		assert.Equal(t, 33.33, *comparison.Categories[0].ChangePercent)
+		assert.Equal(t, money.MustParse("17.50"), comparison.Categories[0].MonthlyAverage)
+
+		assert.Equal(t, 17, comparison.Categories[1].CategoryID)
+		assert.Nil(t, comparison.Categories[1].ChangePercent)
+		// 1200 scaled to 20 of the 365 days of the average
+		assert.Equal(t, money.MustParse("-65.75"), comparison.Categories[1].AverageChange)
+		assert.Equal(t, -100.0, *comparison.Categories[1].AverageChangePercent)
+	}
+
+	if assert.Len(t, comparison.PaymentMethods, 2) {
+		assert.Equal(t, 1, comparison.PaymentMethods[0].PaymentMethodID)
+		assert.Equal(t, money.MustParse("-30"), comparison.PaymentMethods[0].Change)
+		assert.Equal(t, -20.0, *comparison.PaymentMethods[0].ChangePercent)
+		assert.Equal(t, 2, comparison.PaymentMethods[1].PaymentMethodID)
+		assert.Nil(t, comparison.PaymentMethods[1].ChangePercent)
+	}
+}
+
+func TestGetTransactionComparison_InvalidOptions(t *testing.T) {
+	service := NewPersonalTransactionService(&infrastructure.MockTransactionRepository{}, &MockCategoryService{}, &PaymentService{}, &MockTagService{})
+
+	_, err := service.GetTransactionComparison("test-user-id", domain.TransactionComparisonOptions{Type: "transfer"})
+	assert.True(t, financeErrors.IsValidationError(err))
+}
+
+func TestCreateTransactionsBulk_AppliesCategorizationRules(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	groceries := 9
+	rules := &infrastructure.MockCategorizationRuleRepository{Rules: []domain.CategorizationRule{
+		{ID: 1, UserID: "user-id", Name: "Biedronka", MatchField: domain.RuleMatchName, MatchType: domain.RuleMatchContains, Pattern: "biedronka warszawa", PredefinedCategoryID: &groceries},
+	}}
+	repo := &infrastructure.MockTransactionRepository{}
+	service := NewPersonalTransactionService(repo, &knownCategories{}, ownedPaymentSources{}, &MockTagService{})
+	service.SetCategorizer(NewCategorizationService(rules, repo, &knownCategories{}, &PaymentService{}, &MockTagService{}))
+
+	// the category of the request is unknown, the rule replaces it before the transactions are validated
+	transactions := []*domain.PersonalTransaction{
+		{Name: "BIEDRONKA 1234 WARSZAWA", Date: date, Type: "expense", Amount: money.MustParse("45.10"), PredefinedCategoryID: 99, PaymentMethodID: 1},
+	}
+	result, err := service.CreateTransactionsBulk(transactions, "user-id", domain.BulkCreateOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, groceries, transactions[0].PredefinedCategoryID)
 }
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+type PaymentSourceProvider interface {
+	GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error)
+}
+
+type PaymentSourceHistoryProvider interface {
+	GetPaymentSourceHistory(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PaymentSourceHistoryEntry, error)
+}
+
+type ReconciliationService struct {
+	repo            domain.ReconciliationRepository
+	paymentSources  PaymentSourceProvider
+	historyProvider PaymentSourceHistoryProvider
+}
+
+func NewReconciliationService(repo domain.ReconciliationRepository, paymentSources PaymentSourceProvider, historyProvider PaymentSourceHistoryProvider) *ReconciliationService {
+	return &ReconciliationService{repo: repo, paymentSources: paymentSources, historyProvider: historyProvider}
+}
+
+func (s *ReconciliationService) GetReconciliations(paymentSourceID int, userID string) ([]domain.Reconciliation, error) {
+	if _, err := s.paymentSources.GetUserPaymentSource(paymentSourceID, userID); err != nil {
+		return nil, err
+	}
+	reconciliations, err := s.repo.FindByPaymentSource(paymentSourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if reconciliations == nil {
+		return []domain.Reconciliation{}, nil
+	}
+	return reconciliations, nil
+}
+
+// PreviewReconciliation lists the transactions of the payment source up to the statement date which haven't been
+// reconciled yet, together with the difference between the statement balance and the balance of the reconciled ones.
+func (s *ReconciliationService) PreviewReconciliation(paymentSourceID int, userID string, statementDate time.Time, statementBalance money.Decimal) (*domain.ReconciliationPreview, error) {
+	source, err := s.paymentSources.GetUserPaymentSource(paymentSourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.historyProvider.GetPaymentSourceHistory(userID, paymentSourceID, time.Time{}, statementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	statementBalance = statementBalance.RoundToCurrency(money.DefaultCurrency)
+	preview := &domain.ReconciliationPreview{
+		PaymentSourceID:  paymentSourceID,
+		StatementDate:    statementDate,
+		StatementBalance: statementBalance,
+		ClearedBalance:   source.OpeningBalance,
+		Uncleared:        []domain.PaymentSourceHistoryEntry{},
+	}
+	for _, entry := range history {
+		if entry.Transaction.IsReconciledOn(paymentSourceID) {
+			preview.ClearedBalance = preview.ClearedBalance.Add(entry.Change)
+		} else {
+			preview.Uncleared = append(preview.Uncleared, entry)
+		}
+	}
+	preview.Difference = statementBalance.Sub(preview.ClearedBalance)
+	return preview, nil
+}
+
+// Reconcile marks the transactions as reconciled. They must be uncleared transactions of the payment source up to
+// the statement date and bring the cleared balance exactly to the statement balance.
+func (s *ReconciliationService) Reconcile(reconciliation *domain.Reconciliation, userID string) error {
+	if err := reconciliation.Validate(); err != nil {
+		return err
+	}
+	preview, err := s.PreviewReconciliation(reconciliation.PaymentSourceID, userID, reconciliation.StatementDate, reconciliation.StatementBalance)
+	if err != nil {
+		return err
+	}
+	reconciliation.StatementBalance = preview.StatementBalance
+
+	changes := make(map[string]money.Decimal, len(preview.Uncleared))
+	for _, entry := range preview.Uncleared {
+		changes[entry.Transaction.ID] = entry.Change
+	}
+	cleared := money.Zero
+	for _, id := range reconciliation.TransactionIDs {
+		change, ok := changes[id]
+		if !ok {
+			return financeErrors.NewValidationError(fmt.Sprintf("Transaction %s is not an uncleared transaction of the payment source up to the statement date", id))
+		}
+		cleared = cleared.Add(change)
+	}
+	if remaining := preview.Difference.Sub(cleared); !remaining.IsZero() {
+		return financeErrors.NewValidationError(fmt.Sprintf("The selected transactions leave a difference of %s to the statement balance", remaining))
+	}
+
+	if err := s.repo.Create(reconciliation, userID); err != nil {
+		if errors.Is(err, financeErrors.ErrTransactionReconciled) {
+			return financeErrors.NewValidationError("Some of the selected transactions have already been reconciled")
+		}
+		return err
+	}
+	return nil
+}
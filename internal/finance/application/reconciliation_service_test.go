@@ -0,0 +1,85 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReconcile_ClearsTransactionsAndProtectsThemFromEdits(t *testing.T) {
+	checking := 1
+	date := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "salary", UserID: "user-id", Name: "Salary", Date: date, Type: "income", Amount: money.MustParse("1000"), PredefinedCategoryID: 29, PaymentMethodID: 1, PaymentSourceID: &checking},
+		{ID: "rent", UserID: "user-id", Name: "Rent", Date: date.AddDate(0, 0, 5), Type: "expense", Amount: money.MustParse("600"), PredefinedCategoryID: 17, PaymentMethodID: 1, PaymentSourceID: &checking},
+		{ID: "lunch", UserID: "user-id", Name: "Lunch", Date: date.AddDate(0, 1, 0), Type: "expense", Amount: money.MustParse("25"), PredefinedCategoryID: 3, PaymentMethodID: 1, PaymentSourceID: &checking},
+	}}
+	paymentSources := ownedPaymentSources{sources: []int{checking}}
+	transactionService := NewPersonalTransactionService(transactions, &MockCategoryService{}, paymentSources, &MockTagService{})
+	service := NewReconciliationService(&infrastructure.MockReconciliationRepository{Transactions: transactions}, paymentSources, transactionService)
+
+	statementDate := date.AddDate(0, 0, 30)
+	preview, err := service.PreviewReconciliation(checking, "user-id", statementDate, money.MustParse("500"))
+	assert.NoError(t, err)
+	// opening balance of the test source is 100, the lunch is after the statement date
+	assert.Equal(t, money.MustParse("100"), preview.ClearedBalance)
+	assert.Equal(t, money.MustParse("400"), preview.Difference)
+	assert.Len(t, preview.Uncleared, 2)
+
+	reconciliation := &domain.Reconciliation{PaymentSourceID: checking, StatementDate: statementDate, StatementBalance: money.MustParse("500"), TransactionIDs: []string{"salary"}}
+	err = service.Reconcile(reconciliation, "user-id")
+	assert.EqualError(t, err, "The selected transactions leave a difference of -600.00 to the statement balance")
+
+	reconciliation.TransactionIDs = []string{"salary", "lunch"}
+	assert.True(t, financeErrors.IsValidationError(service.Reconcile(reconciliation, "user-id")))
+
+	tooLarge := &domain.Reconciliation{PaymentSourceID: checking, StatementDate: statementDate, StatementBalance: money.MustParse("10000000000"), TransactionIDs: []string{"salary"}}
+	assert.EqualError(t, service.Reconcile(tooLarge, "user-id"), "StatementBalance must be between -10000000000 and 10000000000")
+
+	reconciliation.TransactionIDs = []string{"salary", "rent"}
+	assert.NoError(t, service.Reconcile(reconciliation, "user-id"))
+
+	preview, err = service.PreviewReconciliation(checking, "user-id", statementDate, money.MustParse("500"))
+	assert.NoError(t, err)
+	assert.True(t, preview.Difference.IsZero())
+	assert.Empty(t, preview.Uncleared)
+
+	rent := transactions.Transactions[1]
+	rent.Amount = money.MustParse("650")
+	assert.ErrorIs(t, transactionService.UpdateTransaction(&rent), financeErrors.ErrTransactionReconciled)
+}
+
+func TestReconcile_TransferOnEachPaymentSource(t *testing.T) {
+	checking, savings := 1, 2
+	date := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{ID: "transfer", UserID: "user-id", Name: "To savings", Date: date, Type: "transfer", Amount: money.MustParse("300"), PaymentMethodID: 1,
+			PaymentSourceID: &checking, DestinationPaymentSourceID: &savings},
+	}}
+	paymentSources := ownedPaymentSources{sources: []int{checking, savings}}
+	transactionService := NewPersonalTransactionService(transactions, &MockCategoryService{}, paymentSources, &MockTagService{})
+	service := NewReconciliationService(&infrastructure.MockReconciliationRepository{Transactions: transactions}, paymentSources, transactionService)
+
+	statementDate := date.AddDate(0, 0, 30)
+	assert.NoError(t, service.Reconcile(&domain.Reconciliation{PaymentSourceID: checking, StatementDate: statementDate,
+		StatementBalance: money.MustParse("-200"), TransactionIDs: []string{"transfer"}}, "user-id"))
+
+	// reconciling the source doesn't clear the transfer on the savings account
+	preview, err := service.PreviewReconciliation(savings, "user-id", statementDate, money.MustParse("500"))
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("200"), preview.ClearedBalance)
+	assert.Len(t, preview.Uncleared, 1)
+
+	assert.NoError(t, service.Reconcile(&domain.Reconciliation{PaymentSourceID: savings, StatementDate: statementDate,
+		StatementBalance: money.MustParse("500"), TransactionIDs: []string{"transfer"}}, "user-id"))
+	assert.NotNil(t, transactions.Transactions[0].ReconciliationID)
+	assert.NotNil(t, transactions.Transactions[0].DestinationReconciliationID)
+
+	err = service.Reconcile(&domain.Reconciliation{PaymentSourceID: savings, StatementDate: statementDate,
+		StatementBalance: money.MustParse("500"), TransactionIDs: []string{"transfer"}}, "user-id")
+	assert.True(t, financeErrors.IsValidationError(err))
+}
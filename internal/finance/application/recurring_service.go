@@ -0,0 +1,265 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"log"
+	"time"
+)
+
+type TransactionValidator interface {
+	ValidateTransaction(transaction *domain.PersonalTransaction) error
+}
+
+type RecurringTransactionService struct {
+	repo          domain.RecurringRuleRepository
+	validator     TransactionValidator
+	budgetAlerter BudgetAlerter
+}
+
+func NewRecurringTransactionService(repo domain.RecurringRuleRepository, validator TransactionValidator) *RecurringTransactionService {
+	return &RecurringTransactionService{repo: repo, validator: validator}
+}
+
+// SetBudgetAlerter makes booked occurrences raise the same budget alerts as transactions created by the user.
+func (s *RecurringTransactionService) SetBudgetAlerter(budgetAlerter BudgetAlerter) {
+	s.budgetAlerter = budgetAlerter
+}
+
+func (s *RecurringTransactionService) GetUserRules(userID string) ([]domain.RecurringRule, error) {
+	rules, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return []domain.RecurringRule{}, nil
+	}
+	return rules, nil
+}
+
+func (s *RecurringTransactionService) GetRule(ruleID, userID string) (*domain.RecurringRule, error) {
+	rule, err := s.repo.FindByID(ruleID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrRecurringRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RecurringTransactionService) CreateRule(rule *domain.RecurringRule) error {
+	rule.ID = uuid.NewString()
+	rule.GeneratedUntil = nil
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+	return s.repo.Create(rule)
+}
+
+// UpdateRule changes the schedule and template of the rule. Occurrences which have already been booked are left untouched.
+func (s *RecurringTransactionService) UpdateRule(rule *domain.RecurringRule) error {
+	stored, err := s.GetRule(rule.ID, rule.UserID)
+	if err != nil {
+		return err
+	}
+	rule.GeneratedUntil = stored.GeneratedUntil
+
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*rule)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrRecurringRuleNotFound
+	}
+	return nil
+}
+
+func (s *RecurringTransactionService) DeleteRule(ruleID, userID string) error {
+	affected, err := s.repo.Delete(ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrRecurringRuleNotFound
+	}
+	return nil
+}
+
+func (s *RecurringTransactionService) validateRule(rule *domain.RecurringRule) error {
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	rule.StartDate = domain.TruncateToDate(rule.StartDate)
+	if rule.EndDate != nil {
+		endDate := domain.TruncateToDate(*rule.EndDate)
+		rule.EndDate = &endDate
+	}
+
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	transaction := rule.TransactionOn(rule.StartDate, nil)
+	if err := s.validator.ValidateTransaction(&transaction); err != nil {
+		return err
+	}
+	rule.Template.Amount = transaction.Amount
+	return nil
+}
+
+// PreviewOccurrences lists up to limit occurrences of the rule starting at from, including skipped and modified ones.
+func (s *RecurringTransactionService) PreviewOccurrences(ruleID, userID string, from, to time.Time, limit int) ([]domain.RecurringOccurrence, error) {
+	rule, err := s.GetRule(ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.exceptionsByDate(rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []domain.RecurringOccurrence{}
+	for _, date := range rule.OccurrencesBetween(from, to, limit) {
+		exception, modified := exceptions[date]
+		occurrence := domain.RecurringOccurrence{
+			Date:        date,
+			Modified:    modified && !exception.Skipped,
+			Skipped:     modified && exception.Skipped,
+			Booked:      rule.GeneratedUntil != nil && !date.After(*rule.GeneratedUntil),
+			Transaction: rule.TransactionOn(date, exception),
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// SetOccurrenceException skips or modifies a single occurrence which has not been booked yet.
+func (s *RecurringTransactionService) SetOccurrenceException(exception *domain.RecurringException, userID string) error {
+	rule, err := s.GetRule(exception.RuleID, userID)
+	if err != nil {
+		return err
+	}
+
+	exception.OccurrenceDate = domain.TruncateToDate(exception.OccurrenceDate)
+	if !rule.IsOccurrence(exception.OccurrenceDate) {
+		return financeErrors.ErrNotAnOccurrence
+	}
+	if rule.GeneratedUntil != nil && !exception.OccurrenceDate.After(*rule.GeneratedUntil) {
+		return financeErrors.ErrOccurrenceAlreadyBooked
+	}
+
+	if !exception.Skipped {
+		transaction := rule.TransactionOn(exception.OccurrenceDate, exception)
+		if err := s.validator.ValidateTransaction(&transaction); err != nil {
+			return err
+		}
+		if exception.Amount != nil {
+			exception.Amount = &transaction.Amount
+		}
+	}
+
+	return s.repo.SaveException(*exception)
+}
+
+// DeleteOccurrenceException brings back the occurrence as defined by the rule template.
+func (s *RecurringTransactionService) DeleteOccurrenceException(ruleID, userID string, occurrenceDate time.Time) error {
+	rule, err := s.GetRule(ruleID, userID)
+	if err != nil {
+		return err
+	}
+
+	occurrenceDate = domain.TruncateToDate(occurrenceDate)
+	if rule.GeneratedUntil != nil && !occurrenceDate.After(*rule.GeneratedUntil) {
+		return financeErrors.ErrOccurrenceAlreadyBooked
+	}
+
+	_, err = s.repo.DeleteException(rule.ID, occurrenceDate)
+	return err
+}
+
+func (s *RecurringTransactionService) exceptionsByDate(ruleID string) (map[time.Time]*domain.RecurringException, error) {
+	exceptions, err := s.repo.FindExceptions(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]*domain.RecurringException, len(exceptions))
+	for i := range exceptions {
+		byDate[domain.TruncateToDate(exceptions[i].OccurrenceDate)] = &exceptions[i]
+	}
+	return byDate, nil
+}
+
+// GenerateDueTransactions books all occurrences of all rules due up to now. A rule whose transactions no longer pass
+// validation (e.g. its payment source was archived) is logged and left for the next run.
+func (s *RecurringTransactionService) GenerateDueTransactions(now time.Time) (int, error) {
+	today := domain.TruncateToDate(now)
+	rules, err := s.repo.FindDue(today)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for i := range rules {
+		count, err := s.generateRuleTransactions(&rules[i], today)
+		if err != nil {
+			log.Printf("Error generating transactions for recurring rule %s: %v", rules[i].ID, err)
+			continue
+		}
+		generated += count
+	}
+	return generated, nil
+}
+
+func (s *RecurringTransactionService) generateRuleTransactions(rule *domain.RecurringRule, today time.Time) (int, error) {
+	from := rule.StartDate
+	if rule.GeneratedUntil != nil {
+		from = rule.GeneratedUntil.AddDate(0, 0, 1)
+	}
+
+	exceptions, err := s.exceptionsByDate(rule.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	var transactions []domain.RecurringOccurrenceTransaction
+	for _, date := range rule.OccurrencesBetween(from, today, 0) {
+		exception := exceptions[date]
+		if exception != nil && exception.Skipped {
+			continue
+		}
+
+		transaction := rule.TransactionOn(date, exception)
+		transaction.ID = uuid.NewString()
+		if err := s.validator.ValidateTransaction(&transaction); err != nil {
+			return 0, err
+		}
+		transactions = append(transactions, domain.RecurringOccurrenceTransaction{OccurrenceDate: date, Transaction: transaction})
+	}
+
+	if err := s.repo.SaveOccurrences(rule.ID, transactions, today); err != nil {
+		return 0, err
+	}
+	s.checkBudgetAlerts(rule.UserID, transactions)
+	return len(transactions), nil
+}
+
+func (s *RecurringTransactionService) checkBudgetAlerts(userID string, occurrences []domain.RecurringOccurrenceTransaction) {
+	if s.budgetAlerter == nil || len(occurrences) == 0 {
+		return
+	}
+	transactions := make([]domain.PersonalTransaction, len(occurrences))
+	for i, occurrence := range occurrences {
+		transactions[i] = occurrence.Transaction
+	}
+	s.budgetAlerter.CheckBudgetAlerts(userID, transactions)
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type domainOnlyValidator struct{}
+
+func (domainOnlyValidator) ValidateTransaction(transaction *domain.PersonalTransaction) error {
+	transaction.RoundToMinorUnits()
+	return transaction.Validate()
+}
+
+func TestGenerateDueTransactions_SkipsAndModifiesOccurrences(t *testing.T) {
+	repo := &infrastructure.MockRecurringRuleRepository{}
+	service := NewRecurringTransactionService(repo, domainOnlyValidator{})
+
+	rule := &domain.RecurringRule{
+		UserID:    "user-id",
+		Frequency: domain.RecurrenceMonthly,
+		StartDate: time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+		Template:  domain.RecurringTemplate{Name: "Rent", Amount: money.MustParse("2000"), Type: "expense", PredefinedCategoryID: 17, PaymentMethodID: 1},
+	}
+	assert.NoError(t, service.CreateRule(rule))
+
+	amount := money.MustParse("2100")
+	assert.NoError(t, service.SetOccurrenceException(&domain.RecurringException{
+		RuleID: rule.ID, OccurrenceDate: time.Date(2024, time.February, 10, 0, 0, 0, 0, time.UTC), Skipped: true,
+	}, "user-id"))
+	assert.NoError(t, service.SetOccurrenceException(&domain.RecurringException{
+		RuleID: rule.ID, OccurrenceDate: time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC), Amount: &amount,
+	}, "user-id"))
+
+	err := service.SetOccurrenceException(&domain.RecurringException{
+		RuleID: rule.ID, OccurrenceDate: time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), Skipped: true,
+	}, "user-id")
+	assert.ErrorIs(t, err, financeErrors.ErrNotAnOccurrence)
+
+	generated, err := service.GenerateDueTransactions(time.Date(2024, time.April, 15, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, generated)
+	assert.Equal(t, money.MustParse("2000"), repo.Transactions[0].Transaction.Amount)
+	assert.Equal(t, money.MustParse("2100"), repo.Transactions[1].Transaction.Amount)
+	assert.Equal(t, time.Date(2024, time.April, 10, 0, 0, 0, 0, time.UTC), repo.Transactions[2].Transaction.Date)
+
+	// running the job again on the same day must not book anything twice
+	generated, err = service.GenerateDueTransactions(time.Date(2024, time.April, 15, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, generated)
+
+	err = service.SetOccurrenceException(&domain.RecurringException{
+		RuleID: rule.ID, OccurrenceDate: time.Date(2024, time.April, 10, 0, 0, 0, 0, time.UTC), Skipped: true,
+	}, "user-id")
+	assert.ErrorIs(t, err, financeErrors.ErrOccurrenceAlreadyBooked)
+}
+
+type recordedBudgetAlerts map[string][]domain.PersonalTransaction
+
+func (r recordedBudgetAlerts) CheckBudgetAlerts(userID string, transactions []domain.PersonalTransaction) {
+	r[userID] = append(r[userID], transactions...)
+}
+
+func TestGenerateDueTransactions_StopsAfterCountAndChecksBudgets(t *testing.T) {
+	repo := &infrastructure.MockRecurringRuleRepository{}
+	alerts := recordedBudgetAlerts{}
+	service := NewRecurringTransactionService(repo, domainOnlyValidator{})
+	service.SetBudgetAlerter(alerts)
+
+	count := 2
+	rule := &domain.RecurringRule{
+		UserID:    "user-id",
+		Frequency: domain.RecurrenceWeekly,
+		StartDate: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Count:     &count,
+		Template:  domain.RecurringTemplate{Name: "Cleaning", Amount: money.MustParse("150"), Type: "expense", PredefinedCategoryID: 20, PaymentMethodID: 1},
+	}
+	assert.NoError(t, service.CreateRule(rule))
+
+	generated, err := service.GenerateDueTransactions(time.Date(2024, time.January, 3, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	generated, err = service.GenerateDueTransactions(time.Date(2024, time.January, 10, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	if assert.Len(t, alerts["user-id"], 2) {
+		assert.Equal(t, time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC), alerts["user-id"][1].Date)
+	}
+
+	// the rule ended with its second occurrence, it's no longer due
+	due, err := repo.FindDue(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+}
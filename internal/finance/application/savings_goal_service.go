@@ -0,0 +1,281 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	emailService "github.com/sebuszqo/FinanceManager/internal/email"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"log"
+	"time"
+)
+
+type PortfolioValueProvider interface {
+	GetPortfolioValue(userID, portfolioID string) (money.Decimal, error)
+}
+
+type SavingsGoalService struct {
+	repo            domain.SavingsGoalRepository
+	summaryProvider CategorySummaryProvider
+	categoryService CategoryServiceInterface
+	paymentService  PaymentServiceInterface
+	portfolios      PortfolioValueProvider
+	userProvider    UserProvider
+	emailSender     emailService.EmailSender
+	now             func() time.Time
+}
+
+func NewSavingsGoalService(repo domain.SavingsGoalRepository, summaryProvider CategorySummaryProvider, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface, portfolios PortfolioValueProvider, userProvider UserProvider, emailSender emailService.EmailSender) *SavingsGoalService {
+	return &SavingsGoalService{
+		repo:            repo,
+		summaryProvider: summaryProvider,
+		categoryService: categoryService,
+		paymentService:  paymentService,
+		portfolios:      portfolios,
+		userProvider:    userProvider,
+		emailSender:     emailSender,
+		now:             time.Now,
+	}
+}
+
+func (s *SavingsGoalService) GetGoal(goalID int, userID string) (*domain.SavingsGoal, error) {
+	goal, err := s.repo.FindByID(goalID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrSavingsGoalNotFound
+		}
+		return nil, err
+	}
+	return goal, nil
+}
+
+func (s *SavingsGoalService) GetUserGoalsProgress(userID string) ([]domain.SavingsGoalProgress, error) {
+	goals, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	progress := make([]domain.SavingsGoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		goalProgress, err := s.progress(goal, s.now())
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, goalProgress)
+	}
+	return progress, nil
+}
+
+func (s *SavingsGoalService) GetGoalProgress(goalID int, userID string) (*domain.SavingsGoalProgress, error) {
+	goal, err := s.GetGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.progress(*goal, s.now())
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// CreateGoal starts tracking the goal today, from the amount its funding holds now.
+func (s *SavingsGoalService) CreateGoal(goal *domain.SavingsGoal) error {
+	goal.ID = 0
+	if err := s.validateGoal(goal); err != nil {
+		return err
+	}
+	if err := s.restart(goal); err != nil {
+		return err
+	}
+	return s.repo.Create(goal)
+}
+
+// UpdateGoal changes the goal. Changing where the goal is funded from starts tracking it anew, as the contributions
+// made to the previous funding say nothing about the new one.
+func (s *SavingsGoalService) UpdateGoal(goal *domain.SavingsGoal) error {
+	stored, err := s.GetGoal(goal.ID, goal.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateGoal(goal); err != nil {
+		return err
+	}
+
+	if goal.SameFunding(*stored) {
+		goal.StartDate, goal.StartingAmount = stored.StartDate, stored.StartingAmount
+	} else if err := s.restart(goal); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*goal)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrSavingsGoalNotFound
+	}
+	return nil
+}
+
+func (s *SavingsGoalService) DeleteGoal(goalID int, userID string) error {
+	affected, err := s.repo.Delete(goalID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrSavingsGoalNotFound
+	}
+	return nil
+}
+
+func (s *SavingsGoalService) validateGoal(goal *domain.SavingsGoal) error {
+	goal.RoundToMinorUnits()
+	goal.TargetDate = domain.TruncateToDate(goal.TargetDate)
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+	if !goal.TargetDate.After(domain.TruncateToDate(s.now())) {
+		return financeErrors.NewValidationError("Target date must be in the future")
+	}
+
+	if goal.PaymentSourceID != nil {
+		exists, err := s.paymentService.DoesUserPaymentSourceExistByID(*goal.PaymentSourceID, goal.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return financeErrors.ErrInvalidPaymentSource
+		}
+	}
+	return nil
+}
+
+func (s *SavingsGoalService) restart(goal *domain.SavingsGoal) error {
+	today := domain.TruncateToDate(s.now())
+	if goal.Funding == domain.SavingsGoalFundingCategory {
+		return goal.Restart(today, money.Zero)
+	}
+	goal.StartDate = today
+	saved, err := s.saved(*goal, today)
+	if err != nil {
+		return err
+	}
+	return goal.Restart(today, saved)
+}
+
+func (s *SavingsGoalService) progress(goal domain.SavingsGoal, today time.Time) (domain.SavingsGoalProgress, error) {
+	saved, err := s.saved(goal, today)
+	if err != nil {
+		return domain.SavingsGoalProgress{}, err
+	}
+	return domain.NewSavingsGoalProgress(goal, saved, today), nil
+}
+
+// saved returns the amount the goal has reached: the contributions to the Savings category since the start of the
+// goal, the balance of its payment source or the value of its portfolio.
+func (s *SavingsGoalService) saved(goal domain.SavingsGoal, today time.Time) (money.Decimal, error) {
+	switch goal.Funding {
+	case domain.SavingsGoalFundingPaymentSource:
+		source, err := s.paymentService.GetUserPaymentSource(*goal.PaymentSourceID, goal.UserID)
+		if err != nil {
+			if errors.Is(err, financeErrors.ErrPaymentSourceNotFound) {
+				return money.Zero, financeErrors.ErrInvalidPaymentSource
+			}
+			return money.Zero, err
+		}
+		return source.Balance, nil
+	case domain.SavingsGoalFundingPortfolio:
+		return s.portfolios.GetPortfolioValue(goal.UserID, *goal.PortfolioID)
+	default:
+		categoryID, err := s.savingsCategoryID()
+		if err != nil {
+			return money.Zero, err
+		}
+		summaries, err := s.summaryProvider.GetTransactionSummaryByCategory(goal.UserID, goal.StartDate, today, string(domain.TransactionTypeExpense))
+		if err != nil {
+			return money.Zero, err
+		}
+		for _, summary := range summaries {
+			if summary.CategoryID == categoryID {
+				return summary.TotalAmount, nil
+			}
+		}
+		return money.Zero, nil
+	}
+}
+
+func (s *SavingsGoalService) savingsCategoryID() (int, error) {
+	categories, err := s.categoryService.GetAllPredefinedCategories(string(domain.TransactionTypeExpense))
+	if err != nil {
+		return 0, err
+	}
+	for _, category := range categories {
+		if category.Name == domain.SavingsCategoryName {
+			return category.ID, nil
+		}
+	}
+	return 0, errors.New("predefined category " + domain.SavingsCategoryName + " not found")
+}
+
+// SendMonthlyProgressEmails queues one email per user with the progress of their goals, once a month. Goals created
+// this month are left for the next one. It returns the number of queued emails, errors of single users are logged.
+func (s *SavingsGoalService) SendMonthlyProgressEmails(now time.Time) (int, error) {
+	today := domain.TruncateToDate(now)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	goals, err := s.repo.FindDueForProgressEmail(monthStart)
+	if err != nil {
+		return 0, err
+	}
+
+	var userIDs []string
+	goalsByUser := map[string][]domain.SavingsGoal{}
+	for _, goal := range goals {
+		if _, seen := goalsByUser[goal.UserID]; !seen {
+			userIDs = append(userIDs, goal.UserID)
+		}
+		goalsByUser[goal.UserID] = append(goalsByUser[goal.UserID], goal)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		if err := s.sendProgressEmail(userID, goalsByUser[userID], today); err != nil {
+			log.Printf("Error sending savings goals progress to user %s: %v", userID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *SavingsGoalService) sendProgressEmail(userID string, goals []domain.SavingsGoal, today time.Time) error {
+	recipient, err := s.userProvider.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	data := emailService.SavingsGoalProgressData{UserName: recipient.Login, Month: today.Format("January 2006")}
+	goalIDs := make([]int, 0, len(goals))
+	for _, goal := range goals {
+		progress, err := s.progress(goal, today)
+		if err != nil {
+			return err
+		}
+		data.Goals = append(data.Goals, emailService.SavingsGoalProgressLine{
+			Name:                        goal.Name,
+			Saved:                       progress.Saved.StringFixed(2),
+			TargetAmount:                goal.TargetAmount.StringFixed(2),
+			TargetDate:                  goal.TargetDate.Format("2006-01-02"),
+			PercentComplete:             progress.PercentComplete,
+			RequiredMonthlyContribution: progress.RequiredMonthlyContribution.StringFixed(2),
+			Status:                      string(progress.Status),
+		})
+		goalIDs = append(goalIDs, goal.ID)
+	}
+
+	// recorded first, a failure must not make the next run send the same email again
+	if err := s.repo.RecordProgressEmail(goalIDs, today); err != nil {
+		return err
+	}
+	s.emailSender.QueueEmail(recipient.Email, data)
+	return nil
+}
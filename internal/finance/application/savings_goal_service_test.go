@@ -0,0 +1,85 @@
+package application
+
+import (
+	emailService "github.com/sebuszqo/FinanceManager/internal/email"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fixedPortfolioValue struct{}
+
+func (fixedPortfolioValue) GetPortfolioValue(userID, portfolioID string) (money.Decimal, error) {
+	return money.MustParse("12000"), nil
+}
+
+func TestCreateGoal_StartsFromCurrentFundedAmount(t *testing.T) {
+	savings := 1
+	payments := fundedPaymentSource{ownedPaymentSources: ownedPaymentSources{sources: []int{savings}}, source: domain.PaymentSource{ID: savings, Balance: money.MustParse("4000")}}
+	goals := &infrastructure.MockSavingsGoalRepository{}
+	service := NewSavingsGoalService(goals, &infrastructure.MockTransactionRepository{}, &namedCategories{}, payments, fixedPortfolioValue{}, staticUserProvider{}, &queuedEmails{})
+	service.now = func() time.Time { return time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC) }
+
+	goal := &domain.SavingsGoal{UserID: "user-id", Name: "Emergency fund", TargetAmount: money.MustParse("30000"),
+		TargetDate: time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC), Funding: domain.SavingsGoalFundingPaymentSource, PaymentSourceID: &savings}
+	assert.NoError(t, service.CreateGoal(goal))
+	assert.Equal(t, time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC), goal.StartDate)
+	assert.Equal(t, money.MustParse("4000"), goal.StartingAmount)
+
+	// switching to a portfolio starts tracking the goal again from the value of the portfolio
+	portfolioID := "1d9c5f1e-6c3b-4f0e-9f1a-2b7d8e4c5a60"
+	update := *goal
+	update.Funding, update.PaymentSourceID, update.PortfolioID = domain.SavingsGoalFundingPortfolio, nil, &portfolioID
+	assert.NoError(t, service.UpdateGoal(&update))
+	assert.Equal(t, money.MustParse("12000"), update.StartingAmount)
+
+	progress, err := service.GetGoalProgress(goal.ID, "user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("12000"), progress.Saved)
+	assert.Equal(t, 40.0, progress.PercentComplete)
+
+	goal.TargetDate = time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	assert.EqualError(t, service.CreateGoal(goal), "Target date must be in the future")
+}
+
+func TestSendMonthlyProgressEmails_OncePerMonth(t *testing.T) {
+	savingsCategory := 31
+	transactions := &infrastructure.MockTransactionRepository{Transactions: []domain.PersonalTransaction{
+		{UserID: "user-id", Type: "expense", PredefinedCategoryID: savingsCategory, Amount: money.MustParse("500"), Date: time.Date(2026, time.February, 15, 0, 0, 0, 0, time.UTC)},
+		{UserID: "user-id", Type: "expense", PredefinedCategoryID: savingsCategory, Amount: money.MustParse("300"), Date: time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)},
+	}}
+	goals := &infrastructure.MockSavingsGoalRepository{Goals: []domain.SavingsGoal{
+		{ID: 1, UserID: "user-id", Name: "Holidays", TargetAmount: money.MustParse("2000"), TargetDate: time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+			Funding: domain.SavingsGoalFundingCategory, StartDate: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		// created this month, it's emailed from the next one on
+		{ID: 2, UserID: "user-id", Name: "Bike", TargetAmount: money.MustParse("3000"), TargetDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+			Funding: domain.SavingsGoalFundingCategory, StartDate: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	emails := &queuedEmails{}
+	service := NewSavingsGoalService(goals, transactions, &namedCategories{}, ownedPaymentSources{}, fixedPortfolioValue{}, staticUserProvider{}, emails)
+
+	sent, err := service.SendMonthlyProgressEmails(time.Date(2026, time.March, 2, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	if assert.Len(t, emails.emails, 1) {
+		data := emails.emails[0].(emailService.SavingsGoalProgressData)
+		assert.Equal(t, "March 2026", data.Month)
+		// only the contribution made after the goal was created counts
+		assert.Equal(t, []emailService.SavingsGoalProgressLine{{
+			Name: "Holidays", Saved: "500.00", TargetAmount: "2000.00", TargetDate: "2026-07-01", PercentComplete: 25,
+			RequiredMonthlyContribution: "375.00", Status: "on_track",
+		}}, data.Goals)
+	}
+
+	sent, err = service.SendMonthlyProgressEmails(time.Date(2026, time.March, 20, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	sent, err = service.SendMonthlyProgressEmails(time.Date(2026, time.April, 1, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, emails.emails[1].(emailService.SavingsGoalProgressData).Goals, 2)
+}
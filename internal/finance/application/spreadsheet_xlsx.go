@@ -0,0 +1,193 @@
+package application
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Style indexes of cellXfs in xlsxStyles.
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDate
+	xlsxStyleAmount
+	xlsxStyleHeader
+)
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const xlsxStyles = xlsxHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs></styleSheet>`
+
+// excelEpoch is day zero of the 1900 date system, shifted by the leap day Excel wrongly assumes in 1900.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxCell holds either a text or a number, numbers are kept as their literal so amounts are written exactly.
+type xlsxCell struct {
+	text     string
+	number   string
+	isNumber bool
+	style    int
+}
+
+func xlsxText(text string) xlsxCell {
+	return xlsxCell{text: text}
+}
+
+func xlsxHeaderText(text string) xlsxCell {
+	return xlsxCell{text: text, style: xlsxStyleHeader}
+}
+
+func xlsxAmount(amount money.Decimal) xlsxCell {
+	return xlsxCell{number: amount.String(), isNumber: true, style: xlsxStyleAmount}
+}
+
+func xlsxDate(date time.Time) xlsxCell {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return xlsxCell{number: strconv.Itoa(int(date.Sub(excelEpoch) / (24 * time.Hour))), isNumber: true, style: xlsxStyleDate}
+}
+
+func xlsxInt(value int) xlsxCell {
+	return xlsxCell{number: strconv.Itoa(value), isNumber: true}
+}
+
+// xlsxWriter streams a workbook into a zip archive row by row. Sheet names are fixed upfront, so the package
+// parts are written first and each sheet is written completely before the next one starts.
+type xlsxWriter struct {
+	zip          *zip.Writer
+	sheetCount   int
+	sheetsOpened int
+	sheet        *bufio.Writer
+	row          int
+}
+
+func newXLSXWriter(w io.Writer, sheetNames ...string) (*xlsxWriter, error) {
+	x := &xlsxWriter{zip: zip.NewWriter(w), sheetCount: len(sheetNames)}
+
+	contentTypes := xlsxHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	workbookRels := xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	workbook := xlsxHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+
+	for i, name := range sheetNames {
+		contentTypes += fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		workbookRels += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		workbook += fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+	}
+	contentTypes += `</Types>`
+	workbookRels += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheetNames)+1) +
+		`</Relationships>`
+	workbook += `</sheets></workbook>`
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		file, err := x.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+// NextSheet finishes the current sheet and starts the next one of the names given to newXLSXWriter.
+func (x *xlsxWriter) NextSheet() error {
+	if err := x.finishSheet(); err != nil {
+		return err
+	}
+	if x.sheetsOpened == x.sheetCount {
+		return fmt.Errorf("xlsx: all %d sheets are already written", x.sheetCount)
+	}
+	x.sheetsOpened++
+
+	file, err := x.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", x.sheetsOpened))
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(file)
+	x.row = 0
+	_, err = x.sheet.WriteString(xlsxHeader + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (x *xlsxWriter) WriteRow(cells ...xlsxCell) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		reference := xlsxColumn(i) + strconv.Itoa(x.row)
+		style := ""
+		if cell.style != xlsxStyleDefault {
+			style = fmt.Sprintf(` s="%d"`, cell.style)
+		}
+		if cell.isNumber {
+			fmt.Fprintf(x.sheet, `<c r="%s"%s><v>%s</v></c>`, reference, style, cell.number)
+		} else if cell.text != "" {
+			fmt.Fprintf(x.sheet, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, reference, style, xmlEscape(cell.text))
+		}
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) finishSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	if _, err := x.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	err := x.sheet.Flush()
+	x.sheet = nil
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.finishSheet(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumn converts a zero based column index into its letters, 0 is A and 26 is AA.
+func xlsxColumn(index int) string {
+	column := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		column = string(rune('A'+(index-1)%26)) + column
+	}
+	return column
+}
+
+func xmlEscape(text string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
@@ -0,0 +1,188 @@
+package application
+
+import (
+	"encoding/xml"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"strings"
+	"time"
+)
+
+// camt053Document covers the part of ISO 20022 camt.053 (versions 02 up to 08) needed to read booked entries.
+// Tags are matched without the namespace, so every version of the schema is accepted.
+type camt053Document struct {
+	Statements []struct {
+		Entries []camt053Entry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camt053Entry struct {
+	Amount            string                     `xml:"Amt"`
+	CreditDebit       string                     `xml:"CdtDbtInd"`
+	Reversal          bool                       `xml:"RvslInd"`
+	Status            camt053Status              `xml:"Sts"`
+	BookingDate       camt053Date                `xml:"BookgDt"`
+	ValueDate         camt053Date                `xml:"ValDt"`
+	AdditionalInfo    string                     `xml:"AddtlNtryInf"`
+	TransactionDetail []camt053TransactionDetail `xml:"NtryDtls>TxDtls"`
+}
+
+// camt053Status is a plain code up to version 05 (<Sts>BOOK</Sts>) and a nested one later (<Sts><Cd>BOOK</Cd></Sts>).
+type camt053Status struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+func (s camt053Status) value() string {
+	if s.Code != "" {
+		return s.Code
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+type camt053Date struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camt053Date) parse() (time.Time, bool) {
+	if d.Date != "" {
+		date, err := time.Parse("2006-01-02", d.Date)
+		return date, err == nil
+	}
+	if d.DateTime != "" {
+		date, err := time.Parse(time.RFC3339, d.DateTime)
+		if err != nil {
+			date, err = time.Parse("2006-01-02T15:04:05", d.DateTime)
+		}
+		return domain.TruncateToDate(date), err == nil
+	}
+	return time.Time{}, false
+}
+
+type camt053Party struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"` // version 08 wraps the party
+}
+
+func (p camt053Party) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.PartyName
+}
+
+type camt053TransactionDetail struct {
+	Amount         string       `xml:"Amt"`
+	InstructedAmt  string       `xml:"AmtDtls>TxAmt>Amt"`
+	Debtor         camt053Party `xml:"RltdPties>Dbtr"`
+	Creditor       camt053Party `xml:"RltdPties>Cdtr"`
+	Unstructured   []string     `xml:"RmtInf>Ustrd"`
+	Reference      []string     `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInfo string       `xml:"AddtlTxInf"`
+}
+
+func (d camt053TransactionDetail) amount() string {
+	if d.Amount != "" {
+		return d.Amount
+	}
+	return d.InstructedAmt
+}
+
+// parseCAMT053Statement maps booked entries of a camt.053 statement to transactions. A batch entry with several
+// transaction details, each with its own amount, becomes one transaction per detail.
+func parseCAMT053Statement(r io.Reader, defaults domain.ImportDefaults) ([]domain.ImportedTransaction, error) {
+	var document camt053Document
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, financeErrors.NewValidationError("Invalid camt.053 file: " + err.Error())
+	}
+	if len(document.Statements) == 0 {
+		return nil, financeErrors.NewValidationError("Invalid camt.053 file: no statements found")
+	}
+
+	var rows []domain.ImportedTransaction
+	entryNumber := 0
+	for _, statement := range document.Statements {
+		for _, entry := range statement.Entries {
+			entryNumber++
+			if status := entry.Status.value(); status != "" && status != "BOOK" {
+				continue
+			}
+
+			details := entry.TransactionDetail
+			if len(details) <= 1 || !allDetailsHaveAmounts(details) {
+				rows = append(rows, camt053EntryToTransaction(entryNumber, entry, entry.Amount, firstDetail(details), defaults))
+				continue
+			}
+			for _, detail := range details {
+				rows = append(rows, camt053EntryToTransaction(entryNumber, entry, detail.amount(), detail, defaults))
+			}
+		}
+	}
+	if len(rows) > maxImportedRows {
+		return nil, financeErrors.ErrTooManyImportedRows
+	}
+	return rows, nil
+}
+
+func allDetailsHaveAmounts(details []camt053TransactionDetail) bool {
+	for _, detail := range details {
+		if detail.amount() == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func firstDetail(details []camt053TransactionDetail) camt053TransactionDetail {
+	if len(details) == 0 {
+		return camt053TransactionDetail{}
+	}
+	return details[0]
+}
+
+func camt053EntryToTransaction(entryNumber int, entry camt053Entry, amountText string, detail camt053TransactionDetail, defaults domain.ImportDefaults) domain.ImportedTransaction {
+	var parseErrors []string
+
+	date, ok := entry.BookingDate.parse()
+	if !ok {
+		date, ok = entry.ValueDate.parse()
+	}
+	if !ok {
+		parseErrors = append(parseErrors, "Missing or invalid booking date")
+	}
+
+	amount, err := money.Parse(amountText)
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid amount '"+amountText+"'")
+	}
+
+	// the reversal indicator turns a debit back into money received and the other way round
+	credit := entry.CreditDebit == "CRDT"
+	if entry.Reversal {
+		credit = !credit
+	}
+
+	transactionType := domain.TransactionTypeExpense
+	name := detail.Creditor.name()
+	if credit {
+		transactionType = domain.TransactionTypeIncome
+		name = detail.Debtor.name()
+	}
+
+	description := strings.TrimSpace(strings.Join(detail.Unstructured, " "))
+	if description == "" {
+		description = strings.TrimSpace(strings.Join(detail.Reference, " "))
+	}
+	if description == "" {
+		description = strings.TrimSpace(detail.AdditionalInfo)
+	}
+	if description == "" {
+		description = strings.TrimSpace(entry.AdditionalInfo)
+	}
+
+	transaction := defaults.NewTransaction(date, amount, transactionType, strings.TrimSpace(name), description)
+	return domain.NewImportedTransaction(entryNumber, transaction, parseErrors)
+}
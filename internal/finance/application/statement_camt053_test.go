@@ -0,0 +1,72 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCAMT053Statement(t *testing.T) {
+	file, err := os.Open("testdata/statement_camt053.xml")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	rows, err := parseCAMT053Statement(file, statementDefaults)
+	assert.NoError(t, err)
+	// the pending entry is skipped and the batch entry is split into its two payments
+	assert.Len(t, rows, 5)
+	for _, row := range rows {
+		assert.Empty(t, row.Errors)
+	}
+
+	assert.Equal(t, 1, rows[0].Row)
+	assert.Equal(t, "Stadtwerke Muenchen", rows[0].Transaction.Name)
+	assert.Equal(t, "Abschlag Strom Maerz", *rows[0].Transaction.Description)
+	assert.Equal(t, money.MustParse("42.90"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), rows[0].Transaction.Date)
+
+	assert.Equal(t, "ACME GmbH", rows[1].Transaction.Name)
+	assert.Equal(t, "GEHALT-2024-03", *rows[1].Transaction.Description)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Equal(t, 29, rows[1].Transaction.PredefinedCategoryID)
+	assert.Equal(t, 6, rows[1].Transaction.Date.Day())
+
+	assert.Equal(t, 4, rows[2].Row)
+	assert.Equal(t, "Hausverwaltung Meyer", rows[2].Transaction.Name)
+	assert.Equal(t, money.MustParse("100"), rows[2].Transaction.Amount)
+	assert.Equal(t, 4, rows[3].Row)
+	assert.Equal(t, "Sportverein", rows[3].Transaction.Name)
+	assert.Equal(t, money.MustParse("50"), rows[3].Transaction.Amount)
+	assert.Equal(t, "SAMMELUEBERWEISUNG", *rows[3].Transaction.Description)
+
+	// a reversed debit returns the money
+	assert.Equal(t, "income", rows[4].Transaction.Type)
+	assert.Equal(t, "Storno Lastschrift", rows[4].Transaction.Name)
+}
+
+func TestParseCAMT053Statement_NestedStatusCode(t *testing.T) {
+	statement := `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"><BkToCstmrStmt><Stmt>
+		<Ntry><Amt Ccy="EUR">10.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+		<BookgDt><Dt>2024-04-01</Dt></BookgDt>
+		<NtryDtls><TxDtls><RltdPties><Dbtr><Pty><Nm>John Smith</Nm></Pty></Dbtr></RltdPties>
+		<RmtInf><Ustrd>Dinner</Ustrd></RmtInf></TxDtls></NtryDtls></Ntry>
+		<Ntry><Amt Ccy="EUR">5.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>INFO</Cd></Sts>
+		<BookgDt><Dt>2024-04-02</Dt></BookgDt></Ntry>
+	</Stmt></BkToCstmrStmt></Document>`
+
+	rows, err := parseCAMT053Statement(strings.NewReader(statement), statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "John Smith", rows[0].Transaction.Name)
+	assert.Equal(t, "Dinner", *rows[0].Transaction.Description)
+	assert.Equal(t, "income", rows[0].Transaction.Type)
+}
+
+func TestParseCAMT053Statement_InvalidXML(t *testing.T) {
+	_, err := parseCAMT053Statement(strings.NewReader("not xml"), statementDefaults)
+	assert.Error(t, err)
+}
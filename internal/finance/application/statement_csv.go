@@ -0,0 +1,119 @@
+package application
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxImportedRows = 5000
+
+// parseCSVStatement converts every data row of a bank CSV export into a transaction using the mapping profile.
+// Rows which can't be converted or don't pass PersonalTransaction.Validate are returned with their errors.
+func parseCSVStatement(r io.Reader, profile domain.ImportProfile) ([]domain.ImportedTransaction, error) {
+	reader := bufio.NewReader(r)
+	// exports from spreadsheets often start with a byte order mark
+	if bom, err := reader.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		_, _ = reader.Discard(3)
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma, _ = utf8.DecodeRuneInString(profile.Delimiter)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+
+	var rows []domain.ImportedTransaction
+	for recordNumber := 1; ; recordNumber++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, financeErrors.NewValidationError("Invalid CSV file: " + err.Error())
+		}
+		if recordNumber <= profile.SkipRows || (profile.HasHeader && recordNumber == profile.SkipRows+1) || isEmptyRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportedRows {
+			return nil, financeErrors.ErrTooManyImportedRows
+		}
+		// rows are reported by their line in the file, as the user sees it in a text editor
+		line, _ := csvReader.FieldPos(0)
+		rows = append(rows, csvRecordToTransaction(line, record, profile))
+	}
+	return rows, nil
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func csvRecordToTransaction(line int, record []string, profile domain.ImportProfile) domain.ImportedTransaction {
+	field := func(index int) string {
+		if index < len(record) {
+			return strings.TrimSpace(record[index])
+		}
+		return ""
+	}
+
+	var parseErrors []string
+	date, err := profile.ParseDate(field(profile.Columns.Date))
+	if err != nil {
+		parseErrors = append(parseErrors, err.Error())
+	}
+
+	amount, transactionType, err := csvAmount(field, profile)
+	if err != nil {
+		parseErrors = append(parseErrors, err.Error())
+	}
+
+	description := ""
+	if profile.Columns.Description != nil {
+		description = field(*profile.Columns.Description)
+	}
+	transaction := profile.NewTransaction(date, amount, transactionType, field(profile.Columns.Name), description)
+	return domain.NewImportedTransaction(line, transaction, parseErrors)
+}
+
+func csvAmount(field func(int) string, profile domain.ImportProfile) (money.Decimal, domain.TransactionType, error) {
+	if profile.SignConvention == domain.SignDebitCreditColumns {
+		debit, credit := field(*profile.Columns.Debit), field(*profile.Columns.Credit)
+		if debit != "" {
+			amount, err := profile.ParseAmount(debit)
+			if err != nil {
+				return money.Zero, "", err
+			}
+			// some banks write 0.00 into the column which doesn't apply to the row
+			if !amount.IsZero() || credit == "" {
+				return amount.Abs(), domain.TransactionTypeExpense, nil
+			}
+		}
+		amount, err := profile.ParseAmount(credit)
+		return amount.Abs(), domain.TransactionTypeIncome, err
+	}
+
+	amount, err := profile.ParseAmount(field(*profile.Columns.Amount))
+	if err != nil {
+		return money.Zero, "", err
+	}
+	negativeType, positiveType := domain.TransactionTypeExpense, domain.TransactionTypeIncome
+	if profile.SignConvention == domain.SignNegativeIncome {
+		negativeType, positiveType = positiveType, negativeType
+	}
+	if amount.IsNegative() {
+		return amount.Neg(), negativeType, nil
+	}
+	return amount, positiveType, nil
+}
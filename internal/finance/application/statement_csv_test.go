@@ -0,0 +1,101 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestParseCSVStatement_SemicolonDecimalComma(t *testing.T) {
+	statement := "\xef\xbb\xbfAccount history;;;\n" +
+		"Date;Payee;Title;Amount\n" +
+		"05.03.2024;Biedronka;Groceries;-1 234,56\n" +
+		"06.03.2024;ACME Sp. z o.o.;Salary 03/2024;\"8.500,00\"\n" +
+		"\n" +
+		"07/03/2024;Orlen;Fuel;-250,00\n"
+
+	profile := domain.ImportProfile{
+		Delimiter: ";", DecimalSeparator: ",", DateFormat: "DD.MM.YYYY", HasHeader: true, SkipRows: 1,
+		Columns:        domain.ImportColumns{Date: 0, Name: 1, Description: intPointer(2), Amount: intPointer(3)},
+		SignConvention: domain.SignNegativeExpense,
+		ImportDefaults: domain.ImportDefaults{UserID: "user-id", ExpenseCategoryID: 9, IncomeCategoryID: 29, PaymentMethodID: 1},
+	}
+
+	rows, err := parseCSVStatement(strings.NewReader(statement), profile)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, 3, rows[0].Row)
+	assert.Equal(t, "Biedronka", rows[0].Transaction.Name)
+	assert.Equal(t, money.MustParse("1234.56"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, 9, rows[0].Transaction.PredefinedCategoryID)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), rows[0].Transaction.Date)
+
+	assert.Empty(t, rows[1].Errors)
+	assert.Equal(t, money.MustParse("8500"), rows[1].Transaction.Amount)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Equal(t, 29, rows[1].Transaction.PredefinedCategoryID)
+	assert.Equal(t, "Salary 03/2024", *rows[1].Transaction.Description)
+
+	assert.Equal(t, 6, rows[2].Row)
+	assert.Len(t, rows[2].Errors, 1)
+	assert.Contains(t, rows[2].Errors[0], "Invalid date")
+}
+
+func TestParseCSVStatement_DebitCreditColumns(t *testing.T) {
+	statement := "date,description,debit,credit\n" +
+		"2024-01-02,Netflix,15.99,\n" +
+		"2024-01-03,Refund,,20.00\n" +
+		"2024-01-04,,0.00,\n"
+
+	profile := domain.ImportProfile{
+		Delimiter: ",", DecimalSeparator: ".", DateFormat: "YYYY-MM-DD", HasHeader: true,
+		Columns:        domain.ImportColumns{Date: 0, Name: 1, Debit: intPointer(2), Credit: intPointer(3)},
+		SignConvention: domain.SignDebitCreditColumns,
+		ImportDefaults: domain.ImportDefaults{UserID: "user-id", ExpenseCategoryID: 20, IncomeCategoryID: 27, PaymentMethodID: 1},
+	}
+
+	preview := domain.NewImportPreview(mustParseCSV(t, statement, profile))
+	assert.Equal(t, 2, preview.ValidCount)
+	assert.Equal(t, 1, preview.InvalidCount)
+	assert.Equal(t, "expense", preview.Rows[0].Transaction.Type)
+	assert.Equal(t, money.MustParse("15.99"), preview.Rows[0].Transaction.Amount)
+	assert.Equal(t, "income", preview.Rows[1].Transaction.Type)
+	assert.Equal(t, []string{"Name should be between 0 and 50"}, preview.Rows[2].Errors)
+	assert.Len(t, preview.ValidTransactions(), 2)
+}
+
+func TestParseCSVStatement_ZeroInUnusedDebitCreditColumn(t *testing.T) {
+	statement := "date;description;debit;credit\n" +
+		"2024-01-02;Netflix;15,99;0,00\n" +
+		"2024-01-03;Refund;0,00;20,00\n"
+
+	profile := domain.ImportProfile{
+		Delimiter: ";", DecimalSeparator: ",", DateFormat: "YYYY-MM-DD", HasHeader: true,
+		Columns:        domain.ImportColumns{Date: 0, Name: 1, Debit: intPointer(2), Credit: intPointer(3)},
+		SignConvention: domain.SignDebitCreditColumns,
+		ImportDefaults: domain.ImportDefaults{UserID: "user-id", ExpenseCategoryID: 20, IncomeCategoryID: 27, PaymentMethodID: 1},
+	}
+
+	preview := domain.NewImportPreview(mustParseCSV(t, statement, profile))
+	assert.Equal(t, 2, preview.ValidCount)
+	assert.Equal(t, "expense", preview.Rows[0].Transaction.Type)
+	assert.Equal(t, money.MustParse("15.99"), preview.Rows[0].Transaction.Amount)
+	assert.Equal(t, "income", preview.Rows[1].Transaction.Type)
+	assert.Equal(t, money.MustParse("20"), preview.Rows[1].Transaction.Amount)
+}
+
+func mustParseCSV(t *testing.T, statement string, profile domain.ImportProfile) []domain.ImportedTransaction {
+	rows, err := parseCSVStatement(strings.NewReader(statement), profile)
+	assert.NoError(t, err)
+	return rows
+}
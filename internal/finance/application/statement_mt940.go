@@ -0,0 +1,149 @@
+package application
+
+import (
+	"bufio"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	// :61: value date, optional entry date, debit/credit mark, optional funds code, amount, transaction type and references
+	mt940StatementLinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RD|RC|D|C)([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})`)
+	// structured :86: fields start with a 3 digit transaction code followed by subfields such as ?20 or ~20
+	mt940StructuredDetailsPattern = regexp.MustCompile(`^\d{3}([?~^<])`)
+)
+
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// parseMT940Statement maps the :61: statement lines of an MT940 file to transactions, using the :86: line
+// following each of them for the payee and the remittance information.
+func parseMT940Statement(r io.Reader, defaults domain.ImportDefaults) ([]domain.ImportedTransaction, error) {
+	fields, err := readMT940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, financeErrors.NewValidationError("Invalid MT940 file: no statement fields found")
+	}
+
+	var rows []domain.ImportedTransaction
+	for i, field := range fields {
+		if field.tag != "61" {
+			continue
+		}
+		if len(rows) == maxImportedRows {
+			return nil, financeErrors.ErrTooManyImportedRows
+		}
+
+		details := ""
+		if i+1 < len(fields) && fields[i+1].tag == "86" {
+			details = fields[i+1].value
+		}
+		rows = append(rows, mt940StatementLineToTransaction(field, details, defaults))
+	}
+	return rows, nil
+}
+
+func readMT940Fields(r io.Reader) ([]mt940Field, error) {
+	scanner := bufio.NewScanner(r)
+	var fields []mt940Field
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r ")
+		// SWIFT block headers and the end of message marker carry no statement data
+		if text == "" || text == "-" || text == "-}" || strings.HasPrefix(text, "{1:") {
+			continue
+		}
+
+		if match := mt940TagPattern.FindStringSubmatch(text); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: match[2], line: line})
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		// continuation lines of :86: are wrapped at 65 characters, other fields keep their line structure
+		last := &fields[len(fields)-1]
+		if last.tag == "86" {
+			last.value += text
+		} else {
+			last.value += "\n" + text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, financeErrors.NewValidationError("Invalid MT940 file: " + err.Error())
+	}
+	return fields, nil
+}
+
+func mt940StatementLineToTransaction(field mt940Field, details string, defaults domain.ImportDefaults) domain.ImportedTransaction {
+	match := mt940StatementLinePattern.FindStringSubmatch(field.value)
+	if match == nil {
+		return domain.ImportedTransaction{Row: field.line, Errors: []string{"Invalid :61: statement line"}}
+	}
+
+	var parseErrors []string
+	date, err := time.Parse("060102", match[1])
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid value date '"+match[1]+"'")
+	}
+
+	amount, err := money.Parse(strings.Replace(match[5], ",", ".", 1))
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid amount '"+match[5]+"'")
+	}
+
+	// a reversal of a debit gives the money back, so it's booked as income
+	transactionType := domain.TransactionTypeExpense
+	if match[3] == "C" || match[3] == "RD" {
+		transactionType = domain.TransactionTypeIncome
+	}
+
+	name, description := parseMT940Details(details)
+	if description == "" {
+		// without :86: the reference of the statement line is the only description available
+		description = strings.TrimSpace(strings.SplitN(field.value[len(match[0]):], "\n", 2)[0])
+	}
+
+	transaction := defaults.NewTransaction(date, amount, transactionType, name, description)
+	return domain.NewImportedTransaction(field.line, transaction, parseErrors)
+}
+
+// parseMT940Details splits the :86: field into the counterparty name (subfields 32-33) and the remittance information
+// (subfields 20-29). Unstructured details are used as the description as a whole.
+func parseMT940Details(details string) (string, string) {
+	details = strings.TrimSpace(details)
+	match := mt940StructuredDetailsPattern.FindStringSubmatch(details)
+	if match == nil {
+		return "", details
+	}
+
+	var name, remittance strings.Builder
+	for _, subfield := range strings.Split(details[3:], match[1])[1:] {
+		if len(subfield) < 2 {
+			continue
+		}
+		code, err := strconv.Atoi(subfield[:2])
+		if err != nil {
+			continue
+		}
+		value := subfield[2:]
+		switch {
+		case code >= 20 && code <= 29:
+			remittance.WriteString(value)
+		case code == 32 || code == 33:
+			name.WriteString(value)
+		}
+	}
+	return strings.TrimSpace(name.String()), strings.TrimSpace(remittance.String())
+}
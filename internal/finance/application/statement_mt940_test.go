@@ -0,0 +1,72 @@
+package application
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var statementDefaults = domain.ImportDefaults{UserID: "user-id", ExpenseCategoryID: 9, IncomeCategoryID: 29, PaymentMethodID: 2}
+
+func TestParseMT940Statement(t *testing.T) {
+	file, err := os.Open("testdata/statement.mt940")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	rows, err := parseMT940Statement(file, statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, 6, rows[0].Row)
+	assert.Equal(t, "BIEDRONKA SKLEP 1234WARSZAWA", rows[0].Transaction.Name)
+	assert.Equal(t, "Zakupy spozywczeparagon 3312", *rows[0].Transaction.Description)
+	assert.Equal(t, money.MustParse("1234.56"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, 9, rows[0].Transaction.PredefinedCategoryID)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), rows[0].Transaction.Date)
+
+	assert.Empty(t, rows[1].Errors)
+	assert.Equal(t, "ACME SP. Z O.O.", rows[1].Transaction.Name)
+	assert.Equal(t, "Wynagrodzenie 03/2024", *rows[1].Transaction.Description)
+	assert.Equal(t, money.MustParse("8500"), rows[1].Transaction.Amount)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Equal(t, 29, rows[1].Transaction.PredefinedCategoryID)
+
+	// no :86: line, the reference of the statement line becomes the name and description
+	assert.Empty(t, rows[2].Errors)
+	assert.Equal(t, "KARTA 4411", rows[2].Transaction.Name)
+
+	// a zero amount fee notice can't be stored as a transaction
+	assert.Len(t, rows[3].Errors, 1)
+	assert.Equal(t, "Oplata za prowadzenie rachunku", *rows[3].Transaction.Description)
+}
+
+func TestParseMT940Statement_InvalidStatementLine(t *testing.T) {
+	statement := ":20:REF\n:61:ABC\n:86:Details\n"
+
+	rows, err := parseMT940Statement(strings.NewReader(statement), statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 2, rows[0].Row)
+	assert.Equal(t, []string{"Invalid :61: statement line"}, rows[0].Errors)
+}
+
+func TestParseMT940Statement_NotMT940(t *testing.T) {
+	_, err := parseMT940Statement(strings.NewReader("date,name,amount\n"), statementDefaults)
+	assert.Error(t, err)
+}
+
+func TestParseMT940Details(t *testing.T) {
+	name, description := parseMT940Details("020?00SEPA?20EREF+123?21Invoice 77?32Jane?33 Doe")
+	assert.Equal(t, "Jane Doe", name)
+	assert.Equal(t, "EREF+123Invoice 77", description)
+
+	name, description = parseMT940Details(" Free text details ")
+	assert.Empty(t, name)
+	assert.Equal(t, "Free text details", description)
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ofxTagPattern matches both OFX 1.x SGML, where elements are not closed (<NAME>Shop), and OFX 2.x XML.
+var ofxTagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+var (
+	ofxUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+	ofxEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ")
+)
+
+// parseOFXStatement maps the STMTTRN aggregates of bank and credit card statements in an OFX or QFX file to
+// transactions. The sign of TRNAMT decides the type, the payee goes to Name and MEMO to Description.
+func parseOFXStatement(r io.Reader, defaults domain.ImportDefaults) ([]domain.ImportedTransaction, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	body := string(content)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, financeErrors.NewValidationError("Invalid OFX file: missing <OFX> element")
+	}
+
+	var rows []domain.ImportedTransaction
+	var current map[string]string
+	for _, match := range ofxTagPattern.FindAllStringSubmatch(body[start:], -1) {
+		closing, tag, value := match[1] == "/", strings.ToUpper(match[2]), strings.TrimSpace(ofxUnescaper.Replace(match[3]))
+		switch {
+		case tag == "STMTTRN" && !closing:
+			current = map[string]string{}
+		case tag == "STMTTRN" && closing:
+			if current == nil {
+				continue
+			}
+			if len(rows) == maxImportedRows {
+				return nil, financeErrors.ErrTooManyImportedRows
+			}
+			rows = append(rows, ofxTransactionToImported(len(rows)+1, current, defaults))
+			current = nil
+		case current != nil && !closing && value != "":
+			// NAME is either set directly or inside of the PAYEE aggregate, both end up in the same field
+			current[tag] = value
+		}
+	}
+	return rows, nil
+}
+
+func ofxTransactionToImported(row int, fields map[string]string, defaults domain.ImportDefaults) domain.ImportedTransaction {
+	var parseErrors []string
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid posting date '"+fields["DTPOSTED"]+"'")
+	}
+
+	amountText := strings.Replace(fields["TRNAMT"], ",", ".", 1)
+	amount, err := money.Parse(amountText)
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid amount '"+fields["TRNAMT"]+"'")
+	}
+
+	transactionType := domain.TransactionTypeIncome
+	if amount.IsNegative() {
+		transactionType = domain.TransactionTypeExpense
+		amount = amount.Neg()
+	}
+
+	transaction := defaults.NewTransaction(date, amount, transactionType, fields["NAME"], fields["MEMO"])
+	// FITID is unique within the account, it recognizes the transaction when overlapping statements are imported
+	if fitID := fields["FITID"]; fitID != "" {
+		transaction.ExternalID = &fitID
+	}
+	return domain.NewImportedTransaction(row, transaction, parseErrors)
+}
+
+// parseOFXDate reads the date part of an OFX datetime such as 20240305, 20240305120000 or 20240305120000.000[-5:EST].
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// writeOFXStatement writes the accounts as an OFX 1.0.2 file, one bank statement per account. OFX has no notion of
+// categories, so only the payee and the description of each transaction are exported.
+func writeOFXStatement(w io.Writer, accounts []exportAccount, currency string, startDate, endDate time.Time) error {
+	out := bufio.NewWriter(w)
+	now := time.Now().UTC().Format("20060102150405")
+
+	fmt.Fprint(out, "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nSECURITY:NONE\r\nENCODING:UTF-8\r\n"+
+		"CHARSET:NONE\r\nCOMPRESSION:NONE\r\nOLDFILEUID:NONE\r\nNEWFILEUID:NONE\r\n\r\n")
+	fmt.Fprintf(out, "<OFX>\r\n<SIGNONMSGSRSV1>\r\n<SONRS>\r\n<STATUS>\r\n<CODE>0\r\n<SEVERITY>INFO\r\n</STATUS>\r\n"+
+		"<DTSERVER>%s\r\n<LANGUAGE>ENG\r\n</SONRS>\r\n</SIGNONMSGSRSV1>\r\n<BANKMSGSRSV1>\r\n", now)
+
+	for i, account := range accounts {
+		fmt.Fprintf(out, "<STMTTRNRS>\r\n<TRNUID>%d\r\n<STATUS>\r\n<CODE>0\r\n<SEVERITY>INFO\r\n</STATUS>\r\n<STMTRS>\r\n"+
+			"<CURDEF>%s\r\n<BANKACCTFROM>\r\n<BANKID>FinanceManager\r\n<ACCTID>%s\r\n<ACCTTYPE>CHECKING\r\n</BANKACCTFROM>\r\n"+
+			"<BANKTRANLIST>\r\n<DTSTART>%s\r\n<DTEND>%s\r\n",
+			i+1, currency, ofxEscaper.Replace(domain.TruncateText(account.Name, 22)), startDate.Format("20060102"), endDate.Format("20060102"))
+
+		balance := money.Zero
+		for _, transaction := range account.Transactions {
+			amount := transaction.Amount
+			transactionType := "CREDIT"
+			if transaction.Type == string(domain.TransactionTypeExpense) {
+				amount = amount.Neg()
+				transactionType = "DEBIT"
+			}
+			balance = balance.Add(amount)
+
+			fmt.Fprintf(out, "<STMTTRN>\r\n<TRNTYPE>%s\r\n<DTPOSTED>%s\r\n<TRNAMT>%s\r\n<FITID>%s\r\n<NAME>%s\r\n",
+				transactionType, transaction.Date.Format("20060102"), amount.StringFixed(2), transaction.ID,
+				ofxEscaper.Replace(domain.TruncateText(transaction.Name, 32)))
+			if transaction.Description != nil {
+				fmt.Fprintf(out, "<MEMO>%s\r\n", ofxEscaper.Replace(domain.TruncateText(*transaction.Description, 255)))
+			}
+			fmt.Fprint(out, "</STMTTRN>\r\n")
+		}
+
+		// the real balance of the account isn't known, the ledger balance is the net of the exported period
+		fmt.Fprintf(out, "</BANKTRANLIST>\r\n<LEDGERBAL>\r\n<BALAMT>%s\r\n<DTASOF>%s\r\n</LEDGERBAL>\r\n</STMTRS>\r\n</STMTTRNRS>\r\n",
+			balance.StringFixed(2), endDate.Format("20060102"))
+	}
+
+	fmt.Fprint(out, "</BANKMSGSRSV1>\r\n</OFX>\r\n")
+	return out.Flush()
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"bytes"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOFXStatement(t *testing.T) {
+	file, err := os.Open("testdata/statement.ofx")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	rows, err := parseOFXStatement(file, statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, 1, rows[0].Row)
+	assert.Equal(t, "Whole Foods & Market", rows[0].Transaction.Name)
+	assert.Equal(t, "Groceries", *rows[0].Transaction.Description)
+	assert.Equal(t, money.MustParse("42.90"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), rows[0].Transaction.Date)
+
+	// the payee aggregate carries the name
+	assert.Empty(t, rows[1].Errors)
+	assert.Equal(t, "ACME Corp", rows[1].Transaction.Name)
+	assert.Equal(t, money.MustParse("3100"), rows[1].Transaction.Amount)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Equal(t, 29, rows[1].Transaction.PredefinedCategoryID)
+
+	assert.Equal(t, []string{"Invalid posting date '2024'"}, rows[2].Errors)
+}
+
+func TestParseOFXStatement_NotOFX(t *testing.T) {
+	_, err := parseOFXStatement(strings.NewReader("!Type:Bank\n"), statementDefaults)
+	assert.Error(t, err)
+}
+
+func TestWriteOFXStatement_RoundTrip(t *testing.T) {
+	description := "Invoice <77> & more"
+	accounts := []exportAccount{{
+		Name: "Main account",
+		Transactions: []exportedTransaction{
+			{PersonalTransaction: domain.PersonalTransaction{ID: "a1", Name: "Rent", Amount: money.MustParse("1500"), Type: "expense",
+				Date: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Description: &description}},
+			{PersonalTransaction: domain.PersonalTransaction{ID: "a2", Name: "Salary", Amount: money.MustParse("5000.5"), Type: "income",
+				Date: time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)}},
+		},
+	}}
+
+	var out bytes.Buffer
+	err := writeOFXStatement(&out, accounts, "PLN", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "<CURDEF>PLN")
+	assert.Contains(t, out.String(), "<ACCTID>Main account")
+	assert.Contains(t, out.String(), "<BALAMT>3500.50")
+
+	rows, err := parseOFXStatement(&out, statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "Rent", rows[0].Transaction.Name)
+	assert.Equal(t, description, *rows[0].Transaction.Description)
+	assert.Equal(t, money.MustParse("1500"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, money.MustParse("5000.5"), rows[1].Transaction.Amount)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Equal(t, 10, rows[1].Transaction.Date.Day())
+}
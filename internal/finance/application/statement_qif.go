@@ -0,0 +1,217 @@
+package application
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// qifDateLayouts are tried in order, Quicken writes 3/ 5'24 (spaces are removed before parsing) and GnuCash
+// follows the locale of the user.
+var qifDateLayouts = []string{"1/2/2006", "1/2'06", "1/2/06", "2006-01-02", "2.1.2006", "2.1.06"}
+
+// qifTransactionSections are the !Type headers of non-investment accounts, other sections such as categories,
+// classes or memorized transactions are skipped on import.
+var qifTransactionSections = map[string]bool{
+	"!type:bank":  true,
+	"!type:cash":  true,
+	"!type:ccard": true,
+	"!type:oth a": true,
+	"!type:oth l": true,
+}
+
+var (
+	qifTextReplacer     = strings.NewReplacer("\r", " ", "\n", " ")
+	qifCategoryReplacer = strings.NewReplacer(":", "-", "/", "-", "\r", " ", "\n", " ")
+)
+
+// parseQIFStatement maps the records of bank, cash and credit card sections of a QIF file to transactions.
+// Split lines are ignored, the total amount of the record is imported with the default category.
+func parseQIFStatement(r io.Reader, defaults domain.ImportDefaults) ([]domain.ImportedTransaction, error) {
+	scanner := bufio.NewScanner(r)
+	var rows []domain.ImportedTransaction
+	section, hasTransactionSection := "", false
+	var record map[byte]string
+	recordLine := 0
+
+	addRecord := func() error {
+		if record != nil && qifTransactionSections[section] {
+			if len(rows) == maxImportedRows {
+				return financeErrors.ErrTooManyImportedRows
+			}
+			rows = append(rows, qifRecordToImported(recordLine, record, defaults))
+		}
+		record = nil
+		return nil
+	}
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\xef\xbb\xbf")
+		}
+		if text == "" {
+			continue
+		}
+
+		switch text[0] {
+		case '!':
+			header := strings.ToLower(text)
+			if strings.HasPrefix(header, "!option:") || strings.HasPrefix(header, "!clear:") {
+				continue
+			}
+			if err := addRecord(); err != nil {
+				return nil, err
+			}
+			section = header
+			hasTransactionSection = hasTransactionSection || qifTransactionSections[section]
+		case '^':
+			if err := addRecord(); err != nil {
+				return nil, err
+			}
+		default:
+			if record == nil {
+				record, recordLine = map[byte]string{}, line
+			}
+			// only the first occurrence counts, split lines of the record reuse some of the codes
+			if _, exists := record[text[0]]; !exists {
+				record[text[0]] = strings.TrimSpace(text[1:])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, financeErrors.NewValidationError("Invalid QIF file: " + err.Error())
+	}
+	if !hasTransactionSection {
+		return nil, financeErrors.NewValidationError("Invalid QIF file: no bank, cash or credit card section found")
+	}
+	// the last record may miss its closing ^
+	if err := addRecord(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func qifRecordToImported(line int, record map[byte]string, defaults domain.ImportDefaults) domain.ImportedTransaction {
+	var parseErrors []string
+
+	date, err := parseQIFDate(record['D'])
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid date '"+record['D']+"'")
+	}
+
+	amountText, ok := record['T']
+	if !ok {
+		amountText = record['U']
+	}
+	amount, err := parseQIFAmount(amountText)
+	if err != nil {
+		parseErrors = append(parseErrors, "Invalid amount '"+amountText+"'")
+	}
+
+	transactionType := domain.TransactionTypeIncome
+	if amount.IsNegative() {
+		transactionType = domain.TransactionTypeExpense
+		amount = amount.Neg()
+	}
+
+	transaction := defaults.NewTransaction(date, amount, transactionType, record['P'], record['M'])
+	return domain.NewImportedTransaction(line, transaction, parseErrors)
+}
+
+func parseQIFDate(value string) (time.Time, error) {
+	value = strings.ReplaceAll(value, " ", "")
+	for _, layout := range qifDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid QIF date %q", value)
+}
+
+// parseQIFAmount accepts both 1,234.56 and 1.234,56, a comma followed by exactly two digits is a decimal separator.
+func parseQIFAmount(value string) (money.Decimal, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	lastComma, lastDot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	if lastComma > lastDot && len(value)-lastComma == 3 {
+		value = strings.Replace(strings.ReplaceAll(value, ".", ""), ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return money.Parse(value)
+}
+
+// writeQIFStatement writes the category list followed by one section per account. Categories are written to
+// L lines as "Predefined:User category", which Quicken and GnuCash read as a category with its subcategory.
+func writeQIFStatement(w io.Writer, accounts []exportAccount) error {
+	out := bufio.NewWriter(w)
+
+	categories := map[string]string{}
+	for _, account := range accounts {
+		for _, transaction := range account.Transactions {
+			flag := "E"
+			if transaction.Type == string(domain.TransactionTypeIncome) {
+				flag = "I"
+			}
+			categories[qifCategoryReplacer.Replace(transaction.CategoryName)] = flag
+			if category := qifCategory(transaction); category != "" {
+				categories[category] = flag
+			}
+		}
+	}
+	delete(categories, "")
+
+	if len(categories) > 0 {
+		names := make([]string, 0, len(categories))
+		for name := range categories {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprint(out, "!Type:Cat\n")
+		for _, name := range names {
+			fmt.Fprintf(out, "N%s\n%s\n^\n", name, categories[name])
+		}
+	}
+
+	fmt.Fprint(out, "!Option:AutoSwitch\n")
+	for _, account := range accounts {
+		accountType := "Bank"
+		if account.Cash {
+			accountType = "Cash"
+		}
+		fmt.Fprintf(out, "!Account\nN%s\nT%s\n^\n!Type:%s\n", qifTextReplacer.Replace(account.Name), accountType, accountType)
+
+		for _, transaction := range account.Transactions {
+			amount := transaction.Amount
+			if transaction.Type == string(domain.TransactionTypeExpense) {
+				amount = amount.Neg()
+			}
+			fmt.Fprintf(out, "D%s\nT%s\nP%s\n", transaction.Date.Format("01/02/2006"), amount.StringFixed(2), qifTextReplacer.Replace(transaction.Name))
+			if transaction.Description != nil {
+				fmt.Fprintf(out, "M%s\n", qifTextReplacer.Replace(*transaction.Description))
+			}
+			if category := qifCategory(transaction); category != "" {
+				fmt.Fprintf(out, "L%s\n", category)
+			}
+			fmt.Fprint(out, "^\n")
+		}
+	}
+	fmt.Fprint(out, "!Clear:AutoSwitch\n")
+
+	return out.Flush()
+}
+
+func qifCategory(transaction exportedTransaction) string {
+	category := qifCategoryReplacer.Replace(transaction.CategoryName)
+	if category != "" && transaction.UserCategoryName != "" {
+		category += ":" + qifCategoryReplacer.Replace(transaction.UserCategoryName)
+	}
+	return category
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"bytes"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQIFStatement(t *testing.T) {
+	file, err := os.Open("testdata/statement.qif")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	rows, err := parseQIFStatement(file, statementDefaults)
+	assert.NoError(t, err)
+	// category, account and memorized transaction records are not imported
+	assert.Len(t, rows, 3)
+
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, 10, rows[0].Row)
+	assert.Equal(t, "Safeway", rows[0].Transaction.Name)
+	assert.Equal(t, "Weekly shopping", *rows[0].Transaction.Description)
+	assert.Equal(t, money.MustParse("1234.56"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[0].Transaction.Type)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), rows[0].Transaction.Date)
+
+	// split lines don't change the total of the record
+	assert.Empty(t, rows[1].Errors)
+	assert.Equal(t, money.MustParse("3100"), rows[1].Transaction.Amount)
+	assert.Equal(t, "income", rows[1].Transaction.Type)
+	assert.Nil(t, rows[1].Transaction.Description)
+
+	assert.Equal(t, 25, rows[2].Row)
+	assert.Equal(t, []string{"Invalid date '13/45/2024'"}, rows[2].Errors)
+}
+
+func TestParseQIFStatement_NoTransactionSection(t *testing.T) {
+	_, err := parseQIFStatement(strings.NewReader("!Type:Cat\nNFood\nE\n^\n"), statementDefaults)
+	assert.Error(t, err)
+}
+
+func TestParseQIFAmount(t *testing.T) {
+	for value, expected := range map[string]string{"-1,234.56": "-1234.56", "1.234,56": "1234.56", "12,50": "12.50", "1,234": "1234", "7": "7"} {
+		amount, err := parseQIFAmount(value)
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse(expected), amount, value)
+	}
+}
+
+func TestWriteQIFStatement(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	accounts, err := groupByAccount([]exportedTransaction{
+		{PersonalTransaction: domain.PersonalTransaction{Name: "Market", Amount: money.MustParse("12.5"), Type: "expense", Date: date, PaymentMethodID: 5},
+			CategoryName: "Groceries", UserCategoryName: "Organic", PaymentMethodName: "Cash"},
+		{PersonalTransaction: domain.PersonalTransaction{Name: "Salary", Amount: money.MustParse("5000"), Type: "income", Date: date, PaymentSourceID: intPointer(3)},
+			CategoryName: "Salary", PaymentSourceName: "Bank account"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	var out bytes.Buffer
+	assert.NoError(t, writeQIFStatement(&out, accounts))
+
+	expected := "!Type:Cat\n" +
+		"NGroceries\nE\n^\n" +
+		"NGroceries:Organic\nE\n^\n" +
+		"NSalary\nI\n^\n" +
+		"!Option:AutoSwitch\n" +
+		"!Account\nNBank account\nTBank\n^\n!Type:Bank\n" +
+		"D03/05/2024\nT5000.00\nPSalary\nLSalary\n^\n" +
+		"!Account\nNCash\nTCash\n^\n!Type:Cash\n" +
+		"D03/05/2024\nT-12.50\nPMarket\nLGroceries:Organic\n^\n" +
+		"!Clear:AutoSwitch\n"
+	assert.Equal(t, expected, out.String())
+
+	rows, err := parseQIFStatement(&out, statementDefaults)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, money.MustParse("5000"), rows[0].Transaction.Amount)
+	assert.Equal(t, "expense", rows[1].Transaction.Type)
+}
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
+
+type TagService struct {
+	repo domain.TagRepository
+}
+
+func NewTagService(repo domain.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) GetUserTags(userID string) ([]domain.Tag, error) {
+	tags, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		return []domain.Tag{}, nil
+	}
+	return tags, nil
+}
+
+func (s *TagService) GetTag(tagID int, userID string) (*domain.Tag, error) {
+	tag, err := s.repo.FindByID(tagID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) CreateTag(tag *domain.Tag) error {
+	if err := s.validateTag(tag, 0); err != nil {
+		return err
+	}
+	return s.repo.Create(tag)
+}
+
+func (s *TagService) UpdateTag(tag *domain.Tag) error {
+	if _, err := s.GetTag(tag.ID, tag.UserID); err != nil {
+		return err
+	}
+	if err := s.validateTag(tag, tag.ID); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*tag)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrTagNotFound
+	}
+	return nil
+}
+
+// DeleteTag removes the tag from the vocabulary of the user, the transactions keep their other tags.
+func (s *TagService) DeleteTag(tagID int, userID string) error {
+	affected, err := s.repo.Delete(tagID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrTagNotFound
+	}
+	return nil
+}
+
+// validateTag validates the name and its uniqueness among the tags of the user, ignoring the tag with excludedID.
+func (s *TagService) validateTag(tag *domain.Tag, excludedID int) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+	taken, err := s.repo.DoesTagNameExist(tag.Name, tag.UserID, excludedID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return financeErrors.ErrTagNameTaken
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package application
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
 	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
 	"log"
+	"sort"
 	"time"
 )
 
@@ -22,143 +24,260 @@ type PaymentServiceInterface interface {
 	GetUserPaymentSources(userID string) ([]domain.PaymentSource, error)
 	DoesPaymentMethodExistByID(methodID int) (bool, error)
 	DoesUserPaymentSourceExistByID(sourceID int, userID string) (bool, error)
+	GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error)
+}
+
+type TagProvider interface {
+	GetUserTags(userID string) ([]domain.Tag, error)
+}
+
+type BudgetAlerter interface {
+	CheckBudgetAlerts(userID string, transactions []domain.PersonalTransaction)
+}
+
+type AttachmentRemover interface {
+	RemoveAttachmentBlobs(storageKeys []string)
 }
 
 type PersonalTransactionService struct {
-	repo            domain.PersonalTransactionRepository
-	categoryService CategoryServiceInterface
-	paymentService  PaymentServiceInterface
+	repo              domain.PersonalTransactionRepository
+	categoryService   CategoryServiceInterface
+	paymentService    PaymentServiceInterface
+	tagProvider       TagProvider
+	budgetAlerter     BudgetAlerter
+	attachmentRemover AttachmentRemover
+	categorizer       TransactionCategorizer
+	settings          FinanceSettingsProvider
+	now               func() time.Time
 }
 
-func NewPersonalTransactionService(repo domain.PersonalTransactionRepository, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface) *PersonalTransactionService {
-	return &PersonalTransactionService{repo: repo, categoryService: categoryService, paymentService: paymentService}
+func NewPersonalTransactionService(repo domain.PersonalTransactionRepository, categoryService CategoryServiceInterface, paymentService PaymentServiceInterface, tagProvider TagProvider) *PersonalTransactionService {
+	return &PersonalTransactionService{repo: repo, categoryService: categoryService, paymentService: paymentService, tagProvider: tagProvider, now: time.Now}
 }
 
-type TransactionSummary struct {
-	Year         int
-	IncomeTotal  float64
-	ExpenseTotal float64
-	Months       map[string]MonthSummary
+// SetBudgetAlerter is used to break the dependency cycle, budgets are computed from the summaries of this service.
+func (s *PersonalTransactionService) SetBudgetAlerter(budgetAlerter BudgetAlerter) {
+	s.budgetAlerter = budgetAlerter
 }
 
-type MonthSummary struct {
-	IncomeTotal  float64
-	ExpenseTotal float64
-	Weeks        []WeekSummary
+// SetAttachmentRemover lets deleted transactions take the content of their attachments with them, the attachment
+// service depends on the transactions of this service.
+func (s *PersonalTransactionService) SetAttachmentRemover(attachmentRemover AttachmentRemover) {
+	s.attachmentRemover = attachmentRemover
 }
 
-type WeekSummary struct {
-	Week         int
-	IncomeTotal  float64
-	ExpenseTotal float64
+// SetCategorizer makes bulk created transactions go through the categorization rules of the user, the rules are
+// matched against the transactions of this service.
+func (s *PersonalTransactionService) SetCategorizer(categorizer TransactionCategorizer) {
+	s.categorizer = categorizer
 }
 
-func (s *PersonalTransactionService) GetTransactionSummary(userID string, startDate, endDate time.Time) (map[int]TransactionSummary, error) {
-	transactions, err := s.repo.GetTransactionsInDateRange(userID, startDate, endDate)
-	if err != nil {
-		return nil, err
+// SetSettingsProvider makes summaries use the time zone and week start of the user, the defaults are used until it's
+// set.
+func (s *PersonalTransactionService) SetSettingsProvider(settings FinanceSettingsProvider) {
+	s.settings = settings
+}
+
+func (s *PersonalTransactionService) checkBudgetAlerts(userID string, transactions []domain.PersonalTransaction) {
+	if s.budgetAlerter == nil || len(transactions) == 0 {
+		return
 	}
+	s.budgetAlerter.CheckBudgetAlerts(userID, transactions)
+}
 
-	summary := make(map[int]TransactionSummary)
+// GetTransactionSummary returns the income and expense totals per year, month and week between the dates, aggregated
+// by the database. Weeks start on the day set by the user. Zero dates default to the start of the current year and
+// today, in the time zone of the user.
+func (s *PersonalTransactionService) GetTransactionSummary(userID string, startDate, endDate time.Time) (*domain.TransactionSummary, error) {
+	return s.GetFilteredTransactionSummary(userID, domain.TransactionSearch{StartDate: startDate, EndDate: endDate})
+}
 
-	for _, transaction := range transactions {
-		year := transaction.Date.Year()
-		month := transaction.Date.Month().String()
-		_, week := transaction.Date.ISOWeek()
-
-		if _, exists := summary[year]; !exists {
-			summary[year] = TransactionSummary{
-				Year:         year,
-				Months:       make(map[string]MonthSummary),
-				IncomeTotal:  0,
-				ExpenseTotal: 0,
-			}
-		}
+// GetFilteredTransactionSummary is GetTransactionSummary limited to the transactions matching the filters of the search.
+func (s *PersonalTransactionService) GetFilteredTransactionSummary(userID string, search domain.TransactionSearch) (*domain.TransactionSummary, error) {
+	settings, err := s.userSettings(userID)
+	if err != nil {
+		return nil, err
+	}
 
-		yearSummary := summary[year]
+	today := settings.Today(s.now())
+	if search.EndDate.IsZero() {
+		search.EndDate = today
+	}
+	if search.StartDate.IsZero() {
+		search.StartDate = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	search.StartDate, search.EndDate = domain.TruncateToDate(search.StartDate), domain.TruncateToDate(search.EndDate)
+	if search.StartDate.After(search.EndDate) {
+		return nil, financeErrors.NewValidationError("Start date must not be after end date")
+	}
 
-		if _, exists := yearSummary.Months[month]; !exists {
-			yearSummary.Months[month] = MonthSummary{
-				IncomeTotal:  0,
-				ExpenseTotal: 0,
-				Weeks:        []WeekSummary{},
-			}
-		}
+	totals, err := s.repo.GetTransactionPeriodTotals(userID, search, settings.FirstDayOfWeek())
+	if err != nil {
+		return nil, err
+	}
+	summary := domain.NewTransactionSummary(settings, search.StartDate, search.EndDate, totals)
+	return &summary, nil
+}
 
-		monthSummary := yearSummary.Months[month]
+// GetTransactionComparison compares the totals per category and per payment method of a period with another period
+// and with the monthly average of the months before it. The default period is the current month in the time zone of
+// the user.
+func (s *PersonalTransactionService) GetTransactionComparison(userID string, options domain.TransactionComparisonOptions) (*domain.TransactionComparison, error) {
+	settings, err := s.userSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := options.Normalize(settings.Today(s.now())); err != nil {
+		return nil, err
+	}
 
-		if transaction.Type == "income" {
-			yearSummary.IncomeTotal += transaction.Amount
-			monthSummary.IncomeTotal += transaction.Amount
-		} else if transaction.Type == "expense" {
-			yearSummary.ExpenseTotal += transaction.Amount
-			monthSummary.ExpenseTotal += transaction.Amount
-		}
+	current, err := s.comparisonTotals(userID, options.StartDate, options.EndDate, options.Type)
+	if err != nil {
+		return nil, err
+	}
+	compared, err := s.comparisonTotals(userID, options.CompareStartDate, options.CompareEndDate, options.Type)
+	if err != nil {
+		return nil, err
+	}
+	averageStart, averageEnd := options.AveragePeriod()
+	average, err := s.comparisonTotals(userID, averageStart, averageEnd, options.Type)
+	if err != nil {
+		return nil, err
+	}
 
-		found := false
-		for i, weekSummary := range monthSummary.Weeks {
-			if weekSummary.Week == week {
-				if transaction.Type == "income" {
-					monthSummary.Weeks[i].IncomeTotal += transaction.Amount
-				} else if transaction.Type == "expense" {
-					monthSummary.Weeks[i].ExpenseTotal += transaction.Amount
-				}
-				found = true
-				break
-			}
-		}
-		if !found {
-			weekSummary := WeekSummary{
-				Week: week,
-			}
-			if transaction.Type == "income" {
-				weekSummary.IncomeTotal = transaction.Amount
-			} else if transaction.Type == "expense" {
-				weekSummary.ExpenseTotal = transaction.Amount
-			}
-			monthSummary.Weeks = append(monthSummary.Weeks, weekSummary)
-		}
+	comparison := domain.NewTransactionComparison(options, current, compared, average)
+	return &comparison, nil
+}
 
-		yearSummary.Months[month] = monthSummary
-		summary[year] = yearSummary
+func (s *PersonalTransactionService) comparisonTotals(userID string, startDate, endDate time.Time, transactionType string) (domain.TransactionComparisonTotals, error) {
+	categories, err := s.repo.GetTransactionSummaryByCategory(userID, startDate, endDate, transactionType)
+	if err != nil {
+		return domain.TransactionComparisonTotals{}, err
 	}
+	paymentMethods, err := s.repo.GetTransactionSummaryByPaymentMethod(userID, startDate, endDate, transactionType)
+	if err != nil {
+		return domain.TransactionComparisonTotals{}, err
+	}
+	return domain.TransactionComparisonTotals{Categories: categories, PaymentMethods: paymentMethods}, nil
+}
 
-	return summary, nil
+// userSettings returns the finance settings of the user, the defaults if no settings provider is set.
+func (s *PersonalTransactionService) userSettings(userID string) (domain.FinanceSettings, error) {
+	if s.settings == nil {
+		return domain.DefaultFinanceSettings(userID), nil
+	}
+	settings, err := s.settings.GetSettings(userID)
+	if err != nil {
+		return domain.FinanceSettings{}, err
+	}
+	return *settings, nil
 }
 
 func (s *PersonalTransactionService) CreateTransaction(transaction *domain.PersonalTransaction) error {
 	transaction.ID = uuid.NewString()
-	transaction.RoundToTwoDecimalPlaces()
+	transaction.ClearReconciliation()
+	transaction.RoundToMinorUnits()
 	if err := transaction.Validate(); err != nil {
 		return err
 	}
 
-	exists, err := s.categoryService.DoesPredefinedCategoryExist(transaction.PredefinedCategoryID)
+	if err := s.validateTransactionReferences(transaction); err != nil {
+		return err
+	}
+
+	if err := s.repo.Save(*transaction); err != nil {
+		return err
+	}
+	s.checkBudgetAlerts(transaction.UserID, []domain.PersonalTransaction{*transaction})
+	return nil
+}
+
+// ValidateTransaction validates the transaction and everything it references, without saving it.
+func (s *PersonalTransactionService) ValidateTransaction(transaction *domain.PersonalTransaction) error {
+	transaction.RoundToMinorUnits()
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+	return s.validateTransactionReferences(transaction)
+}
+
+// validateTransactionReferences checks that categories, payment method, payment source and tags referenced by the transaction exist and belong to its owner.
+func (s *PersonalTransactionService) validateTransactionReferences(transaction *domain.PersonalTransaction) error {
+	if len(transaction.TagIDs) > 0 {
+		userTags, err := userTagIDs(s.tagProvider, transaction.UserID)
+		if err != nil {
+			return err
+		}
+		if err := checkTags(transaction.TagIDs, userTags); err != nil {
+			return err
+		}
+	}
+
+	if !transaction.IsTransfer() {
+		if err := s.validateCategoryReferences(transaction.PredefinedCategoryID, transaction.UserCategoryID, transaction.UserID); err != nil {
+			return err
+		}
+	}
+	for _, split := range transaction.Splits {
+		if err := s.validateCategoryReferences(split.PredefinedCategoryID, split.UserCategoryID, transaction.UserID); err != nil {
+			return err
+		}
+	}
+
+	exists, err := s.paymentService.DoesPaymentMethodExistByID(transaction.PaymentMethodID)
 	if err != nil {
 		return err
 	}
 	if !exists {
-		return financeErrors.ErrInvalidPredefinedCategory
+		return financeErrors.ErrInvalidPaymentMethod
 	}
-	if transaction.UserCategoryID != nil {
-		exists, err = s.categoryService.DoesUserCategoryExist(*transaction.UserCategoryID, transaction.UserID)
+	for _, sourceID := range []*int{transaction.PaymentSourceID, transaction.DestinationPaymentSourceID} {
+		if sourceID == nil {
+			continue
+		}
+		exists, err = s.paymentService.DoesUserPaymentSourceExistByID(*sourceID, transaction.UserID)
 		if err != nil {
 			return err
 		}
 		if !exists {
-			return financeErrors.ErrInvalidUserCategory
+			return financeErrors.ErrInvalidPaymentSource
 		}
 	}
+	return nil
+}
 
-	exists, err = s.paymentService.DoesPaymentMethodExistByID(transaction.PaymentMethodID)
+// userTagIDs returns the set of the tag IDs of the user.
+func userTagIDs(tagProvider TagProvider, userID string) (map[int]bool, error) {
+	tags, err := tagProvider.GetUserTags(userID)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs := make(map[int]bool, len(tags))
+	for _, tag := range tags {
+		tagIDs[tag.ID] = true
+	}
+	return tagIDs, nil
+}
+
+func checkTags(tagIDs []int, userTags map[int]bool) error {
+	for _, tagID := range tagIDs {
+		if !userTags[tagID] {
+			return financeErrors.ErrInvalidTag
+		}
+	}
+	return nil
+}
+
+func (s *PersonalTransactionService) validateCategoryReferences(predefinedCategoryID int, userCategoryID *int, userID string) error {
+	exists, err := s.categoryService.DoesPredefinedCategoryExist(predefinedCategoryID)
 	if err != nil {
 		return err
 	}
 	if !exists {
-		return financeErrors.ErrInvalidPaymentMethod
+		return financeErrors.ErrInvalidPredefinedCategory
 	}
-	if transaction.PaymentSourceID != nil {
-		exists, err = s.paymentService.DoesUserPaymentSourceExistByID(*transaction.PaymentSourceID, transaction.UserID)
+	if userCategoryID != nil {
+		exists, err = s.categoryService.DoesUserCategoryExist(*userCategoryID, userID)
 		if err != nil {
 			return err
 		}
@@ -166,19 +285,28 @@ func (s *PersonalTransactionService) CreateTransaction(transaction *domain.Perso
 			return financeErrors.ErrInvalidUserCategory
 		}
 	}
-
-	return s.repo.Save(*transaction)
+	return nil
 }
 
-func (s *PersonalTransactionService) CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string) error {
+// CreateTransactionsBulk validates all transactions first and saves them in a single database transaction. A single
+// invalid transaction rejects all of them, unless the options ask for a partial creation, which saves the valid ones
+// and reports the errors of the others per index, including the rows the database refuses to save. Existing transactions are recognized by their external ID or
+// fingerprint, see domain.FindDuplicates, and handled according to the duplicate policy of the options. Duplicates of
+// reconciled transactions are never overwritten, only skipped. The categorization rules of the user are applied before
+// the transactions are validated.
+func (s *PersonalTransactionService) CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string, options domain.BulkCreateOptions) (*domain.BulkCreateResult, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
 	predefinedCategories, err := s.categoryService.GetAllPredefinedCategories("")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	userCategories, err := s.categoryService.GetAllUserCategories(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	predefinedCategoryMap := make(map[int]bool)
@@ -193,12 +321,12 @@ func (s *PersonalTransactionService) CreateTransactionsBulk(transactions []*doma
 
 	paymentMethods, err := s.paymentService.GetAllPaymentMethods()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	paymentUserSource, err := s.paymentService.GetUserPaymentSources(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	paymentMethodsMap := make(map[int]bool)
@@ -211,86 +339,360 @@ func (s *PersonalTransactionService) CreateTransactionsBulk(transactions []*doma
 		paymentSourceMap[source.ID] = true
 	}
 
-	tx, err := s.repo.BeginTransaction()
+	userTags, err := userTagIDs(s.tagProvider, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+
 	var validationErrors = &financeErrors.ValidationErrors{}
-	defer func() {
-		if p := recover(); p != nil {
-			safeRollback(tx)
-			panic(p)
-		} else if err != nil {
-			safeRollback(tx)
-		} else {
-			err = tx.Commit()
+	rowErrors := make(map[int]error)
+	reject := func(i int, message string) {
+		err := financeErrors.NewIndexedValidationError(i+1, message)
+		validationErrors.Add(err)
+		rowErrors[i] = err
+	}
+	externalIDs := make(map[string]int)
+
+	for _, transaction := range transactions {
+		transaction.ClearReconciliation()
+		transaction.RoundToMinorUnits()
+		transaction.UserID = userID
+	}
+	if s.categorizer != nil {
+		if _, err := s.categorizer.Categorize(userID, transactions); err != nil {
+			return nil, err
 		}
-	}()
+	}
 
 	for i, transaction := range transactions {
-		transaction.ID = uuid.NewString()
-		transaction.RoundToTwoDecimalPlaces()
-		transaction.UserID = userID
 		if err := transaction.Validate(); err != nil {
-			validationErrors.Add(financeErrors.NewIndexedValidationError(i+1, err.Error()))
+			reject(i, err.Error())
 			continue
 		}
 
-		if _, exists := predefinedCategoryMap[transaction.PredefinedCategoryID]; !exists {
-			validationErrors.Add(financeErrors.NewIndexedValidationError(i+1, financeErrors.ErrInvalidPredefinedCategory.Error()))
+		if err := checkCategories(transaction, predefinedCategoryMap, userCategoryMap); err != nil {
+			reject(i, err.Error())
 			continue
 		}
-		if transaction.UserCategoryID != nil {
-			if _, exists := userCategoryMap[*transaction.UserCategoryID]; !exists {
-				validationErrors.Add(financeErrors.NewIndexedValidationError(i+1, financeErrors.ErrInvalidUserCategory.Error()))
+		if _, exists := paymentMethodsMap[transaction.PaymentMethodID]; !exists {
+			reject(i, financeErrors.ErrInvalidPaymentMethod.Error())
+			continue
+		}
+		if (transaction.PaymentSourceID != nil && !paymentSourceMap[*transaction.PaymentSourceID]) ||
+			(transaction.DestinationPaymentSourceID != nil && !paymentSourceMap[*transaction.DestinationPaymentSourceID]) {
+			reject(i, financeErrors.ErrInvalidPaymentSource.Error())
+			continue
+		}
+		if err := checkTags(transaction.TagIDs, userTags); err != nil {
+			reject(i, err.Error())
+			continue
+		}
+		if transaction.ExternalID != nil {
+			if first, exists := externalIDs[*transaction.ExternalID]; exists {
+				reject(i, fmt.Sprintf("ExternalID is already used by transaction %d", first))
 				continue
 			}
+			externalIDs[*transaction.ExternalID] = i + 1
 		}
-		if _, exists := paymentMethodsMap[transaction.PaymentMethodID]; !exists {
-			validationErrors.Add(financeErrors.NewIndexedValidationError(i+1, financeErrors.ErrInvalidPaymentMethod.Error()))
+	}
+
+	if len(validationErrors.Errors) > 0 && !options.Partial {
+		return nil, validationErrors
+	}
+
+	var valid []*domain.PersonalTransaction
+	var indexes []int
+	for i, transaction := range transactions {
+		if rowErrors[i] == nil {
+			valid = append(valid, transaction)
+			indexes = append(indexes, i)
+		}
+	}
+	result, err := s.planBulkCreate(valid, indexes, userID, options)
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range rowErrors {
+		result.Rows = append(result.Rows, domain.BulkRowResult{Index: i + 1, Action: domain.BulkActionReject, Error: err.Error()})
+		result.Rejected++
+	}
+	sort.Slice(result.Rows, func(i, j int) bool {
+		return result.Rows[i].Index < result.Rows[j].Index
+	})
+	if options.DryRun {
+		return result, nil
+	}
+
+	tx, err := s.repo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			safeRollback(tx)
+			panic(p)
+		}
+	}()
+
+	saved := make([]domain.PersonalTransaction, 0, len(transactions))
+
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		transaction := transactions[row.Index-1]
+		if row.Action != domain.BulkActionCreate && row.Action != domain.BulkActionOverwrite {
 			continue
 		}
-		if transaction.PaymentSourceID != nil {
-			if _, exists := paymentSourceMap[*transaction.PaymentSourceID]; !exists {
-				validationErrors.Add(financeErrors.NewIndexedValidationError(i+1, financeErrors.ErrInvalidPaymentSource.Error()))
+
+		save := func() error {
+			return s.saveBulkRow(transaction, row, result, options.DuplicatePolicy, tx)
+		}
+		if options.Partial {
+			// a row the database refuses only undoes its own changes and is reported like an invalid one
+			if err := s.repo.WithSavepoint(tx, save); err != nil {
+				log.Printf("Error saving transaction %d of a bulk creation: %v", row.Index, err)
+				rejectSavedRow(result, row)
 				continue
 			}
+		} else if err := save(); err != nil {
+			safeRollback(tx)
+			return nil, fmt.Errorf("database error at transaction %d: %w", row.Index, err)
 		}
-		if err := s.repo.SaveWithTransaction(*transaction, tx); err != nil {
-			return fmt.Errorf("database error at transaction %d: %w", i+1, err)
+		if row.Action == domain.BulkActionSkip {
+			continue
 		}
+		row.TransactionID = transaction.ID
+		saved = append(saved, *transaction)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	if len(validationErrors.Errors) > 0 {
-		safeRollback(tx)
-		return validationErrors
+	s.checkBudgetAlerts(userID, saved)
+	return result, nil
+}
+
+// saveBulkRow creates or overwrites the transaction as planned for its row.
+func (s *PersonalTransactionService) saveBulkRow(transaction *domain.PersonalTransaction, row *domain.BulkRowResult, result *domain.BulkCreateResult, policy domain.DuplicatePolicy, tx *sql.Tx) error {
+	if row.Action == domain.BulkActionOverwrite {
+		transaction.ID = row.MatchedTransactionID
+		_, err := s.repo.UpdateWithTransaction(*transaction, tx)
+		return err
+	}
+
+	transaction.ID = uuid.NewString()
+	err := s.repo.SaveWithTransaction(*transaction, tx)
+	if errors.Is(err, financeErrors.ErrExternalIDTaken) {
+		return s.resolveExternalIDConflict(transaction, row, result, policy, tx)
+	}
+	return err
+}
+
+// rejectSavedRow turns a row which failed to save into a rejected one.
+func rejectSavedRow(result *domain.BulkCreateResult, row *domain.BulkRowResult) {
+	switch row.Action {
+	case domain.BulkActionCreate:
+		result.Created--
+	case domain.BulkActionOverwrite:
+		result.Overwritten--
+	}
+	*row = domain.BulkRowResult{Index: row.Index, Action: domain.BulkActionReject, Error: fmt.Sprintf("Transaction %d could not be saved", row.Index)}
+	result.Rejected++
+}
+
+// resolveExternalIDConflict applies the duplicate policy to a created transaction whose external ID was taken after the
+// bulk creation was planned, e.g. by an import of the same statement running at the same time.
+func (s *PersonalTransactionService) resolveExternalIDConflict(transaction *domain.PersonalTransaction, row *domain.BulkRowResult, result *domain.BulkCreateResult, policy domain.DuplicatePolicy, tx *sql.Tx) error {
+	if policy == domain.DuplicatePolicyImport {
+		// the copy is imported anyway, the external ID stays with the existing transaction
+		transaction.ExternalID = nil
+		return s.repo.SaveWithTransaction(*transaction, tx)
+	}
+
+	existing, err := s.repo.FindByExternalIDWithTransaction(transaction.UserID, *transaction.ExternalID, tx)
+	if err != nil {
+		return err
+	}
+	row.Status = domain.DuplicateStatusDuplicate
+	row.MatchedTransactionID = existing.ID
+	result.Created--
+	if policy == domain.DuplicatePolicySkip || existing.IsReconciled() {
+		row.Action = domain.BulkActionSkip
+		result.Skipped++
+		return nil
+	}
+	row.Action = domain.BulkActionOverwrite
+	result.Overwritten++
+	transaction.ID = existing.ID
+	_, err = s.repo.UpdateWithTransaction(*transaction, tx)
+	return err
+}
+
+// planBulkCreate matches the validated transactions against the existing ones and decides what happens to each.
+// indexes are the positions of the transactions in the request.
+func (s *PersonalTransactionService) planBulkCreate(transactions []*domain.PersonalTransaction, indexes []int, userID string, options domain.BulkCreateOptions) (*domain.BulkCreateResult, error) {
+	var externalIDs []string
+	for _, transaction := range transactions {
+		if transaction.ExternalID != nil {
+			externalIDs = append(externalIDs, *transaction.ExternalID)
+		}
+	}
+	result := &domain.BulkCreateResult{DryRun: options.DryRun, Rows: make([]domain.BulkRowResult, len(transactions))}
+	if len(transactions) == 0 {
+		return result, nil
+	}
+	startDate, endDate := domain.DuplicateSearchRange(transactions)
+	existing, err := s.repo.FindDuplicateCandidates(userID, startDate, endDate, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, match := range domain.FindDuplicates(transactions, existing) {
+		row := domain.BulkRowResult{Index: indexes[i] + 1, Status: match.Status, Action: domain.BulkActionCreate}
+		if match.Match != nil {
+			row.MatchedTransactionID = match.Match.ID
+		}
+		if match.Status == domain.DuplicateStatusDuplicate {
+			switch {
+			case options.DuplicatePolicy == domain.DuplicatePolicySkip,
+				options.DuplicatePolicy == domain.DuplicatePolicyOverwrite && match.Match.IsReconciled():
+				row.Action = domain.BulkActionSkip
+			case options.DuplicatePolicy == domain.DuplicatePolicyOverwrite:
+				row.Action = domain.BulkActionOverwrite
+			}
+		}
+
+		switch row.Action {
+		case domain.BulkActionCreate:
+			result.Created++
+		case domain.BulkActionSkip:
+			result.Skipped++
+		case domain.BulkActionOverwrite:
+			result.Overwritten++
+		}
+		result.Rows[i] = row
+	}
+	return result, nil
+}
+
+// checkCategories checks the categories of the transaction and of its split lines against the known ones.
+func checkCategories(transaction *domain.PersonalTransaction, predefinedCategories, userCategories map[int]bool) error {
+	if transaction.IsTransfer() {
+		return nil
+	}
+	lines := append([]domain.TransactionSplit{{PredefinedCategoryID: transaction.PredefinedCategoryID, UserCategoryID: transaction.UserCategoryID}}, transaction.Splits...)
+	for _, line := range lines {
+		if !predefinedCategories[line.PredefinedCategoryID] {
+			return financeErrors.ErrInvalidPredefinedCategory
+		}
+		if line.UserCategoryID != nil && !userCategories[*line.UserCategoryID] {
+			return financeErrors.ErrInvalidUserCategory
+		}
 	}
 	return nil
 }
+
 func safeRollback(tx *sql.Tx) {
 	if err := tx.Rollback(); err != nil {
 		log.Printf("Error during transaction rollback: %v", err)
 	}
 }
 
-func (s *PersonalTransactionService) GetUserTransactions(userID, transactionType string, startDate, endDate time.Time, limit, page int) ([]domain.PersonalTransaction, error) {
-	transactions, err := s.repo.GetTransactionsByType(userID, transactionType, startDate, endDate, limit, page)
+// GetUserTransactions returns a page of the transactions of the user matching the search, see domain.TransactionSearch.
+func (s *PersonalTransactionService) GetUserTransactions(userID string, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.SearchTransactions(userID, search)
+}
+
+func (s *PersonalTransactionService) GetTransaction(transactionID, userID string) (*domain.PersonalTransaction, error) {
+	transaction, err := s.repo.FindByID(transactionID, userID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, financeErrors.ErrTransactionNotFound
+		}
 		return nil, err
 	}
-	if transactions == nil {
-		return []domain.PersonalTransaction{}, nil
+	return transaction, nil
+}
+
+// UpdateTransaction replaces the transaction, reconciled transactions are rejected with ErrTransactionReconciled.
+func (s *PersonalTransactionService) UpdateTransaction(transaction *domain.PersonalTransaction) error {
+	existing, err := s.GetTransaction(transaction.ID, transaction.UserID)
+	if err != nil {
+		return err
 	}
-	return transactions, nil
+	if existing.IsReconciled() {
+		return financeErrors.ErrTransactionReconciled
+	}
+	transaction.ClearReconciliation()
+
+	transaction.RoundToMinorUnits()
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.validateTransactionReferences(transaction); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.Update(*transaction)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrTransactionNotFound
+	}
+	return nil
 }
 
-func (s *PersonalTransactionService) UpdateTransaction(transaction domain.PersonalTransaction) error {
-	return s.repo.Update(transaction)
+// GetPaymentSourceHistory lists the transactions of the payment source with the change each of them made to its
+// balance and the running balance after it. Unlike the summaries it includes transfers, in the history of both
+// payment sources.
+func (s *PersonalTransactionService) GetPaymentSourceHistory(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PaymentSourceHistoryEntry, error) {
+	source, err := s.paymentService.GetUserPaymentSource(paymentSourceID, userID)
+	if err != nil {
+		if errors.Is(err, financeErrors.ErrPaymentSourceNotFound) {
+			return nil, financeErrors.ErrInvalidPaymentSource
+		}
+		return nil, err
+	}
+
+	// the running balance starts at the opening balance, so the transactions before startDate are needed as well
+	transactions, err := s.repo.GetPaymentSourceTransactions(userID, paymentSourceID, time.Time{}, endDate)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]domain.PaymentSourceHistoryEntry, 0, len(transactions))
+	balance := source.OpeningBalance
+	for _, transaction := range transactions {
+		change, err := transaction.BalanceChange(paymentSourceID)
+		if err != nil {
+			return nil, err
+		}
+		balance = balance.Add(change)
+		if transaction.Date.Before(startDate) {
+			continue
+		}
+		history = append(history, domain.PaymentSourceHistoryEntry{Transaction: transaction, Change: change, Balance: balance})
+	}
+	return history, nil
 }
 
-func (s *PersonalTransactionService) DeleteTransaction(transactionID int) error {
-	return s.repo.Delete(transactionID)
+// DeleteTransaction deletes the transaction together with its attachments.
+func (s *PersonalTransactionService) DeleteTransaction(transactionID, userID string) error {
+	affected, storageKeys, err := s.repo.Delete(transactionID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return financeErrors.ErrTransactionNotFound
+	}
+	if s.attachmentRemover != nil && len(storageKeys) > 0 {
+		s.attachmentRemover.RemoveAttachmentBlobs(storageKeys)
+	}
+	return nil
 }
 
 func (s *PersonalTransactionService) GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error) {
@@ -301,3 +703,16 @@ func (s *PersonalTransactionService) GetTransactionSummaryByCategory(userID stri
 
 	return transactions, nil
 }
+
+// GetTransactionSummaryByTag sums up the incomes and expenses per tag. Transactions with several tags are counted in
+// each of them, untagged transactions are left out.
+func (s *PersonalTransactionService) GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByTagSummary, error) {
+	summaries, err := s.repo.GetTransactionSummaryByTag(userID, startDate, endDate, transactionType)
+	if err != nil {
+		return nil, err
+	}
+	if summaries == nil {
+		return []domain.TransactionByTagSummary{}, nil
+	}
+	return summaries, nil
+}
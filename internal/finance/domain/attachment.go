@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxAttachmentSize limits a single uploaded file, scans of receipts and invoices are well below it.
+	MaxAttachmentSize         = 10 << 20
+	MaxTransactionAttachments = 20
+)
+
+// AttachmentContentTypes are the types of files which can be attached to transactions, as detected from their content.
+// The type claimed by the client or implied by the file name is never trusted.
+var AttachmentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+// BlobStorage keeps the content of attachments, it is addressed by the keys chosen by the attachment service.
+type BlobStorage interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob, deleting a missing blob is not an error.
+	Delete(key string) error
+}
+
+type AttachmentRepository interface {
+	FindByTransaction(transactionID string, userID string) ([]Attachment, error)
+	FindByID(attachmentID string, userID string) (*Attachment, error)
+	CountByTransaction(transactionID string, userID string) (int, error)
+	// Create fails with errors.ErrTooManyAttachments when the transaction already has MaxTransactionAttachments
+	// attachments and with errors.ErrTransactionNotFound when the transaction is gone, uploads running at the same
+	// time included.
+	Create(attachment *Attachment) error
+	Delete(attachmentID string, userID string) (int64, error)
+}
+
+// Attachment is a receipt, invoice or other document attached to a transaction of the user. Only its metadata is
+// kept in the database, the content is in the BlobStorage under StorageKey.
+type Attachment struct {
+	ID            string    `json:"id"`
+	TransactionID string    `json:"transaction_id"`
+	UserID        string    `json:"-"` // user UUID
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+	StorageKey    string    `json:"-"`
+}
+
+// AttachmentStorageKey places the attachments of every user in a directory of their own.
+func AttachmentStorageKey(userID, attachmentID string) string {
+	return userID + "/" + attachmentID
+}
+
+// SanitizeAttachmentFileName keeps the base name of the uploaded file, without any directories or control characters,
+// so that it can be sent back in a Content-Disposition header.
+func SanitizeAttachmentFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	BudgetPeriodYearly  BudgetPeriod = "yearly"
+)
+
+// BudgetAlertThresholds are the percentages of a budget limit which trigger an email once per period.
+var BudgetAlertThresholds = []int{80, 100}
+
+type BudgetRepository interface {
+	FindByUser(userID string) ([]Budget, error)
+	FindByID(budgetID int, userID string) (*Budget, error)
+	DoesBudgetExist(budget Budget) (bool, error)
+	Create(budget *Budget) error
+	Update(budget Budget) (int64, error)
+	Delete(budgetID int, userID string) (int64, error)
+	RecordAlert(budgetID int, periodStart time.Time, threshold int) (bool, error)
+}
+
+// Budget caps the expenses of a single PredefinedCategory or UserCategory per period.
+// With Rollover enabled the unspent part of previous periods is added to the limit of the next one.
+type Budget struct {
+	ID                   int           `json:"id"`
+	UserID               string        `json:"-"` // user UUID
+	PredefinedCategoryID *int          `json:"predefined_category_id"`
+	UserCategoryID       *int          `json:"user_category_id"`
+	CategoryName         string        `json:"category_name"`
+	Period               BudgetPeriod  `json:"period"`
+	Amount               money.Decimal `json:"amount"`
+	Rollover             bool          `json:"rollover"`
+	StartDate            time.Time     `json:"start_date"`
+}
+
+// BudgetProgress is the state of a budget in a single period.
+type BudgetProgress struct {
+	Budget      Budget        `json:"budget"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	RolledOver  money.Decimal `json:"rolled_over"`
+	Limit       money.Decimal `json:"limit"`
+	Spent       money.Decimal `json:"spent"`
+	Remaining   money.Decimal `json:"remaining"`
+	PercentUsed float64       `json:"percent_used"`
+}
+
+func (b *Budget) Validate() error {
+	if (b.PredefinedCategoryID == nil) == (b.UserCategoryID == nil) {
+		return errors.NewValidationError("Exactly one of PredefinedCategoryID or UserCategoryID must be provided")
+	}
+
+	switch b.Period {
+	case BudgetPeriodWeekly, BudgetPeriodMonthly, BudgetPeriodYearly:
+	default:
+		return errors.NewValidationError("Period must be one of 'weekly', 'monthly' or 'yearly'")
+	}
+
+	if !b.Amount.IsPositive() {
+		return errors.NewValidationError("Amount must be greater than zero")
+	}
+	if !b.Amount.FitsNumeric(10, 2) {
+		return errors.NewValidationError("Amount must be less than 100000000")
+	}
+
+	if b.StartDate.IsZero() {
+		return errors.NewValidationError("StartDate is required")
+	}
+
+	return nil
+}
+
+func (b *Budget) RoundToMinorUnits() {
+	b.Amount = b.Amount.RoundToCurrency(money.DefaultCurrency)
+}
+
+// PeriodContaining returns the first and the last day of the budget period containing the given date.
+// Weekly periods start on Monday, monthly and yearly periods follow the calendar.
+func (b *Budget) PeriodContaining(date time.Time) (time.Time, time.Time) {
+	date = TruncateToDate(date)
+	switch b.Period {
+	case BudgetPeriodWeekly:
+		start := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 6)
+	case BudgetPeriodYearly:
+		start := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+}
+
+// Spent picks the amount spent on the budget category out of a summary returned by GetTransactionSummaryByCategory.
+func (b *Budget) Spent(summaries []TransactionByCategorySummary) money.Decimal {
+	for _, summary := range summaries {
+		if b.PredefinedCategoryID != nil && summary.CategoryID == *b.PredefinedCategoryID {
+			return summary.TotalAmount
+		}
+		if b.UserCategoryID != nil {
+			for _, subCategory := range summary.SubCategories {
+				if subCategory.UserCategoryID == *b.UserCategoryID {
+					return subCategory.TotalAmount
+				}
+			}
+		}
+	}
+	return money.Zero
+}
+
+// NewBudgetProgress computes limit, remaining amount and percentage used of a period.
+func NewBudgetProgress(budget Budget, periodStart, periodEnd time.Time, rolledOver, spent money.Decimal) BudgetProgress {
+	limit := budget.Amount.Add(rolledOver)
+	progress := BudgetProgress{
+		Budget:      budget,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		RolledOver:  rolledOver.RoundToCurrency(money.DefaultCurrency),
+		Limit:       limit.RoundToCurrency(money.DefaultCurrency),
+		Spent:       spent.RoundToCurrency(money.DefaultCurrency),
+		Remaining:   limit.Sub(spent).RoundToCurrency(money.DefaultCurrency),
+	}
+	if limit.IsPositive() {
+		progress.PercentUsed = spent.Div(limit).Mul(money.FromInt(100)).Round(2).Float64()
+	}
+	return progress
+}
@@ -0,0 +1,303 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type RuleMatchField string
+
+const (
+	RuleMatchName              RuleMatchField = "name"
+	RuleMatchDescription       RuleMatchField = "description"
+	RuleMatchNameOrDescription RuleMatchField = "name_or_description"
+)
+
+type RuleMatchType string
+
+const (
+	RuleMatchContains RuleMatchType = "contains"
+	RuleMatchRegex    RuleMatchType = "regex"
+)
+
+// MaxCategorizationRules limits the number of rules of a single user, every imported transaction is checked against
+// all of them.
+const MaxCategorizationRules = 200
+
+type CategorizationRuleRepository interface {
+	// FindByUser returns the rules of the user in the order they are evaluated in.
+	FindByUser(userID string) ([]CategorizationRule, error)
+	FindByID(ruleID int, userID string) (*CategorizationRule, error)
+	Create(rule *CategorizationRule) error
+	Update(rule CategorizationRule) (int64, error)
+	Delete(ruleID int, userID string) (int64, error)
+}
+
+// CategorizationRule assigns categories, a payment method and tags to transactions whose name or description matches
+// Pattern and whose amount lies within MinAmount and MaxAmount, both inclusive. Rules are evaluated by ascending
+// Priority and only the first matching rule is applied. Actions left nil keep the value of the transaction, TagIDs
+// are added to the tags the transaction already has.
+type CategorizationRule struct {
+	ID              int            `json:"id"`
+	UserID          string         `json:"-"` // user UUID
+	Name            string         `json:"name"`
+	Priority        int            `json:"priority"`
+	MatchField      RuleMatchField `json:"match_field"`
+	MatchType       RuleMatchType  `json:"match_type"`
+	Pattern         string         `json:"pattern"`
+	MinAmount       *money.Decimal `json:"min_amount"`
+	MaxAmount       *money.Decimal `json:"max_amount"`
+	TransactionType string         `json:"transaction_type"` // empty matches both incomes and expenses
+
+	PredefinedCategoryID *int  `json:"predefined_category_id"`
+	UserCategoryID       *int  `json:"user_category_id"`
+	PaymentMethodID      *int  `json:"payment_method_id"`
+	TagIDs               []int `json:"tag_ids"`
+
+	pattern *regexp.Regexp
+}
+
+// RuleSuggestion is a rule learned from the categorized history of the user, not saved until the user accepts it.
+type RuleSuggestion struct {
+	Rule        CategorizationRule `json:"rule"`
+	Occurrences int                `json:"occurrences"`
+}
+
+func (r *CategorizationRule) Validate() error {
+	if len(r.Name) <= 0 || len(r.Name) > 50 {
+		return errors.NewValidationError("Name should be between 0 and 50")
+	}
+
+	switch r.MatchField {
+	case RuleMatchName, RuleMatchDescription, RuleMatchNameOrDescription:
+	default:
+		return errors.NewValidationError("MatchField must be one of 'name', 'description' or 'name_or_description'")
+	}
+
+	if len(r.Pattern) > 200 {
+		return errors.NewValidationError("Pattern must be at most 200 characters long")
+	}
+	switch r.MatchType {
+	case RuleMatchContains:
+	case RuleMatchRegex:
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("Pattern is not a valid regular expression: %v", err))
+		}
+	default:
+		return errors.NewValidationError("MatchType must be either 'contains' or 'regex'")
+	}
+
+	if r.Pattern == "" && r.MinAmount == nil && r.MaxAmount == nil {
+		return errors.NewValidationError("A rule needs a Pattern or an amount range")
+	}
+
+	// transaction amounts are always positive
+	for _, amount := range []*money.Decimal{r.MinAmount, r.MaxAmount} {
+		if amount != nil && (!amount.IsPositive() || !amount.FitsNumeric(12, 2)) {
+			return errors.NewValidationError("MinAmount and MaxAmount, if provided, must be greater than zero and less than 10000000000")
+		}
+	}
+
+	if r.MinAmount != nil && r.MaxAmount != nil && r.MinAmount.GreaterThan(*r.MaxAmount) {
+		return errors.NewValidationError("MinAmount must not be greater than MaxAmount")
+	}
+
+	if r.TransactionType != "" && r.TransactionType != string(TransactionTypeIncome) && r.TransactionType != string(TransactionTypeExpense) {
+		return errors.NewValidationError("TransactionType, if provided, must be either 'income' or 'expense'")
+	}
+
+	if r.PredefinedCategoryID == nil && r.UserCategoryID == nil && r.PaymentMethodID == nil && len(r.TagIDs) == 0 {
+		return errors.NewValidationError("A rule must set a category, a payment method or tags")
+	}
+
+	if err := validateTagIDs(r.TagIDs, "TagIDs"); err != nil {
+		return err
+	}
+
+	for _, id := range []*int{r.PredefinedCategoryID, r.UserCategoryID, r.PaymentMethodID} {
+		if id != nil && *id <= 0 {
+			return errors.NewValidationError("PredefinedCategoryID, UserCategoryID and PaymentMethodID, if provided, must be greater than zero")
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the rule applies to the transaction. Transfers and split transactions are never matched,
+// they have no single category to assign.
+func (r *CategorizationRule) Matches(transaction *PersonalTransaction) bool {
+	if transaction.IsTransfer() || len(transaction.Splits) > 0 {
+		return false
+	}
+	if r.TransactionType != "" && transaction.Type != r.TransactionType {
+		return false
+	}
+	if r.MinAmount != nil && transaction.Amount.LessThan(*r.MinAmount) {
+		return false
+	}
+	if r.MaxAmount != nil && transaction.Amount.GreaterThan(*r.MaxAmount) {
+		return false
+	}
+	if r.Pattern == "" {
+		return true
+	}
+
+	description := ""
+	if transaction.Description != nil {
+		description = *transaction.Description
+	}
+	switch r.MatchField {
+	case RuleMatchName:
+		return r.matchesText(transaction.Name)
+	case RuleMatchDescription:
+		return r.matchesText(description)
+	default:
+		return r.matchesText(transaction.Name) || r.matchesText(description)
+	}
+}
+
+func (r *CategorizationRule) matchesText(text string) bool {
+	if r.MatchType == RuleMatchRegex {
+		if r.pattern == nil {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return false
+			}
+			r.pattern = pattern
+		}
+		return r.pattern.MatchString(text)
+	}
+	pattern := strings.ToLower(r.Pattern)
+	// suggested patterns are normalized names, they have to match the names they were learned from
+	return strings.Contains(strings.ToLower(text), pattern) || strings.Contains(NormalizeTransactionName(text), pattern)
+}
+
+// Apply sets the fields the rule assigns and reports whether the transaction changed. A new predefined category
+// also replaces the user category, which belongs to the previous one.
+func (r *CategorizationRule) Apply(transaction *PersonalTransaction) bool {
+	before := *transaction
+	if r.PredefinedCategoryID != nil {
+		transaction.PredefinedCategoryID = *r.PredefinedCategoryID
+		transaction.UserCategoryID = nil
+	}
+	if r.UserCategoryID != nil {
+		value := *r.UserCategoryID
+		transaction.UserCategoryID = &value
+	}
+	if r.PaymentMethodID != nil {
+		transaction.PaymentMethodID = *r.PaymentMethodID
+	}
+	tagsAdded := false
+	for _, tagID := range r.TagIDs {
+		if !slices.Contains(transaction.TagIDs, tagID) {
+			transaction.TagIDs = append(transaction.TagIDs, tagID)
+			tagsAdded = true
+		}
+	}
+	return tagsAdded || before.PredefinedCategoryID != transaction.PredefinedCategoryID ||
+		before.PaymentMethodID != transaction.PaymentMethodID ||
+		(before.UserCategoryID == nil) != (transaction.UserCategoryID == nil) ||
+		(before.UserCategoryID != nil && *before.UserCategoryID != *transaction.UserCategoryID)
+}
+
+// ApplyFirstMatchingRule applies the first of the rules, which must be sorted by priority, matching the transaction.
+// It returns the applied rule or nil.
+func ApplyFirstMatchingRule(rules []CategorizationRule, transaction *PersonalTransaction) *CategorizationRule {
+	for i := range rules {
+		if rules[i].Matches(transaction) {
+			rules[i].Apply(transaction)
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// SuggestRules learns rules from categorized transactions: names which appear at least minOccurrences times and were
+// booked on the same category every time become a "contains" rule, unless one of the existing rules already matches
+// them. Numbers, e.g. store or card numbers, are ignored when comparing names.
+func SuggestRules(history []PersonalTransaction, existing []CategorizationRule, minOccurrences int) []RuleSuggestion {
+	type group struct {
+		example     PersonalTransaction
+		occurrences int
+		consistent  bool
+	}
+	groups := map[string]*group{}
+	var keys []string
+	for _, transaction := range history {
+		if transaction.IsTransfer() || len(transaction.Splits) > 0 {
+			continue
+		}
+		key := NormalizeTransactionName(transaction.Name)
+		if len(key) < 3 {
+			continue
+		}
+		key = transaction.Type + ":" + key
+		existingGroup, ok := groups[key]
+		if !ok {
+			groups[key] = &group{example: transaction, occurrences: 1, consistent: true}
+			keys = append(keys, key)
+			continue
+		}
+		existingGroup.occurrences++
+		if existingGroup.example.PredefinedCategoryID != transaction.PredefinedCategoryID ||
+			(existingGroup.example.UserCategoryID == nil) != (transaction.UserCategoryID == nil) ||
+			(transaction.UserCategoryID != nil && *existingGroup.example.UserCategoryID != *transaction.UserCategoryID) {
+			existingGroup.consistent = false
+		}
+	}
+
+	var suggestions []RuleSuggestion
+	for _, key := range keys {
+		g := groups[key]
+		if !g.consistent || g.occurrences < minOccurrences {
+			continue
+		}
+		covered := false
+		for i := range existing {
+			if existing[i].Matches(&g.example) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		pattern := NormalizeTransactionName(g.example.Name)
+		predefinedCategoryID := g.example.PredefinedCategoryID
+		suggestions = append(suggestions, RuleSuggestion{
+			Rule: CategorizationRule{
+				Name:                 TruncateText(pattern, 50),
+				MatchField:           RuleMatchName,
+				MatchType:            RuleMatchContains,
+				Pattern:              pattern,
+				TransactionType:      g.example.Type,
+				PredefinedCategoryID: &predefinedCategoryID,
+				UserCategoryID:       g.example.UserCategoryID,
+			},
+			Occurrences: g.occurrences,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Occurrences > suggestions[j].Occurrences
+	})
+	return suggestions
+}
+
+// NormalizeTransactionName lowercases the name and drops the words containing digits, e.g. "BIEDRONKA 1234 WARSZAWA"
+// becomes "biedronka warszawa".
+func NormalizeTransactionName(name string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		if strings.IndexFunc(word, unicode.IsDigit) < 0 {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func categorizedExpense(name string, amount string, predefinedCategoryID int) PersonalTransaction {
+	return PersonalTransaction{Name: name, Amount: money.MustParse(amount), Type: "expense", Date: date(2024, 3, 1),
+		PredefinedCategoryID: predefinedCategoryID, PaymentMethodID: 1}
+}
+
+func TestCategorizationRule_Validate(t *testing.T) {
+	groceries := 9
+	rule := CategorizationRule{Name: "Groceries", MatchField: RuleMatchName, MatchType: RuleMatchRegex, Pattern: `(?i)^lidl|biedronka`,
+		PredefinedCategoryID: &groceries}
+	assert.NoError(t, rule.Validate())
+
+	invalidPattern := rule
+	invalidPattern.Pattern = "lidl("
+	assert.Error(t, invalidPattern.Validate())
+
+	noAction := rule
+	noAction.PredefinedCategoryID = nil
+	assert.EqualError(t, noAction.Validate(), "A rule must set a category, a payment method or tags")
+
+	noCondition := rule
+	noCondition.Pattern = ""
+	assert.Error(t, noCondition.Validate())
+
+	min, max := money.MustParse("100"), money.MustParse("50")
+	invertedRange := noCondition
+	invertedRange.MinAmount, invertedRange.MaxAmount = &min, &max
+	assert.EqualError(t, invertedRange.Validate(), "MinAmount must not be greater than MaxAmount")
+
+	for _, amount := range []string{"-5", "0", "10000000000"} {
+		outOfRange := noCondition
+		invalid := money.MustParse(amount)
+		outOfRange.MinAmount = &invalid
+		assert.EqualError(t, outOfRange.Validate(), "MinAmount and MaxAmount, if provided, must be greater than zero and less than 10000000000", amount)
+	}
+}
+
+func TestApplyFirstMatchingRule_UsesPriorityOrderAndAmountRange(t *testing.T) {
+	fuel, groceries, card := 12, 9, 3
+	max := money.MustParse("50")
+	rules := []CategorizationRule{
+		{ID: 1, Name: "Small station purchases", MatchField: RuleMatchName, MatchType: RuleMatchContains, Pattern: "orlen",
+			MaxAmount: &max, PredefinedCategoryID: &groceries},
+		{ID: 2, Name: "Fuel", MatchField: RuleMatchNameOrDescription, MatchType: RuleMatchContains, Pattern: "ORLEN",
+			PredefinedCategoryID: &fuel, PaymentMethodID: &card},
+	}
+
+	snacks := categorizedExpense("Orlen Stacja 123", "18.50", 1)
+	assert.Equal(t, 1, ApplyFirstMatchingRule(rules, &snacks).ID)
+	assert.Equal(t, groceries, snacks.PredefinedCategoryID)
+	assert.Equal(t, 1, snacks.PaymentMethodID)
+
+	refuel := categorizedExpense("Card payment", "250", 1)
+	description := "PKN ORLEN S.A."
+	refuel.Description = &description
+	userCategory := 7
+	refuel.UserCategoryID = &userCategory
+	assert.Equal(t, 2, ApplyFirstMatchingRule(rules, &refuel).ID)
+	assert.Equal(t, fuel, refuel.PredefinedCategoryID)
+	assert.Nil(t, refuel.UserCategoryID)
+	assert.Equal(t, card, refuel.PaymentMethodID)
+
+	other := categorizedExpense("Cinema", "30", 1)
+	assert.Nil(t, ApplyFirstMatchingRule(rules, &other))
+	assert.Equal(t, 1, other.PredefinedCategoryID)
+}
+
+func TestCategorizationRule_ApplyAddsTags(t *testing.T) {
+	vacation, travel := 4, 5
+	rule := CategorizationRule{Name: "Holiday bookings", MatchField: RuleMatchName, MatchType: RuleMatchContains, Pattern: "booking.com",
+		TagIDs: []int{vacation, travel}}
+	assert.NoError(t, rule.Validate())
+
+	hotel := categorizedExpense("Booking.com Hotel", "400", 20)
+	hotel.TagIDs = []int{travel}
+	assert.True(t, rule.Apply(&hotel))
+	assert.Equal(t, []int{travel, vacation}, hotel.TagIDs)
+	assert.Equal(t, 20, hotel.PredefinedCategoryID)
+	assert.False(t, rule.Apply(&hotel))
+}
+
+func TestSuggestRules_LearnsConsistentlyCategorizedNames(t *testing.T) {
+	history := []PersonalTransaction{
+		categorizedExpense("BIEDRONKA 1234 WARSZAWA", "45.10", 9),
+		categorizedExpense("Biedronka 0871 Warszawa", "12.99", 9),
+		categorizedExpense("BIEDRONKA 1234 WARSZAWA", "80", 9),
+		categorizedExpense("Allegro 1", "20", 5),
+		categorizedExpense("Allegro 2", "20", 6),
+		categorizedExpense("Allegro 3", "20", 5),
+		categorizedExpense("Netflix", "43", 14),
+		categorizedExpense("Netflix", "43", 14),
+		categorizedExpense("Netflix", "43", 14),
+		categorizedExpense("Netflix", "43", 14),
+		categorizedExpense("Spotify", "20", 14),
+	}
+
+	suggestions := SuggestRules(history, nil, 3)
+	assert.Len(t, suggestions, 2)
+	assert.Equal(t, "netflix", suggestions[0].Rule.Pattern)
+	assert.Equal(t, 4, suggestions[0].Occurrences)
+	assert.Equal(t, "biedronka warszawa", suggestions[1].Rule.Pattern)
+	assert.Equal(t, 9, *suggestions[1].Rule.PredefinedCategoryID)
+	assert.NoError(t, suggestions[1].Rule.Validate())
+
+	// the suggested rules match the transactions they were learned from
+	for _, transaction := range history[:3] {
+		assert.True(t, suggestions[1].Rule.Matches(&transaction), transaction.Name)
+	}
+	assert.True(t, suggestions[0].Rule.Matches(&history[6]))
+
+	existing := []CategorizationRule{suggestions[0].Rule}
+	suggestions = SuggestRules(history, existing, 3)
+	assert.Len(t, suggestions, 1)
+	assert.Equal(t, "biedronka warszawa", suggestions[0].Rule.Pattern)
+}
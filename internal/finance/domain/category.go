@@ -1,20 +1,63 @@
 package domain
 
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"regexp"
+)
+
 type PredefinedCategory struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 	Type string `json:"type"` // "income" or "expense"
 }
 
+// UserCategory is a user defined category, optionally placed under a PredefinedCategory as its parent
+// e.g. "Organic" and "Discounter" under "Groceries".
 type UserCategory struct {
-	ID     int
-	Name   string
-	UserID string // user UUID
+	ID               int     `json:"id"`
+	Name             string  `json:"name"`
+	UserID           string  `json:"-"` // user UUID
+	ParentCategoryID *int    `json:"parent_category_id"`
+	Type             string  `json:"type"` // "income" or "expense"
+	Color            *string `json:"color"`
+	Icon             *string `json:"icon"`
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func (c *UserCategory) Validate() error {
+	if len(c.Name) <= 0 || len(c.Name) > 50 {
+		return errors.NewValidationError("Name should be between 0 and 50")
+	}
+
+	if c.Type != string(TransactionTypeIncome) && c.Type != string(TransactionTypeExpense) {
+		return errors.NewValidationError("Type must be either 'income' or 'expense'")
+	}
+
+	if c.ParentCategoryID != nil && *c.ParentCategoryID <= 0 {
+		return errors.NewValidationError("ParentCategoryID, if provided, must be greater than zero")
+	}
+
+	if c.Color != nil && !colorPattern.MatchString(*c.Color) {
+		return errors.NewValidationError("Color, if provided, must be a hex value like #1A2B3C")
+	}
+
+	if c.Icon != nil && (len(*c.Icon) <= 0 || len(*c.Icon) > 50) {
+		return errors.NewValidationError("Icon if provided length must be less than or equal to 50 characters and greater than 0")
+	}
+
+	return nil
 }
 
 type CategoryRepository interface {
 	FindPredefinedCategories(categoryType string) ([]PredefinedCategory, error)
+	FindPredefinedCategoryByID(categoryID int) (*PredefinedCategory, error)
 	FindUserCategories(userID string) ([]UserCategory, error)
+	FindUserCategoryByID(categoryID int, userID string) (*UserCategory, error)
 	DoesPredefinedCategoryExistByID(categoryID int) (bool, error)
 	DoesUserCategoryExistByID(categoryID int, userID string) (bool, error)
+	DoesUserCategoryNameExist(name string, userID string, excludedID int) (bool, error)
+	CreateUserCategory(category *UserCategory) error
+	UpdateUserCategory(category UserCategory) (int64, error)
+	DeleteUserCategory(categoryID int, userID string) (int64, error)
 }
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"strings"
+	"time"
+)
+
+// DuplicatePolicy decides what happens to a created transaction which already exists.
+type DuplicatePolicy string
+
+const (
+	DuplicatePolicySkip      DuplicatePolicy = "skip"
+	DuplicatePolicyOverwrite DuplicatePolicy = "overwrite"
+	DuplicatePolicyImport    DuplicatePolicy = "import"
+)
+
+type DuplicateStatus string
+
+const (
+	DuplicateStatusNew DuplicateStatus = "new"
+	// DuplicateStatusDuplicate rows have the external ID or the fingerprint of an existing transaction.
+	DuplicateStatusDuplicate DuplicateStatus = "duplicate"
+	// DuplicateStatusNearDuplicate rows resemble an existing transaction, they are reported but always created.
+	DuplicateStatusNearDuplicate DuplicateStatus = "near_duplicate"
+)
+
+type BulkAction string
+
+const (
+	BulkActionCreate    BulkAction = "create"
+	BulkActionSkip      BulkAction = "skip"
+	BulkActionOverwrite BulkAction = "overwrite"
+	// BulkActionReject marks invalid transactions left out by a partial creation.
+	BulkActionReject BulkAction = "reject"
+)
+
+// NearDuplicateDays is how far apart the dates of near duplicates may be, banks often book card payments a few days
+// after they were made.
+const NearDuplicateDays = 3
+
+// MaxExternalIDLength limits the external IDs, e.g. the FITID of OFX statements, supplied by clients.
+const MaxExternalIDLength = 100
+
+// BulkCreateOptions control how CreateTransactionsBulk treats transactions which already exist. A dry run only
+// reports what would happen. Partial saves the valid transactions even if some of the others are invalid.
+type BulkCreateOptions struct {
+	DuplicatePolicy DuplicatePolicy
+	DryRun          bool
+	Partial         bool
+}
+
+// BulkCreateResult reports what happened, or with DryRun what would happen, to every created transaction.
+type BulkCreateResult struct {
+	DryRun      bool            `json:"dry_run"`
+	Created     int             `json:"created"`
+	Skipped     int             `json:"skipped"`
+	Overwritten int             `json:"overwritten"`
+	Rejected    int             `json:"rejected"`
+	Rows        []BulkRowResult `json:"rows"`
+}
+
+type BulkRowResult struct {
+	Index         int             `json:"index"`            // 1-based position in the request
+	Status        DuplicateStatus `json:"status,omitempty"` // empty for rejected transactions
+	Action        BulkAction      `json:"action"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	// MatchedTransactionID is the existing transaction the row duplicates or resembles.
+	MatchedTransactionID string `json:"matched_transaction_id,omitempty"`
+	// Error is the validation error of a rejected transaction.
+	Error string `json:"error,omitempty"`
+}
+
+// DuplicateMatch is the existing transaction a created one was matched with, Match is nil for new transactions.
+type DuplicateMatch struct {
+	Status DuplicateStatus
+	Match  *PersonalTransaction
+}
+
+func (o *BulkCreateOptions) Validate() error {
+	if o.DuplicatePolicy == "" {
+		o.DuplicatePolicy = DuplicatePolicySkip
+	}
+	switch o.DuplicatePolicy {
+	case DuplicatePolicySkip, DuplicatePolicyOverwrite, DuplicatePolicyImport:
+		return nil
+	default:
+		return errors.NewValidationError("Duplicate policy must be one of 'skip', 'overwrite' or 'import'")
+	}
+}
+
+// Fingerprint identifies the transaction by its date, type, amount, name and payment sources. The name is compared
+// case-insensitively and ignoring repeated whitespace, so that re-exported statements still match.
+func (t *PersonalTransaction) Fingerprint() string {
+	sources := [2]string{}
+	for i, sourceID := range []*int{t.PaymentSourceID, t.DestinationPaymentSourceID} {
+		if sourceID != nil {
+			sources[i] = fmt.Sprint(*sourceID)
+		}
+	}
+	key := strings.Join([]string{
+		t.Date.Format("2006-01-02"), t.Type, t.Amount.String(),
+		strings.Join(strings.Fields(strings.ToLower(t.Name)), " "),
+		sources[0], sources[1],
+	}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// resembles reports whether the transactions have the same type, amount and payment source, dates at most
+// NearDuplicateDays apart and names of which one contains the other, once numbers are dropped.
+func (t *PersonalTransaction) resembles(other *PersonalTransaction) bool {
+	if t.Type != other.Type || t.Amount.Cmp(other.Amount) != 0 || !sameID(t.PaymentSourceID, other.PaymentSourceID) {
+		return false
+	}
+	days := t.Date.Sub(other.Date).Hours() / 24
+	if days > NearDuplicateDays || days < -NearDuplicateDays {
+		return false
+	}
+	name, otherName := NormalizeTransactionName(t.Name), NormalizeTransactionName(other.Name)
+	return strings.Contains(name, otherName) || strings.Contains(otherName, name)
+}
+
+func sameID(a, b *int) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+// DuplicateSearchRange returns the dates the existing transactions compared with the created ones must lie in.
+func DuplicateSearchRange(transactions []*PersonalTransaction) (time.Time, time.Time) {
+	var start, end time.Time
+	for i, transaction := range transactions {
+		if i == 0 || transaction.Date.Before(start) {
+			start = transaction.Date
+		}
+		if i == 0 || transaction.Date.After(end) {
+			end = transaction.Date
+		}
+	}
+	return start.AddDate(0, 0, -NearDuplicateDays), end.AddDate(0, 0, NearDuplicateDays)
+}
+
+// FindDuplicates matches the created transactions against the existing ones. A transaction duplicates the existing one
+// with the same external ID, or, unless both have different external IDs, the same fingerprint. Each existing
+// transaction is the duplicate of a single created one, so that two identical payments on the same day in a statement
+// are both matched when it's imported again, but only one of them if the other is new.
+func FindDuplicates(transactions []*PersonalTransaction, existing []PersonalTransaction) []DuplicateMatch {
+	matches := make([]DuplicateMatch, len(transactions))
+	used := make([]bool, len(existing))
+
+	byExternalID := map[string]int{}
+	byFingerprint := map[string][]int{}
+	for i := range existing {
+		if existing[i].ExternalID != nil {
+			byExternalID[*existing[i].ExternalID] = i
+		}
+		fingerprint := existing[i].Fingerprint()
+		byFingerprint[fingerprint] = append(byFingerprint[fingerprint], i)
+	}
+
+	match := func(i, j int) {
+		used[j] = true
+		matches[i] = DuplicateMatch{Status: DuplicateStatusDuplicate, Match: &existing[j]}
+	}
+	for i, transaction := range transactions {
+		if transaction.ExternalID == nil {
+			continue
+		}
+		if j, ok := byExternalID[*transaction.ExternalID]; ok && !used[j] {
+			match(i, j)
+		}
+	}
+	for i, transaction := range transactions {
+		if matches[i].Match != nil {
+			continue
+		}
+		for _, j := range byFingerprint[transaction.Fingerprint()] {
+			if !used[j] && (transaction.ExternalID == nil || existing[j].ExternalID == nil) {
+				match(i, j)
+				break
+			}
+		}
+	}
+
+	for i, transaction := range transactions {
+		if matches[i].Match != nil {
+			continue
+		}
+		matches[i].Status = DuplicateStatusNew
+		for j := range existing {
+			if !used[j] && transaction.resembles(&existing[j]) {
+				matches[i] = DuplicateMatch{Status: DuplicateStatusNearDuplicate, Match: &existing[j]}
+				break
+			}
+		}
+	}
+	return matches
+}
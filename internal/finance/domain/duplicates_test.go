@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	fitID, otherFitID, gymFitID := "2024030100001", "2024030100002", "2024030500003"
+	coffee := categorizedExpense("Coffee  SHOP", "4.50", 9)
+	existing := []PersonalTransaction{
+		{ID: "coffee-1", Name: "coffee shop", Amount: money.MustParse("4.5"), Type: "expense", Date: date(2024, 3, 1)},
+		{ID: "rent", Name: "Rent", Amount: money.MustParse("2500"), Type: "expense", Date: date(2024, 2, 1), ExternalID: &fitID},
+		{ID: "card", Name: "Biedronka", Amount: money.MustParse("87.20"), Type: "expense", Date: date(2024, 3, 2)},
+		{ID: "bank-transfer", Name: "Gym", Amount: money.MustParse("120"), Type: "expense", Date: date(2024, 3, 5), ExternalID: &otherFitID},
+	}
+
+	secondCoffee := coffee
+	renamedRent := categorizedExpense("Rent March", "2500", 17)
+	renamedRent.ExternalID = &fitID
+	cardPayment := categorizedExpense("CARD PAYMENT BIEDRONKA 1234", "87.20", 9)
+	cardPayment.Date = date(2024, 3, 4)
+	gym := categorizedExpense("Gym", "120", 5)
+	gym.Date = date(2024, 3, 5)
+	gym.ExternalID = &gymFitID
+
+	matches := FindDuplicates([]*PersonalTransaction{&coffee, &secondCoffee, &renamedRent, &cardPayment, &gym}, existing)
+
+	// the second coffee is new, the statement has one more of them than the ones already imported
+	assert.Equal(t, DuplicateStatusDuplicate, matches[0].Status)
+	assert.Equal(t, "coffee-1", matches[0].Match.ID)
+	assert.Equal(t, DuplicateMatch{Status: DuplicateStatusNew}, matches[1])
+	assert.Equal(t, DuplicateStatusDuplicate, matches[2].Status)
+	assert.Equal(t, "rent", matches[2].Match.ID)
+	assert.Equal(t, DuplicateStatusNearDuplicate, matches[3].Status)
+	assert.Equal(t, "card", matches[3].Match.ID)
+	// different external IDs are different transactions, even with the same fingerprint
+	assert.Equal(t, DuplicateStatusNearDuplicate, matches[4].Status)
+	assert.Equal(t, "bank-transfer", matches[4].Match.ID)
+
+	lunch := categorizedExpense("Lunch", "30", 9)
+	assert.Equal(t, []DuplicateMatch{{Status: DuplicateStatusNew}}, FindDuplicates([]*PersonalTransaction{&lunch}, existing))
+}
+
+func TestBulkCreateOptions_Validate(t *testing.T) {
+	options := BulkCreateOptions{}
+	assert.NoError(t, options.Validate())
+	assert.Equal(t, DuplicatePolicySkip, options.DuplicatePolicy)
+
+	options.DuplicatePolicy = "replace"
+	assert.Error(t, options.Validate())
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+const (
+	DefaultForecastMonths        = 3
+	MaxForecastMonths            = 24
+	DefaultForecastHistoryMonths = 6
+	MaxForecastHistoryMonths     = 24
+)
+
+// InvestmentContributionCategories are the predefined expense categories of recurring items which move money into
+// investments, they are reported as investment contributions in forecasts.
+var InvestmentContributionCategories = map[string]bool{
+	"Investments": true,
+	"Savings":     true,
+}
+
+type ForecastItemKind string
+
+const (
+	// ForecastItemScheduled items are transactions already booked with a date in the future.
+	ForecastItemScheduled  ForecastItemKind = "scheduled"
+	ForecastItemRecurring  ForecastItemKind = "recurring"
+	ForecastItemInvestment ForecastItemKind = "investment"
+)
+
+// ForecastRequest is the length of the forecast and of the history the average variable spend is computed from.
+type ForecastRequest struct {
+	Months        int
+	HistoryMonths int
+}
+
+// CashFlowForecast projects the daily balances of the payment sources of the user from StartDate to EndDate.
+type CashFlowForecast struct {
+	StartDate      time.Time               `json:"start_date"`
+	EndDate        time.Time               `json:"end_date"`
+	HistoryMonths  int                     `json:"history_months"`
+	VariableSpend  []CategorySpendAverage  `json:"variable_spend"`
+	PaymentSources []PaymentSourceForecast `json:"payment_sources"`
+}
+
+// CategorySpendAverage is the average monthly spend in a category, without the recurring items booked on it.
+type CategorySpendAverage struct {
+	CategoryID     int           `json:"category_id"`
+	CategoryName   string        `json:"category_name"`
+	MonthlyAverage money.Decimal `json:"monthly_average"`
+}
+
+// PaymentSourceForecast is the projection of a single payment source. NegativeDates are the days on which its balance
+// goes below zero, LowestBalance is the lowest projected balance and LowestBalanceDate the first day it's reached.
+type PaymentSourceForecast struct {
+	PaymentSourceID    int           `json:"payment_source_id"`
+	Name               string        `json:"name"`
+	StartingBalance    money.Decimal `json:"starting_balance"`
+	EndingBalance      money.Decimal `json:"ending_balance"`
+	LowestBalance      money.Decimal `json:"lowest_balance"`
+	LowestBalanceDate  time.Time     `json:"lowest_balance_date"`
+	DailyVariableSpend money.Decimal `json:"daily_variable_spend"`
+	NegativeDates      []time.Time   `json:"negative_dates"`
+	Days               []ForecastDay `json:"days"`
+}
+
+// ForecastDay is the projected balance of a payment source at the end of the day.
+type ForecastDay struct {
+	Date          time.Time      `json:"date"`
+	VariableSpend money.Decimal  `json:"variable_spend"`
+	Items         []ForecastItem `json:"items,omitempty"`
+	Balance       money.Decimal  `json:"balance"`
+	Negative      bool           `json:"negative"`
+}
+
+// ForecastItem is a known future change of a balance, Amount is negative for money leaving the payment source.
+type ForecastItem struct {
+	Kind            ForecastItemKind `json:"kind"`
+	Date            time.Time        `json:"-"`
+	Name            string           `json:"name"`
+	Amount          money.Decimal    `json:"amount"`
+	RecurringRuleID string           `json:"recurring_rule_id,omitempty"`
+	TransactionID   string           `json:"transaction_id,omitempty"`
+}
+
+// Validate checks the request and fills in the default lengths.
+func (r *ForecastRequest) Validate() error {
+	if r.Months == 0 {
+		r.Months = DefaultForecastMonths
+	}
+	if r.Months < 0 || r.Months > MaxForecastMonths {
+		return errors.NewValidationError(fmt.Sprintf("Months must be between 1 and %d", MaxForecastMonths))
+	}
+	if r.HistoryMonths == 0 {
+		r.HistoryMonths = DefaultForecastHistoryMonths
+	}
+	if r.HistoryMonths < 0 || r.HistoryMonths > MaxForecastHistoryMonths {
+		return errors.NewValidationError(fmt.Sprintf("History months must be between 1 and %d", MaxForecastHistoryMonths))
+	}
+	return nil
+}
+
+// ProjectPaymentSource applies the items and the daily variable spend to the starting balance of the source, day by
+// day from start to end inclusive. Items outside of the range are ignored.
+func ProjectPaymentSource(source PaymentSource, startingBalance, dailyVariableSpend money.Decimal, items []ForecastItem, start, end time.Time) PaymentSourceForecast {
+	start, end = TruncateToDate(start), TruncateToDate(end)
+	itemsByDate := map[time.Time][]ForecastItem{}
+	for _, item := range items {
+		date := TruncateToDate(item.Date)
+		itemsByDate[date] = append(itemsByDate[date], item)
+	}
+
+	forecast := PaymentSourceForecast{
+		PaymentSourceID:    source.ID,
+		Name:               source.Name,
+		StartingBalance:    startingBalance,
+		LowestBalance:      startingBalance,
+		LowestBalanceDate:  start,
+		DailyVariableSpend: dailyVariableSpend,
+		NegativeDates:      []time.Time{},
+		Days:               []ForecastDay{},
+	}
+	balance := startingBalance
+	negative := startingBalance.IsNegative()
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day := ForecastDay{Date: date, VariableSpend: dailyVariableSpend, Items: itemsByDate[date]}
+		for _, item := range day.Items {
+			balance = balance.Add(item.Amount)
+		}
+		balance = balance.Sub(dailyVariableSpend)
+		day.Balance = balance
+		day.Negative = balance.IsNegative()
+
+		// only the day the balance goes below zero is flagged, not every day it stays there
+		if day.Negative && !negative {
+			forecast.NegativeDates = append(forecast.NegativeDates, date)
+		}
+		negative = day.Negative
+		if balance.LessThan(forecast.LowestBalance) {
+			forecast.LowestBalance = balance
+			forecast.LowestBalanceDate = date
+		}
+		forecast.Days = append(forecast.Days, day)
+	}
+	forecast.EndingBalance = balance
+	return forecast
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestProjectPaymentSource_FlagsDaysGoingNegative(t *testing.T) {
+	source := PaymentSource{ID: 1, Name: "Checking"}
+	items := []ForecastItem{
+		{Kind: ForecastItemRecurring, Date: date(2024, time.March, 3), Name: "Insurance", Amount: money.MustParse("-150")},
+		{Kind: ForecastItemRecurring, Date: date(2024, time.March, 4), Name: "Salary", Amount: money.MustParse("500")},
+		{Kind: ForecastItemScheduled, Date: date(2024, time.March, 6), Name: "Car service", Amount: money.MustParse("-600")},
+		{Kind: ForecastItemScheduled, Date: date(2024, time.April, 1), Name: "Out of range", Amount: money.MustParse("-1000")},
+	}
+
+	forecast := ProjectPaymentSource(source, money.MustParse("100"), money.MustParse("10"), items, date(2024, time.March, 1), date(2024, time.March, 7))
+
+	var balances []string
+	for _, day := range forecast.Days {
+		balances = append(balances, day.Balance.String())
+	}
+	assert.Equal(t, []string{"90.00", "80.00", "-80.00", "410.00", "400.00", "-210.00", "-220.00"}, balances)
+	assert.Equal(t, []time.Time{date(2024, time.March, 3), date(2024, time.March, 6)}, forecast.NegativeDates)
+	assert.Equal(t, money.MustParse("-220"), forecast.EndingBalance)
+	assert.Equal(t, money.MustParse("-220"), forecast.LowestBalance)
+	assert.Equal(t, date(2024, time.March, 7), forecast.LowestBalanceDate)
+	assert.Len(t, forecast.Days[2].Items, 1)
+}
+
+func TestForecastRequest_Validate(t *testing.T) {
+	request := ForecastRequest{}
+	assert.NoError(t, request.Validate())
+	assert.Equal(t, ForecastRequest{Months: DefaultForecastMonths, HistoryMonths: DefaultForecastHistoryMonths}, request)
+
+	request = ForecastRequest{Months: MaxForecastMonths + 1}
+	assert.EqualError(t, request.Validate(), "Months must be between 1 and 24")
+}
@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type SignConvention string
+
+const (
+	// SignNegativeExpense is used by most bank accounts: money going out is negative.
+	SignNegativeExpense SignConvention = "negative_expense"
+	// SignNegativeIncome is used by some credit card statements: payments and refunds are negative.
+	SignNegativeIncome SignConvention = "negative_income"
+	// SignDebitCreditColumns reads expenses from the debit column and income from the credit column.
+	SignDebitCreditColumns SignConvention = "debit_credit_columns"
+)
+
+type StatementFormat string
+
+const (
+	StatementFormatMT940   StatementFormat = "mt940"
+	StatementFormatCAMT053 StatementFormat = "camt053"
+	StatementFormatOFX     StatementFormat = "ofx"
+	StatementFormatQFX     StatementFormat = "qfx" // Quicken flavour of OFX
+	StatementFormatQIF     StatementFormat = "qif"
+	StatementFormatCSV     StatementFormat = "csv"
+	StatementFormatXLSX    StatementFormat = "xlsx"
+)
+
+type ImportProfileRepository interface {
+	FindByUser(userID string) ([]ImportProfile, error)
+	FindByID(profileID int, userID string) (*ImportProfile, error)
+	DoesNameExist(name string, userID string, excludedID int) (bool, error)
+	Create(profile *ImportProfile) error
+	Update(profile ImportProfile) (int64, error)
+	Delete(profileID int, userID string) (int64, error)
+}
+
+// ImportColumns holds 0 based indexes of the CSV columns mapped to transaction fields.
+type ImportColumns struct {
+	Date        int  `json:"date"`
+	Name        int  `json:"name"` // payee or counterparty
+	Amount      *int `json:"amount"`
+	Debit       *int `json:"debit"`
+	Credit      *int `json:"credit"`
+	Description *int `json:"description"`
+}
+
+// ImportDefaults are the categories and payment method given to imported transactions, as bank statements don't carry them.
+type ImportDefaults struct {
+	UserID            string `json:"-"` // user UUID
+	ExpenseCategoryID int    `json:"expense_category_id"`
+	IncomeCategoryID  int    `json:"income_category_id"`
+	PaymentMethodID   int    `json:"payment_method_id"`
+	PaymentSourceID   *int   `json:"payment_source_id"`
+}
+
+// ImportProfile describes how the CSV export of a given bank maps to transactions, so the statements of the same bank
+// can be imported without repeating the mapping.
+type ImportProfile struct {
+	ID               int            `json:"id"`
+	Name             string         `json:"name"`
+	Delimiter        string         `json:"delimiter"`
+	DecimalSeparator string         `json:"decimal_separator"`
+	DateFormat       string         `json:"date_format"` // e.g. DD.MM.YYYY or a Go layout
+	HasHeader        bool           `json:"has_header"`
+	SkipRows         int            `json:"skip_rows"`
+	Columns          ImportColumns  `json:"columns"`
+	SignConvention   SignConvention `json:"sign_convention"`
+	ImportDefaults
+}
+
+// ImportedTransaction is a single statement row converted to a transaction, with the reasons it can't be imported if any.
+type ImportedTransaction struct {
+	Row         int                 `json:"row"` // line of the file, or the number of the entry in XML statements
+	Transaction PersonalTransaction `json:"transaction"`
+	Errors      []string            `json:"errors,omitempty"`
+	// AppliedRuleID is the categorization rule which set the category of the transaction, if any
+	AppliedRuleID *int `json:"applied_rule_id,omitempty"`
+}
+
+type ImportPreview struct {
+	Rows         []ImportedTransaction `json:"rows"`
+	ValidCount   int                   `json:"valid_count"`
+	InvalidCount int                   `json:"invalid_count"`
+}
+
+func NewImportPreview(rows []ImportedTransaction) *ImportPreview {
+	preview := &ImportPreview{Rows: rows}
+	if preview.Rows == nil {
+		preview.Rows = []ImportedTransaction{}
+	}
+	for _, row := range preview.Rows {
+		if len(row.Errors) == 0 {
+			preview.ValidCount++
+		} else {
+			preview.InvalidCount++
+		}
+	}
+	return preview
+}
+
+// ValidTransactions returns the transactions of all rows without errors.
+func (p *ImportPreview) ValidTransactions() []*PersonalTransaction {
+	transactions := make([]*PersonalTransaction, 0, p.ValidCount)
+	for i := range p.Rows {
+		if len(p.Rows[i].Errors) == 0 {
+			transactions = append(transactions, &p.Rows[i].Transaction)
+		}
+	}
+	return transactions
+}
+
+func (p *ImportProfile) Validate() error {
+	if len(p.Name) <= 0 || len(p.Name) > 50 {
+		return errors.NewValidationError("Name should be between 0 and 50")
+	}
+
+	if utf8.RuneCountInString(p.Delimiter) != 1 || strings.ContainsAny(p.Delimiter, "\"\r\n") {
+		return errors.NewValidationError("Delimiter must be a single character, e.g. ',' ';' or a tab")
+	}
+
+	if p.DecimalSeparator != "." && p.DecimalSeparator != "," {
+		return errors.NewValidationError("DecimalSeparator must be either '.' or ','")
+	}
+
+	if p.DecimalSeparator == p.Delimiter {
+		return errors.NewValidationError("DecimalSeparator must differ from Delimiter")
+	}
+
+	if len(p.DateFormat) <= 0 || len(p.DateFormat) > 30 {
+		return errors.NewValidationError("DateFormat should be between 0 and 30")
+	}
+
+	if p.SkipRows < 0 {
+		return errors.NewValidationError("SkipRows must not be negative")
+	}
+
+	if p.Columns.Date < 0 || p.Columns.Name < 0 || (p.Columns.Description != nil && *p.Columns.Description < 0) {
+		return errors.NewValidationError("Column indexes must not be negative")
+	}
+
+	switch p.SignConvention {
+	case SignNegativeExpense, SignNegativeIncome:
+		if p.Columns.Amount == nil || *p.Columns.Amount < 0 {
+			return errors.NewValidationError("Amount column is required for this sign convention")
+		}
+	case SignDebitCreditColumns:
+		if p.Columns.Debit == nil || *p.Columns.Debit < 0 || p.Columns.Credit == nil || *p.Columns.Credit < 0 {
+			return errors.NewValidationError("Debit and credit columns are required for this sign convention")
+		}
+	default:
+		return errors.NewValidationError("SignConvention must be one of 'negative_expense', 'negative_income' or 'debit_credit_columns'")
+	}
+
+	return p.ImportDefaults.Validate()
+}
+
+func (d *ImportDefaults) Validate() error {
+	if d.ExpenseCategoryID <= 0 || d.IncomeCategoryID <= 0 {
+		return errors.NewValidationError("ExpenseCategoryID and IncomeCategoryID must be provided and must be greater than zero")
+	}
+
+	if d.PaymentMethodID <= 0 {
+		return errors.NewValidationError("PaymentMethodID must be provided and must be greater than zero")
+	}
+
+	if d.PaymentSourceID != nil && *d.PaymentSourceID <= 0 {
+		return errors.NewValidationError("PaymentSourceID, if provided, must be greater than zero")
+	}
+
+	return nil
+}
+
+// NewTransaction builds an imported transaction with the default category of its type. Name falls back to the
+// description when the statement has no payee, both are shortened to the limits of PersonalTransaction.
+func (d *ImportDefaults) NewTransaction(date time.Time, amount money.Decimal, transactionType TransactionType, name, description string) PersonalTransaction {
+	transaction := PersonalTransaction{
+		Name:                 TruncateText(name, 50),
+		UserID:               d.UserID,
+		Amount:               amount,
+		Type:                 string(transactionType),
+		Date:                 date,
+		PredefinedCategoryID: d.ExpenseCategoryID,
+		PaymentMethodID:      d.PaymentMethodID,
+		PaymentSourceID:      d.PaymentSourceID,
+	}
+	if transactionType == TransactionTypeIncome {
+		transaction.PredefinedCategoryID = d.IncomeCategoryID
+	}
+	if transaction.Name == "" {
+		transaction.Name = TruncateText(description, 50)
+	}
+	if description = TruncateText(description, 200); description != "" {
+		transaction.Description = &description
+	}
+	transaction.RoundToMinorUnits()
+	return transaction
+}
+
+// NewImportedTransaction validates the transaction unless the statement row already failed to parse.
+func NewImportedTransaction(row int, transaction PersonalTransaction, parseErrors []string) ImportedTransaction {
+	imported := ImportedTransaction{Row: row, Transaction: transaction, Errors: parseErrors}
+	if len(imported.Errors) == 0 {
+		if err := transaction.Validate(); err != nil {
+			imported.Errors = append(imported.Errors, err.Error())
+		}
+	}
+	return imported
+}
+
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+
+// DateLayout converts DateFormat written with YYYY, YY, MM and DD tokens into a Go time layout.
+func (p *ImportProfile) DateLayout() string {
+	return dateFormatTokens.Replace(p.DateFormat)
+}
+
+func (p *ImportProfile) ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(p.DateLayout(), strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, errors.NewValidationError("Invalid date '" + value + "', expected format " + p.DateFormat)
+	}
+	return date, nil
+}
+
+// ParseAmount reads a signed amount written with the decimal separator of the profile. Thousands separators,
+// spaces and currency symbols are ignored.
+func (p *ImportProfile) ParseAmount(value string) (money.Decimal, error) {
+	thousandsSeparator := ","
+	if p.DecimalSeparator == "," {
+		thousandsSeparator = "."
+	}
+
+	var normalized strings.Builder
+	for _, r := range strings.ReplaceAll(value, thousandsSeparator, "") {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '+':
+			normalized.WriteRune(r)
+		case string(r) == p.DecimalSeparator:
+			normalized.WriteRune('.')
+		}
+	}
+
+	amount, err := money.Parse(normalized.String())
+	if err != nil {
+		return money.Zero, errors.NewValidationError("Invalid amount '" + value + "'")
+	}
+	return amount, nil
+}
+
+// TruncateText shortens the text to at most maxLength bytes without splitting a multibyte character.
+func TruncateText(text string, maxLength int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= maxLength {
+		return text
+	}
+	for maxLength > 0 && !utf8.RuneStart(text[maxLength]) {
+		maxLength--
+	}
+	return strings.TrimSpace(text[:maxLength])
+}
@@ -0,0 +1,429 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"math"
+	"sort"
+	"time"
+)
+
+// ObligationsCategoryName is the predefined expense category installments are booked on unless the loan names another.
+const ObligationsCategoryName = "Obligations"
+
+const (
+	MaxLoanTermMonths   = 600
+	MaxLoanRateChanges  = 200
+	MaxLoanOverpayments = 600
+	// LoanMatchDays is how far from the due date the payment of an installment may be booked.
+	LoanMatchDays = 7
+)
+
+// loanMatchTolerance is the relative difference between an installment and its payment, banks round variable rate
+// installments differently.
+var loanMatchTolerance = money.MustParse("0.01")
+
+type LoanInstallmentMethod string
+
+const (
+	// LoanInstallmentsEqual loans are paid off with equal installments (an annuity), recalculated when the rate changes.
+	LoanInstallmentsEqual LoanInstallmentMethod = "equal"
+	// LoanInstallmentsDecreasing loans pay the same part of the principal every month and the interest on top of it.
+	LoanInstallmentsDecreasing LoanInstallmentMethod = "decreasing"
+)
+
+type LoanRateType string
+
+const (
+	LoanRateFixed    LoanRateType = "fixed"
+	LoanRateVariable LoanRateType = "variable"
+)
+
+type LoanRepository interface {
+	FindByUser(userID string) ([]Loan, error)
+	FindByID(loanID int, userID string) (*Loan, error)
+	Create(loan *Loan) error
+	// Update replaces the loan together with its rate changes.
+	Update(loan Loan) (int64, error)
+	Delete(loanID int, userID string) (int64, error)
+}
+
+// Loan is a debt of the user paid off in monthly installments, the first one due on FirstInstallmentDate. The
+// installments are expenses in PredefinedCategoryID, paid from PaymentSourceID when it's set.
+type Loan struct {
+	ID                   int                   `json:"id"`
+	UserID               string                `json:"-"` // user UUID
+	Name                 string                `json:"name"`
+	Principal            money.Decimal         `json:"principal"`
+	AnnualRate           money.Decimal         `json:"annual_rate"` // in percent, e.g. 7.5
+	RateType             LoanRateType          `json:"rate_type"`
+	TermMonths           int                   `json:"term_months"`
+	Method               LoanInstallmentMethod `json:"method"`
+	FirstInstallmentDate time.Time             `json:"first_installment_date"`
+	PredefinedCategoryID int                   `json:"predefined_category_id"`
+	PaymentSourceID      *int                  `json:"payment_source_id"`
+	// Currency is the ISO 4217 code of the principal and the installments, PLN unless the user picked another one.
+	Currency string `json:"currency"`
+	// RateChanges of variable rate loans replace AnnualRate from their effective date on, replaced as a whole on update.
+	RateChanges []LoanRateChange `json:"rate_changes"`
+}
+
+type LoanRateChange struct {
+	EffectiveDate time.Time     `json:"effective_date"`
+	AnnualRate    money.Decimal `json:"annual_rate"`
+}
+
+// LoanInstallment is a single row of an amortization schedule. Payment is the regular installment, the principal and
+// interest part of it, Overpayment is paid on top and RemainingPrincipal is what's left after both.
+type LoanInstallment struct {
+	Number             int           `json:"number"`
+	DueDate            time.Time     `json:"due_date"`
+	AnnualRate         money.Decimal `json:"annual_rate"`
+	Payment            money.Decimal `json:"payment"`
+	Principal          money.Decimal `json:"principal"`
+	Interest           money.Decimal `json:"interest"`
+	Overpayment        money.Decimal `json:"overpayment"`
+	RemainingPrincipal money.Decimal `json:"remaining_principal"`
+	// TransactionID is the expense the installment was paid with, if one was found.
+	TransactionID *string `json:"transaction_id"`
+}
+
+type LoanSchedule struct {
+	Installments     []LoanInstallment `json:"installments,omitempty"`
+	InstallmentCount int               `json:"installment_count"`
+	TotalInterest    money.Decimal     `json:"total_interest"`
+	TotalOverpayment money.Decimal     `json:"total_overpayment"`
+	PayoffDate       time.Time         `json:"payoff_date"`
+}
+
+type LoanOverpayment struct {
+	Date   time.Time     `json:"date"`
+	Amount money.Decimal `json:"amount"`
+}
+
+// LoanOverpaymentPlan is paid on top of the regular installments: each of Overpayments with the installment due on or
+// after its date, MonthlyAmount with every installment due after Since. Overpayments shorten the loan, the
+// installments stay the same.
+type LoanOverpaymentPlan struct {
+	Overpayments  []LoanOverpayment `json:"overpayments"`
+	MonthlyAmount money.Decimal     `json:"monthly_amount"`
+	Since         time.Time         `json:"-"`
+}
+
+// LoanSimulation compares the schedule with the overpayment plan against the one without it.
+type LoanSimulation struct {
+	Baseline      LoanSchedule  `json:"baseline"`
+	Simulated     LoanSchedule  `json:"simulated"`
+	InterestSaved money.Decimal `json:"interest_saved"`
+	// MonthsSaved is how many installments fewer the loan takes.
+	MonthsSaved int `json:"months_saved"`
+}
+
+func (l *Loan) Validate() error {
+	if len(l.Name) == 0 || len(l.Name) > 100 {
+		return errors.NewValidationError("Name must be between 1 and 100 characters long")
+	}
+
+	if !l.Principal.IsPositive() {
+		return errors.NewValidationError("Principal must be greater than zero")
+	}
+	if !l.Principal.FitsNumeric(15, 2) {
+		return errors.NewValidationError("Principal must be less than 10000000000000")
+	}
+
+	if !currencyCodePattern.MatchString(l.Currency) {
+		return errors.NewValidationError("Currency must be a three letter ISO 4217 code like PLN")
+	}
+
+	if err := validateLoanRate(l.AnnualRate); err != nil {
+		return err
+	}
+
+	if l.TermMonths <= 0 || l.TermMonths > MaxLoanTermMonths {
+		return errors.NewValidationError("Term must be between 1 and 600 months")
+	}
+
+	switch l.Method {
+	case LoanInstallmentsEqual:
+	case LoanInstallmentsDecreasing:
+		if !l.decreasingPrincipal().IsPositive() {
+			return errors.NewValidationError("Principal is too small to be repaid in decreasing installments over the term")
+		}
+	default:
+		return errors.NewValidationError("Method must be either 'equal' or 'decreasing'")
+	}
+
+	if l.FirstInstallmentDate.IsZero() {
+		return errors.NewValidationError("First installment date is required")
+	}
+
+	switch l.RateType {
+	case LoanRateFixed:
+		if len(l.RateChanges) > 0 {
+			return errors.NewValidationError("Fixed rate loans can't have rate changes")
+		}
+	case LoanRateVariable:
+	default:
+		return errors.NewValidationError("Rate type must be either 'fixed' or 'variable'")
+	}
+
+	if len(l.RateChanges) > MaxLoanRateChanges {
+		return errors.NewValidationError("Too many rate changes")
+	}
+	dates := make(map[time.Time]bool, len(l.RateChanges))
+	for _, change := range l.RateChanges {
+		if change.EffectiveDate.IsZero() {
+			return errors.NewValidationError("Effective date of a rate change is required")
+		}
+		date := TruncateToDate(change.EffectiveDate)
+		if dates[date] {
+			return errors.NewValidationError("Only one rate change per day is allowed")
+		}
+		dates[date] = true
+		if err := validateLoanRate(change.AnnualRate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *LoanOverpaymentPlan) Validate() error {
+	if len(p.Overpayments) > MaxLoanOverpayments {
+		return errors.NewValidationError("Too many overpayments")
+	}
+	if p.MonthlyAmount.IsNegative() {
+		return errors.NewValidationError("Monthly overpayment must not be negative")
+	}
+	p.MonthlyAmount = p.MonthlyAmount.RoundToCurrency(money.DefaultCurrency)
+	for i := range p.Overpayments {
+		if p.Overpayments[i].Date.IsZero() {
+			return errors.NewValidationError("Date of an overpayment is required")
+		}
+		if !p.Overpayments[i].Amount.IsPositive() {
+			return errors.NewValidationError("Overpayment amount must be greater than zero")
+		}
+		p.Overpayments[i].Amount = p.Overpayments[i].Amount.RoundToCurrency(money.DefaultCurrency)
+	}
+	if len(p.Overpayments) == 0 && p.MonthlyAmount.IsZero() {
+		return errors.NewValidationError("Either overpayments or a monthly overpayment is required")
+	}
+	return nil
+}
+
+func validateLoanRate(rate money.Decimal) error {
+	if rate.IsNegative() || rate.GreaterThan(money.FromInt(100)) {
+		return errors.NewValidationError("Annual rate must be between 0 and 100 percent")
+	}
+	return nil
+}
+
+// Normalize rounds the amounts and sorts the rate changes by their effective date.
+func (l *Loan) Normalize() {
+	l.Currency = NormalizeCurrency(l.Currency)
+	l.Principal = l.Principal.RoundToCurrency(l.Currency)
+	l.FirstInstallmentDate = TruncateToDate(l.FirstInstallmentDate)
+	for i := range l.RateChanges {
+		l.RateChanges[i].EffectiveDate = TruncateToDate(l.RateChanges[i].EffectiveDate)
+	}
+	sort.Slice(l.RateChanges, func(i, j int) bool {
+		return l.RateChanges[i].EffectiveDate.Before(l.RateChanges[j].EffectiveDate)
+	})
+}
+
+// RateOn returns the annual rate in effect on the date, the rate changes must be sorted.
+func (l *Loan) RateOn(date time.Time) money.Decimal {
+	rate := l.AnnualRate
+	for _, change := range l.RateChanges {
+		if change.EffectiveDate.After(date) {
+			break
+		}
+		rate = change.AnnualRate
+	}
+	return rate
+}
+
+// Schedule computes the amortization schedule with the overpayment plan. Interest is charged monthly on the remaining
+// principal, at the rate in effect at the start of each period. Equal installments are recalculated over the remaining
+// installments whenever the rate changes. Overpayments keep the installment and shorten the loan instead.
+func (l *Loan) Schedule(plan LoanOverpaymentPlan) LoanSchedule {
+	schedule := LoanSchedule{Installments: []LoanInstallment{}, TotalInterest: money.Zero, TotalOverpayment: money.Zero}
+	balance := l.Principal
+	remaining := l.TermMonths
+	decreasingPrincipal := l.decreasingPrincipal()
+
+	var payment, rate money.Decimal
+	periodStart := addMonthsClamped(l.FirstInstallmentDate, -1)
+	for n := 0; balance.IsPositive() && n < MaxLoanTermMonths; n++ {
+		due := addMonthsClamped(l.FirstInstallmentDate, n)
+		periodRate := l.RateOn(periodStart)
+		if n == 0 || !periodRate.Equal(rate) {
+			rate = periodRate
+			payment = annuity(balance, rate, remaining, l.Currency)
+		}
+
+		interest := balance.Mul(rate).Div(money.FromInt(1200)).RoundToCurrency(l.Currency)
+		principal := decreasingPrincipal
+		if l.Method == LoanInstallmentsEqual {
+			principal = payment.Sub(interest)
+			if principal.IsNegative() {
+				principal = money.Zero
+			}
+		}
+		if remaining <= 1 || principal.GreaterThan(balance) {
+			principal = balance
+		}
+		balance = balance.Sub(principal)
+
+		overpayment := money.Zero
+		for _, planned := range plan.Overpayments {
+			if date := TruncateToDate(planned.Date); date.After(periodStart) && !date.After(due) {
+				overpayment = overpayment.Add(planned.Amount)
+			}
+		}
+		if due.After(TruncateToDate(plan.Since)) {
+			overpayment = overpayment.Add(plan.MonthlyAmount)
+		}
+		if overpayment.GreaterThan(balance) {
+			overpayment = balance
+		}
+		balance = balance.Sub(overpayment)
+
+		schedule.Installments = append(schedule.Installments, LoanInstallment{
+			Number:             n + 1,
+			DueDate:            due,
+			AnnualRate:         rate,
+			Payment:            principal.Add(interest),
+			Principal:          principal,
+			Interest:           interest,
+			Overpayment:        overpayment,
+			RemainingPrincipal: balance,
+		})
+		schedule.TotalInterest = schedule.TotalInterest.Add(interest)
+		schedule.TotalOverpayment = schedule.TotalOverpayment.Add(overpayment)
+		schedule.PayoffDate = due
+
+		remaining--
+		if overpayment.IsPositive() && balance.IsPositive() {
+			if l.Method == LoanInstallmentsEqual {
+				remaining = installmentsLeft(balance, rate, payment, remaining)
+			} else if installments, err := balance.CheckedDiv(decreasingPrincipal); err == nil {
+				remaining = int(math.Ceil(installments.Float64()))
+			}
+		}
+		periodStart = due
+	}
+	schedule.InstallmentCount = len(schedule.Installments)
+	return schedule
+}
+
+// decreasingPrincipal is the principal part of each decreasing installment, the last one repays the rest.
+func (l *Loan) decreasingPrincipal() money.Decimal {
+	if l.TermMonths <= 0 {
+		return money.Zero
+	}
+	return l.Principal.Div(money.FromInt(int64(l.TermMonths))).RoundToCurrency(l.Currency)
+}
+
+// OutstandingPrincipal is the principal left after the installments due on or before the date, as scheduled.
+func (l *Loan) OutstandingPrincipal(date time.Time) money.Decimal {
+	outstanding := l.Principal
+	for _, installment := range l.Schedule(LoanOverpaymentPlan{}).Installments {
+		if installment.DueDate.After(date) {
+			break
+		}
+		outstanding = installment.RemainingPrincipal
+	}
+	return outstanding
+}
+
+// annuity is the equal monthly installment paying off the balance in the given number of installments.
+func annuity(balance, annualRate money.Decimal, installments int, currency string) money.Decimal {
+	if installments <= 0 {
+		return balance
+	}
+	rate := annualRate.Float64() / 1200
+	if rate == 0 {
+		return balance.Div(money.FromInt(int64(installments))).RoundToCurrency(currency)
+	}
+	payment := balance.Float64() * rate / (1 - math.Pow(1+rate, -float64(installments)))
+	return money.FromFloat(payment).RoundToCurrency(currency)
+}
+
+// installmentsLeft is the number of installments paying off the balance, at most the current number.
+func installmentsLeft(balance, annualRate, payment money.Decimal, current int) int {
+	rate := annualRate.Float64() / 1200
+	var count float64
+	if rate == 0 {
+		count = balance.Div(payment).Float64()
+	} else {
+		ratio := 1 - rate*balance.Float64()/payment.Float64()
+		if ratio <= 0 {
+			return current
+		}
+		count = -math.Log(ratio) / math.Log(1+rate)
+	}
+	// rounding the installments to cents leaves a few cents over, which mustn't add a whole installment
+	left := int(math.Ceil(count - 1e-6))
+	if left > current {
+		return current
+	}
+	return max(left, 1)
+}
+
+// Simulate compares the schedule with the overpayment plan against the one without it.
+func (l *Loan) Simulate(plan LoanOverpaymentPlan) LoanSimulation {
+	baseline := l.Schedule(LoanOverpaymentPlan{})
+	baseline.Installments = nil
+	simulated := l.Schedule(plan)
+	return LoanSimulation{
+		Baseline:      baseline,
+		Simulated:     simulated,
+		InterestSaved: baseline.TotalInterest.Sub(simulated.TotalInterest),
+		MonthsSaved:   baseline.InstallmentCount - simulated.InstallmentCount,
+	}
+}
+
+// MatchInstallments finds the expense paying each installment: in the category of the loan, from its payment source
+// if it has one, booked at most LoanMatchDays from the due date, for the installment within loanMatchTolerance. Only
+// the split lines in the category of the loan count towards the amount. Each transaction pays a single installment,
+// the one booked closest to the due date is picked.
+func (l *Loan) MatchInstallments(installments []LoanInstallment, transactions []PersonalTransaction) {
+	used := make([]bool, len(transactions))
+	for i := range installments {
+		installment := &installments[i]
+		tolerance := installment.Payment.Mul(loanMatchTolerance)
+		best, bestDays := -1, 0.0
+		for j := range transactions {
+			transaction := &transactions[j]
+			if used[j] || transaction.Type != string(TransactionTypeExpense) {
+				continue
+			}
+			if l.PaymentSourceID != nil && !sameID(transaction.PaymentSourceID, l.PaymentSourceID) {
+				continue
+			}
+			amount := l.amountInCategory(transaction)
+			if amount.IsZero() || amount.Sub(installment.Payment).Abs().GreaterThan(tolerance) {
+				continue
+			}
+			days := math.Abs(transaction.Date.Sub(installment.DueDate).Hours() / 24)
+			if days <= LoanMatchDays && (best < 0 || days < bestDays) {
+				best, bestDays = j, days
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			installment.TransactionID = &transactions[best].ID
+		}
+	}
+}
+
+func (l *Loan) amountInCategory(transaction *PersonalTransaction) money.Decimal {
+	amount := money.Zero
+	for _, line := range transaction.Lines() {
+		if line.PredefinedCategoryID == l.PredefinedCategoryID {
+			amount = amount.Add(line.Amount)
+		}
+	}
+	return amount
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLoanSchedule(t *testing.T) {
+	loan := Loan{Principal: money.MustParse("12000"), AnnualRate: money.MustParse("12"), RateType: LoanRateFixed, TermMonths: 12,
+		Method: LoanInstallmentsEqual, FirstInstallmentDate: date(2026, time.January, 31)}
+
+	equal := loan.Schedule(LoanOverpaymentPlan{})
+	assert.Equal(t, 12, equal.InstallmentCount)
+	assert.Equal(t, money.MustParse("1066.19"), equal.Installments[0].Payment)
+	assert.Equal(t, money.MustParse("120"), equal.Installments[0].Interest)
+	// the last installment pays off what the rounding left over
+	assert.Equal(t, money.MustParse("1066.14"), equal.Installments[11].Payment)
+	assert.True(t, equal.Installments[11].RemainingPrincipal.IsZero())
+	assert.Equal(t, money.MustParse("794.23"), equal.TotalInterest)
+	// installments due at the end of the month stay at its end
+	assert.Equal(t, date(2026, time.February, 28), equal.Installments[1].DueDate)
+	assert.Equal(t, date(2026, time.December, 31), equal.PayoffDate)
+
+	loan.Method = LoanInstallmentsDecreasing
+	decreasing := loan.Schedule(LoanOverpaymentPlan{})
+	assert.Equal(t, money.MustParse("1120"), decreasing.Installments[0].Payment)
+	assert.Equal(t, money.MustParse("1010"), decreasing.Installments[11].Payment)
+	assert.Equal(t, money.MustParse("780"), decreasing.TotalInterest)
+}
+
+func TestLoanSchedule_VariableRate(t *testing.T) {
+	loan := Loan{Principal: money.MustParse("12000"), AnnualRate: money.MustParse("12"), RateType: LoanRateVariable, TermMonths: 12,
+		Method: LoanInstallmentsEqual, FirstInstallmentDate: date(2026, time.January, 31),
+		RateChanges: []LoanRateChange{{EffectiveDate: date(2026, time.May, 15), AnnualRate: money.MustParse("6")}}}
+
+	schedule := loan.Schedule(LoanOverpaymentPlan{})
+	// the installment due on the 31st of May still pays the interest of a period started at the old rate
+	assert.Equal(t, money.MustParse("12"), schedule.Installments[4].AnnualRate)
+	assert.Equal(t, money.MustParse("6"), schedule.Installments[5].AnnualRate)
+	// the installment is recalculated over the seven remaining months
+	assert.Equal(t, money.MustParse("1045.38"), schedule.Installments[5].Payment)
+	assert.Equal(t, 12, schedule.InstallmentCount)
+	assert.Equal(t, money.MustParse("648.63"), schedule.TotalInterest)
+}
+
+func TestLoanSimulate(t *testing.T) {
+	loan := Loan{Principal: money.MustParse("12000"), AnnualRate: money.MustParse("12"), RateType: LoanRateFixed, TermMonths: 12,
+		Method: LoanInstallmentsEqual, FirstInstallmentDate: date(2026, time.January, 31)}
+
+	simulation := loan.Simulate(LoanOverpaymentPlan{Overpayments: []LoanOverpayment{{Date: date(2026, time.March, 10), Amount: money.MustParse("3000")}}})
+	assert.Nil(t, simulation.Baseline.Installments)
+	// the overpayment is paid with the March installment and keeps the installment, shortening the loan instead
+	assert.Equal(t, money.MustParse("3000"), simulation.Simulated.Installments[2].Overpayment)
+	assert.Equal(t, money.MustParse("1066.19"), simulation.Simulated.Installments[3].Payment)
+	assert.Equal(t, 3, simulation.MonthsSaved)
+	assert.Equal(t, date(2026, time.September, 30), simulation.Simulated.PayoffDate)
+	assert.Equal(t, money.MustParse("247.49"), simulation.InterestSaved)
+
+	loan.Method = LoanInstallmentsDecreasing
+	monthly := loan.Simulate(LoanOverpaymentPlan{MonthlyAmount: money.MustParse("500")})
+	assert.Equal(t, 4, monthly.MonthsSaved)
+	assert.Equal(t, money.MustParse("240"), monthly.InterestSaved)
+	assert.Equal(t, money.MustParse("4000"), monthly.Simulated.TotalOverpayment)
+}
+
+func TestLoan_Validate(t *testing.T) {
+	loan := Loan{Name: "Mortgage", Principal: money.MustParse("300000"), AnnualRate: money.MustParse("7.5"), RateType: LoanRateFixed,
+		TermMonths: 360, Method: LoanInstallmentsEqual, FirstInstallmentDate: date(2026, time.January, 10), Currency: "PLN"}
+	assert.NoError(t, loan.Validate())
+
+	loan.Currency = "zloty"
+	assert.EqualError(t, loan.Validate(), "Currency must be a three letter ISO 4217 code like PLN")
+	loan.Currency = "PLN"
+
+	loan.RateChanges = []LoanRateChange{{EffectiveDate: date(2026, time.July, 1), AnnualRate: money.MustParse("6.9")}}
+	assert.EqualError(t, loan.Validate(), "Fixed rate loans can't have rate changes")
+	loan.RateType = LoanRateVariable
+	assert.NoError(t, loan.Validate())
+
+	loan.Principal = money.MustParse("10000000000000")
+	assert.EqualError(t, loan.Validate(), "Principal must be less than 10000000000000")
+	loan.Principal = money.MustParse("300000")
+
+	loan.TermMonths = 601
+	assert.EqualError(t, loan.Validate(), "Term must be between 1 and 600 months")
+	loan.TermMonths = 360
+
+	loan.Method = "balloon"
+	assert.EqualError(t, loan.Validate(), "Method must be either 'equal' or 'decreasing'")
+
+	// 1.00 over 600 months rounds to no principal per installment
+	loan.Method = LoanInstallmentsDecreasing
+	loan.Principal, loan.TermMonths = money.MustParse("1"), 600
+	assert.EqualError(t, loan.Validate(), "Principal is too small to be repaid in decreasing installments over the term")
+	loan.TermMonths = 100
+	assert.NoError(t, loan.Validate())
+}
+
+func TestLoanSchedule_DecreasingPrincipalRoundedToZero(t *testing.T) {
+	// saved before the principal was validated against the term
+	loan := Loan{Principal: money.MustParse("1"), AnnualRate: money.MustParse("5"), RateType: LoanRateFixed, TermMonths: 600,
+		Method: LoanInstallmentsDecreasing, FirstInstallmentDate: date(2026, time.January, 10), Currency: "PLN"}
+
+	schedule := loan.Schedule(LoanOverpaymentPlan{MonthlyAmount: money.MustParse("0.10")})
+	assert.Equal(t, 10, schedule.InstallmentCount)
+	assert.Equal(t, money.MustParse("1"), schedule.TotalOverpayment)
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"sort"
+	"time"
+)
+
+const (
+	// AssetClassAccounts holds the balances of the payment sources.
+	AssetClassAccounts = "Accounts"
+	// AssetClassLoans holds the outstanding principal of the loans, as a negative value.
+	AssetClassLoans = "Loans"
+	// DefaultNetWorthHistoryDays is the length of the net worth history returned when no start date is given.
+	DefaultNetWorthHistoryDays = 365
+	MaxNetWorthHistoryDays     = 3660
+)
+
+type NetWorthRepository interface {
+	// Save stores the snapshot of the user, replacing the one taken earlier the same day.
+	Save(userID string, snapshot NetWorthSnapshot) error
+	FindInRange(userID string, startDate, endDate time.Time) ([]NetWorthSnapshot, error)
+	// FindUsersDueForSnapshot returns the users with payment sources, portfolios or loans and no snapshot on the date.
+	FindUsersDueForSnapshot(date time.Time) ([]string, error)
+}
+
+// NetWorthHolding is a single asset or liability of the user in its own currency, liabilities are negative.
+type NetWorthHolding struct {
+	AssetClass string
+	Currency   string
+	Value      money.Decimal
+}
+
+// NetWorthSnapshot is the wealth of the user on a day, in BaseCurrency. Assets and Liabilities are both positive,
+// Breakdown holds the value of each asset class, liabilities as negative values.
+type NetWorthSnapshot struct {
+	Date         time.Time           `json:"date"`
+	BaseCurrency string              `json:"base_currency"`
+	Assets       money.Decimal       `json:"assets"`
+	Liabilities  money.Decimal       `json:"liabilities"`
+	NetWorth     money.Decimal       `json:"net_worth"`
+	Breakdown    []NetWorthComponent `json:"breakdown"`
+}
+
+type NetWorthComponent struct {
+	AssetClass string        `json:"asset_class"`
+	Value      money.Decimal `json:"value"`
+}
+
+// NewNetWorthSnapshot sums the holdings, already converted to the base currency, per asset class. Accounts with a
+// negative balance, like a credit card, count as a liability.
+func NewNetWorthSnapshot(date time.Time, baseCurrency string, holdings []NetWorthHolding) NetWorthSnapshot {
+	snapshot := NetWorthSnapshot{
+		Date: TruncateToDate(date), BaseCurrency: baseCurrency,
+		Assets: money.Zero, Liabilities: money.Zero, Breakdown: []NetWorthComponent{},
+	}
+	values := map[string]money.Decimal{}
+	for _, holding := range holdings {
+		value := holding.Value.RoundToCurrency(baseCurrency)
+		if value.IsNegative() {
+			snapshot.Liabilities = snapshot.Liabilities.Sub(value)
+		} else {
+			snapshot.Assets = snapshot.Assets.Add(value)
+		}
+		values[holding.AssetClass] = values[holding.AssetClass].Add(value)
+	}
+	snapshot.NetWorth = snapshot.Assets.Sub(snapshot.Liabilities)
+
+	for assetClass, value := range values {
+		snapshot.Breakdown = append(snapshot.Breakdown, NetWorthComponent{AssetClass: assetClass, Value: value})
+	}
+	// the largest asset classes first, liabilities last
+	sort.Slice(snapshot.Breakdown, func(i, j int) bool {
+		if cmp := snapshot.Breakdown[i].Value.Cmp(snapshot.Breakdown[j].Value); cmp != 0 {
+			return cmp > 0
+		}
+		return snapshot.Breakdown[i].AssetClass < snapshot.Breakdown[j].AssetClass
+	})
+	return snapshot
+}
+
+// NetWorthHistory is the daily net worth of the user. The snapshots keep the base currency they were taken in.
+type NetWorthHistory struct {
+	StartDate    time.Time          `json:"start_date"`
+	EndDate      time.Time          `json:"end_date"`
+	BaseCurrency string             `json:"base_currency"`
+	Snapshots    []NetWorthSnapshot `json:"snapshots"`
+}
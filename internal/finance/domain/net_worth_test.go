@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewNetWorthSnapshot(t *testing.T) {
+	snapshot := NewNetWorthSnapshot(time.Date(2026, time.May, 4, 23, 30, 0, 0, time.UTC), "PLN", []NetWorthHolding{
+		{AssetClass: AssetClassAccounts, Value: money.MustParse("5200")},
+		// an overdrawn credit card is a liability
+		{AssetClass: AssetClassAccounts, Value: money.MustParse("-700")},
+		{AssetClass: "ETF", Value: money.MustParse("12000.004")},
+		{AssetClass: "ETF", Value: money.MustParse("3000")},
+		{AssetClass: AssetClassLoans, Value: money.MustParse("-9000")},
+	})
+
+	assert.Equal(t, date(2026, time.May, 4), snapshot.Date)
+	assert.Equal(t, money.MustParse("20200"), snapshot.Assets)
+	assert.Equal(t, money.MustParse("9700"), snapshot.Liabilities)
+	assert.Equal(t, money.MustParse("10500"), snapshot.NetWorth)
+	assert.Equal(t, []NetWorthComponent{
+		{AssetClass: "ETF", Value: money.MustParse("15000")},
+		{AssetClass: AssetClassAccounts, Value: money.MustParse("4500")},
+		{AssetClass: AssetClassLoans, Value: money.MustParse("-9000")},
+	}, snapshot.Breakdown)
+}
+
+func TestLoan_OutstandingPrincipal(t *testing.T) {
+	loan := Loan{Principal: money.MustParse("12000"), AnnualRate: money.MustParse("12"), TermMonths: 12,
+		Method: LoanInstallmentsDecreasing, FirstInstallmentDate: date(2026, time.January, 31)}
+
+	assert.Equal(t, money.MustParse("12000"), loan.OutstandingPrincipal(date(2026, time.January, 30)))
+	assert.Equal(t, money.MustParse("11000"), loan.OutstandingPrincipal(date(2026, time.January, 31)))
+	assert.Equal(t, money.MustParse("9000"), loan.OutstandingPrincipal(date(2026, time.April, 15)))
+	assert.True(t, loan.OutstandingPrincipal(date(2027, time.January, 1)).IsZero())
+}
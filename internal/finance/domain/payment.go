@@ -1,21 +1,151 @@
 package domain
 
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"strings"
+	"unicode"
+)
+
 type PaymentMethod struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
 
 type PaymentSource struct {
-	ID              int
-	UserID          string
-	PaymentMethodID int
-	Name            string
-	Details         map[string]string // e.g. account number
+	ID              int               `json:"id"`
+	UserID          string            `json:"-"`
+	PaymentMethodID int               `json:"payment_method_id"`
+	Name            string            `json:"name"`
+	Details         map[string]string `json:"details"` // e.g. account number
+	Archived        bool              `json:"archived"`
+	// Currency is the ISO 4217 code of the balances, PLN unless the user picked another one.
+	Currency       string        `json:"currency"`
+	OpeningBalance money.Decimal `json:"opening_balance"`
+	// Balance is the opening balance with all transactions of the source applied, it is computed and never stored.
+	Balance money.Decimal `json:"balance"`
+}
+
+// SensitivePaymentDetailKeys are Details keys which are encrypted at rest and masked in responses.
+var SensitivePaymentDetailKeys = map[string]bool{
+	"account_number": true,
+	"iban":           true,
+	"card_number":    true,
+}
+
+// EncryptedPaymentDetailPrefix marks a stored sensitive detail as encrypted, other details can't start with it.
+const EncryptedPaymentDetailPrefix = "enc:v1:"
+
+func IsSensitivePaymentDetail(key string) bool {
+	return SensitivePaymentDetailKeys[strings.ToLower(key)]
+}
+
+// MaskPaymentDetail hides everything but the last four characters of a sensitive value.
+func MaskPaymentDetail(value string) string {
+	value = strings.ReplaceAll(value, " ", "")
+	if len(value) <= 4 {
+		return "****"
+	}
+	return "**** " + value[len(value)-4:]
+}
+
+// Masked returns a copy of the payment source with sensitive details masked.
+func (s PaymentSource) Masked() PaymentSource {
+	masked := s
+	masked.Details = make(map[string]string, len(s.Details))
+	for key, value := range s.Details {
+		if IsSensitivePaymentDetail(key) {
+			value = MaskPaymentDetail(value)
+		}
+		masked.Details[key] = value
+	}
+	return masked
+}
+
+func (s *PaymentSource) Validate() error {
+	if len(s.Name) <= 0 || len(s.Name) > 50 {
+		return errors.NewValidationError("Name should be between 0 and 50")
+	}
+
+	if s.PaymentMethodID <= 0 {
+		return errors.NewValidationError("PaymentMethodID must be provided and must be greater than zero")
+	}
+
+	if !currencyCodePattern.MatchString(s.Currency) {
+		return errors.NewValidationError("Currency must be a three letter ISO 4217 code like PLN")
+	}
+
+	if !s.OpeningBalance.FitsNumeric(12, 2) {
+		return errors.NewValidationError("Opening balance must be between -10000000000 and 10000000000")
+	}
+
+	if len(s.Details) > 20 {
+		return errors.NewValidationError("Details can contain at most 20 entries")
+	}
+
+	for key, value := range s.Details {
+		if len(key) <= 0 || len(key) > 50 || len(value) > 100 {
+			return errors.NewValidationError("Details keys must be between 0 and 50 characters and values at most 100 characters")
+		}
+		if !IsSensitivePaymentDetail(key) && strings.HasPrefix(value, EncryptedPaymentDetailPrefix) {
+			return errors.NewValidationError("Details values must not start with " + EncryptedPaymentDetailPrefix)
+		}
+		switch strings.ToLower(key) {
+		case "iban":
+			if !isValidIBAN(value) {
+				return errors.NewValidationError("Details iban is not a valid IBAN")
+			}
+		case "card_number":
+			if !isValidCardNumber(value) {
+				return errors.NewValidationError("Details card_number must contain 12 to 19 digits")
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidIBAN(iban string) bool {
+	iban = strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	// move the country code and checksum to the end, letters count as two digits (A = 10 ... Z = 35)
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+func isValidCardNumber(number string) bool {
+	number = strings.ReplaceAll(number, " ", "")
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	for _, r := range number {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
 }
 
 type PaymentRepository interface {
 	GetAllPaymentMethods() ([]PaymentMethod, error)
-	GetUserPaymentSources(userID string) ([]PaymentSource, error)
+	GetUserPaymentSources(userID string, includeArchived bool) ([]PaymentSource, error)
+	FindUserPaymentSourceByID(sourceID int, userID string) (*PaymentSource, error)
+	CreatePaymentSource(source *PaymentSource) error
+	UpdatePaymentSource(source PaymentSource) (int64, error)
+	SetPaymentSourceArchived(sourceID int, userID string, archived bool) (int64, error)
 	PaymentMethodExists(methodID int) (bool, error)
 	UserPaymentSourceExists(sourceID int, userID string) (bool, error)
 }
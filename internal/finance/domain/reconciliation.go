@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+// MaxReconciledTransactions limits the number of transactions cleared by a single reconciliation.
+const MaxReconciledTransactions = 1000
+
+type ReconciliationRepository interface {
+	FindByPaymentSource(paymentSourceID int, userID string) ([]Reconciliation, error)
+	// Create saves the reconciliation and marks its transactions as reconciled, either all of them or none.
+	Create(reconciliation *Reconciliation, userID string) error
+}
+
+// Reconciliation records that the balance of a payment source matched a bank statement on the statement date once
+// TransactionIDs were cleared.
+type Reconciliation struct {
+	ID               int           `json:"id"`
+	PaymentSourceID  int           `json:"payment_source_id"`
+	StatementDate    time.Time     `json:"statement_date"`
+	StatementBalance money.Decimal `json:"statement_balance"`
+	TransactionIDs   []string      `json:"transaction_ids,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// ReconciliationPreview compares a statement balance with the balance of the reconciled transactions of the payment
+// source up to the statement date. The uncleared transactions which account for Difference have to be reconciled.
+type ReconciliationPreview struct {
+	PaymentSourceID  int                         `json:"payment_source_id"`
+	StatementDate    time.Time                   `json:"statement_date"`
+	StatementBalance money.Decimal               `json:"statement_balance"`
+	ClearedBalance   money.Decimal               `json:"cleared_balance"`
+	Difference       money.Decimal               `json:"difference"`
+	Uncleared        []PaymentSourceHistoryEntry `json:"uncleared"`
+}
+
+func (r *Reconciliation) Validate() error {
+	if r.StatementDate.IsZero() {
+		return errors.NewValidationError("StatementDate is required")
+	}
+
+	if !r.StatementBalance.FitsNumeric(12, 2) {
+		return errors.NewValidationError("StatementBalance must be between -10000000000 and 10000000000")
+	}
+
+	if len(r.TransactionIDs) > MaxReconciledTransactions {
+		return errors.NewValidationError("Too many transactions, reconcile them in several steps")
+	}
+
+	seen := make(map[string]bool, len(r.TransactionIDs))
+	for _, id := range r.TransactionIDs {
+		if seen[id] {
+			return errors.NewValidationError("TransactionIDs must not contain duplicates")
+		}
+		seen[id] = true
+	}
+	return nil
+}
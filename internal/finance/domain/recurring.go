@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+	RecurrenceYearly  RecurrenceFrequency = "yearly"
+)
+
+// MinRecurringYear and MaxRecurringYear bound the year a rule may start in.
+const (
+	MinRecurringYear = 1970
+	MaxRecurringYear = 2099
+)
+
+type RecurringRuleRepository interface {
+	Create(rule *RecurringRule) error
+	Update(rule RecurringRule) (int64, error)
+	Delete(ruleID string, userID string) (int64, error)
+	FindByID(ruleID string, userID string) (*RecurringRule, error)
+	FindByUser(userID string) ([]RecurringRule, error)
+	FindDue(until time.Time) ([]RecurringRule, error)
+	FindExceptions(ruleID string) ([]RecurringException, error)
+	SaveException(exception RecurringException) error
+	DeleteException(ruleID string, occurrenceDate time.Time) (int64, error)
+	SaveOccurrences(ruleID string, transactions []RecurringOccurrenceTransaction, generatedUntil time.Time) error
+}
+
+// RecurringTemplate holds the fields copied into every transaction generated by a RecurringRule.
+type RecurringTemplate struct {
+	Name                 string        `json:"name"`
+	Amount               money.Decimal `json:"amount"`
+	Type                 string        `json:"type"`
+	Description          *string       `json:"description"`
+	PredefinedCategoryID int           `json:"predefined_category_id"`
+	UserCategoryID       *int          `json:"user_category_id"`
+	PaymentMethodID      int           `json:"payment_method_id"`
+	PaymentSourceID      *int          `json:"payment_source_id"`
+	// DestinationPaymentSourceID is the payment source a recurring transfer moves the amount to.
+	DestinationPaymentSourceID *int `json:"destination_payment_source_id"`
+}
+
+// RecurringRule describes a schedule (e.g. every month on the 10th) and the transaction booked on each occurrence.
+// A rule ends on EndDate or after Count occurrences, whichever comes first, or never when both are empty.
+type RecurringRule struct {
+	ID             string              `json:"id"`
+	UserID         string              `json:"-"`
+	Frequency      RecurrenceFrequency `json:"frequency"`
+	Interval       int                 `json:"interval"` // every N days/weeks/months/years
+	StartDate      time.Time           `json:"start_date"`
+	EndDate        *time.Time          `json:"end_date"`
+	Count          *int                `json:"count"`
+	Template       RecurringTemplate   `json:"template"`
+	GeneratedUntil *time.Time          `json:"generated_until"` // occurrences up to this date are already booked
+}
+
+// RecurringException skips or modifies a single occurrence of a rule.
+type RecurringException struct {
+	RuleID         string         `json:"rule_id"`
+	OccurrenceDate time.Time      `json:"occurrence_date"`
+	Skipped        bool           `json:"skipped"`
+	Name           *string        `json:"name"`
+	Amount         *money.Decimal `json:"amount"`
+	Description    *string        `json:"description"`
+}
+
+// RecurringOccurrence is a single (planned or booked) occurrence of a rule.
+type RecurringOccurrence struct {
+	Date        time.Time           `json:"date"`
+	Skipped     bool                `json:"skipped"`
+	Modified    bool                `json:"modified"`
+	Booked      bool                `json:"booked"`
+	Transaction PersonalTransaction `json:"transaction"`
+}
+
+// RecurringOccurrenceTransaction is a transaction generated for the occurrence of a rule on OccurrenceDate.
+type RecurringOccurrenceTransaction struct {
+	OccurrenceDate time.Time
+	Transaction    PersonalTransaction
+}
+
+// TruncateToDate drops the time of day, dates of recurring rules are always compared in UTC.
+func TruncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (r *RecurringRule) Validate() error {
+	switch r.Frequency {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly:
+	default:
+		return errors.NewValidationError("Frequency must be one of 'daily', 'weekly', 'monthly' or 'yearly'")
+	}
+
+	if r.Interval <= 0 || r.Interval > 366 {
+		return errors.NewValidationError("Interval must be between 1 and 366")
+	}
+
+	if r.StartDate.IsZero() {
+		return errors.NewValidationError("StartDate is required")
+	}
+	if r.StartDate.Year() < MinRecurringYear || r.StartDate.Year() > MaxRecurringYear {
+		return errors.NewValidationError("StartDate must be between 1970 and 2099")
+	}
+
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return errors.NewValidationError("EndDate must not be before StartDate")
+	}
+
+	if r.Count != nil && *r.Count <= 0 {
+		return errors.NewValidationError("Count, if provided, must be greater than zero")
+	}
+
+	return nil
+}
+
+// OccurrenceDate returns the date of the n-th (0 based) occurrence without checking the end of the rule.
+// Monthly and yearly rules keep the day of StartDate, clamped to the last day of shorter months.
+func (r *RecurringRule) OccurrenceDate(n int) time.Time {
+	start := TruncateToDate(r.StartDate)
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return start.AddDate(0, 0, n*r.Interval)
+	case RecurrenceWeekly:
+		return start.AddDate(0, 0, 7*n*r.Interval)
+	case RecurrenceMonthly:
+		return addMonthsClamped(start, n*r.Interval)
+	case RecurrenceYearly:
+		return addMonthsClamped(start, 12*n*r.Interval)
+	}
+	return start
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	monthIndex := int(t.Month()) - 1 + months
+	year := t.Year() + monthIndex/12
+	month := time.Month(monthIndex%12 + 1)
+
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// LastOccurrenceDate returns the latest date the rule may have an occurrence on, as limited by EndDate and Count, or
+// nil if the rule never ends.
+func (r *RecurringRule) LastOccurrenceDate() *time.Time {
+	var last *time.Time
+	if r.Count != nil {
+		date := r.OccurrenceDate(*r.Count - 1)
+		last = &date
+	}
+	if r.EndDate != nil && (last == nil || TruncateToDate(*r.EndDate).Before(*last)) {
+		endDate := TruncateToDate(*r.EndDate)
+		last = &endDate
+	}
+	return last
+}
+
+// OccurrencesBetween returns the occurrence dates in the inclusive range [from, to], honouring EndDate and Count. It
+// returns at most limit dates, all of them when limit is zero.
+func (r *RecurringRule) OccurrencesBetween(from, to time.Time, limit int) []time.Time {
+	from, to = TruncateToDate(from), TruncateToDate(to)
+	if r.EndDate != nil && TruncateToDate(*r.EndDate).Before(to) {
+		to = TruncateToDate(*r.EndDate)
+	}
+
+	var dates []time.Time
+	for n := r.firstOccurrenceFrom(from); (r.Count == nil || n < *r.Count) && (limit <= 0 || len(dates) < limit); n++ {
+		date := r.OccurrenceDate(n)
+		if date.After(to) {
+			break
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// firstOccurrenceFrom returns the number of the first occurrence on or after the date. It estimates the number from
+// the time between StartDate and the date, which is never past the first occurrence, and steps forward from there.
+func (r *RecurringRule) firstOccurrenceFrom(date time.Time) int {
+	start := TruncateToDate(r.StartDate)
+	if !date.After(start) {
+		return 0
+	}
+
+	var n int
+	switch r.Frequency {
+	case RecurrenceDaily:
+		n = int(date.Sub(start).Hours()/24) / r.Interval
+	case RecurrenceWeekly:
+		n = int(date.Sub(start).Hours()/24) / (7 * r.Interval)
+	case RecurrenceMonthly:
+		n = ((date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())) / r.Interval
+	case RecurrenceYearly:
+		n = (date.Year() - start.Year()) / r.Interval
+	}
+	for r.OccurrenceDate(n).Before(date) {
+		n++
+	}
+	return n
+}
+
+// IsOccurrence reports whether the rule has an occurrence on the given date.
+func (r *RecurringRule) IsOccurrence(date time.Time) bool {
+	date = TruncateToDate(date)
+	return len(r.OccurrencesBetween(date, date, 1)) == 1
+}
+
+// TransactionOn builds the transaction booked for the occurrence on the given date, with the exception applied if any.
+func (r *RecurringRule) TransactionOn(date time.Time, exception *RecurringException) PersonalTransaction {
+	transaction := PersonalTransaction{
+		Name:                       r.Template.Name,
+		UserID:                     r.UserID,
+		Amount:                     r.Template.Amount,
+		Type:                       r.Template.Type,
+		Date:                       TruncateToDate(date),
+		Description:                r.Template.Description,
+		PredefinedCategoryID:       r.Template.PredefinedCategoryID,
+		UserCategoryID:             r.Template.UserCategoryID,
+		PaymentMethodID:            r.Template.PaymentMethodID,
+		PaymentSourceID:            r.Template.PaymentSourceID,
+		DestinationPaymentSourceID: r.Template.DestinationPaymentSourceID,
+	}
+
+	if exception != nil {
+		if exception.Name != nil {
+			transaction.Name = *exception.Name
+		}
+		if exception.Amount != nil {
+			transaction.Amount = *exception.Amount
+		}
+		if exception.Description != nil {
+			transaction.Description = exception.Description
+		}
+	}
+	return transaction
+}
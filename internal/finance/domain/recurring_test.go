@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRecurringRule_MonthlyClampsToEndOfMonth(t *testing.T) {
+	rule := RecurringRule{Frequency: RecurrenceMonthly, Interval: 1, StartDate: date(2024, time.January, 31)}
+
+	dates := rule.OccurrencesBetween(date(2024, time.January, 1), date(2024, time.May, 31), 0)
+
+	assert.Equal(t, []time.Time{
+		date(2024, time.January, 31),
+		date(2024, time.February, 29),
+		date(2024, time.March, 31),
+		date(2024, time.April, 30),
+		date(2024, time.May, 31),
+	}, dates)
+}
+
+func TestRecurringRule_StopsAfterCountAndEndDate(t *testing.T) {
+	count := 3
+	rule := RecurringRule{Frequency: RecurrenceWeekly, Interval: 2, StartDate: date(2024, time.March, 1), Count: &count}
+
+	dates := rule.OccurrencesBetween(date(2024, time.January, 1), date(2024, time.December, 31), 0)
+	assert.Equal(t, []time.Time{date(2024, time.March, 1), date(2024, time.March, 15), date(2024, time.March, 29)}, dates)
+	assert.Equal(t, date(2024, time.March, 29), *rule.LastOccurrenceDate())
+
+	endDate := date(2026, time.June, 30)
+	rule = RecurringRule{Frequency: RecurrenceYearly, Interval: 1, StartDate: date(2024, time.February, 29), EndDate: &endDate}
+
+	dates = rule.OccurrencesBetween(date(2025, time.January, 1), date(2030, time.January, 1), 0)
+	assert.Equal(t, []time.Time{date(2025, time.February, 28), date(2026, time.February, 28)}, dates)
+	assert.Equal(t, endDate, *rule.LastOccurrenceDate())
+
+	rule.EndDate = nil
+	assert.Nil(t, rule.LastOccurrenceDate())
+}
+
+func TestRecurringRule_TransactionOnAppliesException(t *testing.T) {
+	amount := money.MustParse("2150")
+	rule := RecurringRule{
+		UserID:    "user-id",
+		Frequency: RecurrenceMonthly,
+		Interval:  1,
+		StartDate: date(2024, time.January, 10),
+		Template:  RecurringTemplate{Name: "Rent", Amount: money.MustParse("2000"), Type: "expense", PredefinedCategoryID: 17, PaymentMethodID: 1},
+	}
+
+	assert.True(t, rule.IsOccurrence(date(2024, time.March, 10)))
+	assert.False(t, rule.IsOccurrence(date(2024, time.March, 11)))
+
+	transaction := rule.TransactionOn(date(2024, time.March, 10), &RecurringException{Amount: &amount})
+	assert.Equal(t, "Rent", transaction.Name)
+	assert.Equal(t, money.MustParse("2150"), transaction.Amount)
+	assert.Equal(t, "user-id", transaction.UserID)
+	assert.Equal(t, date(2024, time.March, 10), transaction.Date)
+}
+
+func TestRecurringRule_OccurrencesBetweenStartsAtFromAndStopsAtLimit(t *testing.T) {
+	rule := RecurringRule{Frequency: RecurrenceDaily, Interval: 3, StartDate: date(1970, time.January, 1)}
+
+	dates := rule.OccurrencesBetween(date(2024, time.March, 1), date(9999, time.December, 31), 3)
+	assert.Equal(t, []time.Time{date(2024, time.March, 3), date(2024, time.March, 6), date(2024, time.March, 9)}, dates)
+
+	rule = RecurringRule{Frequency: RecurrenceMonthly, Interval: 2, StartDate: date(2024, time.January, 31)}
+	dates = rule.OccurrencesBetween(date(2024, time.April, 1), date(2024, time.July, 31), 0)
+	assert.Equal(t, []time.Time{date(2024, time.May, 31), date(2024, time.July, 31)}, dates)
+
+	rule.StartDate = date(1969, time.December, 31)
+	assert.EqualError(t, rule.Validate(), "StartDate must be between 1970 and 2099")
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"math"
+	"time"
+)
+
+// SavingsCategoryName is the predefined expense category of the transactions which fund category goals.
+const SavingsCategoryName = "Savings"
+
+// daysPerMonth is the average length of a month, used to turn contributions into monthly rates.
+const daysPerMonth = 365.25 / 12
+
+type SavingsGoalFunding string
+
+const (
+	// SavingsGoalFundingCategory goals count the expenses in the Savings category since the goal was created.
+	SavingsGoalFundingCategory SavingsGoalFunding = "category"
+	// SavingsGoalFundingPaymentSource goals count the balance of the payment source.
+	SavingsGoalFundingPaymentSource SavingsGoalFunding = "payment_source"
+	// SavingsGoalFundingPortfolio goals count the current value of the investment portfolio.
+	SavingsGoalFundingPortfolio SavingsGoalFunding = "portfolio"
+)
+
+type SavingsGoalStatus string
+
+const (
+	SavingsGoalAchieved SavingsGoalStatus = "achieved"
+	SavingsGoalOnTrack  SavingsGoalStatus = "on_track"
+	SavingsGoalBehind   SavingsGoalStatus = "behind"
+)
+
+type SavingsGoalRepository interface {
+	FindByUser(userID string) ([]SavingsGoal, error)
+	FindByID(goalID int, userID string) (*SavingsGoal, error)
+	// FindDueForProgressEmail returns the goals created before monthStart whose progress hasn't been emailed since.
+	FindDueForProgressEmail(monthStart time.Time) ([]SavingsGoal, error)
+	Create(goal *SavingsGoal) error
+	Update(goal SavingsGoal) (int64, error)
+	Delete(goalID int, userID string) (int64, error)
+	RecordProgressEmail(goalIDs []int, sentOn time.Time) error
+}
+
+// SavingsGoal is an amount the user wants to have saved by TargetDate. StartDate and StartingAmount, the funded amount
+// when the goal was created, are set by the service and reset whenever the funding of the goal changes.
+type SavingsGoal struct {
+	ID              int                `json:"id"`
+	UserID          string             `json:"-"` // user UUID
+	Name            string             `json:"name"`
+	TargetAmount    money.Decimal      `json:"target_amount"`
+	TargetDate      time.Time          `json:"target_date"`
+	Funding         SavingsGoalFunding `json:"funding"`
+	PaymentSourceID *int               `json:"payment_source_id"`
+	PortfolioID     *string            `json:"portfolio_id"`
+	StartDate       time.Time          `json:"start_date"`
+	StartingAmount  money.Decimal      `json:"starting_amount"`
+}
+
+// SavingsGoalProgress is the state of a goal on a given day. The goal is on track while the saved amount keeps up with
+// saving evenly from StartDate to TargetDate. The projected completion date assumes the average monthly contribution
+// since StartDate continues, it's empty when nothing has been contributed.
+type SavingsGoalProgress struct {
+	Goal                        SavingsGoal       `json:"goal"`
+	Saved                       money.Decimal     `json:"saved"`
+	Remaining                   money.Decimal     `json:"remaining"`
+	PercentComplete             float64           `json:"percent_complete"`
+	MonthsLeft                  int               `json:"months_left"`
+	RequiredMonthlyContribution money.Decimal     `json:"required_monthly_contribution"`
+	AverageMonthlyContribution  money.Decimal     `json:"average_monthly_contribution"`
+	ProjectedCompletionDate     *time.Time        `json:"projected_completion_date"`
+	Status                      SavingsGoalStatus `json:"status"`
+}
+
+func (g *SavingsGoal) Validate() error {
+	if len(g.Name) == 0 || len(g.Name) > 100 {
+		return errors.NewValidationError("Name must be between 1 and 100 characters long")
+	}
+
+	if !g.TargetAmount.IsPositive() {
+		return errors.NewValidationError("Target amount must be greater than zero")
+	}
+	if !g.TargetAmount.FitsNumeric(15, 2) {
+		return errors.NewValidationError("Target amount must be less than 10000000000000")
+	}
+	if err := validateStartingAmount(g.StartingAmount); err != nil {
+		return err
+	}
+
+	if g.TargetDate.IsZero() {
+		return errors.NewValidationError("Target date is required")
+	}
+
+	switch g.Funding {
+	case SavingsGoalFundingCategory:
+		if g.PaymentSourceID != nil || g.PortfolioID != nil {
+			return errors.NewValidationError("Goals funded by the Savings category can't have a payment source or portfolio")
+		}
+	case SavingsGoalFundingPaymentSource:
+		if g.PaymentSourceID == nil || g.PortfolioID != nil {
+			return errors.NewValidationError("Goals funded by a payment source need PaymentSourceID and no portfolio")
+		}
+	case SavingsGoalFundingPortfolio:
+		if g.PortfolioID == nil || g.PaymentSourceID != nil {
+			return errors.NewValidationError("Goals funded by a portfolio need PortfolioID and no payment source")
+		}
+	default:
+		return errors.NewValidationError("Funding must be one of 'category', 'payment_source' or 'portfolio'")
+	}
+
+	return nil
+}
+
+func validateStartingAmount(amount money.Decimal) error {
+	if !amount.FitsNumeric(15, 2) {
+		return errors.NewValidationError("Starting amount must be between -10000000000000 and 10000000000000")
+	}
+	return nil
+}
+
+// Restart starts the goal over on the date with the amount already funded.
+func (g *SavingsGoal) Restart(date time.Time, startingAmount money.Decimal) error {
+	if err := validateStartingAmount(startingAmount); err != nil {
+		return err
+	}
+	g.StartDate, g.StartingAmount = TruncateToDate(date), startingAmount
+	return nil
+}
+
+func (g *SavingsGoal) RoundToMinorUnits() {
+	g.TargetAmount = g.TargetAmount.RoundToCurrency(money.DefaultCurrency)
+}
+
+// SameFunding reports whether both goals are funded from the same place.
+func (g *SavingsGoal) SameFunding(other SavingsGoal) bool {
+	return g.Funding == other.Funding && sameID(g.PaymentSourceID, other.PaymentSourceID) &&
+		(g.PortfolioID == nil) == (other.PortfolioID == nil) && (g.PortfolioID == nil || *g.PortfolioID == *other.PortfolioID)
+}
+
+// NewSavingsGoalProgress computes the progress of the goal with the saved amount on the given day.
+func NewSavingsGoalProgress(goal SavingsGoal, saved money.Decimal, today time.Time) SavingsGoalProgress {
+	today = TruncateToDate(today)
+	start, target := TruncateToDate(goal.StartDate), TruncateToDate(goal.TargetDate)
+	remaining := goal.TargetAmount.Sub(saved)
+	if remaining.IsNegative() {
+		remaining = money.Zero
+	}
+
+	progress := SavingsGoalProgress{
+		Goal:                        goal,
+		Saved:                       saved.RoundToCurrency(money.DefaultCurrency),
+		Remaining:                   remaining.RoundToCurrency(money.DefaultCurrency),
+		PercentComplete:             saved.Div(goal.TargetAmount).Mul(money.FromInt(100)).Round(2).Float64(),
+		RequiredMonthlyContribution: money.Zero,
+		AverageMonthlyContribution:  money.Zero,
+	}
+
+	if today.Before(target) {
+		progress.MonthsLeft = (target.Year()-today.Year())*12 + int(target.Month()) - int(today.Month())
+	}
+	if remaining.IsPositive() {
+		// a goal due this month, or already overdue, needs the whole remaining amount now
+		progress.RequiredMonthlyContribution = remaining.Div(money.FromInt(int64(max(progress.MonthsLeft, 1)))).RoundToCurrency(money.DefaultCurrency)
+	}
+
+	elapsedDays := today.Sub(start).Hours() / 24
+	if elapsedDays > 0 {
+		contributed := saved.Sub(goal.StartingAmount)
+		average := contributed.Div(money.FromFloat(elapsedDays / daysPerMonth))
+		progress.AverageMonthlyContribution = average.RoundToCurrency(money.DefaultCurrency)
+		if average.IsPositive() && remaining.IsPositive() {
+			days := math.Ceil(remaining.Div(average).Float64() * daysPerMonth)
+			projected := today.AddDate(0, 0, int(days))
+			progress.ProjectedCompletionDate = &projected
+		}
+	}
+
+	switch {
+	case !remaining.IsPositive():
+		progress.Status = SavingsGoalAchieved
+		progress.ProjectedCompletionDate = nil
+	case !today.Before(target):
+		progress.Status = SavingsGoalBehind
+	default:
+		// the amount which would have been saved by now when saving evenly from the start to the target date
+		planned := goal.StartingAmount
+		if totalDays := target.Sub(start).Hours() / 24; totalDays > 0 && elapsedDays > 0 {
+			share := money.FromFloat(elapsedDays / totalDays)
+			planned = planned.Add(goal.TargetAmount.Sub(goal.StartingAmount).Mul(share))
+		}
+		progress.Status = SavingsGoalOnTrack
+		if saved.LessThan(planned.RoundToCurrency(money.DefaultCurrency)) {
+			progress.Status = SavingsGoalBehind
+		}
+	}
+	return progress
+}
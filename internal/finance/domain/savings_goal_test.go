@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewSavingsGoalProgress(t *testing.T) {
+	goal := SavingsGoal{
+		Name: "Emergency fund", TargetAmount: money.MustParse("30000"), TargetDate: date(2027, time.June, 30),
+		Funding: SavingsGoalFundingCategory, StartDate: date(2026, time.January, 1),
+	}
+	today := date(2026, time.July, 1)
+
+	// saving evenly would have put aside about 9963 by now
+	behind := NewSavingsGoalProgress(goal, money.MustParse("9000"), today)
+	assert.Equal(t, SavingsGoalBehind, behind.Status)
+	assert.Equal(t, 30.0, behind.PercentComplete)
+	assert.Equal(t, 11, behind.MonthsLeft)
+	assert.Equal(t, money.MustParse("21000"), behind.Remaining)
+	assert.Equal(t, money.MustParse("1909.09"), behind.RequiredMonthlyContribution)
+	assert.Equal(t, money.MustParse("1513.47"), behind.AverageMonthlyContribution)
+	if assert.NotNil(t, behind.ProjectedCompletionDate) {
+		assert.True(t, behind.ProjectedCompletionDate.After(goal.TargetDate))
+	}
+
+	onTrack := NewSavingsGoalProgress(goal, money.MustParse("10000"), today)
+	assert.Equal(t, SavingsGoalOnTrack, onTrack.Status)
+	if assert.NotNil(t, onTrack.ProjectedCompletionDate) {
+		assert.False(t, onTrack.ProjectedCompletionDate.After(goal.TargetDate))
+	}
+
+	achieved := NewSavingsGoalProgress(goal, money.MustParse("31000"), today)
+	assert.Equal(t, SavingsGoalAchieved, achieved.Status)
+	assert.True(t, achieved.Remaining.IsZero())
+	assert.True(t, achieved.RequiredMonthlyContribution.IsZero())
+	assert.Nil(t, achieved.ProjectedCompletionDate)
+
+	overdue := NewSavingsGoalProgress(goal, money.MustParse("25000"), date(2027, time.July, 2))
+	assert.Equal(t, SavingsGoalBehind, overdue.Status)
+	assert.Equal(t, 0, overdue.MonthsLeft)
+	assert.Equal(t, money.MustParse("5000"), overdue.RequiredMonthlyContribution)
+}
+
+func TestSavingsGoal_ValidateFunding(t *testing.T) {
+	sourceID, portfolioID := 1, "1d9c5f1e-6c3b-4f0e-9f1a-2b7d8e4c5a60"
+	goal := SavingsGoal{Name: "Car", TargetAmount: money.MustParse("5000"), TargetDate: date(2027, time.January, 1)}
+
+	goal.Funding = SavingsGoalFundingPaymentSource
+	assert.Error(t, goal.Validate())
+	goal.PaymentSourceID = &sourceID
+	assert.NoError(t, goal.Validate())
+
+	goal.Funding = SavingsGoalFundingPortfolio
+	goal.PortfolioID = &portfolioID
+	assert.Error(t, goal.Validate())
+	goal.PaymentSourceID = nil
+	assert.NoError(t, goal.Validate())
+
+	goal.Funding = "bonds"
+	assert.EqualError(t, goal.Validate(), "Funding must be one of 'category', 'payment_source' or 'portfolio'")
+}
+
+func TestSavingsGoal_ValidateAmounts(t *testing.T) {
+	goal := SavingsGoal{Name: "Car", TargetAmount: money.MustParse("10000000000000"), TargetDate: date(2027, time.January, 1), Funding: SavingsGoalFundingCategory}
+	assert.EqualError(t, goal.Validate(), "Target amount must be less than 10000000000000")
+
+	goal.TargetAmount = money.MustParse("5000")
+	assert.NoError(t, goal.Validate())
+	assert.EqualError(t, goal.Restart(date(2026, time.January, 1), money.MustParse("-10000000000000")), "Starting amount must be between -10000000000000 and 10000000000000")
+	assert.True(t, goal.StartDate.IsZero())
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+const (
+	DefaultTimezone  = "UTC"
+	DefaultWeekStart = "monday"
+)
+
+type FinanceSettingsRepository interface {
+	// FindByUser returns sql.ErrNoRows for users who never changed their settings.
+	FindByUser(userID string) (*FinanceSettings, error)
+	Save(settings FinanceSettings) error
+}
+
+// FinanceSettings are the preferences of the user for the finance module.
+type FinanceSettings struct {
+	UserID string `json:"-"` // user UUID
+	// BaseCurrency is the currency net worth is reported in.
+	BaseCurrency string `json:"base_currency"`
+	// Timezone is the IANA name of the zone which decides the current day, e.g. Europe/Warsaw.
+	Timezone string `json:"timezone"`
+	// WeekStart is the lowercase English name of the day summaries start the weeks on.
+	WeekStart string `json:"week_start"`
+}
+
+// NormalizeCurrency upper-cases the ISO 4217 code, an empty code is money.DefaultCurrency.
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
+
+func DefaultFinanceSettings(userID string) FinanceSettings {
+	return FinanceSettings{UserID: userID, BaseCurrency: money.DefaultCurrency, Timezone: DefaultTimezone, WeekStart: DefaultWeekStart}
+}
+
+func (s *FinanceSettings) Validate() error {
+	s.BaseCurrency = strings.ToUpper(strings.TrimSpace(s.BaseCurrency))
+	if !currencyCodePattern.MatchString(s.BaseCurrency) {
+		return errors.NewValidationError("Base currency must be a three letter ISO 4217 code like PLN")
+	}
+
+	s.Timezone = strings.TrimSpace(s.Timezone)
+	// an empty name is UTC for time.LoadLocation, but "Local" would be the zone of the server
+	if s.Timezone == "" || s.Timezone == "Local" {
+		return errors.NewValidationError("Timezone must be an IANA time zone name like Europe/Warsaw")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return errors.NewValidationError("Timezone must be an IANA time zone name like Europe/Warsaw")
+	}
+
+	s.WeekStart = strings.ToLower(strings.TrimSpace(s.WeekStart))
+	if _, ok := parseWeekday(s.WeekStart); !ok {
+		return errors.NewValidationError("Week start must be the name of a day like monday or sunday")
+	}
+	return nil
+}
+
+// Location returns the time zone of the user, UTC if it can't be loaded.
+func (s *FinanceSettings) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil || s.Timezone == "" {
+		return time.UTC
+	}
+	return location
+}
+
+// FirstDayOfWeek returns the day weeks start on, Monday if it's not set.
+func (s *FinanceSettings) FirstDayOfWeek() time.Weekday {
+	weekday, ok := parseWeekday(s.WeekStart)
+	if !ok {
+		return time.Monday
+	}
+	return weekday
+}
+
+// Today returns the current date of the user, as a UTC midnight like all dates of the finance module.
+func (s *FinanceSettings) Today(now time.Time) time.Time {
+	local := now.In(s.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.ToLower(weekday.String()) == name {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"strings"
+)
+
+// MaxTransactionTags limits the number of tags of a single transaction.
+const MaxTransactionTags = 20
+
+// Tag is a free-form label of the user, e.g. "vacation-2026", put on any number of transactions across categories.
+type Tag struct {
+	ID     int    `json:"id"`
+	UserID string `json:"-"` // user UUID
+	Name   string `json:"name"`
+}
+
+type TagRepository interface {
+	FindByUser(userID string) ([]Tag, error)
+	FindByID(tagID int, userID string) (*Tag, error)
+	DoesTagNameExist(name string, userID string, excludedID int) (bool, error)
+	Create(tag *Tag) error
+	Update(tag Tag) (int64, error)
+	// Delete removes the tag from the vocabulary and from every transaction and categorization rule.
+	Delete(tagID int, userID string) (int64, error)
+}
+
+type TransactionByTagSummary struct {
+	TagID            int           `json:"tag_id"`
+	TagName          string        `json:"tag_name"`
+	TotalAmount      money.Decimal `json:"total_amount"`
+	TransactionCount int           `json:"transaction_count"`
+}
+
+func (t *Tag) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if len(t.Name) <= 0 || len(t.Name) > 50 {
+		return errors.NewValidationError("Name should be between 0 and 50")
+	}
+	return nil
+}
+
+// validateTagIDs checks a list of tag references, field is the name used in error messages.
+func validateTagIDs(tagIDs []int, field string) error {
+	if len(tagIDs) > MaxTransactionTags {
+		return errors.NewValidationError(fmt.Sprintf("%s can hold at most %d tags", field, MaxTransactionTags))
+	}
+	seen := make(map[int]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		if id <= 0 {
+			return errors.NewValidationError(field + " must contain only IDs greater than zero")
+		}
+		if seen[id] {
+			return errors.NewValidationError(field + " must not contain duplicates")
+		}
+		seen[id] = true
+	}
+	return nil
+}
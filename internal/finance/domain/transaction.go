@@ -2,40 +2,165 @@ package domain
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
-	"math"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"time"
 )
 
 type PersonalTransactionRepository interface {
 	Save(transaction PersonalTransaction) error
-	GetTransactionsByType(userID string, transactionType string, startDate time.Time, endDate time.Time, limit int, page int) ([]PersonalTransaction, error)
-	FindByID(transactionID int) (*PersonalTransaction, error)
-	Delete(transactionID int) error
-	Update(transaction PersonalTransaction) error
+	SearchTransactions(userID string, search TransactionSearch) (*TransactionPage, error)
+	FindByID(transactionID string, userID string) (*PersonalTransaction, error)
+	// Delete removes the transaction together with the metadata of its attachments and returns their storage keys, the
+	// content of the attachments is left to the caller.
+	Delete(transactionID string, userID string) (int64, []string, error)
+	Update(transaction PersonalTransaction) (int64, error)
+	// SaveWithTransaction returns errors.ErrExternalIDTaken, leaving the database transaction usable, when the user
+	// already has a transaction with the external ID.
 	SaveWithTransaction(transaction PersonalTransaction, tx *sql.Tx) error
+	UpdateWithTransaction(transaction PersonalTransaction, tx *sql.Tx) (int64, error)
+	// FindByExternalIDWithTransaction finds and locks the transaction of the user with the external ID.
+	FindByExternalIDWithTransaction(userID, externalID string, tx *sql.Tx) (*PersonalTransaction, error)
+	// WithSavepoint runs fn in a savepoint of the database transaction, an error of fn undoes only what fn did.
+	WithSavepoint(tx *sql.Tx, fn func() error) error
 	BeginTransaction() (*sql.Tx, error)
 	GetTransactionsInDateRange(userID string, startDate, endDate time.Time) ([]PersonalTransaction, error)
+	// FindDuplicateCandidates returns the transactions in the date range and those with any of the external IDs.
+	FindDuplicateCandidates(userID string, startDate, endDate time.Time, externalIDs []string) ([]PersonalTransaction, error)
+	// StreamTransactions calls fn for every transaction matching the filters of the search, sorting and paging are ignored.
+	StreamTransactions(userID string, search TransactionSearch, fn func(transaction PersonalTransaction) error) error
+	GetPaymentSourceTransactions(userID string, paymentSourceID int, startDate, endDate time.Time) ([]PersonalTransaction, error)
+	// GetTransactionPeriodTotals sums income and expenses matching the filters of the search per month and week, weeks
+	// start on firstDay. The totals are ordered by month and week.
+	GetTransactionPeriodTotals(userID string, search TransactionSearch, firstDay time.Weekday) ([]TransactionPeriodTotal, error)
 	GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]TransactionByCategorySummary, error)
 	GetTransactionSummaryByPaymentMethod(userID string, startDate, endDate time.Time, transactionType string) ([]TransactionByPaymentMethodSummary, error)
+	GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]TransactionByTagSummary, error)
 }
 
 type PersonalTransaction struct {
-	ID                   string    `json:"id"`
-	Name                 string    `json:"name"`
-	UserID               string    // user UUID
-	Amount               float64   `json:"amount"`
-	Type                 string    `json:"type"` // "income" lub "expense"
-	Date                 time.Time `json:"date"`
-	Description          *string   `json:"description"`
-	PredefinedCategoryID int       `json:"predefined_category_id"`
-	UserCategoryID       *int      `json:"user_category_id"`
-	PaymentMethodID      int       `json:"payment_method_id"`
-	PaymentSourceID      *int      `json:"payment_source_id"`
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	UserID               string             // user UUID
+	Amount               money.Decimal      `json:"amount"`
+	Type                 string             `json:"type"` // "income", "expense" lub "transfer"
+	Date                 time.Time          `json:"date"`
+	Description          *string            `json:"description"`
+	PredefinedCategoryID int                `json:"predefined_category_id"`
+	UserCategoryID       *int               `json:"user_category_id"`
+	PaymentMethodID      int                `json:"payment_method_id"`
+	PaymentSourceID      *int               `json:"payment_source_id"`
+	Splits               []TransactionSplit `json:"splits,omitempty"`
+	// DestinationPaymentSourceID is the payment source a transfer moves the money to from PaymentSourceID.
+	DestinationPaymentSourceID *int `json:"destination_payment_source_id,omitempty"`
+	// FXRate converts the amount of a transfer into the currency of the destination, nil means the same currency.
+	FXRate *money.Decimal `json:"fx_rate,omitempty"`
+	// ReconciliationID is set once the transaction has been matched against a bank statement of PaymentSourceID, it
+	// can't be set directly.
+	ReconciliationID *int `json:"reconciliation_id"`
+	// DestinationReconciliationID is set once a transfer has been matched against a bank statement of
+	// DestinationPaymentSourceID, both ends of a transfer are reconciled separately.
+	DestinationReconciliationID *int `json:"destination_reconciliation_id,omitempty"`
+	// TagIDs are the tags of the user put on the transaction, replaced as a whole on update.
+	TagIDs []int `json:"tag_ids"`
+	// ExternalID is the ID the bank or the client gave the transaction, e.g. the FITID of an OFX statement. It is unique
+	// per user and identifies the transaction when the same statement is imported again.
+	ExternalID *string `json:"external_id,omitempty"`
+	// Attachments are read only, they are uploaded and deleted through their own endpoints.
+	Attachments []Attachment `json:"attachments"`
 }
 
-func (t *PersonalTransaction) RoundToTwoDecimalPlaces() {
-	t.Amount = math.Round(t.Amount*100) / 100
+// TransactionSplit is a part of a transaction booked on its own category, e.g. the hygiene items on a supermarket
+// receipt. The lines of a split transaction always sum up to its amount and are replaced as a whole on update.
+type TransactionSplit struct {
+	PredefinedCategoryID int           `json:"predefined_category_id"`
+	UserCategoryID       *int          `json:"user_category_id"`
+	Amount               money.Decimal `json:"amount"`
+	Description          *string       `json:"description"`
+}
+
+// MaxTransactionSplits limits the number of lines of a single split transaction.
+const MaxTransactionSplits = 50
+
+// RoundToMinorUnits rounds the amount to the minor units of the currency the amounts are kept in.
+func (t *PersonalTransaction) RoundToMinorUnits() {
+	t.Amount = t.Amount.RoundToCurrency(money.DefaultCurrency)
+	for i := range t.Splits {
+		t.Splits[i].Amount = t.Splits[i].Amount.RoundToCurrency(money.DefaultCurrency)
+	}
+}
+
+// Lines returns the split lines of the transaction, or the whole transaction as a single line when it isn't split.
+func (t *PersonalTransaction) Lines() []TransactionSplit {
+	if len(t.Splits) > 0 {
+		return t.Splits
+	}
+	return []TransactionSplit{{
+		PredefinedCategoryID: t.PredefinedCategoryID,
+		UserCategoryID:       t.UserCategoryID,
+		Amount:               t.Amount,
+		Description:          t.Description,
+	}}
+}
+
+// IsTransfer reports whether the transaction moves money between two payment sources of the user, such transactions
+// are neither an income nor an expense.
+func (t *PersonalTransaction) IsTransfer() bool {
+	return t.Type == string(TransactionTypeTransfer)
+}
+
+// IsReconciled reports whether the transaction has been matched against a statement of any of its payment sources,
+// such transactions can't be edited.
+func (t *PersonalTransaction) IsReconciled() bool {
+	return t.ReconciliationID != nil || t.DestinationReconciliationID != nil
+}
+
+// IsReconciledOn reports whether the transaction has been matched against a statement of the payment source.
+func (t *PersonalTransaction) IsReconciledOn(paymentSourceID int) bool {
+	if t.DestinationPaymentSourceID != nil && *t.DestinationPaymentSourceID == paymentSourceID {
+		return t.DestinationReconciliationID != nil
+	}
+	return t.ReconciliationID != nil
+}
+
+// ClearReconciliation drops the reconciliations sent by a client, they are only set by reconciling.
+func (t *PersonalTransaction) ClearReconciliation() {
+	t.ReconciliationID = nil
+	t.DestinationReconciliationID = nil
+}
+
+// DestinationAmount is the amount a transfer adds to its destination payment source. It fails only for amounts and
+// rates rejected by Validate.
+func (t *PersonalTransaction) DestinationAmount() (money.Decimal, error) {
+	if t.FXRate == nil {
+		return t.Amount, nil
+	}
+	amount, err := t.Amount.CheckedMul(*t.FXRate)
+	if err != nil {
+		return money.Zero, err
+	}
+	return amount.RoundToCurrency(money.DefaultCurrency), nil
+}
+
+// BalanceChange is how much the transaction changes the balance of the payment source, zero if it doesn't touch it.
+func (t *PersonalTransaction) BalanceChange(paymentSourceID int) (money.Decimal, error) {
+	change := money.Zero
+	if t.PaymentSourceID != nil && *t.PaymentSourceID == paymentSourceID {
+		if t.Type == string(TransactionTypeIncome) {
+			change = t.Amount
+		} else {
+			change = t.Amount.Neg()
+		}
+	}
+	if t.IsTransfer() && t.DestinationPaymentSourceID != nil && *t.DestinationPaymentSourceID == paymentSourceID {
+		destinationAmount, err := t.DestinationAmount()
+		if err != nil {
+			return money.Zero, err
+		}
+		return change.CheckedAdd(destinationAmount)
+	}
+	return change, nil
 }
 
 func (t *PersonalTransaction) Validate() error {
@@ -43,12 +168,31 @@ func (t *PersonalTransaction) Validate() error {
 		return errors.NewValidationError("Name should be between 0 and 50")
 	}
 
-	if t.Amount <= 0 {
+	if !t.Amount.IsPositive() {
 		return errors.NewValidationError("Amount must be greater than zero")
 	}
+	if !t.Amount.FitsNumeric(10, 2) {
+		return errors.NewValidationError("Amount must be less than 100000000")
+	}
+
+	if t.Type != "income" && t.Type != "expense" && t.Type != "transfer" {
+		return errors.NewValidationError("Type must be either 'income', 'expense' or 'transfer'")
+	}
 
-	if t.Type != "income" && t.Type != "expense" {
-		return errors.NewValidationError("Type must be either 'income' or 'expense'")
+	if err := validateTagIDs(t.TagIDs, "TagIDs"); err != nil {
+		return err
+	}
+
+	if t.ExternalID != nil && (*t.ExternalID == "" || len(*t.ExternalID) > MaxExternalIDLength) {
+		return errors.NewValidationError(fmt.Sprintf("ExternalID, if provided, must be between 1 and %d characters long", MaxExternalIDLength))
+	}
+
+	if t.IsTransfer() {
+		return t.validateTransfer()
+	}
+
+	if t.DestinationPaymentSourceID != nil || t.FXRate != nil {
+		return errors.NewValidationError("DestinationPaymentSourceID and FXRate can only be provided for transfers")
 	}
 
 	if t.PredefinedCategoryID <= 0 {
@@ -75,28 +219,122 @@ func (t *PersonalTransaction) Validate() error {
 		return errors.NewValidationError("Description if provided length must be less than or equal to 200 characters and greater than 0")
 	}
 
+	return t.validateSplits()
+}
+
+func (t *PersonalTransaction) validateTransfer() error {
+	if t.PaymentSourceID == nil || *t.PaymentSourceID <= 0 {
+		return errors.NewValidationError("PaymentSourceID must be provided for transfers and must be greater than zero")
+	}
+
+	if t.DestinationPaymentSourceID == nil || *t.DestinationPaymentSourceID <= 0 {
+		return errors.NewValidationError("DestinationPaymentSourceID must be provided for transfers and must be greater than zero")
+	}
+
+	if *t.PaymentSourceID == *t.DestinationPaymentSourceID {
+		return errors.NewValidationError("A transfer must move money between two different payment sources")
+	}
+
+	if t.FXRate != nil {
+		// fx_rate is a DECIMAL(18, 8)
+		if !t.FXRate.IsPositive() || !t.FXRate.FitsNumeric(18, 8) {
+			return errors.NewValidationError("FXRate, if provided, must be greater than zero and less than 10000000000")
+		}
+		destinationAmount, err := t.DestinationAmount()
+		if err != nil || !destinationAmount.FitsNumeric(10, 2) {
+			return errors.NewValidationError("Amount converted with FXRate must be less than 100000000")
+		}
+	}
+
+	if t.PredefinedCategoryID != 0 || t.UserCategoryID != nil || len(t.Splits) > 0 {
+		return errors.NewValidationError("A transfer can't have categories or split lines")
+	}
+
+	if t.PaymentMethodID <= 0 {
+		return errors.NewValidationError("PaymentMethodID must be provided and must be greater than zero")
+	}
+
+	if t.Date.IsZero() {
+		return errors.NewValidationError("Date is required")
+	}
+
+	if t.Description != nil && (len(*t.Description) > 200 || len(*t.Description) <= 0) {
+		return errors.NewValidationError("Description if provided length must be less than or equal to 200 characters and greater than 0")
+	}
+	return nil
+}
+
+func (t *PersonalTransaction) validateSplits() error {
+	if len(t.Splits) == 0 {
+		return nil
+	}
+	if len(t.Splits) > MaxTransactionSplits {
+		return errors.NewValidationError(fmt.Sprintf("A transaction can be split into at most %d lines", MaxTransactionSplits))
+	}
+
+	total := money.Zero
+	for i, split := range t.Splits {
+		if !split.Amount.IsPositive() {
+			return errors.NewValidationError(fmt.Sprintf("Amount of split line %d must be greater than zero", i+1))
+		}
+		if !split.Amount.FitsNumeric(10, 2) {
+			return errors.NewValidationError(fmt.Sprintf("Amount of split line %d must be less than 100000000", i+1))
+		}
+		if split.PredefinedCategoryID <= 0 {
+			return errors.NewValidationError(fmt.Sprintf("PredefinedCategoryID of split line %d must be provided and must be greater than zero", i+1))
+		}
+		if split.UserCategoryID != nil && *split.UserCategoryID <= 0 {
+			return errors.NewValidationError(fmt.Sprintf("UserCategoryID of split line %d, if provided, must be greater than zero", i+1))
+		}
+		if split.Description != nil && (len(*split.Description) > 200 || len(*split.Description) <= 0) {
+			return errors.NewValidationError(fmt.Sprintf("Description of split line %d if provided length must be less than or equal to 200 characters and greater than 0", i+1))
+		}
+		total = total.Add(split.Amount)
+	}
+
+	if !total.Equal(t.Amount) {
+		return errors.NewValidationError(fmt.Sprintf("Split lines must sum up to the transaction amount %s, got %s", t.Amount, total))
+	}
 	return nil
 }
 
 type TransactionType string
 
 const (
-	TransactionTypeIncome  TransactionType = "income"
-	TransactionTypeExpense TransactionType = "expense"
+	TransactionTypeIncome   TransactionType = "income"
+	TransactionTypeExpense  TransactionType = "expense"
+	TransactionTypeTransfer TransactionType = "transfer"
 )
 
 func IsValidTransactionType(t string) bool {
-	return t == string(TransactionTypeIncome) || t == string(TransactionTypeExpense) || t == ""
+	return t == string(TransactionTypeIncome) || t == string(TransactionTypeExpense) || t == string(TransactionTypeTransfer) || t == ""
 }
 
 type TransactionByCategorySummary struct {
-	CategoryID   int     `json:"category_id"`
-	CategoryName string  `json:"category_name"`
-	TotalAmount  float64 `json:"total_amount"`
+	CategoryID    int                                `json:"category_id"`
+	CategoryName  string                             `json:"category_name"`
+	TotalAmount   money.Decimal                      `json:"total_amount"`
+	SubCategories []TransactionByUserCategorySummary `json:"sub_categories,omitempty"`
+}
+
+// TransactionByUserCategorySummary is the share of a user category in the total of its parent category.
+type TransactionByUserCategorySummary struct {
+	UserCategoryID   int           `json:"user_category_id"`
+	UserCategoryName string        `json:"user_category_name"`
+	TotalAmount      money.Decimal `json:"total_amount"`
 }
 
 type TransactionByPaymentMethodSummary struct {
-	PaymentMethodID   int     `json:"payment_method_id"`
-	PaymentMethodName string  `json:"payment_method_name"`
-	TotalAmount       float64 `json:"total_amount"`
+	PaymentMethodID   int           `json:"payment_method_id"`
+	PaymentMethodName string        `json:"payment_method_name"`
+	TotalAmount       money.Decimal `json:"total_amount"`
+}
+
+// PaymentSourceHistoryEntry is a transaction as seen from a single payment source, transfers appear in the history
+// of both of their payment sources.
+type PaymentSourceHistoryEntry struct {
+	Transaction PersonalTransaction `json:"transaction"`
+	Change      money.Decimal       `json:"change"`
+	// Balance is the running balance of the payment source after the transaction.
+	Balance money.Decimal `json:"balance"`
 }
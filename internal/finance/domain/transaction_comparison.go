@@ -0,0 +1,282 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultComparisonAverageMonths = 12
+	MaxComparisonAverageMonths     = 60
+)
+
+// TransactionComparisonOptions select the periods of a comparison report, zero values are filled in by Normalize.
+type TransactionComparisonOptions struct {
+	// Type is income or expense, expense by default.
+	Type string
+	// StartDate and EndDate are the period being looked at, the current month up to today by default.
+	StartDate time.Time
+	EndDate   time.Time
+	// CompareStartDate and CompareEndDate are the period it is compared with, the previous period by default.
+	CompareStartDate time.Time
+	CompareEndDate   time.Time
+	// AverageMonths is the number of whole months before the period the rolling monthly average is taken over.
+	AverageMonths int
+}
+
+// Normalize fills in the defaults relative to today and validates the options.
+func (o *TransactionComparisonOptions) Normalize(today time.Time) error {
+	if o.Type == "" {
+		o.Type = string(TransactionTypeExpense)
+	}
+	if o.Type != string(TransactionTypeIncome) && o.Type != string(TransactionTypeExpense) {
+		return errors.NewValidationError("Type must be income or expense")
+	}
+
+	if o.StartDate.IsZero() && o.EndDate.IsZero() {
+		today = TruncateToDate(today)
+		o.StartDate = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		o.EndDate = today
+	}
+	if err := validateComparisonPeriod(o.StartDate, o.EndDate); err != nil {
+		return err
+	}
+	o.StartDate, o.EndDate = TruncateToDate(o.StartDate), TruncateToDate(o.EndDate)
+
+	if o.CompareStartDate.IsZero() && o.CompareEndDate.IsZero() {
+		o.CompareStartDate, o.CompareEndDate = PreviousPeriod(o.StartDate, o.EndDate)
+	}
+	if err := validateComparisonPeriod(o.CompareStartDate, o.CompareEndDate); err != nil {
+		return err
+	}
+	o.CompareStartDate, o.CompareEndDate = TruncateToDate(o.CompareStartDate), TruncateToDate(o.CompareEndDate)
+
+	if o.AverageMonths == 0 {
+		o.AverageMonths = DefaultComparisonAverageMonths
+	}
+	if o.AverageMonths < 0 || o.AverageMonths > MaxComparisonAverageMonths {
+		return errors.NewValidationError("Average months must be between 1 and 60")
+	}
+	return nil
+}
+
+func validateComparisonPeriod(startDate, endDate time.Time) error {
+	if startDate.IsZero() || endDate.IsZero() {
+		return errors.NewValidationError("Start and end date of a period must be given together")
+	}
+	if startDate.After(endDate) {
+		return errors.NewValidationError("Start date must not be after end date")
+	}
+	return nil
+}
+
+// AveragePeriod returns the whole months the rolling average is taken over, they end right before the month the
+// period starts in.
+func (o *TransactionComparisonOptions) AveragePeriod() (time.Time, time.Time) {
+	monthStart := time.Date(o.StartDate.Year(), o.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return monthStart.AddDate(0, -o.AverageMonths, 0), monthStart.AddDate(0, 0, -1)
+}
+
+// wholeMonths returns the number of months the period spans if it starts on the first day of a month and ends on the
+// last day of a month.
+func wholeMonths(startDate, endDate time.Time) (int, bool) {
+	if startDate.Day() != 1 || endDate.AddDate(0, 0, 1).Day() != 1 {
+		return 0, false
+	}
+	return (endDate.Year()-startDate.Year())*12 + int(endDate.Month()) - int(startDate.Month()) + 1, true
+}
+
+func periodDays(startDate, endDate time.Time) int {
+	return int(endDate.Sub(startDate).Hours()/24) + 1
+}
+
+// PreviousPeriod returns the period right before the given one. A period starting on the first day of a month moves
+// back by the months it spans, so that a month is compared with the previous month whatever their number of days and
+// the first days of a month with the same days of the previous month. Other periods move back by their number of days.
+func PreviousPeriod(startDate, endDate time.Time) (time.Time, time.Time) {
+	startDate, endDate = TruncateToDate(startDate), TruncateToDate(endDate)
+	if startDate.Day() != 1 {
+		days := periodDays(startDate, endDate)
+		return startDate.AddDate(0, 0, -days), startDate.AddDate(0, 0, -1)
+	}
+
+	months := (endDate.Year()-startDate.Year())*12 + int(endDate.Month()) - int(startDate.Month()) + 1
+	previousEnd := addMonthsClamped(endDate, -months)
+	if _, whole := wholeMonths(startDate, endDate); whole {
+		// the last day of a month stays the last day of a month
+		previousEnd = time.Date(previousEnd.Year(), previousEnd.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+	return addMonthsClamped(startDate, -months), previousEnd
+}
+
+// ComparisonPeriod is a date range of the comparison report, both dates included.
+type ComparisonPeriod struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// TransactionComparison compares the totals of a period per category and per payment method with another period and
+// with the monthly average of the months before it.
+type TransactionComparison struct {
+	Type          string           `json:"type"`
+	Period        ComparisonPeriod `json:"period"`
+	ComparePeriod ComparisonPeriod `json:"compare_period"`
+	AveragePeriod ComparisonPeriod `json:"average_period"`
+	AverageMonths int              `json:"average_months"`
+	Total         ComparisonDelta  `json:"total"`
+	// Categories and PaymentMethods are ordered by the amount in the period, largest first.
+	Categories     []CategoryComparison      `json:"categories"`
+	PaymentMethods []PaymentMethodComparison `json:"payment_methods"`
+}
+
+// ComparisonDelta is the change of an amount against the compared period and against the average. PeriodAverage is
+// the average scaled to the length of the period, by its number of months for whole months and by its number of days
+// otherwise, and AverageChange is relative to it. The percentages are relative to the amount compared with, they are
+// nil when that amount is zero.
+type ComparisonDelta struct {
+	Amount               money.Decimal `json:"amount"`
+	CompareAmount        money.Decimal `json:"compare_amount"`
+	Change               money.Decimal `json:"change"`
+	ChangePercent        *float64      `json:"change_percent"`
+	MonthlyAverage       money.Decimal `json:"monthly_average"`
+	PeriodAverage        money.Decimal `json:"period_average"`
+	AverageChange        money.Decimal `json:"average_change"`
+	AverageChangePercent *float64      `json:"average_change_percent"`
+}
+
+type CategoryComparison struct {
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	ComparisonDelta
+}
+
+type PaymentMethodComparison struct {
+	PaymentMethodID   int    `json:"payment_method_id"`
+	PaymentMethodName string `json:"payment_method_name"`
+	ComparisonDelta
+}
+
+// TransactionComparisonTotals are the summaries of one of the periods of the comparison.
+type TransactionComparisonTotals struct {
+	Categories     []TransactionByCategorySummary
+	PaymentMethods []TransactionByPaymentMethodSummary
+}
+
+// NewTransactionComparison compares the totals of the period with those of the compared period and of the average
+// period, which are divided by the number of months to get the monthly average and scaled to the length of the period
+// to compare it with. Months without transactions count as zero in the average.
+func NewTransactionComparison(options TransactionComparisonOptions, current, compared, average TransactionComparisonTotals) TransactionComparison {
+	averageStart, averageEnd := options.AveragePeriod()
+	comparison := TransactionComparison{
+		Type:           options.Type,
+		Period:         ComparisonPeriod{StartDate: options.StartDate, EndDate: options.EndDate},
+		ComparePeriod:  ComparisonPeriod{StartDate: options.CompareStartDate, EndDate: options.CompareEndDate},
+		AveragePeriod:  ComparisonPeriod{StartDate: averageStart, EndDate: averageEnd},
+		AverageMonths:  options.AverageMonths,
+		Categories:     []CategoryComparison{},
+		PaymentMethods: []PaymentMethodComparison{},
+	}
+	averageMonths := money.FromInt(int64(options.AverageMonths))
+	// the average amount is scaled by periodLength / averageLength to the length of the period
+	periodLength, averageLength := money.FromInt(int64(periodDays(options.StartDate, options.EndDate))), money.FromInt(int64(periodDays(averageStart, averageEnd)))
+	if months, whole := wholeMonths(options.StartDate, options.EndDate); whole {
+		periodLength, averageLength = money.FromInt(int64(months)), averageMonths
+	}
+	newDelta := func(amount, compareAmount, averageAmount money.Decimal) ComparisonDelta {
+		return newComparisonDelta(amount, compareAmount, averageAmount.Div(averageMonths), averageAmount.Mul(periodLength).Div(averageLength))
+	}
+
+	var totals [3]money.Decimal
+	categories := make(map[int]*CategoryComparison)
+	categoryAmounts := make(map[int]*[3]money.Decimal)
+	for i, summaries := range [3][]TransactionByCategorySummary{current.Categories, compared.Categories, average.Categories} {
+		for _, summary := range summaries {
+			category, exists := categories[summary.CategoryID]
+			if !exists {
+				category = &CategoryComparison{CategoryID: summary.CategoryID}
+				categories[summary.CategoryID] = category
+				categoryAmounts[summary.CategoryID] = &[3]money.Decimal{}
+			}
+			if category.CategoryName == "" {
+				category.CategoryName = summary.CategoryName
+			}
+			categoryAmounts[summary.CategoryID][i] = categoryAmounts[summary.CategoryID][i].Add(summary.TotalAmount)
+			totals[i] = totals[i].Add(summary.TotalAmount)
+		}
+	}
+	for id, category := range categories {
+		amounts := categoryAmounts[id]
+		category.ComparisonDelta = newDelta(amounts[0], amounts[1], amounts[2])
+		comparison.Categories = append(comparison.Categories, *category)
+	}
+	sort.Slice(comparison.Categories, func(i, j int) bool {
+		a, b := comparison.Categories[i], comparison.Categories[j]
+		if !a.Amount.Equal(b.Amount) {
+			return a.Amount.GreaterThan(b.Amount)
+		}
+		if !a.CompareAmount.Equal(b.CompareAmount) {
+			return a.CompareAmount.GreaterThan(b.CompareAmount)
+		}
+		return a.CategoryID < b.CategoryID
+	})
+
+	methods := make(map[int]*PaymentMethodComparison)
+	methodAmounts := make(map[int]*[3]money.Decimal)
+	for i, summaries := range [3][]TransactionByPaymentMethodSummary{current.PaymentMethods, compared.PaymentMethods, average.PaymentMethods} {
+		for _, summary := range summaries {
+			method, exists := methods[summary.PaymentMethodID]
+			if !exists {
+				method = &PaymentMethodComparison{PaymentMethodID: summary.PaymentMethodID}
+				methods[summary.PaymentMethodID] = method
+				methodAmounts[summary.PaymentMethodID] = &[3]money.Decimal{}
+			}
+			if method.PaymentMethodName == "" {
+				method.PaymentMethodName = summary.PaymentMethodName
+			}
+			methodAmounts[summary.PaymentMethodID][i] = methodAmounts[summary.PaymentMethodID][i].Add(summary.TotalAmount)
+		}
+	}
+	for id, method := range methods {
+		amounts := methodAmounts[id]
+		method.ComparisonDelta = newDelta(amounts[0], amounts[1], amounts[2])
+		comparison.PaymentMethods = append(comparison.PaymentMethods, *method)
+	}
+	sort.Slice(comparison.PaymentMethods, func(i, j int) bool {
+		a, b := comparison.PaymentMethods[i], comparison.PaymentMethods[j]
+		if !a.Amount.Equal(b.Amount) {
+			return a.Amount.GreaterThan(b.Amount)
+		}
+		if !a.CompareAmount.Equal(b.CompareAmount) {
+			return a.CompareAmount.GreaterThan(b.CompareAmount)
+		}
+		return a.PaymentMethodID < b.PaymentMethodID
+	})
+
+	comparison.Total = newDelta(totals[0], totals[1], totals[2])
+	return comparison
+}
+
+func newComparisonDelta(amount, compareAmount, monthlyAverage, periodAverage money.Decimal) ComparisonDelta {
+	monthlyAverage = monthlyAverage.RoundToCurrency(money.DefaultCurrency)
+	periodAverage = periodAverage.RoundToCurrency(money.DefaultCurrency)
+	return ComparisonDelta{
+		Amount:               amount,
+		CompareAmount:        compareAmount,
+		Change:               amount.Sub(compareAmount),
+		ChangePercent:        changePercent(amount, compareAmount),
+		MonthlyAverage:       monthlyAverage,
+		PeriodAverage:        periodAverage,
+		AverageChange:        amount.Sub(periodAverage),
+		AverageChangePercent: changePercent(amount, periodAverage),
+	}
+}
+
+func changePercent(amount, base money.Decimal) *float64 {
+	if base.IsZero() {
+		return nil
+	}
+	percent := amount.Sub(base).Div(base.Abs()).Mul(money.FromInt(100)).Round(2).Float64()
+	return &percent
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPreviousPeriod(t *testing.T) {
+	tests := []struct {
+		name                       string
+		start, end                 time.Time
+		previousStart, previousEnd time.Time
+	}{
+		{"month", date(2026, time.October, 1), date(2026, time.October, 31), date(2026, time.September, 1), date(2026, time.September, 30)},
+		{"longer month after February", date(2026, time.March, 1), date(2026, time.March, 31), date(2026, time.February, 1), date(2026, time.February, 28)},
+		{"shorter month after a longer one", date(2026, time.February, 1), date(2026, time.February, 28), date(2026, time.January, 1), date(2026, time.January, 31)},
+		{"month to date", date(2026, time.October, 1), date(2026, time.October, 16), date(2026, time.September, 1), date(2026, time.September, 16)},
+		{"month to date past the end of the previous month", date(2026, time.March, 1), date(2026, time.March, 30), date(2026, time.February, 1), date(2026, time.February, 28)},
+		{"quarter across a year", date(2026, time.January, 1), date(2026, time.March, 31), date(2025, time.October, 1), date(2025, time.December, 31)},
+		{"week", date(2026, time.October, 12), date(2026, time.October, 18), date(2026, time.October, 5), date(2026, time.October, 11)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previousStart, previousEnd := PreviousPeriod(tt.start, tt.end)
+			assert.Equal(t, tt.previousStart, previousStart)
+			assert.Equal(t, tt.previousEnd, previousEnd)
+		})
+	}
+}
+
+func TestTransactionComparisonOptions_Normalize(t *testing.T) {
+	options := TransactionComparisonOptions{}
+	assert.NoError(t, options.Normalize(time.Date(2026, time.October, 16, 18, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, "expense", options.Type)
+	assert.Equal(t, date(2026, time.October, 1), options.StartDate)
+	// month to date, compared with the same days of the previous month
+	assert.Equal(t, date(2026, time.October, 16), options.EndDate)
+	assert.Equal(t, date(2026, time.September, 1), options.CompareStartDate)
+	assert.Equal(t, date(2026, time.September, 16), options.CompareEndDate)
+	assert.Equal(t, DefaultComparisonAverageMonths, options.AverageMonths)
+
+	averageStart, averageEnd := options.AveragePeriod()
+	assert.Equal(t, date(2025, time.October, 1), averageStart)
+	assert.Equal(t, date(2026, time.September, 30), averageEnd)
+
+	for _, invalid := range []TransactionComparisonOptions{
+		{Type: "transfer"},
+		{StartDate: date(2026, time.October, 1)},
+		{StartDate: date(2026, time.October, 2), EndDate: date(2026, time.October, 1)},
+		{CompareEndDate: date(2026, time.September, 1)},
+		{AverageMonths: MaxComparisonAverageMonths + 1},
+	} {
+		assert.Error(t, invalid.Normalize(date(2026, time.October, 16)), "%+v", invalid)
+	}
+}
+
+func TestNewTransactionComparison(t *testing.T) {
+	options := TransactionComparisonOptions{StartDate: date(2026, time.October, 1), EndDate: date(2026, time.October, 31)}
+	assert.NoError(t, options.Normalize(date(2026, time.October, 16)))
+
+	current := TransactionComparisonTotals{
+		Categories: []TransactionByCategorySummary{
+			{CategoryID: 9, CategoryName: "Groceries", TotalAmount: money.MustParse("600")},
+			{CategoryID: 17, CategoryName: "Housing", TotalAmount: money.MustParse("2000")},
+		},
+		PaymentMethods: []TransactionByPaymentMethodSummary{
+			{PaymentMethodID: 1, PaymentMethodName: "Card", TotalAmount: money.MustParse("2600")},
+		},
+	}
+	compared := TransactionComparisonTotals{
+		Categories: []TransactionByCategorySummary{
+			{CategoryID: 9, CategoryName: "Groceries", TotalAmount: money.MustParse("500")},
+			{CategoryID: 30, CategoryName: "Investments", TotalAmount: money.MustParse("100")},
+		},
+		PaymentMethods: []TransactionByPaymentMethodSummary{
+			{PaymentMethodID: 1, PaymentMethodName: "Card", TotalAmount: money.MustParse("400")},
+			{PaymentMethodID: 2, PaymentMethodName: "Cash", TotalAmount: money.MustParse("200")},
+		},
+	}
+	average := TransactionComparisonTotals{
+		Categories: []TransactionByCategorySummary{
+			{CategoryID: 9, CategoryName: "Groceries", TotalAmount: money.MustParse("5400")},
+			{CategoryID: 17, CategoryName: "Housing", TotalAmount: money.MustParse("24000")},
+		},
+	}
+
+	comparison := NewTransactionComparison(options, current, compared, average)
+
+	percent := func(value float64) *float64 { return &value }
+	assert.Equal(t, "expense", comparison.Type)
+	assert.Equal(t, ComparisonPeriod{StartDate: date(2025, time.October, 1), EndDate: date(2026, time.September, 30)}, comparison.AveragePeriod)
+	assert.Equal(t, ComparisonDelta{
+		Amount: money.MustParse("2600"), CompareAmount: money.MustParse("600"),
+		Change: money.MustParse("2000"), ChangePercent: percent(333.33),
+		MonthlyAverage: money.MustParse("2450"), PeriodAverage: money.MustParse("2450"),
+		AverageChange: money.MustParse("150"), AverageChangePercent: percent(6.12),
+	}, comparison.Total)
+
+	assert.Equal(t, []CategoryComparison{
+		{CategoryID: 17, CategoryName: "Housing", ComparisonDelta: ComparisonDelta{
+			Amount: money.MustParse("2000"), Change: money.MustParse("2000"),
+			MonthlyAverage: money.MustParse("2000"), PeriodAverage: money.MustParse("2000"), AverageChangePercent: percent(0),
+		}},
+		{CategoryID: 9, CategoryName: "Groceries", ComparisonDelta: ComparisonDelta{
+			Amount: money.MustParse("600"), CompareAmount: money.MustParse("500"),
+			Change: money.MustParse("100"), ChangePercent: percent(20),
+			MonthlyAverage: money.MustParse("450"), PeriodAverage: money.MustParse("450"),
+			AverageChange: money.MustParse("150"), AverageChangePercent: percent(33.33),
+		}},
+		{CategoryID: 30, CategoryName: "Investments", ComparisonDelta: ComparisonDelta{
+			CompareAmount: money.MustParse("100"), Change: money.MustParse("-100"), ChangePercent: percent(-100),
+		}},
+	}, comparison.Categories)
+
+	assert.Equal(t, []PaymentMethodComparison{
+		{PaymentMethodID: 1, PaymentMethodName: "Card", ComparisonDelta: ComparisonDelta{
+			Amount: money.MustParse("2600"), CompareAmount: money.MustParse("400"),
+			Change: money.MustParse("2200"), ChangePercent: percent(550),
+			AverageChange: money.MustParse("2600"),
+		}},
+		{PaymentMethodID: 2, PaymentMethodName: "Cash", ComparisonDelta: ComparisonDelta{
+			CompareAmount: money.MustParse("200"), Change: money.MustParse("-200"), ChangePercent: percent(-100),
+		}},
+	}, comparison.PaymentMethods)
+
+	// the first 16 days of October are compared with 16 of the 365 days of the average
+	monthToDate := TransactionComparisonOptions{}
+	assert.NoError(t, monthToDate.Normalize(date(2026, time.October, 16)))
+	comparison = NewTransactionComparison(monthToDate, current, compared, average)
+	assert.Equal(t, money.MustParse("2450"), comparison.Total.MonthlyAverage)
+	assert.Equal(t, money.MustParse("1288.77"), comparison.Total.PeriodAverage)
+	assert.Equal(t, money.MustParse("1311.23"), comparison.Total.AverageChange)
+	assert.Equal(t, 101.74, *comparison.Total.AverageChangePercent)
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+type TransactionSortField string
+
+const (
+	TransactionSortDate   TransactionSortField = "date"
+	TransactionSortAmount TransactionSortField = "amount"
+	TransactionSortName   TransactionSortField = "name"
+)
+
+const (
+	DefaultTransactionSearchLimit = 20
+	MaxTransactionSearchLimit     = 200
+)
+
+// TransactionSearch filters, sorts and pages the transactions of a user. Empty filters match everything, ID lists match
+// transactions having any of the IDs. Transactions with the same sort value are ordered by ID, so that a page never
+// repeats or skips rows. Pages are fetched with the NextCursor of the previous page, Page (offset based) is kept for
+// older clients and ignored when a Cursor is given.
+type TransactionSearch struct {
+	Type                  string
+	StartDate             time.Time
+	EndDate               time.Time
+	PredefinedCategoryIDs []int // matches split lines too
+	UserCategoryIDs       []int // matches split lines too
+	PaymentMethodIDs      []int
+	PaymentSourceIDs      []int // matches both ends of transfers
+	TagIDs                []int
+	MinAmount             *money.Decimal
+	MaxAmount             *money.Decimal
+	// Text is searched for in the name and description, it supports the web search syntax: "quoted phrases", or, -word.
+	Text string
+
+	SortBy    TransactionSortField
+	Ascending bool
+	Limit     int
+	Page      int
+	Cursor    string
+}
+
+// TransactionPage is a page of search results. NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions []PersonalTransaction `json:"data"`
+	TotalCount   int                   `json:"total_count"`
+	NextCursor   string                `json:"next_cursor"`
+}
+
+// TransactionCursor points at the last transaction of a page, it is handed out to clients as an opaque string.
+type TransactionCursor struct {
+	SortBy    TransactionSortField `json:"s"`
+	Ascending bool                 `json:"a"`
+	Value     string               `json:"v"`
+	ID        string               `json:"id"`
+}
+
+// Validate checks the search and fills in the default sort and limit.
+func (s *TransactionSearch) Validate() error {
+	if !IsValidTransactionType(s.Type) {
+		return errors.NewValidationError("Type must be either 'income', 'expense' or 'transfer'")
+	}
+
+	if s.EndDate.Before(s.StartDate) {
+		return errors.NewValidationError("End date must not be before start date")
+	}
+
+	if s.MinAmount != nil && s.MaxAmount != nil && s.MinAmount.GreaterThan(*s.MaxAmount) {
+		return errors.NewValidationError("Minimum amount must not be greater than maximum amount")
+	}
+
+	if len(s.Text) > 200 {
+		return errors.NewValidationError("Search text must be at most 200 characters long")
+	}
+
+	if s.SortBy == "" {
+		s.SortBy = TransactionSortDate
+	}
+	switch s.SortBy {
+	case TransactionSortDate, TransactionSortAmount, TransactionSortName:
+	default:
+		return errors.NewValidationError("Sort must be one of 'date', 'amount' or 'name'")
+	}
+
+	if s.Limit == 0 {
+		s.Limit = DefaultTransactionSearchLimit
+	}
+	if s.Limit < 0 || s.Limit > MaxTransactionSearchLimit {
+		return errors.NewValidationError(fmt.Sprintf("Limit must be between 1 and %d", MaxTransactionSearchLimit))
+	}
+	if s.Page <= 0 {
+		s.Page = 1
+	}
+
+	if s.Cursor != "" {
+		if _, err := s.DecodeCursor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeCursor returns the cursor of the search, nil if it has none. The cursor must come from a search with the same
+// sort order.
+func (s *TransactionSearch) DecodeCursor() (*TransactionCursor, error) {
+	if s.Cursor == "" {
+		return nil, nil
+	}
+	invalid := errors.NewValidationError("Invalid cursor")
+	data, err := base64.RawURLEncoding.DecodeString(s.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor TransactionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, invalid
+	}
+	if cursor.SortBy != s.SortBy || cursor.Ascending != s.Ascending {
+		return nil, errors.NewValidationError("Cursor doesn't match the sort order of the search")
+	}
+	return &cursor, nil
+}
+
+// NextCursor returns the cursor of the page ending with the transaction.
+func (s *TransactionSearch) NextCursor(last PersonalTransaction) string {
+	data, _ := json.Marshal(TransactionCursor{SortBy: s.SortBy, Ascending: s.Ascending, Value: s.SortValue(last), ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortValue is the value of the sort field of the transaction, as stored in cursors.
+func (s *TransactionSearch) SortValue(transaction PersonalTransaction) string {
+	switch s.SortBy {
+	case TransactionSortAmount:
+		return transaction.Amount.String()
+	case TransactionSortName:
+		return transaction.Name
+	default:
+		return transaction.Date.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"time"
+)
+
+// TransactionPeriodTotal is the total of one transaction type in the part of a week which falls into a month.
+type TransactionPeriodTotal struct {
+	MonthStart time.Time
+	WeekStart  time.Time
+	Type       string
+	Total      money.Decimal
+}
+
+// TransactionSummary holds the income and expense totals per year, month and week, transfers are left out. The periods
+// are ordered and only those with transactions are listed. A week crossing the end of a month is split between both
+// months, the two parts keep the start date of the week.
+type TransactionSummary struct {
+	StartDate    time.Time     `json:"start_date"`
+	EndDate      time.Time     `json:"end_date"`
+	Timezone     string        `json:"timezone"`
+	WeekStart    string        `json:"week_start"`
+	IncomeTotal  money.Decimal `json:"income_total"`
+	ExpenseTotal money.Decimal `json:"expense_total"`
+	Years        []YearSummary `json:"years"`
+}
+
+type YearSummary struct {
+	Year         int            `json:"year"`
+	IncomeTotal  money.Decimal  `json:"income_total"`
+	ExpenseTotal money.Decimal  `json:"expense_total"`
+	Months       []MonthSummary `json:"months"`
+}
+
+type MonthSummary struct {
+	Month        int           `json:"month"` // 1 for January
+	IncomeTotal  money.Decimal `json:"income_total"`
+	ExpenseTotal money.Decimal `json:"expense_total"`
+	Weeks        []WeekSummary `json:"weeks"`
+}
+
+type WeekSummary struct {
+	StartDate    time.Time     `json:"start_date"`
+	IncomeTotal  money.Decimal `json:"income_total"`
+	ExpenseTotal money.Decimal `json:"expense_total"`
+}
+
+// NewTransactionSummary nests the period totals, which must be ordered by month and week start.
+func NewTransactionSummary(settings FinanceSettings, startDate, endDate time.Time, totals []TransactionPeriodTotal) TransactionSummary {
+	summary := TransactionSummary{
+		StartDate: startDate, EndDate: endDate, Timezone: settings.Timezone, WeekStart: settings.WeekStart,
+		IncomeTotal: money.Zero, ExpenseTotal: money.Zero, Years: []YearSummary{},
+	}
+	for _, total := range totals {
+		if total.Type != string(TransactionTypeIncome) && total.Type != string(TransactionTypeExpense) {
+			continue
+		}
+		years := len(summary.Years)
+		if years == 0 || summary.Years[years-1].Year != total.MonthStart.Year() {
+			summary.Years = append(summary.Years, YearSummary{Year: total.MonthStart.Year(), Months: []MonthSummary{}})
+			years++
+		}
+		year := &summary.Years[years-1]
+
+		months := len(year.Months)
+		if months == 0 || year.Months[months-1].Month != int(total.MonthStart.Month()) {
+			year.Months = append(year.Months, MonthSummary{Month: int(total.MonthStart.Month()), Weeks: []WeekSummary{}})
+			months++
+		}
+		month := &year.Months[months-1]
+
+		weeks := len(month.Weeks)
+		if weeks == 0 || !month.Weeks[weeks-1].StartDate.Equal(total.WeekStart) {
+			month.Weeks = append(month.Weeks, WeekSummary{StartDate: total.WeekStart})
+			weeks++
+		}
+		week := &month.Weeks[weeks-1]
+
+		if total.Type == string(TransactionTypeIncome) {
+			summary.IncomeTotal = summary.IncomeTotal.Add(total.Total)
+			year.IncomeTotal = year.IncomeTotal.Add(total.Total)
+			month.IncomeTotal = month.IncomeTotal.Add(total.Total)
+			week.IncomeTotal = week.IncomeTotal.Add(total.Total)
+		} else {
+			summary.ExpenseTotal = summary.ExpenseTotal.Add(total.Total)
+			year.ExpenseTotal = year.ExpenseTotal.Add(total.Total)
+			month.ExpenseTotal = month.ExpenseTotal.Add(total.Total)
+			week.ExpenseTotal = week.ExpenseTotal.Add(total.Total)
+		}
+	}
+	return summary
+}
+
+// Year returns the summary of the year, nil if it had no transactions.
+func (s *TransactionSummary) Year(year int) *YearSummary {
+	for i := range s.Years {
+		if s.Years[i].Year == year {
+			return &s.Years[i]
+		}
+	}
+	return nil
+}
+
+// Month returns the summary of the month, nil if it had no transactions.
+func (y *YearSummary) Month(month time.Month) *MonthSummary {
+	for i := range y.Months {
+		if y.Months[i].Month == int(month) {
+			return &y.Months[i]
+		}
+	}
+	return nil
+}
+
+// WeekStartOf returns the first day of the week the date falls into, for weeks starting on firstDay.
+func WeekStartOf(date time.Time, firstDay time.Weekday) time.Time {
+	offset := (int(date.Weekday()) - int(firstDay) + 7) % 7
+	return TruncateToDate(date).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFinanceSettings_Validate(t *testing.T) {
+	settings := FinanceSettings{BaseCurrency: " eur", Timezone: " America/New_York ", WeekStart: "Sunday"}
+	assert.NoError(t, settings.Validate())
+	assert.Equal(t, "EUR", settings.BaseCurrency)
+	assert.Equal(t, "America/New_York", settings.Timezone)
+	assert.Equal(t, time.Sunday, settings.FirstDayOfWeek())
+
+	for _, invalid := range []FinanceSettings{
+		{BaseCurrency: "EURO", Timezone: "UTC", WeekStart: "monday"},
+		{BaseCurrency: "EUR", Timezone: "", WeekStart: "monday"},
+		{BaseCurrency: "EUR", Timezone: "Local", WeekStart: "monday"},
+		{BaseCurrency: "EUR", Timezone: "Europe/Atlantis", WeekStart: "monday"},
+		{BaseCurrency: "EUR", Timezone: "UTC", WeekStart: "mon"},
+	} {
+		assert.Error(t, invalid.Validate(), "%+v", invalid)
+	}
+}
+
+func TestFinanceSettings_Today(t *testing.T) {
+	// 23:30 UTC on New Year's Eve is already the next year in Warsaw and still the same day in New York
+	now := time.Date(2025, time.December, 31, 23, 30, 0, 0, time.UTC)
+
+	warsaw := FinanceSettings{Timezone: "Europe/Warsaw"}
+	newYork := FinanceSettings{Timezone: "America/New_York"}
+	assert.Equal(t, date(2026, time.January, 1), warsaw.Today(now))
+	assert.Equal(t, date(2025, time.December, 31), newYork.Today(now))
+}
+
+func TestWeekStartOf(t *testing.T) {
+	// Thursday
+	day := date(2026, time.January, 1)
+
+	assert.Equal(t, date(2025, time.December, 29), WeekStartOf(day, time.Monday))
+	assert.Equal(t, date(2025, time.December, 28), WeekStartOf(day, time.Sunday))
+	assert.Equal(t, day, WeekStartOf(day, time.Thursday))
+}
+
+func TestNewTransactionSummary_SplitsWeekAcrossYears(t *testing.T) {
+	settings := FinanceSettings{Timezone: "Europe/Warsaw", WeekStart: "sunday"}
+	week := date(2025, time.December, 28)
+	totals := []TransactionPeriodTotal{
+		{MonthStart: date(2025, time.December, 1), WeekStart: date(2025, time.December, 21), Type: "expense", Total: money.MustParse("40")},
+		{MonthStart: date(2025, time.December, 1), WeekStart: week, Type: "expense", Total: money.MustParse("100")},
+		{MonthStart: date(2025, time.December, 1), WeekStart: week, Type: "income", Total: money.MustParse("2500")},
+		{MonthStart: date(2026, time.January, 1), WeekStart: week, Type: "expense", Total: money.MustParse("60.50")},
+		{MonthStart: date(2026, time.January, 1), WeekStart: week, Type: "transfer", Total: money.MustParse("999")},
+	}
+
+	summary := NewTransactionSummary(settings, date(2025, time.December, 1), date(2026, time.January, 31), totals)
+
+	assert.Equal(t, "Europe/Warsaw", summary.Timezone)
+	assert.Equal(t, "sunday", summary.WeekStart)
+	assert.Equal(t, money.MustParse("2500"), summary.IncomeTotal)
+	assert.Equal(t, money.MustParse("200.50"), summary.ExpenseTotal)
+	if assert.Len(t, summary.Years, 2) {
+		assert.Equal(t, 2025, summary.Years[0].Year)
+		assert.Equal(t, 2026, summary.Years[1].Year)
+	}
+
+	december := summary.Year(2025).Month(time.December)
+	if assert.NotNil(t, december) && assert.Len(t, december.Weeks, 2) {
+		assert.Equal(t, money.MustParse("140"), december.ExpenseTotal)
+		assert.Equal(t, week, december.Weeks[1].StartDate)
+		assert.Equal(t, money.MustParse("100"), december.Weeks[1].ExpenseTotal)
+		assert.Equal(t, money.MustParse("2500"), december.Weeks[1].IncomeTotal)
+	}
+
+	january := summary.Year(2026).Month(time.January)
+	if assert.NotNil(t, january) && assert.Len(t, january.Weeks, 1) {
+		assert.Equal(t, week, january.Weeks[0].StartDate)
+		assert.Equal(t, money.MustParse("60.50"), january.Weeks[0].ExpenseTotal)
+		assert.Equal(t, money.Zero, january.Weeks[0].IncomeTotal)
+	}
+	assert.Nil(t, summary.Year(2026).Month(time.February))
+}
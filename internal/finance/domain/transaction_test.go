@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func splitTransaction(amounts ...string) PersonalTransaction {
+	transaction := PersonalTransaction{Name: "Supermarket", Amount: money.MustParse("100"), Type: "expense", Date: date(2024, 3, 1),
+		PredefinedCategoryID: 9, PaymentMethodID: 1}
+	for _, amount := range amounts {
+		transaction.Splits = append(transaction.Splits, TransactionSplit{PredefinedCategoryID: 9, Amount: money.MustParse(amount)})
+	}
+	return transaction
+}
+
+func TestPersonalTransaction_ValidateSplits(t *testing.T) {
+	transaction := splitTransaction("70.10", "29.90")
+	assert.NoError(t, transaction.Validate())
+	assert.Len(t, transaction.Lines(), 2)
+
+	transaction = splitTransaction("70.10", "29.89")
+	assert.EqualError(t, transaction.Validate(), "Split lines must sum up to the transaction amount 100.00, got 99.99")
+
+	transaction = splitTransaction("100.01", "-0.01")
+	assert.Error(t, transaction.Validate())
+
+	transaction = splitTransaction("50", "50")
+	transaction.Splits[1].PredefinedCategoryID = 0
+	assert.Error(t, transaction.Validate())
+}
+
+func TestPersonalTransaction_ValidateAmount(t *testing.T) {
+	transaction := splitTransaction()
+	transaction.Amount = money.MustParse("99999999.99")
+	assert.NoError(t, transaction.Validate())
+
+	transaction.Amount = money.MustParse("100000000")
+	assert.EqualError(t, transaction.Validate(), "Amount must be less than 100000000")
+
+	transaction = splitTransaction("100000000", "-99999900")
+	assert.Error(t, transaction.Validate())
+}
+
+func TestPersonalTransaction_LinesWithoutSplits(t *testing.T) {
+	transaction := splitTransaction()
+	assert.NoError(t, transaction.Validate())
+	assert.Equal(t, []TransactionSplit{{PredefinedCategoryID: 9, Amount: money.MustParse("100")}}, transaction.Lines())
+}
+
+func TestPersonalTransaction_ValidateTransfer(t *testing.T) {
+	checking, savings := 1, 2
+	transfer := PersonalTransaction{Name: "To savings", Amount: money.MustParse("100"), Type: "transfer", Date: date(2024, 3, 1),
+		PaymentMethodID: 4, PaymentSourceID: &checking, DestinationPaymentSourceID: &savings}
+	assert.NoError(t, transfer.Validate())
+
+	sameSource := transfer
+	sameSource.DestinationPaymentSourceID = &checking
+	assert.Error(t, sameSource.Validate())
+
+	withCategory := transfer
+	withCategory.PredefinedCategoryID = 9
+	assert.Error(t, withCategory.Validate())
+
+	expense := splitTransaction()
+	expense.DestinationPaymentSourceID = &savings
+	assert.Error(t, expense.Validate())
+}
+
+func TestPersonalTransaction_BalanceChange(t *testing.T) {
+	checking, savings := 1, 2
+	rate := money.MustParse("0.2315")
+	transfer := PersonalTransaction{Amount: money.MustParse("100"), Type: "transfer", PaymentSourceID: &checking,
+		DestinationPaymentSourceID: &savings, FXRate: &rate}
+
+	balanceChange := func(transaction PersonalTransaction, paymentSourceID int) money.Decimal {
+		change, err := transaction.BalanceChange(paymentSourceID)
+		assert.NoError(t, err)
+		return change
+	}
+	assert.Equal(t, money.MustParse("-100"), balanceChange(transfer, checking))
+	assert.Equal(t, money.MustParse("23.15"), balanceChange(transfer, savings))
+	assert.True(t, balanceChange(transfer, 3).IsZero())
+
+	income := PersonalTransaction{Amount: money.MustParse("50"), Type: "income", PaymentSourceID: &savings}
+	assert.Equal(t, money.MustParse("50"), balanceChange(income, savings))
+
+	// rates which don't pass Validate fail instead of panicking
+	rate = money.MustParse("1e20")
+	transfer.Amount = money.MustParse("1e20")
+	_, err := transfer.BalanceChange(savings)
+	assert.Error(t, err)
+}
+
+func TestPersonalTransaction_ValidateFXRate(t *testing.T) {
+	checking, savings := 1, 2
+	transfer := PersonalTransaction{Name: "To savings", Amount: money.MustParse("100"), Type: "transfer", PaymentMethodID: 1,
+		PaymentSourceID: &checking, DestinationPaymentSourceID: &savings, Date: date(2024, 3, 1)}
+
+	for rate, valid := range map[string]bool{
+		"0.2315":          true,
+		"999999999.99999": true,
+		"0":               false,
+		"-1":              false,
+		"10000000000":     false,
+		// 100 converted at this rate doesn't fit the amount column
+		"1000000": false,
+	} {
+		fxRate := money.MustParse(rate)
+		transfer.FXRate = &fxRate
+		if valid {
+			transfer.Amount = money.MustParse("0.01")
+			assert.NoError(t, transfer.Validate(), rate)
+		} else {
+			transfer.Amount = money.MustParse("100")
+			assert.Error(t, transfer.Validate(), rate)
+		}
+	}
+}
+
+func TestTransactionSearch_Cursor(t *testing.T) {
+	search := TransactionSearch{Type: "expense", StartDate: date(2024, 3, 1), EndDate: date(2024, 3, 31), SortBy: TransactionSortAmount}
+	assert.NoError(t, search.Validate())
+	assert.Equal(t, DefaultTransactionSearchLimit, search.Limit)
+
+	search.Cursor = search.NextCursor(PersonalTransaction{ID: "f1b2", Amount: money.MustParse("12.5")})
+	assert.NoError(t, search.Validate())
+	cursor, err := search.DecodeCursor()
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionCursor{SortBy: TransactionSortAmount, Value: "12.50", ID: "f1b2"}, *cursor)
+
+	search.Ascending = true
+	assert.EqualError(t, search.Validate(), "Cursor doesn't match the sort order of the search")
+
+	search.Cursor = "not a cursor"
+	assert.EqualError(t, search.Validate(), "Invalid cursor")
+
+	search = TransactionSearch{StartDate: date(2024, 3, 1), EndDate: date(2024, 3, 31), SortBy: "category"}
+	assert.Error(t, search.Validate())
+}
@@ -32,6 +32,37 @@ var ErrInvalidUserCategory = NewValidationError("Invalid personal user category
 var ErrInvalidPredefinedCategory = NewValidationError("Invalid predefined category ID")
 var ErrInvalidPaymentSource = NewValidationError("Invalid payment source ID")
 var ErrInvalidPaymentMethod = NewValidationError("Invalid payment method ID")
+var ErrInvalidTag = NewValidationError("Invalid tag ID")
+
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrUserCategoryNotFound = errors.New("user category not found")
+var ErrUserCategoryNameTaken = errors.New("user category with this name already exists")
+var ErrPaymentSourceNotFound = errors.New("payment source not found")
+var ErrParentCategoryTypeMismatch = NewValidationError("User category type must match the type of its parent category")
+var ErrRecurringRuleNotFound = errors.New("recurring rule not found")
+var ErrNotAnOccurrence = NewValidationError("The recurring rule has no occurrence on this date")
+var ErrBudgetNotFound = errors.New("budget not found")
+var ErrBudgetAlreadyExists = errors.New("budget for this category and period already exists")
+var ErrImportProfileNotFound = errors.New("import profile not found")
+var ErrImportProfileNameTaken = errors.New("import profile with this name already exists")
+var ErrTooManyImportedRows = NewValidationError("The statement has too many rows, split it into smaller files")
+var ErrUnsupportedStatementFormat = errors.New("unsupported statement format")
+var ErrNothingToImport = NewValidationError("The statement has no transactions to import")
+var ErrTransactionReconciled = errors.New("transaction is reconciled")
+var ErrExternalIDTaken = errors.New("transaction with this external ID already exists")
+var ErrOccurrenceAlreadyBooked = NewValidationError("This occurrence has already been booked, edit the transaction instead")
+var ErrCategorizationRuleNotFound = errors.New("categorization rule not found")
+var ErrTagNotFound = errors.New("tag not found")
+var ErrTagNameTaken = errors.New("tag with this name already exists")
+var ErrTooManyCategorizationRules = NewValidationError("Too many categorization rules, remove some of them first")
+var ErrAttachmentNotFound = errors.New("attachment not found")
+var ErrAttachmentTooLarge = NewValidationError("The file is too large, attachments can be up to 10MB")
+var ErrEmptyAttachment = NewValidationError("The file is empty")
+var ErrUnsupportedAttachmentType = NewValidationError("Unsupported file type, only PDF, JPEG, PNG, GIF and WebP files can be attached")
+var ErrTooManyAttachments = NewValidationError("Too many attachments, remove some of them first")
+var ErrSavingsGoalNotFound = errors.New("savings goal not found")
+var ErrInvalidPortfolio = NewValidationError("Invalid portfolio ID")
+var ErrLoanNotFound = errors.New("loan not found")
 
 type ValidationErrors struct {
 	Errors []error
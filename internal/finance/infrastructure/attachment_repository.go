@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
+
+const attachmentColumns = `id, transaction_id, user_id, file_name, content_type, size, storage_key, created_at`
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) FindByTransaction(transactionID string, userID string) ([]domain.Attachment, error) {
+	rows, err := r.db.Query(`
+		SELECT `+attachmentColumns+`
+		FROM personal_transaction_attachments
+		WHERE transaction_id = $1 AND user_id = $2
+		ORDER BY created_at, id`, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []domain.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *AttachmentRepository) FindByID(attachmentID string, userID string) (*domain.Attachment, error) {
+	row := r.db.QueryRow(`
+		SELECT `+attachmentColumns+`
+		FROM personal_transaction_attachments
+		WHERE id = $1 AND user_id = $2`, attachmentID, userID)
+	return scanAttachment(row)
+}
+
+func (r *AttachmentRepository) CountByTransaction(transactionID string, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM personal_transaction_attachments WHERE transaction_id = $1 AND user_id = $2`,
+		transactionID, userID).Scan(&count)
+	return count, err
+}
+
+// Create locks the transaction of the attachment while its attachments are counted, so that concurrent uploads can't
+// exceed the limit together and an upload can't outlive the deletion of the transaction.
+func (r *AttachmentRepository) Create(attachment *domain.Attachment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := createAttachment(tx, attachment); err != nil {
+		safeRollback(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+func createAttachment(tx *sql.Tx, attachment *domain.Attachment) error {
+	var id string
+	err := tx.QueryRow(`SELECT id FROM personal_transactions WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		attachment.TransactionID, attachment.UserID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return financeErrors.ErrTransactionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM personal_transaction_attachments WHERE transaction_id = $1`, attachment.TransactionID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= domain.MaxTransactionAttachments {
+		return financeErrors.ErrTooManyAttachments
+	}
+
+	return tx.QueryRow(`
+		INSERT INTO personal_transaction_attachments (id, transaction_id, user_id, file_name, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		attachment.ID, attachment.TransactionID, attachment.UserID, attachment.FileName, attachment.ContentType, attachment.Size,
+		attachment.StorageKey,
+	).Scan(&attachment.CreatedAt)
+}
+
+func (r *AttachmentRepository) Delete(attachmentID string, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM personal_transaction_attachments WHERE id = $1 AND user_id = $2`, attachmentID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanAttachment(row interface{ Scan(dest ...any) error }) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := row.Scan(&attachment.ID, &attachment.TransactionID, &attachment.UserID, &attachment.FileName, &attachment.ContentType,
+		&attachment.Size, &attachment.StorageKey, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,124 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type BudgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+const budgetSelect = `
+		SELECT b.id, b.user_id, b.predefined_category_id, b.user_category_id, COALESCE(uc.name, pc.name, ''),
+		       b.period, b.amount, b.rollover, b.start_date
+		FROM budgets b
+		LEFT JOIN predefined_categories pc ON pc.id = b.predefined_category_id
+		LEFT JOIN user_categories uc ON uc.id = b.user_category_id`
+
+func (r *BudgetRepository) FindByUser(userID string) ([]domain.Budget, error) {
+	rows, err := r.db.Query(budgetSelect+` WHERE b.user_id = $1 ORDER BY b.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []domain.Budget
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, *budget)
+	}
+	return budgets, rows.Err()
+}
+
+func (r *BudgetRepository) FindByID(budgetID int, userID string) (*domain.Budget, error) {
+	row := r.db.QueryRow(budgetSelect+` WHERE b.id = $1 AND b.user_id = $2`, budgetID, userID)
+	return scanBudget(row)
+}
+
+func scanBudget(row interface{ Scan(dest ...any) error }) (*domain.Budget, error) {
+	var budget domain.Budget
+	var predefinedCategoryID, userCategoryID sql.NullInt32
+	if err := row.Scan(
+		&budget.ID, &budget.UserID, &predefinedCategoryID, &userCategoryID, &budget.CategoryName,
+		&budget.Period, &budget.Amount, &budget.Rollover, &budget.StartDate,
+	); err != nil {
+		return nil, err
+	}
+	if predefinedCategoryID.Valid {
+		value := int(predefinedCategoryID.Int32)
+		budget.PredefinedCategoryID = &value
+	}
+	if userCategoryID.Valid {
+		value := int(userCategoryID.Int32)
+		budget.UserCategoryID = &value
+	}
+	return &budget, nil
+}
+
+// DoesBudgetExist checks whether the user already has another budget for the same category and period.
+func (r *BudgetRepository) DoesBudgetExist(budget domain.Budget) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM budgets
+			WHERE user_id = $1
+			  AND predefined_category_id IS NOT DISTINCT FROM $2
+			  AND user_category_id IS NOT DISTINCT FROM $3
+			  AND period = $4
+			  AND id <> $5)`
+	err := r.db.QueryRow(query, budget.UserID, budget.PredefinedCategoryID, budget.UserCategoryID, budget.Period, budget.ID).Scan(&exists)
+	return exists, err
+}
+
+func (r *BudgetRepository) Create(budget *domain.Budget) error {
+	query := `
+		INSERT INTO budgets (user_id, predefined_category_id, user_category_id, period, amount, rollover, start_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	return r.db.QueryRow(query, budget.UserID, budget.PredefinedCategoryID, budget.UserCategoryID, budget.Period,
+		budget.Amount, budget.Rollover, budget.StartDate).Scan(&budget.ID)
+}
+
+func (r *BudgetRepository) Update(budget domain.Budget) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE budgets
+		SET predefined_category_id = $1, user_category_id = $2, period = $3, amount = $4, rollover = $5, start_date = $6
+		WHERE id = $7 AND user_id = $8`,
+		budget.PredefinedCategoryID, budget.UserCategoryID, budget.Period, budget.Amount, budget.Rollover, budget.StartDate,
+		budget.ID, budget.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *BudgetRepository) Delete(budgetID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM budgets WHERE id = $1 AND user_id = $2`, budgetID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RecordAlert stores that the threshold alert of the period was sent. It returns false when it had already been recorded.
+func (r *BudgetRepository) RecordAlert(budgetID int, periodStart time.Time, threshold int) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO budget_alerts (budget_id, period_start, threshold)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (budget_id, period_start, threshold) DO NOTHING`,
+		budgetID, periodStart, threshold)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
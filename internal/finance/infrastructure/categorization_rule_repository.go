@@ -0,0 +1,187 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type CategorizationRuleRepository struct {
+	db *sql.DB
+}
+
+func NewCategorizationRuleRepository(db *sql.DB) *CategorizationRuleRepository {
+	return &CategorizationRuleRepository{db: db}
+}
+
+const categorizationRuleSelect = `
+		SELECT id, user_id, name, priority, match_field, match_type, pattern, min_amount, max_amount,
+		       COALESCE(transaction_type, ''), predefined_category_id, user_category_id, payment_method_id
+		FROM categorization_rules`
+
+func (r *CategorizationRuleRepository) FindByUser(userID string) ([]domain.CategorizationRule, error) {
+	rows, err := r.db.Query(categorizationRuleSelect+` WHERE user_id = $1 ORDER BY priority, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []domain.CategorizationRule
+	for rows.Next() {
+		rule, err := scanCategorizationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, r.loadTags(rules)
+}
+
+func (r *CategorizationRuleRepository) FindByID(ruleID int, userID string) (*domain.CategorizationRule, error) {
+	row := r.db.QueryRow(categorizationRuleSelect+` WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	rule, err := scanCategorizationRule(row)
+	if err != nil {
+		return nil, err
+	}
+	rules := []domain.CategorizationRule{*rule}
+	if err := r.loadTags(rules); err != nil {
+		return nil, err
+	}
+	return &rules[0], nil
+}
+
+// loadTags fills in the tags the rules add, with a single query.
+func (r *CategorizationRuleRepository) loadTags(rules []domain.CategorizationRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	ids := make([]int, len(rules))
+	indexByID := make(map[int]int, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+		indexByID[rule.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT rule_id, tag_id
+		FROM categorization_rule_tags
+		WHERE rule_id = ANY($1)
+		ORDER BY tag_id
+		`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ruleID, tagID int
+		if err := rows.Scan(&ruleID, &tagID); err != nil {
+			return err
+		}
+		index := indexByID[ruleID]
+		rules[index].TagIDs = append(rules[index].TagIDs, tagID)
+	}
+	return rows.Err()
+}
+
+func insertRuleTags(tx *sql.Tx, rule domain.CategorizationRule) error {
+	for _, tagID := range rule.TagIDs {
+		if _, err := tx.Exec(`INSERT INTO categorization_rule_tags (rule_id, tag_id) VALUES ($1, $2)`, rule.ID, tagID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanCategorizationRule(row interface{ Scan(dest ...any) error }) (*domain.CategorizationRule, error) {
+	var rule domain.CategorizationRule
+	var predefinedCategoryID, userCategoryID, paymentMethodID sql.NullInt32
+	if err := row.Scan(
+		&rule.ID, &rule.UserID, &rule.Name, &rule.Priority, &rule.MatchField, &rule.MatchType, &rule.Pattern,
+		&rule.MinAmount, &rule.MaxAmount, &rule.TransactionType, &predefinedCategoryID, &userCategoryID, &paymentMethodID,
+	); err != nil {
+		return nil, err
+	}
+	if predefinedCategoryID.Valid {
+		value := int(predefinedCategoryID.Int32)
+		rule.PredefinedCategoryID = &value
+	}
+	if userCategoryID.Valid {
+		value := int(userCategoryID.Int32)
+		rule.UserCategoryID = &value
+	}
+	if paymentMethodID.Valid {
+		value := int(paymentMethodID.Int32)
+		rule.PaymentMethodID = &value
+	}
+	return &rule, nil
+}
+
+func (r *CategorizationRuleRepository) Create(rule *domain.CategorizationRule) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO categorization_rules (user_id, name, priority, match_field, match_type, pattern, min_amount, max_amount,
+		                                  transaction_type, predefined_category_id, user_category_id, payment_method_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12)
+		RETURNING id`
+	err = tx.QueryRow(query, rule.UserID, rule.Name, rule.Priority, rule.MatchField, rule.MatchType, rule.Pattern,
+		rule.MinAmount, rule.MaxAmount, rule.TransactionType, rule.PredefinedCategoryID, rule.UserCategoryID,
+		rule.PaymentMethodID).Scan(&rule.ID)
+	if err == nil {
+		err = insertRuleTags(tx, *rule)
+	}
+	if err != nil {
+		safeRollback(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces the rule together with the tags it adds.
+func (r *CategorizationRuleRepository) Update(rule domain.CategorizationRule) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	affected, err := updateCategorizationRule(tx, rule)
+	if err != nil {
+		safeRollback(tx)
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+func updateCategorizationRule(tx *sql.Tx, rule domain.CategorizationRule) (int64, error) {
+	result, err := tx.Exec(`
+		UPDATE categorization_rules
+		SET name = $1, priority = $2, match_field = $3, match_type = $4, pattern = $5, min_amount = $6, max_amount = $7,
+		    transaction_type = NULLIF($8, ''), predefined_category_id = $9, user_category_id = $10, payment_method_id = $11
+		WHERE id = $12 AND user_id = $13`,
+		rule.Name, rule.Priority, rule.MatchField, rule.MatchType, rule.Pattern, rule.MinAmount, rule.MaxAmount,
+		rule.TransactionType, rule.PredefinedCategoryID, rule.UserCategoryID, rule.PaymentMethodID, rule.ID, rule.UserID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM categorization_rule_tags WHERE rule_id = $1`, rule.ID); err != nil {
+		return 0, err
+	}
+	return affected, insertRuleTags(tx, rule)
+}
+
+func (r *CategorizationRuleRepository) Delete(ruleID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM categorization_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"database/sql"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"log"
 )
 
 type CategoryRepository struct {
@@ -53,8 +54,22 @@ func (r *CategoryRepository) FindPredefinedCategories(categoryType string) ([]do
 	return categories, nil
 }
 
+func (r *CategoryRepository) FindPredefinedCategoryByID(categoryID int) (*domain.PredefinedCategory, error) {
+	var category domain.PredefinedCategory
+	query := "SELECT id, name, type FROM predefined_categories WHERE id = $1"
+	err := r.db.QueryRow(query, categoryID).Scan(&category.ID, &category.Name, &category.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
 func (r *CategoryRepository) FindUserCategories(userID string) ([]domain.UserCategory, error) {
-	rows, err := r.db.Query("SELECT id FROM user_categories WHERE user_id = $1", userID)
+	rows, err := r.db.Query(`
+		SELECT id, name, user_id, parent_category_id, type, color, icon
+		FROM user_categories
+		WHERE user_id = $1
+		ORDER BY name`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -62,15 +77,104 @@ func (r *CategoryRepository) FindUserCategories(userID string) ([]domain.UserCat
 
 	var categories []domain.UserCategory
 	for rows.Next() {
-		var category domain.UserCategory
-		if err := rows.Scan(&category.ID); err != nil {
+		category, err := scanUserCategory(rows)
+		if err != nil {
 			return nil, err
 		}
-		categories = append(categories, category)
+		categories = append(categories, *category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 	return categories, nil
 }
 
+func (r *CategoryRepository) FindUserCategoryByID(categoryID int, userID string) (*domain.UserCategory, error) {
+	row := r.db.QueryRow(`
+		SELECT id, name, user_id, parent_category_id, type, color, icon
+		FROM user_categories
+		WHERE id = $1 AND user_id = $2`, categoryID, userID)
+	return scanUserCategory(row)
+}
+
+func scanUserCategory(row interface{ Scan(dest ...any) error }) (*domain.UserCategory, error) {
+	var category domain.UserCategory
+	var parentCategoryID sql.NullInt32
+	if err := row.Scan(&category.ID, &category.Name, &category.UserID, &parentCategoryID, &category.Type, &category.Color, &category.Icon); err != nil {
+		return nil, err
+	}
+	if parentCategoryID.Valid {
+		value := int(parentCategoryID.Int32)
+		category.ParentCategoryID = &value
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) DoesUserCategoryNameExist(name string, userID string, excludedID int) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM user_categories WHERE name = $1 AND user_id = $2 AND id <> $3)"
+	err := r.db.QueryRow(query, name, userID, excludedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *CategoryRepository) CreateUserCategory(category *domain.UserCategory) error {
+	query := `
+		INSERT INTO user_categories (name, user_id, parent_category_id, type, color, icon)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	return r.db.QueryRow(query, category.Name, category.UserID, category.ParentCategoryID, category.Type, category.Color, category.Icon).Scan(&category.ID)
+}
+
+func (r *CategoryRepository) UpdateUserCategory(category domain.UserCategory) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE user_categories
+		SET name = $1, parent_category_id = $2, type = $3, color = $4, icon = $5
+		WHERE id = $6 AND user_id = $7`,
+		category.Name, category.ParentCategoryID, category.Type, category.Color, category.Icon, category.ID, category.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteUserCategory removes the category and detaches it from the user's transactions, which keep their predefined category.
+func (r *CategoryRepository) DeleteUserCategory(categoryID int, userID string) (affected int64, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Error during transaction rollback: %v", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(`UPDATE personal_transactions SET user_category_id = NULL WHERE user_category_id = $1 AND user_id = $2`, categoryID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE personal_transaction_splits SET user_category_id = NULL
+		WHERE user_category_id = $1 AND transaction_id IN (SELECT id FROM personal_transactions WHERE user_id = $2)
+		`, categoryID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM user_categories WHERE id = $1 AND user_id = $2`, categoryID, userID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
 func (r *CategoryRepository) DoesPredefinedCategoryExistByID(categoryID int) (bool, error) {
 	var exists bool
 	query := "SELECT EXISTS(SELECT 1 FROM predefined_categories WHERE id = $1)"
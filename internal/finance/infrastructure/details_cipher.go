@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"os"
+	"strings"
+)
+
+const encryptedDetailPrefix = domain.EncryptedPaymentDetailPrefix
+
+// DetailsCipher encrypts sensitive payment source details with AES-256-GCM before they are stored in the JSONB column.
+type DetailsCipher struct {
+	aead cipher.AEAD
+}
+
+func NewDetailsCipher(key []byte) (*DetailsCipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("payment details key must be 32 bytes long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &DetailsCipher{aead: aead}, nil
+}
+
+// NewDetailsCipherFromEnv reads the base64 encoded key from PAYMENT_DETAILS_KEY.
+func NewDetailsCipherFromEnv() (*DetailsCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(os.Getenv("PAYMENT_DETAILS_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_DETAILS_KEY: %w", err)
+	}
+	return NewDetailsCipher(key)
+}
+
+func (c *DetailsCipher) Encrypt(details map[string]string) (map[string]string, error) {
+	encrypted := make(map[string]string, len(details))
+	for key, value := range details {
+		if !domain.IsSensitivePaymentDetail(key) {
+			encrypted[key] = value
+			continue
+		}
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		sealed := c.aead.Seal(nonce, nonce, []byte(value), []byte(key))
+		encrypted[key] = encryptedDetailPrefix + base64.StdEncoding.EncodeToString(sealed)
+	}
+	return encrypted, nil
+}
+
+// Decrypt decrypts the sensitive details. Values stored before they were encrypted and other details are kept as
+// they are.
+func (c *DetailsCipher) Decrypt(details map[string]string) (map[string]string, error) {
+	decrypted := make(map[string]string, len(details))
+	for key, value := range details {
+		if !domain.IsSensitivePaymentDetail(key) || !strings.HasPrefix(value, encryptedDetailPrefix) {
+			decrypted[key] = value
+			continue
+		}
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedDetailPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if len(sealed) < c.aead.NonceSize() {
+			return nil, errors.New("encrypted payment detail is too short")
+		}
+		nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+		plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt payment detail %s: %w", key, err)
+		}
+		decrypted[key] = string(plaintext)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDetailsCipher_EncryptsOnlySensitiveDetails(t *testing.T) {
+	cipher, err := NewDetailsCipher([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+
+	details := map[string]string{
+		"iban": "PL61109010140000071219812874",
+		"bank": "Santander",
+	}
+
+	encrypted, err := cipher.Encrypt(details)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted["iban"], encryptedDetailPrefix))
+	assert.NotContains(t, encrypted["iban"], "71219812874")
+	assert.Equal(t, "Santander", encrypted["bank"])
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, details, decrypted)
+
+	// only sensitive details are ever encrypted, others are read as they were stored
+	decrypted, err = cipher.Decrypt(map[string]string{"note": encryptedDetailPrefix + "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, encryptedDetailPrefix+"abc", decrypted["note"])
+}
+
+func TestDetailsCipher_RejectsTamperedValue(t *testing.T) {
+	cipher, err := NewDetailsCipher([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt(map[string]string{"card_number": "4111111111111111"})
+	assert.NoError(t, err)
+
+	// a value encrypted for one key must not be readable under another key
+	_, err = cipher.Decrypt(map[string]string{"iban": encrypted["card_number"]})
+	assert.Error(t, err)
+
+	_, err = NewDetailsCipher([]byte("too short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"sync"
+	"time"
+)
+
+type ExchangeRateSource interface {
+	GetExchangeRate(from, to string) (float64, error)
+}
+
+// ExchangeRateProvider converts between currencies with the rates of the market data source. Rates are fetched once
+// a day per currency pair, net worth doesn't need intraday precision and the source limits the number of requests.
+type ExchangeRateProvider struct {
+	source ExchangeRateSource
+	mu     sync.Mutex
+	rates  map[string]cachedExchangeRate
+	now    func() time.Time
+}
+
+type cachedExchangeRate struct {
+	rate      money.Decimal
+	fetchedOn time.Time
+}
+
+func NewExchangeRateProvider(source ExchangeRateSource) *ExchangeRateProvider {
+	return &ExchangeRateProvider{source: source, rates: map[string]cachedExchangeRate{}, now: time.Now}
+}
+
+func (p *ExchangeRateProvider) GetExchangeRate(from, to string) (money.Decimal, error) {
+	if from == to {
+		return money.FromInt(1), nil
+	}
+	key := from + "/" + to
+	today := p.now().UTC().Truncate(24 * time.Hour)
+
+	p.mu.Lock()
+	cached, ok := p.rates[key]
+	p.mu.Unlock()
+	if ok && cached.fetchedOn.Equal(today) {
+		return cached.rate, nil
+	}
+
+	rate, err := p.source.GetExchangeRate(from, to)
+	if err != nil {
+		return money.Zero, err
+	}
+	decimalRate := money.FromFloat(rate)
+	p.mu.Lock()
+	p.rates[key] = cachedExchangeRate{rate: decimalRate, fetchedOn: today}
+	p.mu.Unlock()
+	return decimalRate, nil
+}
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type FinanceSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewFinanceSettingsRepository(db *sql.DB) *FinanceSettingsRepository {
+	return &FinanceSettingsRepository{db: db}
+}
+
+func (r *FinanceSettingsRepository) FindByUser(userID string) (*domain.FinanceSettings, error) {
+	settings := domain.FinanceSettings{UserID: userID}
+	err := r.db.QueryRow(`SELECT base_currency, timezone, week_start FROM finance_settings WHERE user_id = $1`, userID).
+		Scan(&settings.BaseCurrency, &settings.Timezone, &settings.WeekStart)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *FinanceSettingsRepository) Save(settings domain.FinanceSettings) error {
+	_, err := r.db.Exec(`
+		INSERT INTO finance_settings (user_id, base_currency, timezone, week_start)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET base_currency = EXCLUDED.base_currency, timezone = EXCLUDED.timezone, week_start = EXCLUDED.week_start`,
+		settings.UserID, settings.BaseCurrency, settings.Timezone, settings.WeekStart)
+	return err
+}
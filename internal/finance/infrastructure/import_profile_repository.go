@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type ImportProfileRepository struct {
+	db *sql.DB
+}
+
+func NewImportProfileRepository(db *sql.DB) *ImportProfileRepository {
+	return &ImportProfileRepository{db: db}
+}
+
+const importProfileColumns = `
+		id, user_id, name, delimiter, decimal_separator, date_format, has_header, skip_rows, columns, sign_convention,
+		expense_category_id, income_category_id, payment_method_id, payment_source_id`
+
+func (r *ImportProfileRepository) FindByUser(userID string) ([]domain.ImportProfile, error) {
+	rows, err := r.db.Query(`SELECT `+importProfileColumns+` FROM import_profiles WHERE user_id = $1 ORDER BY name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []domain.ImportProfile
+	for rows.Next() {
+		profile, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *ImportProfileRepository) FindByID(profileID int, userID string) (*domain.ImportProfile, error) {
+	row := r.db.QueryRow(`SELECT `+importProfileColumns+` FROM import_profiles WHERE id = $1 AND user_id = $2`, profileID, userID)
+	return scanImportProfile(row)
+}
+
+func scanImportProfile(row interface{ Scan(dest ...any) error }) (*domain.ImportProfile, error) {
+	var profile domain.ImportProfile
+	var columns []byte
+	var paymentSourceID sql.NullInt32
+	if err := row.Scan(
+		&profile.ID, &profile.UserID, &profile.Name, &profile.Delimiter, &profile.DecimalSeparator, &profile.DateFormat,
+		&profile.HasHeader, &profile.SkipRows, &columns, &profile.SignConvention,
+		&profile.ExpenseCategoryID, &profile.IncomeCategoryID, &profile.PaymentMethodID, &paymentSourceID,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &profile.Columns); err != nil {
+		return nil, err
+	}
+	if paymentSourceID.Valid {
+		value := int(paymentSourceID.Int32)
+		profile.PaymentSourceID = &value
+	}
+	return &profile, nil
+}
+
+func (r *ImportProfileRepository) DoesNameExist(name string, userID string, excludedID int) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM import_profiles WHERE name = $1 AND user_id = $2 AND id <> $3)"
+	err := r.db.QueryRow(query, name, userID, excludedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *ImportProfileRepository) Create(profile *domain.ImportProfile) error {
+	columns, err := json.Marshal(profile.Columns)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO import_profiles (user_id, name, delimiter, decimal_separator, date_format, has_header, skip_rows, columns,
+		                             sign_convention, expense_category_id, income_category_id, payment_method_id, payment_source_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`
+	return r.db.QueryRow(query, profile.UserID, profile.Name, profile.Delimiter, profile.DecimalSeparator, profile.DateFormat,
+		profile.HasHeader, profile.SkipRows, columns, profile.SignConvention, profile.ExpenseCategoryID, profile.IncomeCategoryID,
+		profile.PaymentMethodID, profile.PaymentSourceID).Scan(&profile.ID)
+}
+
+func (r *ImportProfileRepository) Update(profile domain.ImportProfile) (int64, error) {
+	columns, err := json.Marshal(profile.Columns)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.Exec(`
+		UPDATE import_profiles
+		SET name = $1, delimiter = $2, decimal_separator = $3, date_format = $4, has_header = $5, skip_rows = $6, columns = $7,
+		    sign_convention = $8, expense_category_id = $9, income_category_id = $10, payment_method_id = $11, payment_source_id = $12
+		WHERE id = $13 AND user_id = $14`,
+		profile.Name, profile.Delimiter, profile.DecimalSeparator, profile.DateFormat, profile.HasHeader, profile.SkipRows, columns,
+		profile.SignConvention, profile.ExpenseCategoryID, profile.IncomeCategoryID, profile.PaymentMethodID, profile.PaymentSourceID,
+		profile.ID, profile.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *ImportProfileRepository) Delete(profileID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM import_profiles WHERE id = $1 AND user_id = $2`, profileID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,181 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type LoanRepository struct {
+	db *sql.DB
+}
+
+func NewLoanRepository(db *sql.DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+const loanSelect = `
+		SELECT id, user_id, name, principal, annual_rate, rate_type, term_months, method, first_installment_date,
+		       predefined_category_id, payment_source_id, currency
+		FROM loans`
+
+func (r *LoanRepository) FindByUser(userID string) ([]domain.Loan, error) {
+	rows, err := r.db.Query(loanSelect+` WHERE user_id = $1 ORDER BY first_installment_date, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loans []domain.Loan
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, *loan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return loans, r.loadRateChanges(loans)
+}
+
+func (r *LoanRepository) FindByID(loanID int, userID string) (*domain.Loan, error) {
+	row := r.db.QueryRow(loanSelect+` WHERE id = $1 AND user_id = $2`, loanID, userID)
+	loan, err := scanLoan(row)
+	if err != nil {
+		return nil, err
+	}
+	loans := []domain.Loan{*loan}
+	if err := r.loadRateChanges(loans); err != nil {
+		return nil, err
+	}
+	return &loans[0], nil
+}
+
+// loadRateChanges fills in the rate changes of the loans, with a single query.
+func (r *LoanRepository) loadRateChanges(loans []domain.Loan) error {
+	if len(loans) == 0 {
+		return nil
+	}
+	ids := make([]int, len(loans))
+	indexByID := make(map[int]int, len(loans))
+	for i, loan := range loans {
+		ids[i] = loan.ID
+		indexByID[loan.ID] = i
+		loans[i].RateChanges = []domain.LoanRateChange{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT loan_id, effective_date, annual_rate
+		FROM loan_rate_changes
+		WHERE loan_id = ANY($1)
+		ORDER BY effective_date
+		`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanID int
+		var change domain.LoanRateChange
+		if err := rows.Scan(&loanID, &change.EffectiveDate, &change.AnnualRate); err != nil {
+			return err
+		}
+		index := indexByID[loanID]
+		loans[index].RateChanges = append(loans[index].RateChanges, change)
+	}
+	return rows.Err()
+}
+
+func insertRateChanges(tx *sql.Tx, loan domain.Loan) error {
+	for _, change := range loan.RateChanges {
+		if _, err := tx.Exec(`INSERT INTO loan_rate_changes (loan_id, effective_date, annual_rate) VALUES ($1, $2, $3)`,
+			loan.ID, change.EffectiveDate, change.AnnualRate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanLoan(row interface{ Scan(dest ...any) error }) (*domain.Loan, error) {
+	var loan domain.Loan
+	var paymentSourceID sql.NullInt32
+	if err := row.Scan(
+		&loan.ID, &loan.UserID, &loan.Name, &loan.Principal, &loan.AnnualRate, &loan.RateType, &loan.TermMonths,
+		&loan.Method, &loan.FirstInstallmentDate, &loan.PredefinedCategoryID, &paymentSourceID, &loan.Currency,
+	); err != nil {
+		return nil, err
+	}
+	if paymentSourceID.Valid {
+		value := int(paymentSourceID.Int32)
+		loan.PaymentSourceID = &value
+	}
+	return &loan, nil
+}
+
+func (r *LoanRepository) Create(loan *domain.Loan) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO loans (user_id, name, principal, annual_rate, rate_type, term_months, method, first_installment_date,
+		                   predefined_category_id, payment_source_id, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+	err = tx.QueryRow(query, loan.UserID, loan.Name, loan.Principal, loan.AnnualRate, loan.RateType, loan.TermMonths,
+		loan.Method, loan.FirstInstallmentDate, loan.PredefinedCategoryID, loan.PaymentSourceID, loan.Currency).Scan(&loan.ID)
+	if err == nil {
+		err = insertRateChanges(tx, *loan)
+	}
+	if err != nil {
+		safeRollback(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces the loan together with its rate changes.
+func (r *LoanRepository) Update(loan domain.Loan) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	affected, err := updateLoan(tx, loan)
+	if err != nil {
+		safeRollback(tx)
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+func updateLoan(tx *sql.Tx, loan domain.Loan) (int64, error) {
+	result, err := tx.Exec(`
+		UPDATE loans
+		SET name = $1, principal = $2, annual_rate = $3, rate_type = $4, term_months = $5, method = $6,
+		    first_installment_date = $7, predefined_category_id = $8, payment_source_id = $9, currency = $10
+		WHERE id = $11 AND user_id = $12`,
+		loan.Name, loan.Principal, loan.AnnualRate, loan.RateType, loan.TermMonths, loan.Method,
+		loan.FirstInstallmentDate, loan.PredefinedCategoryID, loan.PaymentSourceID, loan.Currency, loan.ID, loan.UserID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM loan_rate_changes WHERE loan_id = $1`, loan.ID); err != nil {
+		return 0, err
+	}
+	return affected, insertRateChanges(tx, loan)
+}
+
+func (r *LoanRepository) Delete(loanID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM loans WHERE id = $1 AND user_id = $2`, loanID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultAttachmentsDir = "data/attachments"
+
+// LocalBlobStorage keeps blobs as files below a root directory, the keys are paths relative to it.
+type LocalBlobStorage struct {
+	root string
+}
+
+func NewLocalBlobStorage(root string) (*LocalBlobStorage, error) {
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStorage{root: root}, nil
+}
+
+// NewLocalBlobStorageFromEnv stores the blobs in ATTACHMENTS_DIR, data/attachments by default.
+func NewLocalBlobStorageFromEnv() (*LocalBlobStorage, error) {
+	root := os.Getenv("ATTACHMENTS_DIR")
+	if root == "" {
+		root = defaultAttachmentsDir
+	}
+	return NewLocalBlobStorage(root)
+}
+
+func (s *LocalBlobStorage) path(key string) (string, error) {
+	if key == "" || filepath.IsAbs(key) || strings.Contains(key, "\\") || !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the content to a temporary file first, so that a failed upload never leaves a partial blob behind.
+func (s *LocalBlobStorage) Put(key string, content io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (s *LocalBlobStorage) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalBlobStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
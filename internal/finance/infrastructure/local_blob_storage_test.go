@@ -0,0 +1,30 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalBlobStorage(t *testing.T) {
+	storage, err := NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, storage.Put("user-id/receipt", strings.NewReader("%PDF-1.7")))
+	content, err := storage.Get("user-id/receipt")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(content)
+	assert.NoError(t, err)
+	assert.NoError(t, content.Close())
+	assert.Equal(t, "%PDF-1.7", string(data))
+
+	assert.NoError(t, storage.Delete("user-id/receipt"))
+	assert.NoError(t, storage.Delete("user-id/receipt"))
+	_, err = storage.Get("user-id/receipt")
+	assert.Error(t, err)
+
+	for _, key := range []string{"", "../other-user/receipt", "/etc/passwd", "user-id\\..\\receipt"} {
+		assert.Error(t, storage.Put(key, strings.NewReader("content")), key)
+	}
+}
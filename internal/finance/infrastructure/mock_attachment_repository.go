@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"time"
+)
+
+type MockAttachmentRepository struct {
+	Attachments []domain.Attachment
+}
+
+func (m *MockAttachmentRepository) FindByTransaction(transactionID string, userID string) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	for _, attachment := range m.Attachments {
+		if attachment.TransactionID == transactionID && attachment.UserID == userID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (m *MockAttachmentRepository) FindByID(attachmentID string, userID string) (*domain.Attachment, error) {
+	for _, attachment := range m.Attachments {
+		if attachment.ID == attachmentID && attachment.UserID == userID {
+			return &attachment, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockAttachmentRepository) CountByTransaction(transactionID string, userID string) (int, error) {
+	attachments, err := m.FindByTransaction(transactionID, userID)
+	return len(attachments), err
+}
+
+func (m *MockAttachmentRepository) Create(attachment *domain.Attachment) error {
+	if count, _ := m.CountByTransaction(attachment.TransactionID, attachment.UserID); count >= domain.MaxTransactionAttachments {
+		return financeErrors.ErrTooManyAttachments
+	}
+	attachment.CreatedAt = time.Now()
+	m.Attachments = append(m.Attachments, *attachment)
+	return nil
+}
+
+func (m *MockAttachmentRepository) Delete(attachmentID string, userID string) (int64, error) {
+	for i, attachment := range m.Attachments {
+		if attachment.ID == attachmentID && attachment.UserID == userID {
+			m.Attachments = append(m.Attachments[:i], m.Attachments[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type MockBudgetRepository struct {
+	Budgets []domain.Budget
+	Alerts  map[string]bool
+}
+
+func (m *MockBudgetRepository) FindByUser(userID string) ([]domain.Budget, error) {
+	var budgets []domain.Budget
+	for _, budget := range m.Budgets {
+		if budget.UserID == userID {
+			budgets = append(budgets, budget)
+		}
+	}
+	return budgets, nil
+}
+
+func (m *MockBudgetRepository) FindByID(budgetID int, userID string) (*domain.Budget, error) {
+	for _, budget := range m.Budgets {
+		if budget.ID == budgetID && budget.UserID == userID {
+			return &budget, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockBudgetRepository) DoesBudgetExist(budget domain.Budget) (bool, error) {
+	return false, nil
+}
+
+func (m *MockBudgetRepository) Create(budget *domain.Budget) error {
+	budget.ID = len(m.Budgets) + 1
+	m.Budgets = append(m.Budgets, *budget)
+	return nil
+}
+
+func (m *MockBudgetRepository) Update(budget domain.Budget) (int64, error) {
+	for i := range m.Budgets {
+		if m.Budgets[i].ID == budget.ID && m.Budgets[i].UserID == budget.UserID {
+			m.Budgets[i] = budget
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockBudgetRepository) Delete(budgetID int, userID string) (int64, error) {
+	for i := range m.Budgets {
+		if m.Budgets[i].ID == budgetID && m.Budgets[i].UserID == userID {
+			m.Budgets = append(m.Budgets[:i], m.Budgets[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockBudgetRepository) RecordAlert(budgetID int, periodStart time.Time, threshold int) (bool, error) {
+	if m.Alerts == nil {
+		m.Alerts = make(map[string]bool)
+	}
+	key := fmt.Sprintf("%d/%s/%d", budgetID, periodStart.Format("2006-01-02"), threshold)
+	if m.Alerts[key] {
+		return false, nil
+	}
+	m.Alerts[key] = true
+	return true, nil
+}
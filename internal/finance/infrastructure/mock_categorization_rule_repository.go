@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"sort"
+)
+
+type MockCategorizationRuleRepository struct {
+	Rules []domain.CategorizationRule
+}
+
+func (m *MockCategorizationRuleRepository) FindByUser(userID string) ([]domain.CategorizationRule, error) {
+	var rules []domain.CategorizationRule
+	for _, rule := range m.Rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+func (m *MockCategorizationRuleRepository) FindByID(ruleID int, userID string) (*domain.CategorizationRule, error) {
+	for _, rule := range m.Rules {
+		if rule.ID == ruleID && rule.UserID == userID {
+			return &rule, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockCategorizationRuleRepository) Create(rule *domain.CategorizationRule) error {
+	rule.ID = len(m.Rules) + 1
+	m.Rules = append(m.Rules, *rule)
+	return nil
+}
+
+func (m *MockCategorizationRuleRepository) Update(rule domain.CategorizationRule) (int64, error) {
+	for i := range m.Rules {
+		if m.Rules[i].ID == rule.ID && m.Rules[i].UserID == rule.UserID {
+			m.Rules[i] = rule
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockCategorizationRuleRepository) Delete(ruleID int, userID string) (int64, error) {
+	for i := range m.Rules {
+		if m.Rules[i].ID == ruleID && m.Rules[i].UserID == userID {
+			m.Rules = append(m.Rules[:i], m.Rules[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
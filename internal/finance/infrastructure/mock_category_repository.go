@@ -0,0 +1,94 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type MockCategoryRepository struct {
+	PredefinedCategories []domain.PredefinedCategory
+	UserCategories       []domain.UserCategory
+}
+
+func (m *MockCategoryRepository) FindPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error) {
+	var categories []domain.PredefinedCategory
+	for _, category := range m.PredefinedCategories {
+		if categoryType == "" || category.Type == categoryType {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (m *MockCategoryRepository) FindPredefinedCategoryByID(categoryID int) (*domain.PredefinedCategory, error) {
+	for _, category := range m.PredefinedCategories {
+		if category.ID == categoryID {
+			return &category, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockCategoryRepository) FindUserCategories(userID string) ([]domain.UserCategory, error) {
+	var categories []domain.UserCategory
+	for _, category := range m.UserCategories {
+		if category.UserID == userID {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (m *MockCategoryRepository) FindUserCategoryByID(categoryID int, userID string) (*domain.UserCategory, error) {
+	for _, category := range m.UserCategories {
+		if category.ID == categoryID && category.UserID == userID {
+			return &category, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockCategoryRepository) DoesPredefinedCategoryExistByID(categoryID int) (bool, error) {
+	_, err := m.FindPredefinedCategoryByID(categoryID)
+	return err == nil, nil
+}
+
+func (m *MockCategoryRepository) DoesUserCategoryExistByID(categoryID int, userID string) (bool, error) {
+	_, err := m.FindUserCategoryByID(categoryID, userID)
+	return err == nil, nil
+}
+
+func (m *MockCategoryRepository) DoesUserCategoryNameExist(name string, userID string, excludedID int) (bool, error) {
+	for _, category := range m.UserCategories {
+		if category.Name == name && category.UserID == userID && category.ID != excludedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockCategoryRepository) CreateUserCategory(category *domain.UserCategory) error {
+	category.ID = len(m.UserCategories) + 1
+	m.UserCategories = append(m.UserCategories, *category)
+	return nil
+}
+
+func (m *MockCategoryRepository) UpdateUserCategory(category domain.UserCategory) (int64, error) {
+	for i := range m.UserCategories {
+		if m.UserCategories[i].ID == category.ID && m.UserCategories[i].UserID == category.UserID {
+			m.UserCategories[i] = category
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockCategoryRepository) DeleteUserCategory(categoryID int, userID string) (int64, error) {
+	for i := range m.UserCategories {
+		if m.UserCategories[i].ID == categoryID && m.UserCategories[i].UserID == userID {
+			m.UserCategories = append(m.UserCategories[:i], m.UserCategories[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type MockFinanceSettingsRepository struct {
+	Settings map[string]domain.FinanceSettings
+}
+
+func (m *MockFinanceSettingsRepository) FindByUser(userID string) (*domain.FinanceSettings, error) {
+	settings, ok := m.Settings[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &settings, nil
+}
+
+func (m *MockFinanceSettingsRepository) Save(settings domain.FinanceSettings) error {
+	if m.Settings == nil {
+		m.Settings = map[string]domain.FinanceSettings{}
+	}
+	m.Settings[settings.UserID] = settings
+	return nil
+}
@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type MockLoanRepository struct {
+	Loans []domain.Loan
+}
+
+func (m *MockLoanRepository) FindByUser(userID string) ([]domain.Loan, error) {
+	var loans []domain.Loan
+	for _, loan := range m.Loans {
+		if loan.UserID == userID {
+			loans = append(loans, loan)
+		}
+	}
+	return loans, nil
+}
+
+func (m *MockLoanRepository) FindByID(loanID int, userID string) (*domain.Loan, error) {
+	for _, loan := range m.Loans {
+		if loan.ID == loanID && loan.UserID == userID {
+			return &loan, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockLoanRepository) Create(loan *domain.Loan) error {
+	loan.ID = len(m.Loans) + 1
+	m.Loans = append(m.Loans, *loan)
+	return nil
+}
+
+func (m *MockLoanRepository) Update(loan domain.Loan) (int64, error) {
+	for i := range m.Loans {
+		if m.Loans[i].ID == loan.ID && m.Loans[i].UserID == loan.UserID {
+			m.Loans[i] = loan
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockLoanRepository) Delete(loanID int, userID string) (int64, error) {
+	for i := range m.Loans {
+		if m.Loans[i].ID == loanID && m.Loans[i].UserID == userID {
+			m.Loans = append(m.Loans[:i], m.Loans[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"sort"
+	"time"
+)
+
+type MockNetWorthRepository struct {
+	Snapshots map[string][]domain.NetWorthSnapshot
+	// Users are returned by FindUsersDueForSnapshot unless they have a snapshot on the date.
+	Users []string
+}
+
+func (m *MockNetWorthRepository) Save(userID string, snapshot domain.NetWorthSnapshot) error {
+	if m.Snapshots == nil {
+		m.Snapshots = map[string][]domain.NetWorthSnapshot{}
+	}
+	snapshots := m.Snapshots[userID]
+	for i := range snapshots {
+		if snapshots[i].Date.Equal(snapshot.Date) {
+			snapshots[i] = snapshot
+			return nil
+		}
+	}
+	snapshots = append(snapshots, snapshot)
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Date.Before(snapshots[j].Date) })
+	m.Snapshots[userID] = snapshots
+	return nil
+}
+
+func (m *MockNetWorthRepository) FindInRange(userID string, startDate, endDate time.Time) ([]domain.NetWorthSnapshot, error) {
+	var snapshots []domain.NetWorthSnapshot
+	for _, snapshot := range m.Snapshots[userID] {
+		if !snapshot.Date.Before(startDate) && !snapshot.Date.After(endDate) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func (m *MockNetWorthRepository) FindUsersDueForSnapshot(date time.Time) ([]string, error) {
+	var userIDs []string
+	for _, userID := range m.Users {
+		taken := false
+		for _, snapshot := range m.Snapshots[userID] {
+			taken = taken || snapshot.Date.Equal(date)
+		}
+		if !taken {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type MockPaymentRepository struct {
+	Methods []domain.PaymentMethod
+	Sources []domain.PaymentSource
+}
+
+func (m *MockPaymentRepository) GetAllPaymentMethods() ([]domain.PaymentMethod, error) {
+	return m.Methods, nil
+}
+
+func (m *MockPaymentRepository) GetUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error) {
+	var sources []domain.PaymentSource
+	for _, source := range m.Sources {
+		if source.UserID == userID && (includeArchived || !source.Archived) {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+func (m *MockPaymentRepository) FindUserPaymentSourceByID(sourceID int, userID string) (*domain.PaymentSource, error) {
+	for _, source := range m.Sources {
+		if source.ID == sourceID && source.UserID == userID {
+			return &source, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockPaymentRepository) CreatePaymentSource(source *domain.PaymentSource) error {
+	source.ID = len(m.Sources) + 1
+	m.Sources = append(m.Sources, *source)
+	return nil
+}
+
+func (m *MockPaymentRepository) UpdatePaymentSource(source domain.PaymentSource) (int64, error) {
+	for i := range m.Sources {
+		if m.Sources[i].ID == source.ID && m.Sources[i].UserID == source.UserID {
+			m.Sources[i] = source
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockPaymentRepository) SetPaymentSourceArchived(sourceID int, userID string, archived bool) (int64, error) {
+	for i := range m.Sources {
+		if m.Sources[i].ID == sourceID && m.Sources[i].UserID == userID {
+			m.Sources[i].Archived = archived
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockPaymentRepository) PaymentMethodExists(methodID int) (bool, error) {
+	for _, method := range m.Methods {
+		if method.ID == methodID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockPaymentRepository) UserPaymentSourceExists(sourceID int, userID string) (bool, error) {
+	_, err := m.FindUserPaymentSourceByID(sourceID, userID)
+	return err == nil, nil
+}
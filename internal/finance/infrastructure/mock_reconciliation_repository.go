@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"time"
+)
+
+// MockReconciliationRepository marks the reconciled transactions in the transactions of Transactions.
+type MockReconciliationRepository struct {
+	Reconciliations []domain.Reconciliation
+	Transactions    *MockTransactionRepository
+}
+
+func (m *MockReconciliationRepository) FindByPaymentSource(paymentSourceID int, userID string) ([]domain.Reconciliation, error) {
+	var reconciliations []domain.Reconciliation
+	for _, reconciliation := range m.Reconciliations {
+		if reconciliation.PaymentSourceID == paymentSourceID {
+			reconciliations = append(reconciliations, reconciliation)
+		}
+	}
+	return reconciliations, nil
+}
+
+func (m *MockReconciliationRepository) Create(reconciliation *domain.Reconciliation, userID string) error {
+	reconciliation.ID = len(m.Reconciliations) + 1
+	reconciliation.CreatedAt = time.Now()
+	for _, id := range reconciliation.TransactionIDs {
+		found := false
+		for i := range m.Transactions.Transactions {
+			transaction := &m.Transactions.Transactions[i]
+			if transaction.ID != id || transaction.UserID != userID || transaction.IsReconciledOn(reconciliation.PaymentSourceID) {
+				continue
+			}
+			if sameID(transaction.DestinationPaymentSourceID, reconciliation.PaymentSourceID) {
+				transaction.DestinationReconciliationID = &reconciliation.ID
+				found = true
+			} else if sameID(transaction.PaymentSourceID, reconciliation.PaymentSourceID) {
+				transaction.ReconciliationID = &reconciliation.ID
+				found = true
+			}
+		}
+		if !found {
+			return financeErrors.ErrTransactionReconciled
+		}
+	}
+	m.Reconciliations = append(m.Reconciliations, *reconciliation)
+	return nil
+}
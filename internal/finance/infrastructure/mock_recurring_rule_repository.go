@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type MockRecurringRuleRepository struct {
+	Rules        []domain.RecurringRule
+	Exceptions   []domain.RecurringException
+	Transactions []domain.RecurringOccurrenceTransaction
+}
+
+func (m *MockRecurringRuleRepository) Create(rule *domain.RecurringRule) error {
+	m.Rules = append(m.Rules, *rule)
+	return nil
+}
+
+func (m *MockRecurringRuleRepository) Update(rule domain.RecurringRule) (int64, error) {
+	for i := range m.Rules {
+		if m.Rules[i].ID == rule.ID && m.Rules[i].UserID == rule.UserID {
+			m.Rules[i] = rule
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockRecurringRuleRepository) Delete(ruleID string, userID string) (int64, error) {
+	for i := range m.Rules {
+		if m.Rules[i].ID == ruleID && m.Rules[i].UserID == userID {
+			m.Rules = append(m.Rules[:i], m.Rules[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockRecurringRuleRepository) FindByID(ruleID string, userID string) (*domain.RecurringRule, error) {
+	for _, rule := range m.Rules {
+		if rule.ID == ruleID && rule.UserID == userID {
+			return &rule, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockRecurringRuleRepository) FindByUser(userID string) ([]domain.RecurringRule, error) {
+	var rules []domain.RecurringRule
+	for _, rule := range m.Rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *MockRecurringRuleRepository) FindDue(until time.Time) ([]domain.RecurringRule, error) {
+	var rules []domain.RecurringRule
+	for _, rule := range m.Rules {
+		if rule.StartDate.After(until) || (rule.GeneratedUntil != nil && !rule.GeneratedUntil.Before(until)) {
+			continue
+		}
+		if last := rule.LastOccurrenceDate(); last != nil && rule.GeneratedUntil != nil && !rule.GeneratedUntil.Before(*last) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (m *MockRecurringRuleRepository) FindExceptions(ruleID string) ([]domain.RecurringException, error) {
+	var exceptions []domain.RecurringException
+	for _, exception := range m.Exceptions {
+		if exception.RuleID == ruleID {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	return exceptions, nil
+}
+
+func (m *MockRecurringRuleRepository) SaveException(exception domain.RecurringException) error {
+	for i := range m.Exceptions {
+		if m.Exceptions[i].RuleID == exception.RuleID && m.Exceptions[i].OccurrenceDate.Equal(exception.OccurrenceDate) {
+			m.Exceptions[i] = exception
+			return nil
+		}
+	}
+	m.Exceptions = append(m.Exceptions, exception)
+	return nil
+}
+
+func (m *MockRecurringRuleRepository) DeleteException(ruleID string, occurrenceDate time.Time) (int64, error) {
+	for i := range m.Exceptions {
+		if m.Exceptions[i].RuleID == ruleID && m.Exceptions[i].OccurrenceDate.Equal(occurrenceDate) {
+			m.Exceptions = append(m.Exceptions[:i], m.Exceptions[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockRecurringRuleRepository) SaveOccurrences(ruleID string, transactions []domain.RecurringOccurrenceTransaction, generatedUntil time.Time) error {
+	m.Transactions = append(m.Transactions, transactions...)
+	for i := range m.Rules {
+		if m.Rules[i].ID == ruleID {
+			m.Rules[i].GeneratedUntil = &generatedUntil
+		}
+	}
+	return nil
+}
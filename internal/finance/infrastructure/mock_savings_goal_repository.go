@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type MockSavingsGoalRepository struct {
+	Goals []domain.SavingsGoal
+	// ProgressEmails holds the day the progress of each goal was last emailed on.
+	ProgressEmails map[int]time.Time
+}
+
+func (m *MockSavingsGoalRepository) FindByUser(userID string) ([]domain.SavingsGoal, error) {
+	var goals []domain.SavingsGoal
+	for _, goal := range m.Goals {
+		if goal.UserID == userID {
+			goals = append(goals, goal)
+		}
+	}
+	return goals, nil
+}
+
+func (m *MockSavingsGoalRepository) FindByID(goalID int, userID string) (*domain.SavingsGoal, error) {
+	for _, goal := range m.Goals {
+		if goal.ID == goalID && goal.UserID == userID {
+			return &goal, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockSavingsGoalRepository) FindDueForProgressEmail(monthStart time.Time) ([]domain.SavingsGoal, error) {
+	var goals []domain.SavingsGoal
+	for _, goal := range m.Goals {
+		sentOn, sent := m.ProgressEmails[goal.ID]
+		if goal.StartDate.Before(monthStart) && (!sent || sentOn.Before(monthStart)) {
+			goals = append(goals, goal)
+		}
+	}
+	return goals, nil
+}
+
+func (m *MockSavingsGoalRepository) Create(goal *domain.SavingsGoal) error {
+	goal.ID = len(m.Goals) + 1
+	m.Goals = append(m.Goals, *goal)
+	return nil
+}
+
+func (m *MockSavingsGoalRepository) Update(goal domain.SavingsGoal) (int64, error) {
+	for i := range m.Goals {
+		if m.Goals[i].ID == goal.ID && m.Goals[i].UserID == goal.UserID {
+			m.Goals[i] = goal
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockSavingsGoalRepository) Delete(goalID int, userID string) (int64, error) {
+	for i := range m.Goals {
+		if m.Goals[i].ID == goalID && m.Goals[i].UserID == userID {
+			m.Goals = append(m.Goals[:i], m.Goals[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *MockSavingsGoalRepository) RecordProgressEmail(goalIDs []int, sentOn time.Time) error {
+	if m.ProgressEmails == nil {
+		m.ProgressEmails = map[int]time.Time{}
+	}
+	for _, goalID := range goalIDs {
+		m.ProgressEmails[goalID] = sentOn
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package infrastructure
 import (
 	"database/sql"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -11,18 +14,210 @@ type MockTransactionRepository struct {
 }
 
 func (m *MockTransactionRepository) GetTransactionSummaryByPaymentMethod(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByPaymentMethodSummary, error) {
-	//TODO implement me
-	panic("implement me")
+	var summaries []domain.TransactionByPaymentMethodSummary
+	indexByMethod := make(map[int]int)
+	for _, transaction := range m.Transactions {
+		if transaction.UserID != userID || transaction.Date.Before(startDate) || transaction.Date.After(endDate) {
+			continue
+		}
+		if transaction.IsTransfer() || transactionType != "" && transaction.Type != transactionType {
+			continue
+		}
+		index, exists := indexByMethod[transaction.PaymentMethodID]
+		if !exists {
+			summaries = append(summaries, domain.TransactionByPaymentMethodSummary{PaymentMethodID: transaction.PaymentMethodID})
+			index = len(summaries) - 1
+			indexByMethod[transaction.PaymentMethodID] = index
+		}
+		summaries[index].TotalAmount = summaries[index].TotalAmount.Add(transaction.Amount)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].TotalAmount.GreaterThan(summaries[j].TotalAmount)
+	})
+	return summaries, nil
+}
+
+func (m *MockTransactionRepository) GetTransactionPeriodTotals(userID string, search domain.TransactionSearch, firstDay time.Weekday) ([]domain.TransactionPeriodTotal, error) {
+	var totals []domain.TransactionPeriodTotal
+	for _, transaction := range m.Transactions {
+		if !matchesSearch(transaction, userID, search) || transaction.IsTransfer() {
+			continue
+		}
+		date := domain.TruncateToDate(transaction.Date)
+		key := domain.TransactionPeriodTotal{
+			MonthStart: time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC),
+			WeekStart:  domain.WeekStartOf(date, firstDay),
+			Type:       transaction.Type,
+		}
+		found := false
+		for i := range totals {
+			if totals[i].MonthStart.Equal(key.MonthStart) && totals[i].WeekStart.Equal(key.WeekStart) && totals[i].Type == key.Type {
+				totals[i].Total = totals[i].Total.Add(transaction.Amount)
+				found = true
+				break
+			}
+		}
+		if !found {
+			key.Total = transaction.Amount
+			totals = append(totals, key)
+		}
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if !totals[i].MonthStart.Equal(totals[j].MonthStart) {
+			return totals[i].MonthStart.Before(totals[j].MonthStart)
+		}
+		if !totals[i].WeekStart.Equal(totals[j].WeekStart) {
+			return totals[i].WeekStart.Before(totals[j].WeekStart)
+		}
+		return totals[i].Type < totals[j].Type
+	})
+	return totals, nil
 }
 
 func (m *MockTransactionRepository) GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error) {
-	//TODO implement me
-	panic("implement me")
+	var summaries []domain.TransactionByCategorySummary
+	indexByCategory := make(map[int]int)
+	for _, transaction := range m.Transactions {
+		if transaction.UserID != userID || transaction.Date.Before(startDate) || transaction.Date.After(endDate) {
+			continue
+		}
+		if transaction.IsTransfer() || transactionType != "" && transaction.Type != transactionType {
+			continue
+		}
+		for _, line := range transaction.Lines() {
+			index, exists := indexByCategory[line.PredefinedCategoryID]
+			if !exists {
+				summaries = append(summaries, domain.TransactionByCategorySummary{CategoryID: line.PredefinedCategoryID})
+				index = len(summaries) - 1
+				indexByCategory[line.PredefinedCategoryID] = index
+			}
+			summaries[index].TotalAmount = summaries[index].TotalAmount.Add(line.Amount)
+		}
+	}
+	return summaries, nil
 }
 
-func (m *MockTransactionRepository) GetTransactionsByType(userID string, transactionType string, startDate time.Time, endDate time.Time, limit int, page int) ([]domain.PersonalTransaction, error) {
-	//TODO implement me
-	panic("implement me")
+func (m *MockTransactionRepository) GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByTagSummary, error) {
+	var summaries []domain.TransactionByTagSummary
+	indexByTag := make(map[int]int)
+	for _, transaction := range m.Transactions {
+		if transaction.UserID != userID || transaction.Date.Before(startDate) || transaction.Date.After(endDate) {
+			continue
+		}
+		if transaction.IsTransfer() || transactionType != "" && transaction.Type != transactionType {
+			continue
+		}
+		for _, tagID := range transaction.TagIDs {
+			index, exists := indexByTag[tagID]
+			if !exists {
+				summaries = append(summaries, domain.TransactionByTagSummary{TagID: tagID})
+				index = len(summaries) - 1
+				indexByTag[tagID] = index
+			}
+			summaries[index].TotalAmount = summaries[index].TotalAmount.Add(transaction.Amount)
+			summaries[index].TransactionCount++
+		}
+	}
+	return summaries, nil
+}
+
+// SearchTransactions supports the filters and the sort of the search, the text is matched as a plain substring.
+func (m *MockTransactionRepository) SearchTransactions(userID string, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	var matches []domain.PersonalTransaction
+	for _, transaction := range m.Transactions {
+		if matchesSearch(transaction, userID, search) {
+			matches = append(matches, transaction)
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b domain.PersonalTransaction) int {
+		var result int
+		switch search.SortBy {
+		case domain.TransactionSortAmount:
+			result = a.Amount.Cmp(b.Amount)
+		case domain.TransactionSortName:
+			result = strings.Compare(a.Name, b.Name)
+		default:
+			result = a.Date.Compare(b.Date)
+		}
+		if result == 0 {
+			result = strings.Compare(a.ID, b.ID)
+		}
+		if !search.Ascending {
+			result = -result
+		}
+		return result
+	})
+
+	page := &domain.TransactionPage{Transactions: []domain.PersonalTransaction{}, TotalCount: len(matches)}
+	cursor, err := search.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+	start := (search.Page - 1) * search.Limit
+	if cursor != nil {
+		start = slices.IndexFunc(matches, func(transaction domain.PersonalTransaction) bool { return transaction.ID == cursor.ID }) + 1
+	}
+	start = max(0, min(start, len(matches)))
+	end := min(start+search.Limit, len(matches))
+	page.Transactions = append(page.Transactions, matches[start:end]...)
+	if end < len(matches) {
+		page.NextCursor = search.NextCursor(matches[end-1])
+	}
+	return page, nil
+}
+
+// matchesSearch mirrors the filters of the SQL search, the text is matched as a plain substring.
+func matchesSearch(transaction domain.PersonalTransaction, userID string, search domain.TransactionSearch) bool {
+	anyOf := func(ids []int, values ...*int) bool {
+		for _, value := range values {
+			if value != nil && slices.Contains(ids, *value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if transaction.UserID != userID || transaction.Date.Before(search.StartDate) || transaction.Date.After(search.EndDate) {
+		return false
+	}
+	if search.Type != "" && transaction.Type != search.Type {
+		return false
+	}
+	lines := transaction.Lines()
+	if len(search.PredefinedCategoryIDs) > 0 && !slices.ContainsFunc(lines, func(line domain.TransactionSplit) bool {
+		return anyOf(search.PredefinedCategoryIDs, &line.PredefinedCategoryID)
+	}) {
+		return false
+	}
+	if len(search.UserCategoryIDs) > 0 && !slices.ContainsFunc(lines, func(line domain.TransactionSplit) bool {
+		return anyOf(search.UserCategoryIDs, line.UserCategoryID)
+	}) {
+		return false
+	}
+	if len(search.PaymentMethodIDs) > 0 && !anyOf(search.PaymentMethodIDs, &transaction.PaymentMethodID) {
+		return false
+	}
+	if len(search.PaymentSourceIDs) > 0 && !anyOf(search.PaymentSourceIDs, transaction.PaymentSourceID, transaction.DestinationPaymentSourceID) {
+		return false
+	}
+	if len(search.TagIDs) > 0 && !slices.ContainsFunc(transaction.TagIDs, func(tagID int) bool { return slices.Contains(search.TagIDs, tagID) }) {
+		return false
+	}
+	if search.MinAmount != nil && transaction.Amount.LessThan(*search.MinAmount) ||
+		search.MaxAmount != nil && transaction.Amount.GreaterThan(*search.MaxAmount) {
+		return false
+	}
+	if search.Text != "" {
+		text := strings.ToLower(transaction.Name)
+		if transaction.Description != nil {
+			text += " " + strings.ToLower(*transaction.Description)
+		}
+		if !strings.Contains(text, strings.ToLower(search.Text)) {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *MockTransactionRepository) Save(transaction domain.PersonalTransaction) error {
@@ -35,26 +230,79 @@ func (m *MockTransactionRepository) FindByUser(userID string) ([]domain.Personal
 	panic("implement me")
 }
 
-func (m *MockTransactionRepository) FindByID(transactionID int) (*domain.PersonalTransaction, error) {
-	//TODO implement me
-	panic("implement me")
+func (m *MockTransactionRepository) FindByID(transactionID string, userID string) (*domain.PersonalTransaction, error) {
+	for _, transaction := range m.Transactions {
+		if transaction.ID == transactionID && transaction.UserID == userID {
+			found := transaction
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
 }
 
-func (m *MockTransactionRepository) Delete(transactionID int) error {
-	//TODO implement me
-	panic("implement me")
+func (m *MockTransactionRepository) Delete(transactionID string, userID string) (int64, []string, error) {
+	for i, transaction := range m.Transactions {
+		if transaction.ID == transactionID && transaction.UserID == userID {
+			m.Transactions = append(m.Transactions[:i], m.Transactions[i+1:]...)
+			var storageKeys []string
+			for _, attachment := range transaction.Attachments {
+				storageKeys = append(storageKeys, attachment.StorageKey)
+			}
+			return 1, storageKeys, nil
+		}
+	}
+	return 0, nil, nil
+}
+
+func (m *MockTransactionRepository) Update(transaction domain.PersonalTransaction) (int64, error) {
+	for i, existing := range m.Transactions {
+		if existing.ID == transaction.ID && existing.UserID == transaction.UserID {
+			m.Transactions[i] = transaction
+			return 1, nil
+		}
+	}
+	return 0, nil
 }
 
-func (m *MockTransactionRepository) Update(transaction domain.PersonalTransaction) error {
+func (m *MockTransactionRepository) SaveWithTransaction(transaction domain.PersonalTransaction, tx *sql.Tx) error {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (m *MockTransactionRepository) SaveWithTransaction(transaction domain.PersonalTransaction, tx *sql.Tx) error {
+func (m *MockTransactionRepository) UpdateWithTransaction(transaction domain.PersonalTransaction, tx *sql.Tx) (int64, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
+func (m *MockTransactionRepository) FindByExternalIDWithTransaction(userID, externalID string, tx *sql.Tx) (*domain.PersonalTransaction, error) {
+	for _, transaction := range m.Transactions {
+		if transaction.UserID == userID && transaction.ExternalID != nil && *transaction.ExternalID == externalID {
+			found := transaction
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockTransactionRepository) WithSavepoint(tx *sql.Tx, fn func() error) error {
+	return fn()
+}
+
+// FindDuplicateCandidates returns the transactions of the user in the date range or with any of the external IDs.
+func (m *MockTransactionRepository) FindDuplicateCandidates(userID string, startDate, endDate time.Time, externalIDs []string) ([]domain.PersonalTransaction, error) {
+	var candidates []domain.PersonalTransaction
+	for _, transaction := range m.Transactions {
+		if transaction.UserID != userID {
+			continue
+		}
+		inRange := !transaction.Date.Before(startDate) && !transaction.Date.After(endDate)
+		if inRange || transaction.ExternalID != nil && slices.Contains(externalIDs, *transaction.ExternalID) {
+			candidates = append(candidates, transaction)
+		}
+	}
+	return candidates, nil
+}
+
 func (m *MockTransactionRepository) BeginTransaction() (*sql.Tx, error) {
 	//TODO implement me
 	panic("implement me")
@@ -70,3 +318,32 @@ func (m *MockTransactionRepository) GetTransactionsInDateRange(userID string, st
 	}
 	return filtered, nil
 }
+
+func (m *MockTransactionRepository) StreamTransactions(userID string, search domain.TransactionSearch, fn func(transaction domain.PersonalTransaction) error) error {
+	for _, transaction := range m.Transactions {
+		if !matchesSearch(transaction, userID, search) {
+			continue
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockTransactionRepository) GetPaymentSourceTransactions(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PersonalTransaction, error) {
+	var filtered []domain.PersonalTransaction
+	for _, transaction := range m.Transactions {
+		if transaction.UserID != userID || transaction.Date.Before(startDate) || transaction.Date.After(endDate) {
+			continue
+		}
+		if sameID(transaction.PaymentSourceID, paymentSourceID) || sameID(transaction.DestinationPaymentSourceID, paymentSourceID) {
+			filtered = append(filtered, transaction)
+		}
+	}
+	return filtered, nil
+}
+
+func sameID(id *int, other int) bool {
+	return id != nil && *id == other
+}
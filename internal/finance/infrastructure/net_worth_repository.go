@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type NetWorthRepository struct {
+	db *sql.DB
+}
+
+func NewNetWorthRepository(db *sql.DB) *NetWorthRepository {
+	return &NetWorthRepository{db: db}
+}
+
+func (r *NetWorthRepository) Save(userID string, snapshot domain.NetWorthSnapshot) error {
+	breakdown, err := json.Marshal(snapshot.Breakdown)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO net_worth_snapshots (user_id, snapshot_date, base_currency, assets, liabilities, net_worth, breakdown)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, snapshot_date) DO UPDATE
+		SET base_currency = EXCLUDED.base_currency, assets = EXCLUDED.assets, liabilities = EXCLUDED.liabilities,
+		    net_worth = EXCLUDED.net_worth, breakdown = EXCLUDED.breakdown`,
+		userID, snapshot.Date, snapshot.BaseCurrency, snapshot.Assets, snapshot.Liabilities, snapshot.NetWorth, breakdown)
+	return err
+}
+
+func (r *NetWorthRepository) FindInRange(userID string, startDate, endDate time.Time) ([]domain.NetWorthSnapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT snapshot_date, base_currency, assets, liabilities, net_worth, breakdown
+		FROM net_worth_snapshots
+		WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3
+		ORDER BY snapshot_date`, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []domain.NetWorthSnapshot
+	for rows.Next() {
+		var snapshot domain.NetWorthSnapshot
+		var breakdown []byte
+		if err := rows.Scan(&snapshot.Date, &snapshot.BaseCurrency, &snapshot.Assets, &snapshot.Liabilities,
+			&snapshot.NetWorth, &breakdown); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(breakdown, &snapshot.Breakdown); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (r *NetWorthRepository) FindUsersDueForSnapshot(date time.Time) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT u.id
+		FROM users u
+		WHERE (EXISTS (SELECT 1 FROM payment_sources ps WHERE ps.user_id = u.id)
+		    OR EXISTS (SELECT 1 FROM portfolios p WHERE p.user_id = u.id)
+		    OR EXISTS (SELECT 1 FROM loans l WHERE l.user_id = u.id))
+		  AND NOT EXISTS (SELECT 1 FROM net_worth_snapshots s WHERE s.user_id = u.id AND s.snapshot_date = $1)
+		ORDER BY u.id`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
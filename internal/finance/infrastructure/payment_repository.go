@@ -2,15 +2,28 @@ package infrastructure
 
 import (
 	"database/sql"
+	"encoding/json"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
 )
 
+// paymentSourceColumns select a payment source as ps, its balance applies every transaction booked on it and every
+// transfer to it, converted with the FX rate of the transfer.
+const paymentSourceColumns = `ps.id, ps.user_id, ps.payment_method_id, ps.name, ps.details, ps.archived_at IS NOT NULL, ps.currency, ps.opening_balance,
+	ps.opening_balance + COALESCE((
+		SELECT SUM(
+			CASE WHEN t.payment_source_id = ps.id THEN CASE WHEN t.type = 'income' THEN t.amount ELSE -t.amount END ELSE 0 END +
+			CASE WHEN t.destination_payment_source_id = ps.id THEN ROUND(t.amount * COALESCE(t.fx_rate, 1), 2) ELSE 0 END)
+		FROM personal_transactions t
+		WHERE t.payment_source_id = ps.id OR t.destination_payment_source_id = ps.id
+	), 0)`
+
 type PaymentRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *DetailsCipher
 }
 
-func NewPaymentRepository(db *sql.DB) *PaymentRepository {
-	return &PaymentRepository{db: db}
+func NewPaymentRepository(db *sql.DB, cipher *DetailsCipher) *PaymentRepository {
+	return &PaymentRepository{db: db, cipher: cipher}
 }
 
 func (r *PaymentRepository) GetAllPaymentMethods() ([]domain.PaymentMethod, error) {
@@ -37,8 +50,12 @@ func (r *PaymentRepository) GetAllPaymentMethods() ([]domain.PaymentMethod, erro
 	return paymentMethods, nil
 }
 
-func (r *PaymentRepository) GetUserPaymentSources(userID string) ([]domain.PaymentSource, error) {
-	query := "SELECT id, name FROM payment_sources where user_id = $1"
+func (r *PaymentRepository) GetUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error) {
+	query := "SELECT " + paymentSourceColumns + " FROM payment_sources ps WHERE ps.user_id = $1"
+	if !includeArchived {
+		query += " AND ps.archived_at IS NULL"
+	}
+	query += " ORDER BY ps.name"
 
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
@@ -46,29 +63,110 @@ func (r *PaymentRepository) GetUserPaymentSources(userID string) ([]domain.Payme
 	}
 	defer rows.Close()
 
-	var paymentMethods []domain.PaymentSource
+	var paymentSources []domain.PaymentSource
 	for rows.Next() {
-		var method domain.PaymentSource
-		if err := rows.Scan(&method.ID, &method.Name); err != nil {
+		source, err := r.scanPaymentSource(rows)
+		if err != nil {
 			return nil, err
 		}
-		paymentMethods = append(paymentMethods, method)
+		paymentSources = append(paymentSources, *source)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return paymentMethods, nil
+	return paymentSources, nil
+}
+
+func (r *PaymentRepository) FindUserPaymentSourceByID(sourceID int, userID string) (*domain.PaymentSource, error) {
+	row := r.db.QueryRow(`
+		SELECT `+paymentSourceColumns+`
+		FROM payment_sources ps
+		WHERE ps.id = $1 AND ps.user_id = $2`, sourceID, userID)
+	return r.scanPaymentSource(row)
+}
+
+func (r *PaymentRepository) scanPaymentSource(row interface{ Scan(dest ...any) error }) (*domain.PaymentSource, error) {
+	var source domain.PaymentSource
+	var details []byte
+	if err := row.Scan(&source.ID, &source.UserID, &source.PaymentMethodID, &source.Name, &details, &source.Archived,
+		&source.Currency, &source.OpeningBalance, &source.Balance); err != nil {
+		return nil, err
+	}
+
+	source.Details = map[string]string{}
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &source.Details); err != nil {
+			return nil, err
+		}
+	}
+	decrypted, err := r.cipher.Decrypt(source.Details)
+	if err != nil {
+		return nil, err
+	}
+	source.Details = decrypted
+	return &source, nil
+}
+
+func (r *PaymentRepository) encodeDetails(details map[string]string) ([]byte, error) {
+	encrypted, err := r.cipher.Encrypt(details)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encrypted)
+}
+
+func (r *PaymentRepository) CreatePaymentSource(source *domain.PaymentSource) error {
+	details, err := r.encodeDetails(source.Details)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO payment_sources (user_id, payment_method_id, name, details, currency, opening_balance)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	return r.db.QueryRow(query, source.UserID, source.PaymentMethodID, source.Name, details, source.Currency, source.OpeningBalance).Scan(&source.ID)
+}
+
+func (r *PaymentRepository) UpdatePaymentSource(source domain.PaymentSource) (int64, error) {
+	details, err := r.encodeDetails(source.Details)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.Exec(`
+		UPDATE payment_sources
+		SET payment_method_id = $1, name = $2, details = $3, currency = $4, opening_balance = $5
+		WHERE id = $6 AND user_id = $7`,
+		source.PaymentMethodID, source.Name, details, source.Currency, source.OpeningBalance, source.ID, source.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *PaymentRepository) SetPaymentSourceArchived(sourceID int, userID string, archived bool) (int64, error) {
+	query := "UPDATE payment_sources SET archived_at = NULL WHERE id = $1 AND user_id = $2"
+	if archived {
+		query = "UPDATE payment_sources SET archived_at = COALESCE(archived_at, NOW()) WHERE id = $1 AND user_id = $2"
+	}
+	result, err := r.db.Exec(query, sourceID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
+
 func (r *PaymentRepository) PaymentMethodExists(methodID int) (bool, error) {
 	var exists bool
 	query := "SELECT EXISTS(SELECT 1 FROM payment_methods WHERE id = $1)"
 	err := r.db.QueryRow(query, methodID).Scan(&exists)
 	return exists, err
 }
+
+// UserPaymentSourceExists reports whether the user owns the payment source and it is not archived.
 func (r *PaymentRepository) UserPaymentSourceExists(sourceID int, userID string) (bool, error) {
 	var exists bool
-	query := "SELECT EXISTS(SELECT 1 FROM payment_sources WHERE id = $1 AND user_id = $2)"
+	query := "SELECT EXISTS(SELECT 1 FROM payment_sources WHERE id = $1 AND user_id = $2 AND archived_at IS NULL)"
 	err := r.db.QueryRow(query, sourceID, userID).Scan(&exists)
 	return exists, err
 }
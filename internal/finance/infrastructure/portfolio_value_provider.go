@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	assets "github.com/sebuszqo/FinanceManager/internal/investment/asset"
+	portfolios "github.com/sebuszqo/FinanceManager/internal/investment/portfolio"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+)
+
+// PortfolioValueProvider reads the value of investment portfolios for savings goals and net worth.
+type PortfolioValueProvider struct {
+	portfolioService portfolios.Service
+	assetService     assets.Service
+}
+
+func NewPortfolioValueProvider(portfolioService portfolios.Service, assetService assets.Service) *PortfolioValueProvider {
+	return &PortfolioValueProvider{portfolioService: portfolioService, assetService: assetService}
+}
+
+// GetPortfolioValue sums the current value of the assets in the portfolio of the user. Only assets quoted in
+// money.DefaultCurrency are counted, there are no exchange rates to convert the others with.
+func (p *PortfolioValueProvider) GetPortfolioValue(userID, portfolioID string) (money.Decimal, error) {
+	id, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return money.Zero, financeErrors.ErrInvalidPortfolio
+	}
+	ctx := context.Background()
+	if _, err := p.portfolioService.GetPortfolio(ctx, id, userID); err != nil {
+		if errors.Is(err, portfolios.ErrPortfolioNotFound) || errors.Is(err, portfolios.ErrUnauthorizedAccess) {
+			return money.Zero, financeErrors.ErrInvalidPortfolio
+		}
+		return money.Zero, err
+	}
+
+	portfolioAssets, err := p.assetService.GetAllAssets(ctx, id)
+	if err != nil && !errors.Is(err, assets.ErrAssetNotFound) {
+		return money.Zero, err
+	}
+	value := money.Zero
+	for _, asset := range portfolioAssets {
+		if asset.Currency == "" || asset.Currency == money.DefaultCurrency {
+			value = value.Add(asset.CurrentValue)
+		}
+	}
+	return value, nil
+}
+
+// GetUserHoldings returns the current value of every asset in all portfolios of the user, in the currency of the asset
+// and classed by its asset type.
+func (p *PortfolioValueProvider) GetUserHoldings(userID string) ([]domain.NetWorthHolding, error) {
+	ctx := context.Background()
+	userPortfolios, err := p.portfolioService.GetAllPortfolios(ctx, userID)
+	if err != nil {
+		if errors.Is(err, portfolios.ErrPortfolioNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var holdings []domain.NetWorthHolding
+	for _, portfolio := range userPortfolios {
+		portfolioAssets, err := p.assetService.GetAllAssets(ctx, portfolio.ID)
+		if err != nil && !errors.Is(err, assets.ErrAssetNotFound) {
+			return nil, err
+		}
+		for _, asset := range portfolioAssets {
+			currency := asset.Currency
+			if currency == "" {
+				currency = money.DefaultCurrency
+			}
+			assetClass := p.assetService.GetAssetTypeName(asset.AssetTypeID)
+			if assetClass == "" {
+				assetClass = "Other"
+			}
+			holdings = append(holdings, domain.NetWorthHolding{AssetClass: assetClass, Currency: currency, Value: asset.CurrentValue})
+		}
+	}
+	return holdings, nil
+}
@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
+
+type ReconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) FindByPaymentSource(paymentSourceID int, userID string) ([]domain.Reconciliation, error) {
+	rows, err := r.db.Query(`
+		SELECT r.id, r.payment_source_id, r.statement_date, r.statement_balance, r.created_at
+		FROM payment_source_reconciliations r
+		JOIN payment_sources ps ON ps.id = r.payment_source_id
+		WHERE r.payment_source_id = $1 AND ps.user_id = $2
+		ORDER BY r.statement_date DESC, r.id DESC
+		`, paymentSourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reconciliations []domain.Reconciliation
+	for rows.Next() {
+		var reconciliation domain.Reconciliation
+		if err := rows.Scan(&reconciliation.ID, &reconciliation.PaymentSourceID, &reconciliation.StatementDate,
+			&reconciliation.StatementBalance, &reconciliation.CreatedAt); err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+	return reconciliations, rows.Err()
+}
+
+func (r *ReconciliationRepository) Create(reconciliation *domain.Reconciliation, userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO payment_source_reconciliations (payment_source_id, statement_date, statement_balance)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		reconciliation.PaymentSourceID, reconciliation.StatementDate, reconciliation.StatementBalance,
+	).Scan(&reconciliation.ID, &reconciliation.CreatedAt)
+	if err != nil {
+		safeRollback(tx)
+		return err
+	}
+
+	if len(reconciliation.TransactionIDs) > 0 {
+		// transfers to the payment source are reconciled on their destination end, independently of their source
+		var reconciled int64
+		for _, query := range []string{`
+			UPDATE personal_transactions SET reconciliation_id = $1
+			WHERE id = ANY($2) AND user_id = $3 AND payment_source_id = $4 AND reconciliation_id IS NULL`, `
+			UPDATE personal_transactions SET destination_reconciliation_id = $1
+			WHERE id = ANY($2) AND user_id = $3 AND destination_payment_source_id = $4 AND destination_reconciliation_id IS NULL`,
+		} {
+			result, err := tx.Exec(query, reconciliation.ID, reconciliation.TransactionIDs, userID, reconciliation.PaymentSourceID)
+			if err != nil {
+				safeRollback(tx)
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				safeRollback(tx)
+				return err
+			}
+			reconciled += affected
+		}
+		// another request reconciled some of them in the meantime
+		if reconciled != int64(len(reconciliation.TransactionIDs)) {
+			safeRollback(tx)
+			return financeErrors.ErrTransactionReconciled
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,222 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"log"
+	"time"
+)
+
+type RecurringRuleRepository struct {
+	db *sql.DB
+}
+
+func NewRecurringRuleRepository(db *sql.DB) *RecurringRuleRepository {
+	return &RecurringRuleRepository{db: db}
+}
+
+const recurringRuleColumns = `
+		id, user_id, frequency, interval_count, start_date, end_date, occurrence_count, generated_until,
+		name, amount, type, description, predefined_category_id, user_category_id, payment_method_id, payment_source_id,
+		destination_payment_source_id`
+
+func (r *RecurringRuleRepository) Create(rule *domain.RecurringRule) error {
+	_, err := r.db.Exec(`
+		INSERT INTO recurring_rules (`+recurringRuleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		rule.ID, rule.UserID, rule.Frequency, rule.Interval, rule.StartDate, rule.EndDate, rule.Count, rule.GeneratedUntil,
+		rule.Template.Name, rule.Template.Amount, rule.Template.Type, rule.Template.Description, nullableID(rule.Template.PredefinedCategoryID),
+		rule.Template.UserCategoryID, rule.Template.PaymentMethodID, rule.Template.PaymentSourceID, rule.Template.DestinationPaymentSourceID,
+	)
+	return err
+}
+
+func (r *RecurringRuleRepository) Update(rule domain.RecurringRule) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE recurring_rules
+		SET frequency = $1, interval_count = $2, start_date = $3, end_date = $4, occurrence_count = $5,
+		    name = $6, amount = $7, type = $8, description = $9, predefined_category_id = $10, user_category_id = $11,
+		    payment_method_id = $12, payment_source_id = $13, destination_payment_source_id = $14
+		WHERE id = $15 AND user_id = $16`,
+		rule.Frequency, rule.Interval, rule.StartDate, rule.EndDate, rule.Count,
+		rule.Template.Name, rule.Template.Amount, rule.Template.Type, rule.Template.Description, nullableID(rule.Template.PredefinedCategoryID),
+		rule.Template.UserCategoryID, rule.Template.PaymentMethodID, rule.Template.PaymentSourceID, rule.Template.DestinationPaymentSourceID,
+		rule.ID, rule.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *RecurringRuleRepository) Delete(ruleID string, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM recurring_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *RecurringRuleRepository) FindByID(ruleID string, userID string) (*domain.RecurringRule, error) {
+	row := r.db.QueryRow(`SELECT `+recurringRuleColumns+` FROM recurring_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	return scanRecurringRule(row)
+}
+
+func (r *RecurringRuleRepository) FindByUser(userID string) ([]domain.RecurringRule, error) {
+	return r.queryRules(`SELECT `+recurringRuleColumns+` FROM recurring_rules WHERE user_id = $1 ORDER BY start_date`, userID)
+}
+
+// FindDue returns rules of all users which may have occurrences not booked yet up to the given date. The date of the
+// last occurrence of a rule with a count mirrors RecurringRule.OccurrenceDate, adding months to a date clamps it to the
+// end of shorter months in Postgres too.
+func (r *RecurringRuleRepository) FindDue(until time.Time) ([]domain.RecurringRule, error) {
+	return r.queryRules(`
+		SELECT `+recurringRuleColumns+`
+		FROM recurring_rules
+		WHERE start_date <= $1
+		  AND (generated_until IS NULL OR generated_until < $1)
+		  AND (end_date IS NULL OR generated_until IS NULL OR generated_until < end_date)
+		  AND (occurrence_count IS NULL OR generated_until IS NULL OR generated_until < start_date + CASE frequency
+		      WHEN 'daily' THEN make_interval(days => (occurrence_count - 1) * interval_count)
+		      WHEN 'weekly' THEN make_interval(weeks => (occurrence_count - 1) * interval_count)
+		      WHEN 'monthly' THEN make_interval(months => (occurrence_count - 1) * interval_count)
+		      ELSE make_interval(years => (occurrence_count - 1) * interval_count)
+		  END)`, until)
+}
+
+func (r *RecurringRuleRepository) queryRules(query string, args ...interface{}) ([]domain.RecurringRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []domain.RecurringRule
+	for rows.Next() {
+		rule, err := scanRecurringRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func scanRecurringRule(row interface{ Scan(dest ...any) error }) (*domain.RecurringRule, error) {
+	var rule domain.RecurringRule
+	var endDate, generatedUntil sql.NullTime
+	var count, predefinedCategoryID, userCategoryID, paymentSourceID, destinationPaymentSourceID sql.NullInt32
+
+	if err := row.Scan(
+		&rule.ID, &rule.UserID, &rule.Frequency, &rule.Interval, &rule.StartDate, &endDate, &count, &generatedUntil,
+		&rule.Template.Name, &rule.Template.Amount, &rule.Template.Type, &rule.Template.Description,
+		&predefinedCategoryID, &userCategoryID, &rule.Template.PaymentMethodID, &paymentSourceID, &destinationPaymentSourceID,
+	); err != nil {
+		return nil, err
+	}
+
+	if endDate.Valid {
+		rule.EndDate = &endDate.Time
+	}
+	if generatedUntil.Valid {
+		rule.GeneratedUntil = &generatedUntil.Time
+	}
+	if count.Valid {
+		value := int(count.Int32)
+		rule.Count = &value
+	}
+	rule.Template.PredefinedCategoryID = int(predefinedCategoryID.Int32)
+	if userCategoryID.Valid {
+		value := int(userCategoryID.Int32)
+		rule.Template.UserCategoryID = &value
+	}
+	if paymentSourceID.Valid {
+		value := int(paymentSourceID.Int32)
+		rule.Template.PaymentSourceID = &value
+	}
+	if destinationPaymentSourceID.Valid {
+		value := int(destinationPaymentSourceID.Int32)
+		rule.Template.DestinationPaymentSourceID = &value
+	}
+	return &rule, nil
+}
+
+func (r *RecurringRuleRepository) FindExceptions(ruleID string) ([]domain.RecurringException, error) {
+	rows, err := r.db.Query(`
+		SELECT rule_id, occurrence_date, skipped, name, amount, description
+		FROM recurring_rule_exceptions
+		WHERE rule_id = $1
+		ORDER BY occurrence_date`, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exceptions []domain.RecurringException
+	for rows.Next() {
+		var exception domain.RecurringException
+		if err := rows.Scan(&exception.RuleID, &exception.OccurrenceDate, &exception.Skipped, &exception.Name, &exception.Amount, &exception.Description); err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+	return exceptions, rows.Err()
+}
+
+func (r *RecurringRuleRepository) SaveException(exception domain.RecurringException) error {
+	_, err := r.db.Exec(`
+		INSERT INTO recurring_rule_exceptions (rule_id, occurrence_date, skipped, name, amount, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (rule_id, occurrence_date)
+		DO UPDATE SET skipped = EXCLUDED.skipped, name = EXCLUDED.name, amount = EXCLUDED.amount, description = EXCLUDED.description`,
+		exception.RuleID, exception.OccurrenceDate, exception.Skipped, exception.Name, exception.Amount, exception.Description,
+	)
+	return err
+}
+
+func (r *RecurringRuleRepository) DeleteException(ruleID string, occurrenceDate time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM recurring_rule_exceptions WHERE rule_id = $1 AND occurrence_date = $2`, ruleID, occurrenceDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SaveOccurrences books the generated transactions and moves generated_until of the rule in a single database transaction.
+// An occurrence which has already been booked is skipped, so running the job twice never duplicates transactions.
+func (r *RecurringRuleRepository) SaveOccurrences(ruleID string, transactions []domain.RecurringOccurrenceTransaction, generatedUntil time.Time) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("Error during transaction rollback: %v", rollbackErr)
+			}
+		}
+	}()
+
+	for _, occurrence := range transactions {
+		transaction := occurrence.Transaction
+		_, err = tx.Exec(`
+			INSERT INTO personal_transactions
+			(id, name, predefined_category_id, user_category_id, user_id, amount, type, date, description, payment_method_id, payment_source_id,
+			 destination_payment_source_id, recurring_rule_id, recurring_occurrence_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			ON CONFLICT (recurring_rule_id, recurring_occurrence_date) DO NOTHING`,
+			transaction.ID, transaction.Name, nullableID(transaction.PredefinedCategoryID), transaction.UserCategoryID, transaction.UserID, transaction.Amount,
+			transaction.Type, transaction.Date, transaction.Description, transaction.PaymentMethodID, transaction.PaymentSourceID,
+			transaction.DestinationPaymentSourceID, ruleID, occurrence.OccurrenceDate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE recurring_rules SET generated_until = $1 WHERE id = $2`, generatedUntil, ruleID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"time"
+)
+
+type SavingsGoalRepository struct {
+	db *sql.DB
+}
+
+func NewSavingsGoalRepository(db *sql.DB) *SavingsGoalRepository {
+	return &SavingsGoalRepository{db: db}
+}
+
+const savingsGoalSelect = `
+		SELECT id, user_id, name, target_amount, target_date, funding, payment_source_id, portfolio_id, start_date,
+		       starting_amount
+		FROM savings_goals`
+
+func (r *SavingsGoalRepository) FindByUser(userID string) ([]domain.SavingsGoal, error) {
+	return r.findMany(savingsGoalSelect+` WHERE user_id = $1 ORDER BY target_date, id`, userID)
+}
+
+func (r *SavingsGoalRepository) FindByID(goalID int, userID string) (*domain.SavingsGoal, error) {
+	row := r.db.QueryRow(savingsGoalSelect+` WHERE id = $1 AND user_id = $2`, goalID, userID)
+	return scanSavingsGoal(row)
+}
+
+func (r *SavingsGoalRepository) FindDueForProgressEmail(monthStart time.Time) ([]domain.SavingsGoal, error) {
+	return r.findMany(savingsGoalSelect+`
+		WHERE start_date < $1 AND (last_progress_email IS NULL OR last_progress_email < $1)
+		ORDER BY user_id, target_date, id`, monthStart)
+}
+
+func (r *SavingsGoalRepository) findMany(query string, args ...any) ([]domain.SavingsGoal, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []domain.SavingsGoal
+	for rows.Next() {
+		goal, err := scanSavingsGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, *goal)
+	}
+	return goals, rows.Err()
+}
+
+func scanSavingsGoal(row interface{ Scan(dest ...any) error }) (*domain.SavingsGoal, error) {
+	var goal domain.SavingsGoal
+	var paymentSourceID sql.NullInt32
+	var portfolioID sql.NullString
+	if err := row.Scan(
+		&goal.ID, &goal.UserID, &goal.Name, &goal.TargetAmount, &goal.TargetDate, &goal.Funding, &paymentSourceID,
+		&portfolioID, &goal.StartDate, &goal.StartingAmount,
+	); err != nil {
+		return nil, err
+	}
+	if paymentSourceID.Valid {
+		value := int(paymentSourceID.Int32)
+		goal.PaymentSourceID = &value
+	}
+	if portfolioID.Valid {
+		goal.PortfolioID = &portfolioID.String
+	}
+	return &goal, nil
+}
+
+func (r *SavingsGoalRepository) Create(goal *domain.SavingsGoal) error {
+	query := `
+		INSERT INTO savings_goals (user_id, name, target_amount, target_date, funding, payment_source_id, portfolio_id,
+		                           start_date, starting_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	return r.db.QueryRow(query, goal.UserID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.Funding,
+		goal.PaymentSourceID, goal.PortfolioID, goal.StartDate, goal.StartingAmount).Scan(&goal.ID)
+}
+
+func (r *SavingsGoalRepository) Update(goal domain.SavingsGoal) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE savings_goals
+		SET name = $1, target_amount = $2, target_date = $3, funding = $4, payment_source_id = $5, portfolio_id = $6,
+		    start_date = $7, starting_amount = $8
+		WHERE id = $9 AND user_id = $10`,
+		goal.Name, goal.TargetAmount, goal.TargetDate, goal.Funding, goal.PaymentSourceID, goal.PortfolioID,
+		goal.StartDate, goal.StartingAmount, goal.ID, goal.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SavingsGoalRepository) Delete(goalID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM savings_goals WHERE id = $1 AND user_id = $2`, goalID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SavingsGoalRepository) RecordProgressEmail(goalIDs []int, sentOn time.Time) error {
+	_, err := r.db.Exec(`UPDATE savings_goals SET last_progress_email = $1 WHERE id = ANY($2)`, sentOn, goalIDs)
+	return err
+}
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+)
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) FindByUser(userID string) ([]domain.Tag, error) {
+	rows, err := r.db.Query(`SELECT id, user_id, name FROM tags WHERE user_id = $1 ORDER BY name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []domain.Tag
+	for rows.Next() {
+		var tag domain.Tag
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (r *TagRepository) FindByID(tagID int, userID string) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.QueryRow(`SELECT id, user_id, name FROM tags WHERE id = $1 AND user_id = $2`, tagID, userID).
+		Scan(&tag.ID, &tag.UserID, &tag.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) DoesTagNameExist(name string, userID string, excludedID int) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM tags WHERE LOWER(name) = LOWER($1) AND user_id = $2 AND id <> $3)"
+	err := r.db.QueryRow(query, name, userID, excludedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *TagRepository) Create(tag *domain.Tag) error {
+	return r.db.QueryRow(`INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING id`, tag.UserID, tag.Name).Scan(&tag.ID)
+}
+
+func (r *TagRepository) Update(tag domain.Tag) (int64, error) {
+	result, err := r.db.Exec(`UPDATE tags SET name = $1 WHERE id = $2 AND user_id = $3`, tag.Name, tag.ID, tag.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *TagRepository) Delete(tagID int, userID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE id = $1 AND user_id = $2`, tagID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -2,7 +2,15 @@ package infrastructure
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"log"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,38 +22,76 @@ func NewPersonalTransactionRepository(db *sql.DB) *PersonalTransactionRepository
 	return &PersonalTransactionRepository{db: db}
 }
 
+const transactionColumns = `id, name, user_id, amount, type, date, description, predefined_category_id, user_category_id,
+	payment_method_id, payment_source_id, destination_payment_source_id, fx_rate, reconciliation_id, external_id,
+	destination_reconciliation_id`
+
+// transactionLines lists every income and expense as its split lines, or as a single line when it isn't split, so
+// that summaries attribute amounts to the category of each line. Transfers are left out.
+const transactionLines = `(
+	SELECT t.user_id, t.type, t.date, t.payment_method_id,
+	       COALESCE(s.predefined_category_id, t.predefined_category_id) AS predefined_category_id,
+	       CASE WHEN s.id IS NULL THEN t.user_category_id ELSE s.user_category_id END AS user_category_id,
+	       COALESCE(s.amount, t.amount) AS amount
+	FROM personal_transactions t
+	LEFT JOIN personal_transaction_splits s ON s.transaction_id = t.id
+	WHERE t.type <> 'transfer'
+	)`
+
 func (r *PersonalTransactionRepository) Save(transaction domain.PersonalTransaction) error {
-	_, err := r.db.Exec(
-		`INSERT INTO personal_transactions 
-        (id, name, predefined_category_id, user_category_id, user_id, amount, type, date, description, payment_method_id, payment_source_id) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		transaction.ID, transaction.Name, transaction.PredefinedCategoryID, transaction.UserCategoryID, transaction.UserID, transaction.Amount,
-		transaction.Type, transaction.Date, transaction.Description, transaction.PaymentMethodID, transaction.PaymentSourceID,
-	)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := r.SaveWithTransaction(transaction, tx); err != nil {
+		safeRollback(tx)
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *PersonalTransactionRepository) GetTransactionsByType(userID string, transactionType string, startDate time.Time, endDate time.Time, limit int, page int) ([]domain.PersonalTransaction, error) {
-	query := `
-		SELECT id, name, user_id, amount, type, date, description, predefined_category_id, user_category_id, payment_method_id, payment_source_id 
-		FROM personal_transactions 
-		WHERE user_id = $1 AND date >= $2 AND date <= $3
-		ORDER BY date DESC LIMIT $4 OFFSET $5
-		`
+// transactionSortColumns maps the sort fields to their column and the cast of the cursor value compared with it.
+var transactionSortColumns = map[domain.TransactionSortField][2]string{
+	domain.TransactionSortDate:   {"date", "::date"},
+	domain.TransactionSortAmount: {"amount", "::numeric"},
+	domain.TransactionSortName:   {"name", ""},
+}
 
-	args := []interface{}{userID, startDate, endDate}
+// SearchTransactions returns a page of the transactions of the user matching the search, together with the total
+// number of matches. Pages following a cursor start right after the transaction it points at (keyset pagination),
+// so transactions added in the meantime don't shift them.
+func (r *PersonalTransactionRepository) SearchTransactions(userID string, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := searchConditions(userID, search, arg)
 
-	if transactionType != "" {
-		query = `
-		SELECT id, name, user_id, amount, type, date, description, predefined_category_id, user_category_id, payment_method_id, payment_source_id 
-		FROM personal_transactions 
-		WHERE user_id = $1 AND date >= $2 AND date <= $3 AND type = $4
-		ORDER BY date DESC LIMIT $5 OFFSET $6
-		`
-		args = append(args, transactionType)
+	page := &domain.TransactionPage{Transactions: []domain.PersonalTransaction{}}
+	err := r.db.QueryRow("SELECT COUNT(*) FROM personal_transactions WHERE "+strings.Join(where, " AND "), args...).Scan(&page.TotalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	sortColumn := transactionSortColumns[search.SortBy]
+	direction, comparison := "DESC", "<"
+	if search.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+	cursor, err := search.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s%s, %s::uuid)", sortColumn[0], comparison, arg(cursor.Value), sortColumn[1], arg(cursor.ID)))
 	}
 
-	args = append(args, limit, (page-1)*limit)
+	query := fmt.Sprintf("SELECT %s FROM personal_transactions WHERE %s ORDER BY %s %s, id %s LIMIT %s",
+		transactionColumns, strings.Join(where, " AND "), sortColumn[0], direction, direction, arg(search.Limit+1))
+	if cursor == nil && search.Page > 1 {
+		query += " OFFSET " + arg((search.Page-1)*search.Limit)
+	}
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -53,66 +99,241 @@ func (r *PersonalTransactionRepository) GetTransactionsByType(userID string, tra
 	}
 	defer rows.Close()
 
-	var transactions []domain.PersonalTransaction
 	for rows.Next() {
-		var transaction domain.PersonalTransaction
-
-		var userCategoryID sql.NullInt32
-		var paymentSourceID sql.NullInt32
-
-		if err := rows.Scan(
-			&transaction.ID,
-			&transaction.Name,
-			&transaction.UserID,
-			&transaction.Amount,
-			&transaction.Type,
-			&transaction.Date,
-			&transaction.Description,
-			&transaction.PredefinedCategoryID,
-			&userCategoryID,
-			&transaction.PaymentMethodID,
-			&paymentSourceID,
-		); err != nil {
+		transaction, err := scanPersonalTransaction(rows)
+		if err != nil {
 			return nil, err
 		}
+		page.Transactions = append(page.Transactions, *transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		if userCategoryID.Valid {
-			value := int(userCategoryID.Int32)
-			transaction.UserCategoryID = &value
-		} else {
-			transaction.UserCategoryID = nil
-		}
-
-		if paymentSourceID.Valid {
-			value := int(paymentSourceID.Int32)
-			transaction.PaymentSourceID = &value
-		} else {
-			transaction.PaymentSourceID = nil
-		}
-
-		transactions = append(transactions, transaction)
+	// one more row than requested tells whether there is a next page
+	if len(page.Transactions) > search.Limit {
+		page.Transactions = page.Transactions[:search.Limit]
+		page.NextCursor = search.NextCursor(page.Transactions[search.Limit-1])
 	}
+	return page, r.loadDetails(page.Transactions)
+}
 
-	return transactions, nil
+// searchConditions returns the WHERE conditions of personal_transactions matching the filters of the search, arg adds
+// a query argument and returns its placeholder.
+func searchConditions(userID string, search domain.TransactionSearch, arg func(value interface{}) string) []string {
+	where := []string{"user_id = " + arg(userID), "date >= " + arg(search.StartDate), "date <= " + arg(search.EndDate)}
+	if search.Type != "" {
+		where = append(where, "type = "+arg(search.Type))
+	}
+	if len(search.PredefinedCategoryIDs) > 0 {
+		ids := arg(search.PredefinedCategoryIDs)
+		where = append(where, "(predefined_category_id = ANY("+ids+") OR id IN (SELECT transaction_id FROM personal_transaction_splits WHERE predefined_category_id = ANY("+ids+")))")
+	}
+	if len(search.UserCategoryIDs) > 0 {
+		ids := arg(search.UserCategoryIDs)
+		where = append(where, "(user_category_id = ANY("+ids+") OR id IN (SELECT transaction_id FROM personal_transaction_splits WHERE user_category_id = ANY("+ids+")))")
+	}
+	if len(search.PaymentMethodIDs) > 0 {
+		where = append(where, "payment_method_id = ANY("+arg(search.PaymentMethodIDs)+")")
+	}
+	if len(search.PaymentSourceIDs) > 0 {
+		ids := arg(search.PaymentSourceIDs)
+		where = append(where, "(payment_source_id = ANY("+ids+") OR destination_payment_source_id = ANY("+ids+"))")
+	}
+	if len(search.TagIDs) > 0 {
+		where = append(where, "id IN (SELECT transaction_id FROM personal_transaction_tags WHERE tag_id = ANY("+arg(search.TagIDs)+"))")
+	}
+	if search.MinAmount != nil {
+		where = append(where, "amount >= "+arg(*search.MinAmount))
+	}
+	if search.MaxAmount != nil {
+		where = append(where, "amount <= "+arg(*search.MaxAmount))
+	}
+	if search.Text != "" {
+		where = append(where, "search_vector @@ websearch_to_tsquery('simple', "+arg(search.Text)+")")
+	}
+	return where
 }
 
 func (r *PersonalTransactionRepository) BeginTransaction() (*sql.Tx, error) {
 	return r.db.Begin()
 }
 
+// SaveWithTransaction doesn't fail on a taken external ID, so that the caller can resolve the conflict in the same
+// database transaction.
 func (r *PersonalTransactionRepository) SaveWithTransaction(transaction domain.PersonalTransaction, tx *sql.Tx) error {
-	_, err := tx.Exec(
-		`INSERT INTO personal_transactions (id, name, predefined_category_id, user_category_id, user_id, amount, type, date, description, payment_method_id, payment_source_id) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		transaction.ID, transaction.Name, transaction.PredefinedCategoryID, transaction.UserCategoryID, transaction.UserID, transaction.Amount,
+	result, err := tx.Exec(
+		`INSERT INTO personal_transactions (id, name, predefined_category_id, user_category_id, user_id, amount, type, date, description, payment_method_id, payment_source_id,
+                                   destination_payment_source_id, fx_rate, external_id) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        ON CONFLICT (user_id, external_id) WHERE external_id IS NOT NULL DO NOTHING`,
+		transaction.ID, transaction.Name, nullableID(transaction.PredefinedCategoryID), transaction.UserCategoryID, transaction.UserID, transaction.Amount,
 		transaction.Type, transaction.Date, transaction.Description, transaction.PaymentMethodID, transaction.PaymentSourceID,
+		transaction.DestinationPaymentSourceID, transaction.FXRate, transaction.ExternalID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return financeErrors.ErrExternalIDTaken
+	}
+	if err := insertSplits(tx, transaction); err != nil {
+		return err
+	}
+	return insertTags(tx, transaction)
+}
+
+func insertSplits(tx *sql.Tx, transaction domain.PersonalTransaction) error {
+	for _, split := range transaction.Splits {
+		_, err := tx.Exec(
+			`INSERT INTO personal_transaction_splits (transaction_id, predefined_category_id, user_category_id, amount, description)
+			VALUES ($1, $2, $3, $4, $5)`,
+			transaction.ID, split.PredefinedCategoryID, split.UserCategoryID, split.Amount, split.Description,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertTags(tx *sql.Tx, transaction domain.PersonalTransaction) error {
+	for _, tagID := range transaction.TagIDs {
+		_, err := tx.Exec(`INSERT INTO personal_transaction_tags (transaction_id, tag_id) VALUES ($1, $2)`, transaction.ID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDetails fills in the split lines, tags and attachments of the transactions.
+func (r *PersonalTransactionRepository) loadDetails(transactions []domain.PersonalTransaction) error {
+	if err := r.loadSplits(transactions); err != nil {
+		return err
+	}
+	if err := r.loadTags(transactions); err != nil {
+		return err
+	}
+	return r.loadAttachments(transactions)
+}
+
+// loadSplits fills in the split lines of the transactions with a single query.
+func (r *PersonalTransactionRepository) loadSplits(transactions []domain.PersonalTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(transactions))
+	indexByID := make(map[string]int, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+		indexByID[transaction.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT transaction_id, predefined_category_id, user_category_id, amount, description
+		FROM personal_transaction_splits
+		WHERE transaction_id = ANY($1)
+		ORDER BY id
+		`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID string
+		var split domain.TransactionSplit
+		var userCategoryID sql.NullInt32
+		if err := rows.Scan(&transactionID, &split.PredefinedCategoryID, &userCategoryID, &split.Amount, &split.Description); err != nil {
+			return err
+		}
+		if userCategoryID.Valid {
+			value := int(userCategoryID.Int32)
+			split.UserCategoryID = &value
+		}
+		index := indexByID[transactionID]
+		transactions[index].Splits = append(transactions[index].Splits, split)
+	}
+	return rows.Err()
+}
+
+// loadTags fills in the tag IDs of the transactions with a single query.
+func (r *PersonalTransactionRepository) loadTags(transactions []domain.PersonalTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(transactions))
+	indexByID := make(map[string]int, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+		indexByID[transaction.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT transaction_id, tag_id
+		FROM personal_transaction_tags
+		WHERE transaction_id = ANY($1)
+		ORDER BY tag_id
+		`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID string
+		var tagID int
+		if err := rows.Scan(&transactionID, &tagID); err != nil {
+			return err
+		}
+		index := indexByID[transactionID]
+		transactions[index].TagIDs = append(transactions[index].TagIDs, tagID)
+	}
+	return rows.Err()
+}
+
+// loadAttachments fills in the attachment metadata of the transactions with a single query.
+func (r *PersonalTransactionRepository) loadAttachments(transactions []domain.PersonalTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(transactions))
+	indexByID := make(map[string]int, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+		indexByID[transaction.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+attachmentColumns+`
+		FROM personal_transaction_attachments
+		WHERE transaction_id = ANY($1)
+		ORDER BY created_at, id
+		`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return err
+		}
+		index := indexByID[attachment.TransactionID]
+		transactions[index].Attachments = append(transactions[index].Attachments, *attachment)
+	}
+	return rows.Err()
 }
 
 func (r *PersonalTransactionRepository) GetTransactionsInDateRange(userID string, startDate, endDate time.Time) ([]domain.PersonalTransaction, error) {
 	rows, err := r.db.Query(`
-			SELECT id, name, amount, date, type
+			SELECT `+transactionColumns+`
 			FROM personal_transactions
 			WHERE user_id = $1 AND date >= $2 AND date <= $3
 			ORDER BY date
@@ -124,45 +345,187 @@ func (r *PersonalTransactionRepository) GetTransactionsInDateRange(userID string
 
 	var transactions []domain.PersonalTransaction
 	for rows.Next() {
-		var transaction domain.PersonalTransaction
-		if err := rows.Scan(&transaction.ID, &transaction.Name, &transaction.Amount, &transaction.Date, &transaction.Type); err != nil {
+		transaction, err := scanPersonalTransaction(rows)
+		if err != nil {
 			return nil, err
 		}
-		transactions = append(transactions, transaction)
+		transactions = append(transactions, *transaction)
 	}
-	return transactions, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, r.loadDetails(transactions)
 }
 
+func (r *PersonalTransactionRepository) FindDuplicateCandidates(userID string, startDate, endDate time.Time, externalIDs []string) ([]domain.PersonalTransaction, error) {
+	if externalIDs == nil {
+		externalIDs = []string{}
+	}
+	rows, err := r.db.Query(`
+			SELECT `+transactionColumns+`
+			FROM personal_transactions
+			WHERE user_id = $1 AND ((date >= $2 AND date <= $3) OR external_id = ANY($4))
+			ORDER BY date, id
+		`, userID, startDate, endDate, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []domain.PersonalTransaction
+	for rows.Next() {
+		transaction, err := scanPersonalTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, r.loadDetails(transactions)
+}
+
+// GetPaymentSourceTransactions returns the transactions booked on the payment source, including transfers to and from
+// it, oldest first.
+func (r *PersonalTransactionRepository) GetPaymentSourceTransactions(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PersonalTransaction, error) {
+	rows, err := r.db.Query(`
+			SELECT `+transactionColumns+`
+			FROM personal_transactions
+			WHERE user_id = $1 AND (payment_source_id = $2 OR destination_payment_source_id = $2) AND date >= $3 AND date <= $4
+			ORDER BY date, id
+		`, userID, paymentSourceID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []domain.PersonalTransaction
+	for rows.Next() {
+		transaction, err := scanPersonalTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, r.loadDetails(transactions)
+}
+
+// streamBatchSize is the number of streamed transactions whose split lines are loaded with a single query.
+const streamBatchSize = 500
+
+// StreamTransactions calls fn for every transaction of the user matching the filters of the search, oldest first,
+// without loading them all into memory. Split lines are loaded per batch of transactions, tags and attachments aren't
+// loaded. An error returned by fn stops the iteration and is returned as is.
+func (r *PersonalTransactionRepository) StreamTransactions(userID string, search domain.TransactionSearch, fn func(transaction domain.PersonalTransaction) error) error {
+	var args []interface{}
+	where := searchConditions(userID, search, func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	})
+
+	rows, err := r.db.Query("SELECT "+transactionColumns+" FROM personal_transactions WHERE "+strings.Join(where, " AND ")+" ORDER BY date, id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]domain.PersonalTransaction, 0, streamBatchSize)
+	flush := func() error {
+		if err := r.loadSplits(batch); err != nil {
+			return err
+		}
+		for _, transaction := range batch {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		transaction, err := scanPersonalTransaction(rows)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, *transaction)
+		if len(batch) == streamBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// GetTransactionPeriodTotals groups the income and expenses by month and week in the database. The dates are cast to
+// timestamps without a time zone, date_trunc would otherwise shift them by the time zone of the session. date_trunc
+// starts weeks on Monday, for another first day the dates are moved so that it falls on a Monday and moved back after.
+func (r *PersonalTransactionRepository) GetTransactionPeriodTotals(userID string, search domain.TransactionSearch, firstDay time.Weekday) ([]domain.TransactionPeriodTotal, error) {
+	args := []interface{}{(int(time.Monday) - int(firstDay) + 7) % 7}
+	where := searchConditions(userID, search, func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	})
+
+	rows, err := r.db.Query(`
+		SELECT date_trunc('month', date::timestamp)::date AS month_start,
+		       (date_trunc('week', (date + $1::int)::timestamp) - make_interval(days => $1::int))::date AS week_start,
+		       type,
+		       SUM(amount)
+		FROM personal_transactions
+		WHERE `+strings.Join(where, " AND ")+` AND type IN ('income', 'expense')
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+		`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.TransactionPeriodTotal
+	for rows.Next() {
+		var total domain.TransactionPeriodTotal
+		if err := rows.Scan(&total.MonthStart, &total.WeekStart, &total.Type, &total.Total); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
+
+// GetTransactionSummaryByCategory sums transactions per predefined category, split transactions are attributed per
+// line. Amounts booked on a user category are rolled up into the parent of that user category and reported separately
+// in SubCategories.
 func (r *PersonalTransactionRepository) GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error) {
 	query := `
-	SELECT c.name AS category_name, 
-           t.predefined_category_id AS category_id, 
+	SELECT COALESCE(uc.parent_category_id, t.predefined_category_id) AS category_id,
+           c.name AS category_name,
+           uc.id AS user_category_id,
+           uc.name AS user_category_name,
            SUM(t.amount) AS total_amount
-	FROM personal_transactions t
-	LEFT JOIN predefined_categories c ON t.predefined_category_id = c.id
+	FROM ` + transactionLines + ` t
+	LEFT JOIN user_categories uc ON t.user_category_id = uc.id
+	LEFT JOIN predefined_categories c ON c.id = COALESCE(uc.parent_category_id, t.predefined_category_id)
 	WHERE t.user_id = $1
 	AND t.date >= $2
 	AND t.date <= $3
-	GROUP BY category_id, category_name ORDER BY total_amount DESC
 	 `
 
 	args := []interface{}{userID, startDate, endDate}
 
 	if transactionType != "" {
-		query = `
-	SELECT c.name AS category_name, 
-           t.predefined_category_id AS category_id, 
-           SUM(t.amount) AS total_amount
-	FROM personal_transactions t
-	LEFT JOIN predefined_categories c ON t.predefined_category_id = c.id
-	WHERE t.user_id = $1
-	AND t.date >= $2
-	AND t.date <= $3
-	AND t.type = $4
-	GROUP BY category_id, category_name ORDER BY total_amount DESC
-	 `
+		query += ` AND t.type = $4`
 		args = append(args, transactionType)
 	}
+	query += ` GROUP BY 1, 2, 3, 4`
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -171,47 +534,107 @@ func (r *PersonalTransactionRepository) GetTransactionSummaryByCategory(userID s
 	defer rows.Close()
 
 	var summaries []domain.TransactionByCategorySummary
+	indexByCategory := make(map[int]int)
 	for rows.Next() {
-		var summary domain.TransactionByCategorySummary
-		if err := rows.Scan(&summary.CategoryName, &summary.CategoryID, &summary.TotalAmount); err != nil {
+		var categoryID int
+		var categoryName string
+		var userCategoryID sql.NullInt32
+		var userCategoryName sql.NullString
+		var totalAmount money.Decimal
+		if err := rows.Scan(&categoryID, &categoryName, &userCategoryID, &userCategoryName, &totalAmount); err != nil {
 			return nil, err
 		}
-		summaries = append(summaries, summary)
+
+		index, exists := indexByCategory[categoryID]
+		if !exists {
+			summaries = append(summaries, domain.TransactionByCategorySummary{CategoryID: categoryID, CategoryName: categoryName})
+			index = len(summaries) - 1
+			indexByCategory[categoryID] = index
+		}
+		summaries[index].TotalAmount = summaries[index].TotalAmount.Add(totalAmount)
+
+		if userCategoryID.Valid {
+			summaries[index].SubCategories = append(summaries[index].SubCategories, domain.TransactionByUserCategorySummary{
+				UserCategoryID:   int(userCategoryID.Int32),
+				UserCategoryName: userCategoryName.String,
+				TotalAmount:      totalAmount,
+			})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
+	for i := range summaries {
+		sort.Slice(summaries[i].SubCategories, func(a, b int) bool {
+			return summaries[i].SubCategories[a].TotalAmount.GreaterThan(summaries[i].SubCategories[b].TotalAmount)
+		})
+	}
+	sort.Slice(summaries, func(a, b int) bool {
+		return summaries[a].TotalAmount.GreaterThan(summaries[b].TotalAmount)
+	})
+
 	return summaries, nil
 }
 
-func (r *PersonalTransactionRepository) GetTransactionSummaryByPaymentMethod(userID string, startDate time.Time, endDate time.Time, transactionType string) ([]domain.TransactionByPaymentMethodSummary, error) {
+// GetTransactionSummaryByTag sums the incomes and expenses per tag. A transaction is counted in full in the total of
+// each of its tags, so the totals of different tags may overlap.
+func (r *PersonalTransactionRepository) GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByTagSummary, error) {
 	query := `
-	SELECT c.name AS method_name, 
-           t.payment_method_id AS method_id, 
-           SUM(t.amount) AS total_amount
+	SELECT g.id, g.name, SUM(t.amount), COUNT(*)
 	FROM personal_transactions t
-	LEFT JOIN payment_methods c ON t.payment_method_id = c.id
+	JOIN personal_transaction_tags tt ON tt.transaction_id = t.id
+	JOIN tags g ON g.id = tt.tag_id
 	WHERE t.user_id = $1
 	AND t.date >= $2
 	AND t.date <= $3
-	GROUP BY method_id, method_name  ORDER BY total_amount DESC
-	 `
+	AND t.type <> 'transfer'`
 
 	args := []interface{}{userID, startDate, endDate}
 
 	if transactionType != "" {
-		query = `
-	SELECT c.name AS method_name, 
-           t.payment_method_id AS method_id, 
+		query += ` AND t.type = $4`
+		args = append(args, transactionType)
+	}
+	query += ` GROUP BY g.id, g.name ORDER BY 3 DESC, g.name`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []domain.TransactionByTagSummary
+	for rows.Next() {
+		var summary domain.TransactionByTagSummary
+		if err := rows.Scan(&summary.TagID, &summary.TagName, &summary.TotalAmount, &summary.TransactionCount); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetTransactionSummaryByPaymentMethod sums transactions per payment method, largest total first. Transfers are left out.
+func (r *PersonalTransactionRepository) GetTransactionSummaryByPaymentMethod(userID string, startDate time.Time, endDate time.Time, transactionType string) ([]domain.TransactionByPaymentMethodSummary, error) {
+	query := `
+	SELECT t.payment_method_id AS method_id,
+           c.name AS method_name,
            SUM(t.amount) AS total_amount
 	FROM personal_transactions t
-	LEFT JOIN payment_methods c ON t.payment_method_id = c.id
+	JOIN payment_methods c ON t.payment_method_id = c.id
 	WHERE t.user_id = $1
 	AND t.date >= $2
 	AND t.date <= $3
-	AND t.type = $4
-	GROUP BY method_id, method_name  ORDER BY total_amount DESC
-	 `
+	AND t.type <> 'transfer'`
+
+	args := []interface{}{userID, startDate, endDate}
+
+	if transactionType != "" {
+		query += ` AND t.type = $4`
 		args = append(args, transactionType)
 	}
+	query += ` GROUP BY 1, 2 ORDER BY 3 DESC, 1`
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -222,22 +645,237 @@ func (r *PersonalTransactionRepository) GetTransactionSummaryByPaymentMethod(use
 	var summaries []domain.TransactionByPaymentMethodSummary
 	for rows.Next() {
 		var summary domain.TransactionByPaymentMethodSummary
-		if err := rows.Scan(&summary.PaymentMethodName, &summary.PaymentMethodID, &summary.TotalAmount); err != nil {
+		if err := rows.Scan(&summary.PaymentMethodID, &summary.PaymentMethodName, &summary.TotalAmount); err != nil {
 			return nil, err
 		}
 		summaries = append(summaries, summary)
 	}
+	return summaries, rows.Err()
+}
 
-	return summaries, nil
+func (r *PersonalTransactionRepository) FindByID(transactionID string, userID string) (*domain.PersonalTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM personal_transactions
+		WHERE id = $1 AND user_id = $2
+		`
+
+	transaction, err := scanPersonalTransaction(r.db.QueryRow(query, transactionID, userID))
+	if err != nil {
+		return nil, err
+	}
+	transactions := []domain.PersonalTransaction{*transaction}
+	if err := r.loadDetails(transactions); err != nil {
+		return nil, err
+	}
+	return &transactions[0], nil
+}
+
+func (r *PersonalTransactionRepository) FindByExternalIDWithTransaction(userID, externalID string, tx *sql.Tx) (*domain.PersonalTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM personal_transactions
+		WHERE user_id = $1 AND external_id = $2
+		FOR UPDATE
+		`
+	return scanPersonalTransaction(tx.QueryRow(query, userID, externalID))
+}
+
+func scanPersonalTransaction(row interface{ Scan(dest ...any) error }) (*domain.PersonalTransaction, error) {
+	var transaction domain.PersonalTransaction
+	var predefinedCategoryID sql.NullInt32
+	var userCategoryID sql.NullInt32
+	var paymentSourceID sql.NullInt32
+	var destinationPaymentSourceID sql.NullInt32
+	var reconciliationID sql.NullInt32
+	var destinationReconciliationID sql.NullInt32
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.Name,
+		&transaction.UserID,
+		&transaction.Amount,
+		&transaction.Type,
+		&transaction.Date,
+		&transaction.Description,
+		&predefinedCategoryID,
+		&userCategoryID,
+		&transaction.PaymentMethodID,
+		&paymentSourceID,
+		&destinationPaymentSourceID,
+		&transaction.FXRate,
+		&reconciliationID,
+		&transaction.ExternalID,
+		&destinationReconciliationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// transfers have no category
+	transaction.PredefinedCategoryID = int(predefinedCategoryID.Int32)
+
+	if userCategoryID.Valid {
+		value := int(userCategoryID.Int32)
+		transaction.UserCategoryID = &value
+	}
+
+	if paymentSourceID.Valid {
+		value := int(paymentSourceID.Int32)
+		transaction.PaymentSourceID = &value
+	}
+
+	if destinationPaymentSourceID.Valid {
+		value := int(destinationPaymentSourceID.Int32)
+		transaction.DestinationPaymentSourceID = &value
+	}
+
+	if reconciliationID.Valid {
+		value := int(reconciliationID.Int32)
+		transaction.ReconciliationID = &value
+	}
+
+	if destinationReconciliationID.Valid {
+		value := int(destinationReconciliationID.Int32)
+		transaction.DestinationReconciliationID = &value
+	}
+
+	return &transaction, nil
+}
+
+// nullableID stores a missing reference, which is kept as zero in the domain, as NULL.
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// Delete locks the transaction first, so that attachments uploaded while it's deleted either have their storage key
+// returned or fail to find the transaction.
+func (r *PersonalTransactionRepository) Delete(transactionID string, userID string) (int64, []string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	affected, storageKeys, err := deleteTransaction(tx, transactionID, userID)
+	if err != nil || affected == 0 {
+		safeRollback(tx)
+		return 0, nil, err
+	}
+	return affected, storageKeys, tx.Commit()
+}
+
+func deleteTransaction(tx *sql.Tx, transactionID string, userID string) (int64, []string, error) {
+	var id string
+	err := tx.QueryRow(`SELECT id FROM personal_transactions WHERE id = $1 AND user_id = $2 FOR UPDATE`, transactionID, userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := tx.Query(`DELETE FROM personal_transaction_attachments WHERE transaction_id = $1 RETURNING storage_key`, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	var storageKeys []string
+	for rows.Next() {
+		var storageKey string
+		if err := rows.Scan(&storageKey); err != nil {
+			return 0, nil, err
+		}
+		storageKeys = append(storageKeys, storageKey)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	rows.Close()
+
+	result, err := tx.Exec(`DELETE FROM personal_transactions WHERE id = $1`, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	affected, err := result.RowsAffected()
+	return affected, storageKeys, err
 }
 
-func (r *PersonalTransactionRepository) FindByID(transactionID int) (*domain.PersonalTransaction, error) {
-	panic("Implement me")
+// Update replaces the transaction together with all of its split lines and tags.
+func (r *PersonalTransactionRepository) Update(transaction domain.PersonalTransaction) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	affected, err := updateTransaction(tx, transaction)
+	if err != nil {
+		safeRollback(tx)
+		return 0, err
+	}
+	return affected, tx.Commit()
 }
 
-func (r *PersonalTransactionRepository) Delete(transactionID int) error {
-	panic("Implement me")
+func (r *PersonalTransactionRepository) UpdateWithTransaction(transaction domain.PersonalTransaction, tx *sql.Tx) (int64, error) {
+	return updateTransaction(tx, transaction)
 }
-func (r *PersonalTransactionRepository) Update(transaction domain.PersonalTransaction) error {
-	panic("Implement me")
+
+func updateTransaction(tx *sql.Tx, transaction domain.PersonalTransaction) (int64, error) {
+	result, err := tx.Exec(
+		`UPDATE personal_transactions
+		SET name = $1, predefined_category_id = $2, user_category_id = $3, amount = $4, type = $5, date = $6,
+		    description = $7, payment_method_id = $8, payment_source_id = $9, destination_payment_source_id = $10, fx_rate = $11,
+		    external_id = $12
+		WHERE id = $13 AND user_id = $14`,
+		transaction.Name, nullableID(transaction.PredefinedCategoryID), transaction.UserCategoryID, transaction.Amount, transaction.Type,
+		transaction.Date, transaction.Description, transaction.PaymentMethodID, transaction.PaymentSourceID,
+		transaction.DestinationPaymentSourceID, transaction.FXRate, transaction.ExternalID,
+		transaction.ID, transaction.UserID,
+	)
+	if isUniqueViolation(err, "idx_personal_transactions_user_id_external_id") {
+		return 0, financeErrors.ErrExternalIDTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM personal_transaction_splits WHERE transaction_id = $1`, transaction.ID); err != nil {
+		return 0, err
+	}
+	if err := insertSplits(tx, transaction); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM personal_transaction_tags WHERE transaction_id = $1`, transaction.ID); err != nil {
+		return 0, err
+	}
+	return affected, insertTags(tx, transaction)
+}
+
+func (r *PersonalTransactionRepository) WithSavepoint(tx *sql.Tx, fn func() error) error {
+	if _, err := tx.Exec(`SAVEPOINT savepoint`); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT savepoint`); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	_, err := tx.Exec(`RELEASE SAVEPOINT savepoint`)
+	return err
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
+func safeRollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		log.Printf("Error during transaction rollback: %v", err)
+	}
 }
@@ -0,0 +1,186 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+type AttachmentServiceInterface interface {
+	GetTransactionAttachments(transactionID, userID string) ([]domain.Attachment, error)
+	UploadAttachment(transactionID, userID, fileName string, content io.Reader) (*domain.Attachment, error)
+	OpenAttachment(attachmentID, userID string) (*domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(attachmentID, userID string) error
+}
+
+type AttachmentHandler struct {
+	service      AttachmentServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewAttachmentHandler(
+	service AttachmentServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *AttachmentHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &AttachmentHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *AttachmentHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.uuidFromPath(w, r, "Transaction not found")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.GetTransactionAttachments(transactionID, userID)
+	if err != nil {
+		h.handleAttachmentError(w, err, "Failed to retrieve attachments")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "success",
+		"message":     "Attachments retrieved successfully.",
+		"attachments": attachments,
+	})
+}
+
+// UploadAttachment expects a multipart form with the document in "file".
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.uuidFromPath(w, r, "Transaction not found")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAttachmentSize+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			h.respondError(w, http.StatusRequestEntityTooLarge, financeErrors.ErrAttachmentTooLarge.Error())
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, "Invalid form, the file must be sent as multipart/form-data")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.UploadAttachment(transactionID, userID, header.Filename, file)
+	if err != nil {
+		h.handleAttachmentError(w, err, "Failed to upload attachment")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":     "success",
+		"message":    "Attachment successfully uploaded.",
+		"attachment": attachment,
+	})
+}
+
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	attachmentID, ok := h.uuidFromPath(w, r, "Attachment not found")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.OpenAttachment(attachmentID, userID)
+	if err != nil {
+		h.handleAttachmentError(w, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	// the type was detected on upload, browsers must not guess another one, e.g. HTML, from the content
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		fmt.Println("Error while sending attachment:", err.Error())
+	}
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	attachmentID, ok := h.uuidFromPath(w, r, "Attachment not found")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(attachmentID, userID); err != nil {
+		h.handleAttachmentError(w, err, "Failed to delete attachment")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Attachment deleted successfully.",
+	})
+}
+
+func (h *AttachmentHandler) uuidFromPath(w http.ResponseWriter, r *http.Request, notFoundMessage string) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		h.respondError(w, http.StatusNotFound, notFoundMessage)
+		return "", false
+	}
+	return id, true
+}
+
+func (h *AttachmentHandler) handleAttachmentError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrTransactionNotFound):
+		h.respondError(w, http.StatusNotFound, "Transaction not found")
+	case errors.Is(err, financeErrors.ErrAttachmentNotFound):
+		h.respondError(w, http.StatusNotFound, "Attachment not found")
+	case errors.Is(err, financeErrors.ErrAttachmentTooLarge):
+		h.respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, financeErrors.ErrUnsupportedAttachmentType):
+		h.respondError(w, http.StatusUnsupportedMediaType, err.Error())
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during attachment operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
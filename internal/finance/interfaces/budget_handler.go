@@ -0,0 +1,209 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type BudgetServiceInterface interface {
+	GetUserBudgetsProgress(userID string, date time.Time) ([]domain.BudgetProgress, error)
+	GetBudgetProgress(budgetID int, userID string, date time.Time) (*domain.BudgetProgress, error)
+	CreateBudget(budget *domain.Budget) error
+	UpdateBudget(budget *domain.Budget) error
+	DeleteBudget(budgetID int, userID string) error
+}
+
+type BudgetHandler struct {
+	service      BudgetServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewBudgetHandler(
+	service BudgetServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *BudgetHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &BudgetHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// GetBudgets returns all budgets of the user with their progress in the period containing ?date= (today by default).
+func (h *BudgetHandler) GetBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	date, ok := h.dateFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.service.GetUserBudgetsProgress(userID, date)
+	if err != nil {
+		h.handleBudgetError(w, err, "Failed to retrieve budgets")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Budgets retrieved successfully.",
+		"budgets": budgets,
+	})
+}
+
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	budgetID, ok := h.budgetIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.dateFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	budget, err := h.service.GetBudgetProgress(budgetID, userID, date)
+	if err != nil {
+		h.handleBudgetError(w, err, "Failed to retrieve budget")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Budget retrieved successfully.",
+		"budget":  budget,
+	})
+}
+
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var budget domain.Budget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	budget.UserID = userID
+
+	if err := h.service.CreateBudget(&budget); err != nil {
+		h.handleBudgetError(w, err, "Failed to create budget")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Budget successfully created.",
+		"budget":  budget,
+	})
+}
+
+func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	budgetID, ok := h.budgetIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var budget domain.Budget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	budget.ID = budgetID
+	budget.UserID = userID
+
+	if err := h.service.UpdateBudget(&budget); err != nil {
+		h.handleBudgetError(w, err, "Failed to update budget")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Budget successfully updated.",
+		"budget":  budget,
+	})
+}
+
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	budgetID, ok := h.budgetIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBudget(budgetID, userID); err != nil {
+		h.handleBudgetError(w, err, "Failed to delete budget")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Budget deleted successfully.",
+	})
+}
+
+func (h *BudgetHandler) budgetIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	budgetID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || budgetID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Budget not found")
+		return 0, false
+	}
+	return budgetID, true
+}
+
+func (h *BudgetHandler) dateFromQuery(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	dateStr := r.URL.Query().Get("date")
+	if dateStr == "" {
+		return time.Now(), true
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid date format")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (h *BudgetHandler) handleBudgetError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrBudgetNotFound):
+		h.respondError(w, http.StatusNotFound, "Budget not found")
+	case errors.Is(err, financeErrors.ErrBudgetAlreadyExists):
+		h.respondError(w, http.StatusConflict, "Budget for this category and period already exists")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during budget operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,246 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type CategorizationServiceInterface interface {
+	GetUserRules(userID string) ([]domain.CategorizationRule, error)
+	GetRule(ruleID int, userID string) (*domain.CategorizationRule, error)
+	CreateRule(rule *domain.CategorizationRule) error
+	UpdateRule(rule *domain.CategorizationRule) error
+	DeleteRule(ruleID int, userID string) error
+	SuggestRules(userID string) ([]domain.RuleSuggestion, error)
+	ReapplyRules(userID string, startDate, endDate time.Time) (int, error)
+}
+
+type CategorizationHandler struct {
+	service      CategorizationServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewCategorizationHandler(
+	service CategorizationServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *CategorizationHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &CategorizationHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// GetRules returns the categorization rules of the user in the order they are evaluated in.
+func (h *CategorizationHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rules, err := h.service.GetUserRules(userID)
+	if err != nil {
+		h.handleCategorizationError(w, err, "Failed to retrieve categorization rules")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rules retrieved successfully.",
+		"rules":   rules,
+	})
+}
+
+func (h *CategorizationHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.GetRule(ruleID, userID)
+	if err != nil {
+		h.handleCategorizationError(w, err, "Failed to retrieve categorization rule")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rule retrieved successfully.",
+		"rule":    rule,
+	})
+}
+
+func (h *CategorizationHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var rule domain.CategorizationRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	rule.UserID = userID
+
+	if err := h.service.CreateRule(&rule); err != nil {
+		h.handleCategorizationError(w, err, "Failed to create categorization rule")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rule successfully created.",
+		"rule":    rule,
+	})
+}
+
+func (h *CategorizationHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var rule domain.CategorizationRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	rule.ID = ruleID
+	rule.UserID = userID
+
+	if err := h.service.UpdateRule(&rule); err != nil {
+		h.handleCategorizationError(w, err, "Failed to update categorization rule")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rule successfully updated.",
+		"rule":    rule,
+	})
+}
+
+func (h *CategorizationHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(ruleID, userID); err != nil {
+		h.handleCategorizationError(w, err, "Failed to delete categorization rule")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rule deleted successfully.",
+	})
+}
+
+// GetSuggestions returns rules learned from the categorized transactions of the user, they are saved only once
+// the client posts them back to CreateRule.
+func (h *CategorizationHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	suggestions, err := h.service.SuggestRules(userID)
+	if err != nil {
+		h.handleCategorizationError(w, err, "Failed to suggest categorization rules")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "success",
+		"message":     "Categorization rule suggestions retrieved successfully.",
+		"suggestions": suggestions,
+	})
+}
+
+// ApplyRules re-runs the rules over the transactions between ?start_date= and ?end_date=, both required.
+func (h *CategorizationHandler) ApplyRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", r.URL.Query().Get("start_date"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid start date format")
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", r.URL.Query().Get("end_date"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid end date format")
+		return
+	}
+	if endDate.Before(startDate) {
+		h.respondError(w, http.StatusBadRequest, "End date must not be before start date")
+		return
+	}
+
+	updated, err := h.service.ReapplyRules(userID, startDate, endDate)
+	if err != nil {
+		h.handleCategorizationError(w, err, "Failed to apply categorization rules")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Categorization rules applied successfully.",
+		"updated": updated,
+	})
+}
+
+func (h *CategorizationHandler) ruleIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	ruleID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || ruleID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Categorization rule not found")
+		return 0, false
+	}
+	return ruleID, true
+}
+
+func (h *CategorizationHandler) handleCategorizationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrCategorizationRuleNotFound):
+		h.respondError(w, http.StatusNotFound, "Categorization rule not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during categorization rule operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
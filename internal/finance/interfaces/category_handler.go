@@ -1,12 +1,22 @@
 package interfaces
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
 	"net/http"
+	"strconv"
 )
 
 type CategoryServiceInterface interface {
 	GetAllPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error)
+	GetAllUserCategories(userID string) ([]domain.UserCategory, error)
+	GetUserCategory(categoryID int, userID string) (*domain.UserCategory, error)
+	CreateUserCategory(category *domain.UserCategory) error
+	UpdateUserCategory(category *domain.UserCategory) error
+	DeleteUserCategory(categoryID int, userID string) error
 }
 
 type CategoryHandler struct {
@@ -49,3 +59,150 @@ func (h *CategoryHandler) GetPredefinedCategories(w http.ResponseWriter, r *http
 		"categories": categories,
 	})
 }
+
+func (h *CategoryHandler) GetUserCategories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	categories, err := h.service.GetAllUserCategories(userID)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve categories")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"message":    "Categories retrieved successfully.",
+		"categories": categories,
+	})
+}
+
+func (h *CategoryHandler) GetUserCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	categoryID, ok := h.categoryIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := h.service.GetUserCategory(categoryID, userID)
+	if err != nil {
+		h.handleUserCategoryError(w, err, "Failed to retrieve category")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Category retrieved successfully.",
+		"category": category,
+	})
+}
+
+func (h *CategoryHandler) CreateUserCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var category domain.UserCategory
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	category.ID = 0
+	category.UserID = userID
+
+	if err := h.service.CreateUserCategory(&category); err != nil {
+		h.handleUserCategoryError(w, err, "Failed to create category")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":   "success",
+		"message":  "Category successfully created.",
+		"category": category,
+	})
+}
+
+func (h *CategoryHandler) UpdateUserCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	categoryID, ok := h.categoryIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var category domain.UserCategory
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	category.ID = categoryID
+	category.UserID = userID
+
+	if err := h.service.UpdateUserCategory(&category); err != nil {
+		h.handleUserCategoryError(w, err, "Failed to update category")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Category successfully updated.",
+		"category": category,
+	})
+}
+
+func (h *CategoryHandler) DeleteUserCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	categoryID, ok := h.categoryIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteUserCategory(categoryID, userID); err != nil {
+		h.handleUserCategoryError(w, err, "Failed to delete category")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Category deleted successfully.",
+	})
+}
+
+func (h *CategoryHandler) categoryIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	categoryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || categoryID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Category not found")
+		return 0, false
+	}
+	return categoryID, true
+}
+
+func (h *CategoryHandler) handleUserCategoryError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrUserCategoryNotFound):
+		h.respondError(w, http.StatusNotFound, "Category not found")
+	case errors.Is(err, financeErrors.ErrUserCategoryNameTaken):
+		h.respondError(w, http.StatusConflict, "Category with this name already exists")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during user category operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -1,8 +1,12 @@
 package interfaces
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"github.com/sebuszqo/FinanceManager/internal/finance/application"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -89,3 +93,90 @@ func TestGetCategories_ErrorFromService(t *testing.T) {
 
 	assert.Equal(t, "Failed to retrieve categories", response["message"])
 }
+
+// the user category tests run the handler against the real service, so the status codes follow its rules
+func newUserCategoryHandler(repo *infrastructure.MockCategoryRepository) *CategoryHandler {
+	if repo.PredefinedCategories == nil {
+		repo.PredefinedCategories = []domain.PredefinedCategory{{ID: 9, Name: "Groceries", Type: "expense"}}
+	}
+	return NewCategoryHandler(application.NewCategoryService(repo), respondJSON, respondError)
+}
+
+func TestCreateUserCategory(t *testing.T) {
+	handler := newUserCategoryHandler(&infrastructure.MockCategoryRepository{})
+
+	t.Run("Creates category under a parent", func(t *testing.T) {
+		body := `{"name": "Organic", "type": "expense", "parent_category_id": 9, "color": "#22AA44", "icon": "leaf"}`
+		req := httptest.NewRequest(http.MethodPost, "/categories/user", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.CreateUserCategory(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var response map[string]interface{}
+		err := json.NewDecoder(res.Body).Decode(&response)
+		assert.NoError(t, err)
+		category := response["category"].(map[string]interface{})
+		assert.Equal(t, "Organic", category["name"])
+		assert.Equal(t, float64(9), category["parent_category_id"])
+	})
+
+	t.Run("Conflict if name is already used", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/categories/user", bytes.NewBufferString(`{"name": "Organic", "type": "expense"}`))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.CreateUserCategory(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("Bad request on a parent of the other type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/categories/user", bytes.NewBufferString(`{"name": "Bonus", "type": "income", "parent_category_id": 9}`))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.CreateUserCategory(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Bad request on invalid color", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/categories/user", bytes.NewBufferString(`{"name": "Discounter", "type": "expense", "color": "green"}`))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.CreateUserCategory(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestDeleteUserCategory_OtherUser(t *testing.T) {
+	repo := &infrastructure.MockCategoryRepository{
+		UserCategories: []domain.UserCategory{{ID: 1, Name: "Organic", UserID: "owner-id", Type: "expense"}},
+	}
+	handler := newUserCategoryHandler(repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/categories/user/1", nil)
+	req.SetPathValue("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "intruder-id"))
+	w := httptest.NewRecorder()
+
+	handler.DeleteUserCategory(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Len(t, repo.UserCategories, 1)
+}
@@ -0,0 +1,158 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/application"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultExportCurrency = "PLN"
+
+// exportContentTypes are the content types of the downloadable formats, the format is also used as file extension.
+var exportContentTypes = map[domain.StatementFormat]string{
+	domain.StatementFormatOFX:  "application/x-ofx",
+	domain.StatementFormatQFX:  "application/vnd.intu.qfx",
+	domain.StatementFormatQIF:  "application/qif",
+	domain.StatementFormatCSV:  "text/csv; charset=utf-8",
+	domain.StatementFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type ExportServiceInterface interface {
+	IsSupportedExportFormat(format domain.StatementFormat) bool
+	Export(w io.Writer, options application.ExportOptions) error
+}
+
+type ExportHandler struct {
+	service      ExportServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewExportHandler(
+	service ExportServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *ExportHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &ExportHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// ExportTransactions downloads the transactions matching the filters of the transactions list as a file. Supported
+// formats are csv and xlsx for spreadsheets and ofx, qfx and qif for desktop finance tools. summary=true adds the year,
+// month and week totals of the same transactions as a second xlsx sheet, the optional currency is written to OFX and
+// QFX statements.
+func (h *ExportHandler) ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	options := application.ExportOptions{
+		Format: domain.StatementFormat(r.URL.Query().Get("format")),
+		UserID: userID,
+		Search: domain.TransactionSearch{
+			Type:      r.URL.Query().Get("type"),
+			StartDate: time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Now(),
+		},
+		Currency: strings.ToUpper(r.URL.Query().Get("currency")),
+	}
+	if !h.service.IsSupportedExportFormat(options.Format) {
+		h.respondError(w, http.StatusBadRequest, "Invalid export format")
+		return
+	}
+	if !domain.IsValidTransactionType(options.Search.Type) {
+		h.respondError(w, http.StatusBadRequest, "Invalid transaction type")
+		return
+	}
+	if invalidParam := searchFiltersFromQuery(r, &options.Search); invalidParam != "" {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s value", invalidParam))
+		return
+	}
+
+	var err error
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		options.Search.StartDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+	}
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		options.Search.EndDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+	}
+	if options.Currency == "" {
+		options.Currency = defaultExportCurrency
+	}
+	if summaryStr := r.URL.Query().Get("summary"); summaryStr != "" {
+		options.IncludeSummary, err = strconv.ParseBool(summaryStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid summary value")
+			return
+		}
+	}
+
+	download := &downloadWriter{
+		ResponseWriter: w,
+		contentType:    exportContentTypes[options.Format],
+		fileName: fmt.Sprintf("transactions_%s_%s.%s",
+			options.Search.StartDate.Format("2006-01-02"), options.Search.EndDate.Format("2006-01-02"), options.Format),
+	}
+	if err := h.service.Export(download, options); err != nil {
+		if download.started {
+			// the status is already sent, the client sees a truncated download
+			fmt.Println("Error while streaming transactions export:", err.Error())
+			return
+		}
+		h.handleExportError(w, err)
+	}
+}
+
+// downloadWriter sends the attachment headers with the first write, so an error before any data is written can
+// still be answered with a JSON error.
+type downloadWriter struct {
+	http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		d.Header().Set("Content-Type", d.contentType)
+		d.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, d.fileName))
+		d.WriteHeader(http.StatusOK)
+	}
+	return d.ResponseWriter.Write(p)
+}
+
+func (h *ExportHandler) handleExportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, financeErrors.ErrUnsupportedStatementFormat):
+		h.respondError(w, http.StatusBadRequest, "Invalid export format")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during transactions export:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to export transactions")
+	}
+}
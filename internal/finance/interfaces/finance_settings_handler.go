@@ -0,0 +1,93 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+)
+
+type FinanceSettingsServiceInterface interface {
+	GetSettings(userID string) (*domain.FinanceSettings, error)
+	UpdateSettings(settings *domain.FinanceSettings) error
+}
+
+type FinanceSettingsHandler struct {
+	service      FinanceSettingsServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewFinanceSettingsHandler(
+	service FinanceSettingsServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *FinanceSettingsHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &FinanceSettingsHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *FinanceSettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		fmt.Println("Error retrieving finance settings:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve settings")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Settings retrieved successfully.",
+		"settings": settings,
+	})
+}
+
+func (h *FinanceSettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		fmt.Println("Error retrieving finance settings:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+	// fields missing from the body keep their current values
+	if err := json.NewDecoder(r.Body).Decode(settings); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	settings.UserID = userID
+
+	if err := h.service.UpdateSettings(settings); err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		fmt.Println("Error updating finance settings:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Settings successfully updated.",
+		"settings": settings,
+	})
+}
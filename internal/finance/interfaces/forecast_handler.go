@@ -0,0 +1,75 @@
+package interfaces
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+)
+
+type ForecastServiceInterface interface {
+	GetForecast(userID string, request domain.ForecastRequest) (*domain.CashFlowForecast, error)
+}
+
+type ForecastHandler struct {
+	service      ForecastServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewForecastHandler(
+	service ForecastServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *ForecastHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &ForecastHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// GetForecast projects the daily balances of the payment sources for the next ?months= months, from the average
+// variable spend of the last ?history_months= months and the known future items.
+func (h *ForecastHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var request domain.ForecastRequest
+	for param, value := range map[string]*int{"months": &request.Months, "history_months": &request.HistoryMonths} {
+		text := r.URL.Query().Get(param)
+		if text == "" {
+			continue
+		}
+		number, err := strconv.Atoi(text)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s value", param))
+			return
+		}
+		*value = number
+	}
+
+	forecast, err := h.service.GetForecast(userID, request)
+	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		fmt.Println("Error during cash-flow forecast:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to forecast balances")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Forecast retrieved successfully.",
+		"forecast": forecast,
+	})
+}
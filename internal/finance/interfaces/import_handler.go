@@ -0,0 +1,434 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/application"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+const maxStatementFileSize = 5 << 20
+
+type ImportServiceInterface interface {
+	GetUserProfiles(userID string) ([]domain.ImportProfile, error)
+	GetProfile(profileID int, userID string) (*domain.ImportProfile, error)
+	CreateProfile(profile *domain.ImportProfile) error
+	UpdateProfile(profile *domain.ImportProfile) error
+	DeleteProfile(profileID int, userID string) error
+	ValidateProfile(profile *domain.ImportProfile) error
+	PreviewCSV(r io.Reader, profile domain.ImportProfile) (*domain.ImportPreview, error)
+	ImportCSV(r io.Reader, profile domain.ImportProfile, skipInvalid bool, options domain.BulkCreateOptions) (*application.ImportResult, error)
+	ValidateDefaults(defaults *domain.ImportDefaults) error
+	IsSupportedStatementFormat(format domain.StatementFormat) bool
+	PreviewStatement(format domain.StatementFormat, r io.Reader, defaults domain.ImportDefaults) (*domain.ImportPreview, error)
+	ImportStatement(format domain.StatementFormat, r io.Reader, defaults domain.ImportDefaults, skipInvalid bool, options domain.BulkCreateOptions) (*application.ImportResult, error)
+}
+
+type ImportHandler struct {
+	service      ImportServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewImportHandler(
+	service ImportServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *ImportHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &ImportHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *ImportHandler) GetProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	profiles, err := h.service.GetUserProfiles(userID)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to retrieve import profiles")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Import profiles retrieved successfully.",
+		"profiles": profiles,
+	})
+}
+
+func (h *ImportHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	profileID, ok := h.profileIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetProfile(profileID, userID)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to retrieve import profile")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Import profile retrieved successfully.",
+		"profile": profile,
+	})
+}
+
+func (h *ImportHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var profile domain.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	profile.UserID = userID
+
+	if err := h.service.CreateProfile(&profile); err != nil {
+		h.handleImportError(w, err, "Failed to create import profile")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Import profile successfully created.",
+		"profile": profile,
+	})
+}
+
+func (h *ImportHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	profileID, ok := h.profileIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var profile domain.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	profile.ID = profileID
+	profile.UserID = userID
+
+	if err := h.service.UpdateProfile(&profile); err != nil {
+		h.handleImportError(w, err, "Failed to update import profile")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Import profile successfully updated.",
+		"profile": profile,
+	})
+}
+
+func (h *ImportHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	profileID, ok := h.profileIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteProfile(profileID, userID); err != nil {
+		h.handleImportError(w, err, "Failed to delete import profile")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Import profile deleted successfully.",
+	})
+}
+
+// PreviewCSV expects a multipart form with the statement in "file" and either "profile_id" of a saved profile
+// or the mapping itself as JSON in "profile".
+func (h *ImportHandler) PreviewCSV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	file, profile, ok := h.csvImportRequest(w, r, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := h.service.PreviewCSV(file, *profile)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to read statement")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Statement preview generated successfully.",
+		"data":    preview,
+	})
+}
+
+// ImportCSV takes the same form as PreviewCSV and saves the transactions. With "skip_invalid=true" rows
+// with errors are left out instead of rejecting the whole statement. "duplicates" and "dry_run" work like for
+// the bulk creation of transactions.
+func (h *ImportHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	file, profile, ok := h.csvImportRequest(w, r, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	skipInvalid, _ := strconv.ParseBool(r.FormValue("skip_invalid"))
+	options := bulkCreateOptionsFromForm(r)
+	result, err := h.service.ImportCSV(file, *profile, skipInvalid, options)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to import statement")
+		return
+	}
+	h.respondImportResult(w, result, options)
+}
+
+// PreviewStatement reads a statement in the format from the path (mt940, camt053, ofx, qfx or qif). The multipart
+// form carries the statement in "file" and either "profile_id" of a saved profile or "defaults" as JSON, which set
+// the categories and payment method of imported transactions.
+func (h *ImportHandler) PreviewStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	format, ok := h.statementFormatFromPath(w, r)
+	if !ok {
+		return
+	}
+	file, defaults, ok := h.statementImportRequest(w, r, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := h.service.PreviewStatement(format, file, *defaults)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to read statement")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Statement preview generated successfully.",
+		"data":    preview,
+	})
+}
+
+func (h *ImportHandler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	format, ok := h.statementFormatFromPath(w, r)
+	if !ok {
+		return
+	}
+	file, defaults, ok := h.statementImportRequest(w, r, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	skipInvalid, _ := strconv.ParseBool(r.FormValue("skip_invalid"))
+	options := bulkCreateOptionsFromForm(r)
+	result, err := h.service.ImportStatement(format, file, *defaults, skipInvalid, options)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to import statement")
+		return
+	}
+	h.respondImportResult(w, result, options)
+}
+
+func bulkCreateOptionsFromForm(r *http.Request) domain.BulkCreateOptions {
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	return domain.BulkCreateOptions{DuplicatePolicy: domain.DuplicatePolicy(r.FormValue("duplicates")), DryRun: dryRun}
+}
+
+func (h *ImportHandler) respondImportResult(w http.ResponseWriter, result *application.ImportResult, options domain.BulkCreateOptions) {
+	if options.DryRun {
+		h.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  "success",
+			"message": "Dry run completed, no transactions were saved.",
+			"data":    result,
+		})
+		return
+	}
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Statement imported successfully.",
+		"data":    result,
+	})
+}
+
+func (h *ImportHandler) statementFormatFromPath(w http.ResponseWriter, r *http.Request) (domain.StatementFormat, bool) {
+	format := domain.StatementFormat(r.PathValue("format"))
+	if !h.service.IsSupportedStatementFormat(format) {
+		h.respondError(w, http.StatusNotFound, "Unsupported statement format")
+		return "", false
+	}
+	return format, true
+}
+
+func (h *ImportHandler) statementImportRequest(w http.ResponseWriter, r *http.Request, userID string) (multipart.File, *domain.ImportDefaults, bool) {
+	file, ok := h.statementFile(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if r.FormValue("profile_id") != "" {
+		profile, ok := h.savedProfile(w, r, userID)
+		if !ok {
+			file.Close()
+			return nil, nil, false
+		}
+		return file, &profile.ImportDefaults, true
+	}
+
+	defaults := &domain.ImportDefaults{}
+	if err := json.Unmarshal([]byte(r.FormValue("defaults")), defaults); err != nil {
+		file.Close()
+		h.respondError(w, http.StatusBadRequest, "Either profile_id or valid defaults must be provided")
+		return nil, nil, false
+	}
+	defaults.UserID = userID
+	if err := h.service.ValidateDefaults(defaults); err != nil {
+		file.Close()
+		h.handleImportError(w, err, "Failed to validate import defaults")
+		return nil, nil, false
+	}
+	return file, defaults, true
+}
+
+func (h *ImportHandler) savedProfile(w http.ResponseWriter, r *http.Request, userID string) (*domain.ImportProfile, bool) {
+	profileID, err := strconv.Atoi(r.FormValue("profile_id"))
+	if err != nil || profileID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Import profile not found")
+		return nil, false
+	}
+	profile, err := h.service.GetProfile(profileID, userID)
+	if err != nil {
+		h.handleImportError(w, err, "Failed to retrieve import profile")
+		return nil, false
+	}
+	return profile, true
+}
+
+func (h *ImportHandler) csvImportRequest(w http.ResponseWriter, r *http.Request, userID string) (multipart.File, *domain.ImportProfile, bool) {
+	file, ok := h.statementFile(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if r.FormValue("profile_id") != "" {
+		profile, ok := h.savedProfile(w, r, userID)
+		if !ok {
+			file.Close()
+			return nil, nil, false
+		}
+		return file, profile, true
+	}
+
+	profile := &domain.ImportProfile{}
+	if err := json.Unmarshal([]byte(r.FormValue("profile")), profile); err != nil {
+		file.Close()
+		h.respondError(w, http.StatusBadRequest, "Either profile_id or a valid profile must be provided")
+		return nil, nil, false
+	}
+	profile.ID = 0
+	profile.UserID = userID
+	if err := h.service.ValidateProfile(profile); err != nil {
+		file.Close()
+		h.handleImportError(w, err, "Failed to validate import profile")
+		return nil, nil, false
+	}
+	return file, profile, true
+}
+
+func (h *ImportHandler) statementFile(w http.ResponseWriter, r *http.Request) (multipart.File, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementFileSize+1<<20)
+	if err := r.ParseMultipartForm(maxStatementFileSize); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid form, the statement must be sent as multipart/form-data up to 5MB")
+		return nil, false
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Statement file is required")
+		return nil, false
+	}
+	return file, true
+}
+
+func (h *ImportHandler) profileIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	profileID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || profileID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Import profile not found")
+		return 0, false
+	}
+	return profileID, true
+}
+
+func (h *ImportHandler) handleImportError(w http.ResponseWriter, err error, message string) {
+	var validationErrors *financeErrors.ValidationErrors
+	switch {
+	case errors.Is(err, financeErrors.ErrImportProfileNotFound):
+		h.respondError(w, http.StatusNotFound, "Import profile not found")
+	case errors.Is(err, financeErrors.ErrImportProfileNameTaken):
+		h.respondError(w, http.StatusConflict, "Import profile with this name already exists")
+	case errors.As(err, &validationErrors):
+		errorMessages := make([]string, len(validationErrors.Errors))
+		for i, vErr := range validationErrors.Errors {
+			errorMessages[i] = vErr.Error()
+		}
+		h.respondError(w, http.StatusBadRequest, "Validation errors occurred", errorMessages)
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during statement import:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,242 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+)
+
+type LoanServiceInterface interface {
+	GetUserLoans(userID string) ([]domain.Loan, error)
+	GetLoan(loanID int, userID string) (*domain.Loan, error)
+	CreateLoan(loan *domain.Loan) error
+	UpdateLoan(loan *domain.Loan) error
+	DeleteLoan(loanID int, userID string) error
+	GetSchedule(loanID int, userID string) (*domain.LoanSchedule, error)
+	SimulateOverpayment(loanID int, userID string, plan domain.LoanOverpaymentPlan) (*domain.LoanSimulation, error)
+}
+
+type LoanHandler struct {
+	service      LoanServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewLoanHandler(
+	service LoanServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *LoanHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &LoanHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *LoanHandler) GetLoans(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	loans, err := h.service.GetUserLoans(userID)
+	if err != nil {
+		h.handleLoanError(w, err, "Failed to retrieve loans")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Loans retrieved successfully.",
+		"loans":   loans,
+	})
+}
+
+func (h *LoanHandler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	loanID, ok := h.loanIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	loan, err := h.service.GetLoan(loanID, userID)
+	if err != nil {
+		h.handleLoanError(w, err, "Failed to retrieve loan")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Loan retrieved successfully.",
+		"loan":    loan,
+	})
+}
+
+func (h *LoanHandler) CreateLoan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var loan domain.Loan
+	if err := json.NewDecoder(r.Body).Decode(&loan); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	loan.UserID = userID
+
+	if err := h.service.CreateLoan(&loan); err != nil {
+		h.handleLoanError(w, err, "Failed to create loan")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Loan successfully created.",
+		"loan":    loan,
+	})
+}
+
+func (h *LoanHandler) UpdateLoan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	loanID, ok := h.loanIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var loan domain.Loan
+	if err := json.NewDecoder(r.Body).Decode(&loan); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	loan.ID = loanID
+	loan.UserID = userID
+
+	if err := h.service.UpdateLoan(&loan); err != nil {
+		h.handleLoanError(w, err, "Failed to update loan")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Loan successfully updated.",
+		"loan":    loan,
+	})
+}
+
+func (h *LoanHandler) DeleteLoan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	loanID, ok := h.loanIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLoan(loanID, userID); err != nil {
+		h.handleLoanError(w, err, "Failed to delete loan")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Loan deleted successfully.",
+	})
+}
+
+// GetSchedule returns the amortization schedule of the loan with the transactions the installments were paid with.
+func (h *LoanHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	loanID, ok := h.loanIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(loanID, userID)
+	if err != nil {
+		h.handleLoanError(w, err, "Failed to retrieve loan schedule")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "success",
+		"message":  "Loan schedule retrieved successfully.",
+		"schedule": schedule,
+	})
+}
+
+// SimulateOverpayment shows how much interest and how many installments the overpayments in the request body save.
+func (h *LoanHandler) SimulateOverpayment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	loanID, ok := h.loanIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var plan domain.LoanOverpaymentPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	simulation, err := h.service.SimulateOverpayment(loanID, userID, plan)
+	if err != nil {
+		h.handleLoanError(w, err, "Failed to simulate overpayment")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"message":    "Overpayment simulated successfully.",
+		"simulation": simulation,
+	})
+}
+
+func (h *LoanHandler) loanIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	loanID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || loanID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Loan not found")
+		return 0, false
+	}
+	return loanID, true
+}
+
+func (h *LoanHandler) handleLoanError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrLoanNotFound):
+		h.respondError(w, http.StatusNotFound, "Loan not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during loan operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -3,11 +3,13 @@ package interfaces
 import (
 	"errors"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
 )
 
 type MockCategoryService struct {
-	categories []domain.PredefinedCategory
-	shouldFail bool
+	categories     []domain.PredefinedCategory
+	userCategories []domain.UserCategory
+	shouldFail     bool
 }
 
 func (m *MockCategoryService) GetAllPredefinedCategories(categoryType string) ([]domain.PredefinedCategory, error) {
@@ -16,3 +18,63 @@ func (m *MockCategoryService) GetAllPredefinedCategories(categoryType string) ([
 	}
 	return m.categories, nil
 }
+
+func (m *MockCategoryService) GetAllUserCategories(userID string) ([]domain.UserCategory, error) {
+	if m.shouldFail {
+		return nil, errors.New("service error")
+	}
+	var categories []domain.UserCategory
+	for _, category := range m.userCategories {
+		if category.UserID == userID {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (m *MockCategoryService) GetUserCategory(categoryID int, userID string) (*domain.UserCategory, error) {
+	if m.shouldFail {
+		return nil, errors.New("service error")
+	}
+	for _, category := range m.userCategories {
+		if category.ID == categoryID && category.UserID == userID {
+			return &category, nil
+		}
+	}
+	return nil, financeErrors.ErrUserCategoryNotFound
+}
+
+func (m *MockCategoryService) CreateUserCategory(category *domain.UserCategory) error {
+	if m.shouldFail {
+		return errors.New("service error")
+	}
+	category.ID = len(m.userCategories) + 1
+	m.userCategories = append(m.userCategories, *category)
+	return nil
+}
+
+func (m *MockCategoryService) UpdateUserCategory(category *domain.UserCategory) error {
+	if m.shouldFail {
+		return errors.New("service error")
+	}
+	for i, existing := range m.userCategories {
+		if existing.ID == category.ID && existing.UserID == category.UserID {
+			m.userCategories[i] = *category
+			return nil
+		}
+	}
+	return financeErrors.ErrUserCategoryNotFound
+}
+
+func (m *MockCategoryService) DeleteUserCategory(categoryID int, userID string) error {
+	if m.shouldFail {
+		return errors.New("service error")
+	}
+	for i, existing := range m.userCategories {
+		if existing.ID == categoryID && existing.UserID == userID {
+			m.userCategories = append(m.userCategories[:i], m.userCategories[i+1:]...)
+			return nil
+		}
+	}
+	return financeErrors.ErrUserCategoryNotFound
+}
@@ -1,9 +1,13 @@
 package interfaces
 
-import "github.com/sebuszqo/FinanceManager/internal/finance/domain"
+import (
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+)
 
 type MockPaymentService struct {
 	Methods []domain.PaymentMethod
+	Sources []domain.PaymentSource
 	Err     error
 }
 
@@ -14,6 +18,74 @@ func (m *MockPaymentService) GetAllPaymentMethods() ([]domain.PaymentMethod, err
 	return m.Methods, nil
 }
 
+func (m *MockPaymentService) ListUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	sources := []domain.PaymentSource{}
+	for _, source := range m.Sources {
+		if source.UserID == userID && (includeArchived || !source.Archived) {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+func (m *MockPaymentService) GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	for _, source := range m.Sources {
+		if source.ID == sourceID && source.UserID == userID {
+			return &source, nil
+		}
+	}
+	return nil, financeErrors.ErrPaymentSourceNotFound
+}
+
+func (m *MockPaymentService) CreatePaymentSource(source *domain.PaymentSource) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	source.ID = len(m.Sources) + 1
+	m.Sources = append(m.Sources, *source)
+	return nil
+}
+
+func (m *MockPaymentService) UpdatePaymentSource(source *domain.PaymentSource) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	for i, existing := range m.Sources {
+		if existing.ID == source.ID && existing.UserID == source.UserID {
+			m.Sources[i] = *source
+			return nil
+		}
+	}
+	return financeErrors.ErrPaymentSourceNotFound
+}
+
+func (m *MockPaymentService) ArchivePaymentSource(sourceID int, userID string) error {
+	return m.setArchived(sourceID, userID, true)
+}
+
+func (m *MockPaymentService) RestorePaymentSource(sourceID int, userID string) error {
+	return m.setArchived(sourceID, userID, false)
+}
+
+func (m *MockPaymentService) setArchived(sourceID int, userID string, archived bool) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	for i, existing := range m.Sources {
+		if existing.ID == sourceID && existing.UserID == userID {
+			m.Sources[i].Archived = archived
+			return nil
+		}
+	}
+	return financeErrors.ErrPaymentSourceNotFound
+}
+
 func NewMockPaymentService(methods []domain.PaymentMethod, err error) *MockPaymentService {
 	return &MockPaymentService{Methods: methods, Err: err}
 }
@@ -2,9 +2,9 @@ package interfaces
 
 import (
 	"fmt"
-	"github.com/sebuszqo/FinanceManager/internal/finance/application"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
 	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"github.com/stretchr/testify/mock"
 	"time"
 )
@@ -18,12 +18,12 @@ func (m *MockTransactionService) GetTransactionSummaryByCategory(userID string,
 		{
 			CategoryID:   1,
 			CategoryName: "Food",
-			TotalAmount:  150.00,
+			TotalAmount:  money.MustParse("150.00"),
 		},
 		{
 			CategoryID:   2,
 			CategoryName: "Transport",
-			TotalAmount:  50.00,
+			TotalAmount:  money.MustParse("50.00"),
 		},
 	}
 
@@ -36,17 +36,37 @@ func (m *MockTransactionService) GetTransactionSummaryByCategory(userID string,
 	return nil, fmt.Errorf("invalid transaction type: %s", transactionType)
 }
 
-func (m *MockTransactionService) GetUserTransactions(userID string, transactionType string, startDate time.Time, endDate time.Time, limit int, page int) ([]domain.PersonalTransaction, error) {
+func (m *MockTransactionService) GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByTagSummary, error) {
 	args := m.Called(userID, transactionType)
 
+	summary := args.Get(0)
+	if summary != nil {
+		return summary.([]domain.TransactionByTagSummary), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) GetTransactionComparison(userID string, options domain.TransactionComparisonOptions) (*domain.TransactionComparison, error) {
+	args := m.Called(userID, options)
+
+	comparison := args.Get(0)
+	if comparison != nil {
+		return comparison.(*domain.TransactionComparison), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) GetUserTransactions(userID string, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	args := m.Called(userID, search.Type)
+
 	transactions := args.Get(0)
 	if transactions != nil {
-		return transactions.([]domain.PersonalTransaction), args.Error(1)
+		return &domain.TransactionPage{Transactions: transactions.([]domain.PersonalTransaction)}, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockTransactionService) GetTransactionSummary(userID string, startDate time.Time, endDate time.Time) (map[int]application.TransactionSummary, error) {
+func (m *MockTransactionService) GetTransactionSummary(userID string, startDate time.Time, endDate time.Time) (*domain.TransactionSummary, error) {
 	//TODO implement me
 	panic("implement me")
 }
@@ -56,14 +76,24 @@ func (m *MockTransactionService) CreateTransaction(transaction *domain.PersonalT
 	panic("implement me")
 }
 
-func (m *MockTransactionService) UpdateTransaction(transaction domain.PersonalTransaction) error {
-	//TODO implement me
-	panic("implement me")
+func (m *MockTransactionService) GetTransaction(transactionID, userID string) (*domain.PersonalTransaction, error) {
+	args := m.Called(transactionID, userID)
+
+	transaction := args.Get(0)
+	if transaction != nil {
+		return transaction.(*domain.PersonalTransaction), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockTransactionService) DeleteTransaction(transactionID int) error {
-	//TODO implement me
-	panic("implement me")
+func (m *MockTransactionService) UpdateTransaction(transaction *domain.PersonalTransaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) DeleteTransaction(transactionID, userID string) error {
+	args := m.Called(transactionID, userID)
+	return args.Error(0)
 }
 
 var predefinedCategoryMap = map[int]struct{}{
@@ -76,7 +106,10 @@ var userCategoryMap = map[int]struct{}{
 	20: {},
 }
 
-func (m *MockTransactionService) CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string) error {
+func (m *MockTransactionService) CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string, options domain.BulkCreateOptions) (*domain.BulkCreateResult, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 	var validationErrors = &financeErrors.ValidationErrors{}
 
 	for i, transaction := range transactions {
@@ -101,7 +134,15 @@ func (m *MockTransactionService) CreateTransactionsBulk(transactions []*domain.P
 	}
 
 	if len(validationErrors.Errors) > 0 {
-		return validationErrors
+		return nil, validationErrors
+	}
+	return &domain.BulkCreateResult{DryRun: options.DryRun, Created: len(transactions)}, nil
+}
+
+func (m *MockTransactionService) GetPaymentSourceHistory(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PaymentSourceHistoryEntry, error) {
+	args := m.Called(userID, paymentSourceID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
-	return nil
+	return args.Get(0).([]domain.PaymentSourceHistoryEntry), args.Error(1)
 }
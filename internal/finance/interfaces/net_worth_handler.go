@@ -0,0 +1,100 @@
+package interfaces
+
+import (
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"time"
+)
+
+type NetWorthServiceInterface interface {
+	GetNetWorth(userID string) (*domain.NetWorthSnapshot, error)
+	GetNetWorthHistory(userID string, startDate, endDate time.Time) (*domain.NetWorthHistory, error)
+}
+
+type NetWorthHandler struct {
+	service      NetWorthServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewNetWorthHandler(
+	service NetWorthServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *NetWorthHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &NetWorthHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// GetNetWorth returns the net worth of the user right now, broken down by asset class.
+func (h *NetWorthHandler) GetNetWorth(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	netWorth, err := h.service.GetNetWorth(userID)
+	if err != nil {
+		fmt.Println("Error computing net worth:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to compute net worth")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "success",
+		"message":   "Net worth retrieved successfully.",
+		"net_worth": netWorth,
+	})
+}
+
+// GetNetWorthHistory returns the daily net worth between ?start_date= and ?end_date=, the last year by default.
+func (h *NetWorthHandler) GetNetWorthHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var startDate, endDate time.Time
+	var err error
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+	}
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+	}
+
+	history, err := h.service.GetNetWorthHistory(userID, startDate, endDate)
+	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		fmt.Println("Error retrieving net worth history:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve net worth history")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Net worth history retrieved successfully.",
+		"history": history,
+	})
+}
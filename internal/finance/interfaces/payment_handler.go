@@ -1,12 +1,23 @@
 package interfaces
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
 	"net/http"
+	"strconv"
 )
 
 type PaymentServiceInterface interface {
 	GetAllPaymentMethods() ([]domain.PaymentMethod, error)
+	ListUserPaymentSources(userID string, includeArchived bool) ([]domain.PaymentSource, error)
+	GetUserPaymentSource(sourceID int, userID string) (*domain.PaymentSource, error)
+	CreatePaymentSource(source *domain.PaymentSource) error
+	UpdatePaymentSource(source *domain.PaymentSource) error
+	ArchivePaymentSource(sourceID int, userID string) error
+	RestorePaymentSource(sourceID int, userID string) error
 }
 
 type PaymentHandler struct {
@@ -43,3 +54,185 @@ func (h *PaymentHandler) GetPaymentMethods(w http.ResponseWriter, _ *http.Reques
 		"methods": methods,
 	})
 }
+
+func (h *PaymentHandler) GetUserPaymentSources(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	includeArchived := false
+	if value := r.URL.Query().Get("include_archived"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid include_archived value")
+			return
+		}
+		includeArchived = parsed
+	}
+
+	sources, err := h.service.ListUserPaymentSources(userID, includeArchived)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve payment sources")
+		return
+	}
+
+	masked := make([]domain.PaymentSource, len(sources))
+	for i, source := range sources {
+		masked[i] = source.Masked()
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Sources retrieved successfully.",
+		"sources": masked,
+	})
+}
+
+func (h *PaymentHandler) GetUserPaymentSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	source, err := h.service.GetUserPaymentSource(sourceID, userID)
+	if err != nil {
+		h.handlePaymentSourceError(w, err, "Failed to retrieve payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Source retrieved successfully.",
+		"source":  source.Masked(),
+	})
+}
+
+func (h *PaymentHandler) CreatePaymentSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var source domain.PaymentSource
+	if err := json.NewDecoder(r.Body).Decode(&source); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	source.ID = 0
+	source.UserID = userID
+
+	if err := h.service.CreatePaymentSource(&source); err != nil {
+		h.handlePaymentSourceError(w, err, "Failed to create payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Source successfully created.",
+		"source":  source.Masked(),
+	})
+}
+
+func (h *PaymentHandler) UpdatePaymentSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var source domain.PaymentSource
+	if err := json.NewDecoder(r.Body).Decode(&source); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	source.ID = sourceID
+	source.UserID = userID
+
+	if err := h.service.UpdatePaymentSource(&source); err != nil {
+		h.handlePaymentSourceError(w, err, "Failed to update payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Source successfully updated.",
+		"source":  source.Masked(),
+	})
+}
+
+func (h *PaymentHandler) ArchivePaymentSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ArchivePaymentSource(sourceID, userID); err != nil {
+		h.handlePaymentSourceError(w, err, "Failed to archive payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Source archived successfully.",
+	})
+}
+
+func (h *PaymentHandler) RestorePaymentSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RestorePaymentSource(sourceID, userID); err != nil {
+		h.handlePaymentSourceError(w, err, "Failed to restore payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Source restored successfully.",
+	})
+}
+
+func (h *PaymentHandler) sourceIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	sourceID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || sourceID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Payment source not found")
+		return 0, false
+	}
+	return sourceID, true
+}
+
+func (h *PaymentHandler) handlePaymentSourceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrPaymentSourceNotFound):
+		h.respondError(w, http.StatusNotFound, "Payment source not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during payment source operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -1,9 +1,13 @@
 package interfaces
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"github.com/sebuszqo/FinanceManager/internal/finance/application"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	"github.com/sebuszqo/FinanceManager/internal/finance/infrastructure"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -79,3 +83,87 @@ func TestGetPaymentMethods_Error(t *testing.T) {
 	assert.Equal(t, "error", response["status"])
 	assert.Equal(t, "Failed to retrieve payment methods", response["message"])
 }
+
+// the payment source tests run the handler against the real service, so the status codes follow its rules
+func newPaymentSourceHandler(repo *infrastructure.MockPaymentRepository) *PaymentHandler {
+	repo.Methods = []domain.PaymentMethod{{ID: 1, Name: "Payment Card"}}
+	return NewPaymentHandler(application.NewPaymentService(repo), respondJSON, respondError)
+}
+
+func TestCreatePaymentSource_MasksSensitiveDetails(t *testing.T) {
+	repo := &infrastructure.MockPaymentRepository{}
+	handler := newPaymentSourceHandler(repo)
+
+	body := `{"name": "Main account", "payment_method_id": 1, "details": {"iban": "PL61 1090 1014 0000 0712 1981 2874", "bank": "Santander"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/protected/finance/payment/sources", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	handler.CreatePaymentSource(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var response map[string]interface{}
+	err := json.NewDecoder(res.Body).Decode(&response)
+	assert.NoError(t, err)
+
+	details := response["source"].(map[string]interface{})["details"].(map[string]interface{})
+	assert.Equal(t, "**** 2874", details["iban"])
+	assert.Equal(t, "Santander", details["bank"])
+	assert.Equal(t, "PL61 1090 1014 0000 0712 1981 2874", repo.Sources[0].Details["iban"])
+}
+
+func TestCreatePaymentSource_InvalidIBAN(t *testing.T) {
+	handler := newPaymentSourceHandler(&infrastructure.MockPaymentRepository{})
+
+	body := `{"name": "Main account", "payment_method_id": 1, "details": {"iban": "PL00109010140000071219812874"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/protected/finance/payment/sources", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	handler.CreatePaymentSource(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestCreatePaymentSource_UnknownPaymentMethod(t *testing.T) {
+	handler := newPaymentSourceHandler(&infrastructure.MockPaymentRepository{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/protected/finance/payment/sources", bytes.NewBufferString(`{"name": "Main account", "payment_method_id": 7}`))
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	handler.CreatePaymentSource(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestArchivePaymentSource_HiddenFromDefaultList(t *testing.T) {
+	handler := newPaymentSourceHandler(&infrastructure.MockPaymentRepository{
+		Sources: []domain.PaymentSource{
+			{ID: 1, UserID: "valid-user-id", PaymentMethodID: 1, Name: "Old card"},
+			{ID: 2, UserID: "valid-user-id", PaymentMethodID: 1, Name: "New card"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/protected/finance/payment/sources/1/archive", nil)
+	req.SetPathValue("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+	handler.ArchivePaymentSource(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/protected/finance/payment/sources", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w = httptest.NewRecorder()
+	handler.GetUserPaymentSources(w, req)
+
+	var response map[string]interface{}
+	err := json.NewDecoder(w.Result().Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response["sources"], 1)
+}
@@ -0,0 +1,156 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ReconciliationServiceInterface interface {
+	GetReconciliations(paymentSourceID int, userID string) ([]domain.Reconciliation, error)
+	PreviewReconciliation(paymentSourceID int, userID string, statementDate time.Time, statementBalance money.Decimal) (*domain.ReconciliationPreview, error)
+	Reconcile(reconciliation *domain.Reconciliation, userID string) error
+}
+
+type ReconciliationHandler struct {
+	service      ReconciliationServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewReconciliationHandler(
+	service ReconciliationServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *ReconciliationHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &ReconciliationHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *ReconciliationHandler) GetReconciliations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	reconciliations, err := h.service.GetReconciliations(sourceID, userID)
+	if err != nil {
+		h.handleReconciliationError(w, err, "Failed to retrieve reconciliations")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":          "success",
+		"message":         "Reconciliations retrieved successfully.",
+		"reconciliations": reconciliations,
+	})
+}
+
+// PreviewReconciliation compares ?balance= of a statement from ?date= (today by default) with the reconciled
+// transactions and lists the uncleared ones.
+func (h *ReconciliationHandler) PreviewReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	statementDate := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid date format")
+			return
+		}
+		statementDate = date
+	}
+	statementBalance, err := money.Parse(r.URL.Query().Get("balance"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid or missing statement balance")
+		return
+	}
+
+	preview, err := h.service.PreviewReconciliation(sourceID, userID, statementDate, statementBalance)
+	if err != nil {
+		h.handleReconciliationError(w, err, "Failed to preview reconciliation")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         "success",
+		"message":        "Reconciliation preview retrieved successfully.",
+		"reconciliation": preview,
+	})
+}
+
+func (h *ReconciliationHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, ok := h.sourceIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var reconciliation domain.Reconciliation
+	if err := json.NewDecoder(r.Body).Decode(&reconciliation); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	reconciliation.PaymentSourceID = sourceID
+
+	if err := h.service.Reconcile(&reconciliation, userID); err != nil {
+		h.handleReconciliationError(w, err, "Failed to reconcile payment source")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":         "success",
+		"message":        "Payment source successfully reconciled.",
+		"reconciliation": reconciliation,
+	})
+}
+
+func (h *ReconciliationHandler) sourceIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	sourceID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || sourceID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Payment source not found")
+		return 0, false
+	}
+	return sourceID, true
+}
+
+func (h *ReconciliationHandler) handleReconciliationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrPaymentSourceNotFound):
+		h.respondError(w, http.StatusNotFound, "Payment source not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during reconciliation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
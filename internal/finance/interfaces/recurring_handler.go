@@ -0,0 +1,326 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxOccurrencePreviewYears bounds the date range of an occurrence preview.
+const maxOccurrencePreviewYears = 5
+
+type RecurringTransactionServiceInterface interface {
+	GetUserRules(userID string) ([]domain.RecurringRule, error)
+	GetRule(ruleID, userID string) (*domain.RecurringRule, error)
+	CreateRule(rule *domain.RecurringRule) error
+	UpdateRule(rule *domain.RecurringRule) error
+	DeleteRule(ruleID, userID string) error
+	PreviewOccurrences(ruleID, userID string, from, to time.Time, limit int) ([]domain.RecurringOccurrence, error)
+	SetOccurrenceException(exception *domain.RecurringException, userID string) error
+	DeleteOccurrenceException(ruleID, userID string, occurrenceDate time.Time) error
+}
+
+type RecurringTransactionHandler struct {
+	service      RecurringTransactionServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewRecurringTransactionHandler(
+	service RecurringTransactionServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *RecurringTransactionHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &RecurringTransactionHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *RecurringTransactionHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rules, err := h.service.GetUserRules(userID)
+	if err != nil {
+		h.handleRecurringError(w, err, "Failed to retrieve recurring transactions")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Recurring transactions retrieved successfully.",
+		"rules":   rules,
+	})
+}
+
+func (h *RecurringTransactionHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.GetRule(ruleID, userID)
+	if err != nil {
+		h.handleRecurringError(w, err, "Failed to retrieve recurring transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Recurring transaction retrieved successfully.",
+		"rule":    rule,
+	})
+}
+
+func (h *RecurringTransactionHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var rule domain.RecurringRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	rule.UserID = userID
+
+	if err := h.service.CreateRule(&rule); err != nil {
+		h.handleRecurringError(w, err, "Failed to create recurring transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Recurring transaction successfully created.",
+		"rule":    rule,
+	})
+}
+
+func (h *RecurringTransactionHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var rule domain.RecurringRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	rule.ID = ruleID
+	rule.UserID = userID
+
+	if err := h.service.UpdateRule(&rule); err != nil {
+		h.handleRecurringError(w, err, "Failed to update recurring transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Recurring transaction successfully updated.",
+		"rule":    rule,
+	})
+}
+
+func (h *RecurringTransactionHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(ruleID, userID); err != nil {
+		h.handleRecurringError(w, err, "Failed to delete recurring transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Recurring transaction deleted successfully.",
+	})
+}
+
+// GetOccurrences previews the upcoming occurrences of a rule, by default the next 12 within a year from today.
+func (h *RecurringTransactionHandler) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	var startDate, endDate time.Time
+	var err error
+
+	if startDateStr == "" {
+		startDate = domain.TruncateToDate(time.Now())
+	} else {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+	}
+
+	if endDateStr == "" {
+		endDate = startDate.AddDate(1, 0, 0)
+	} else {
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+	}
+	if endDate.After(startDate.AddDate(maxOccurrencePreviewYears, 0, 0)) {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Date range must not exceed %d years", maxOccurrencePreviewYears))
+		return
+	}
+
+	limit := 12
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 366 {
+			h.respondError(w, http.StatusBadRequest, "Invalid limit value")
+			return
+		}
+	}
+
+	occurrences, err := h.service.PreviewOccurrences(ruleID, userID, startDate, endDate, limit)
+	if err != nil {
+		h.handleRecurringError(w, err, "Failed to retrieve occurrences")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "success",
+		"message":     "Occurrences retrieved successfully.",
+		"occurrences": occurrences,
+	})
+}
+
+// UpdateOccurrence skips ({"skipped": true}) or overrides name, amount or description of a single upcoming occurrence.
+func (h *RecurringTransactionHandler) UpdateOccurrence(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	occurrenceDate, ok := h.occurrenceDateFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var exception domain.RecurringException
+	if err := json.NewDecoder(r.Body).Decode(&exception); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	exception.RuleID = ruleID
+	exception.OccurrenceDate = occurrenceDate
+
+	if err := h.service.SetOccurrenceException(&exception, userID); err != nil {
+		h.handleRecurringError(w, err, "Failed to update occurrence")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"message":    "Occurrence successfully updated.",
+		"occurrence": exception,
+	})
+}
+
+func (h *RecurringTransactionHandler) DeleteOccurrenceException(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ruleID, ok := h.ruleIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	occurrenceDate, ok := h.occurrenceDateFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteOccurrenceException(ruleID, userID, occurrenceDate); err != nil {
+		h.handleRecurringError(w, err, "Failed to restore occurrence")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Occurrence restored successfully.",
+	})
+}
+
+func (h *RecurringTransactionHandler) ruleIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ruleID := r.PathValue("id")
+	if _, err := uuid.Parse(ruleID); err != nil {
+		h.respondError(w, http.StatusNotFound, "Recurring transaction not found")
+		return "", false
+	}
+	return ruleID, true
+}
+
+func (h *RecurringTransactionHandler) occurrenceDateFromPath(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	occurrenceDate, err := time.Parse("2006-01-02", r.PathValue("date"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid occurrence date format")
+		return time.Time{}, false
+	}
+	return occurrenceDate, true
+}
+
+func (h *RecurringTransactionHandler) handleRecurringError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrRecurringRuleNotFound):
+		h.respondError(w, http.StatusNotFound, "Recurring transaction not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during recurring transaction operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,185 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+)
+
+type SavingsGoalServiceInterface interface {
+	GetUserGoalsProgress(userID string) ([]domain.SavingsGoalProgress, error)
+	GetGoalProgress(goalID int, userID string) (*domain.SavingsGoalProgress, error)
+	CreateGoal(goal *domain.SavingsGoal) error
+	UpdateGoal(goal *domain.SavingsGoal) error
+	DeleteGoal(goalID int, userID string) error
+}
+
+type SavingsGoalHandler struct {
+	service      SavingsGoalServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewSavingsGoalHandler(
+	service SavingsGoalServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *SavingsGoalHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &SavingsGoalHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+// GetGoals returns all savings goals of the user with their progress today.
+func (h *SavingsGoalHandler) GetGoals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	goals, err := h.service.GetUserGoalsProgress(userID)
+	if err != nil {
+		h.handleSavingsGoalError(w, err, "Failed to retrieve savings goals")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Savings goals retrieved successfully.",
+		"goals":   goals,
+	})
+}
+
+func (h *SavingsGoalHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	goalID, ok := h.goalIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	goal, err := h.service.GetGoalProgress(goalID, userID)
+	if err != nil {
+		h.handleSavingsGoalError(w, err, "Failed to retrieve savings goal")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Savings goal retrieved successfully.",
+		"goal":    goal,
+	})
+}
+
+func (h *SavingsGoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var goal domain.SavingsGoal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	goal.UserID = userID
+
+	if err := h.service.CreateGoal(&goal); err != nil {
+		h.handleSavingsGoalError(w, err, "Failed to create savings goal")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Savings goal successfully created.",
+		"goal":    goal,
+	})
+}
+
+func (h *SavingsGoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	goalID, ok := h.goalIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var goal domain.SavingsGoal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	goal.ID = goalID
+	goal.UserID = userID
+
+	if err := h.service.UpdateGoal(&goal); err != nil {
+		h.handleSavingsGoalError(w, err, "Failed to update savings goal")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Savings goal successfully updated.",
+		"goal":    goal,
+	})
+}
+
+func (h *SavingsGoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	goalID, ok := h.goalIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteGoal(goalID, userID); err != nil {
+		h.handleSavingsGoalError(w, err, "Failed to delete savings goal")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Savings goal deleted successfully.",
+	})
+}
+
+func (h *SavingsGoalHandler) goalIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	goalID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || goalID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Savings goal not found")
+		return 0, false
+	}
+	return goalID, true
+}
+
+func (h *SavingsGoalHandler) handleSavingsGoalError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrSavingsGoalNotFound):
+		h.respondError(w, http.StatusNotFound, "Savings goal not found")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during savings goal operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,186 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"net/http"
+	"strconv"
+)
+
+type TagServiceInterface interface {
+	GetUserTags(userID string) ([]domain.Tag, error)
+	GetTag(tagID int, userID string) (*domain.Tag, error)
+	CreateTag(tag *domain.Tag) error
+	UpdateTag(tag *domain.Tag) error
+	DeleteTag(tagID int, userID string) error
+}
+
+type TagHandler struct {
+	service      TagServiceInterface
+	respondJSON  func(w http.ResponseWriter, status int, payload interface{})
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string)
+}
+
+func NewTagHandler(
+	service TagServiceInterface,
+	respondJSON func(w http.ResponseWriter, status int, payload interface{}),
+	respondError func(w http.ResponseWriter, status int, message string, errors ...[]string),
+) *TagHandler {
+	if service == nil || respondJSON == nil || respondError == nil {
+		panic("Service and response functions must not be nil")
+	}
+	return &TagHandler{
+		service:      service,
+		respondJSON:  respondJSON,
+		respondError: respondError,
+	}
+}
+
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tags, err := h.service.GetUserTags(userID)
+	if err != nil {
+		h.handleTagError(w, err, "Failed to retrieve tags")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tags retrieved successfully.",
+		"tags":    tags,
+	})
+}
+
+func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	tagID, ok := h.tagIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	tag, err := h.service.GetTag(tagID, userID)
+	if err != nil {
+		h.handleTagError(w, err, "Failed to retrieve tag")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tag retrieved successfully.",
+		"tag":     tag,
+	})
+}
+
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var tag domain.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	tag.UserID = userID
+
+	if err := h.service.CreateTag(&tag); err != nil {
+		h.handleTagError(w, err, "Failed to create tag")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "Tag successfully created.",
+		"tag":     tag,
+	})
+}
+
+func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	tagID, ok := h.tagIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var tag domain.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	tag.ID = tagID
+	tag.UserID = userID
+
+	if err := h.service.UpdateTag(&tag); err != nil {
+		h.handleTagError(w, err, "Failed to update tag")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tag successfully updated.",
+		"tag":     tag,
+	})
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	tagID, ok := h.tagIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(tagID, userID); err != nil {
+		h.handleTagError(w, err, "Failed to delete tag")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tag deleted successfully.",
+	})
+}
+
+func (h *TagHandler) tagIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	tagID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || tagID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Tag not found")
+		return 0, false
+	}
+	return tagID, true
+}
+
+func (h *TagHandler) handleTagError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, financeErrors.ErrTagNotFound):
+		h.respondError(w, http.StatusNotFound, "Tag not found")
+	case errors.Is(err, financeErrors.ErrTagNameTaken):
+		h.respondError(w, http.StatusConflict, "Tag with this name already exists")
+	case financeErrors.IsValidationError(err):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println("Error during tag operation:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -4,23 +4,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/sebuszqo/FinanceManager/internal/finance/application"
+	"github.com/google/uuid"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
 	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type TransactionServiceInterface interface {
 	CreateTransaction(transaction *domain.PersonalTransaction) error
-	CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string) error
-	GetUserTransactions(userID, transactionType string, startDate, endDate time.Time, limit, page int) ([]domain.PersonalTransaction, error)
-	UpdateTransaction(transaction domain.PersonalTransaction) error
-	DeleteTransaction(transactionID int) error
-	GetTransactionSummary(userID string, startDate, endDate time.Time) (map[int]application.TransactionSummary, error)
+	CreateTransactionsBulk(transactions []*domain.PersonalTransaction, userID string, options domain.BulkCreateOptions) (*domain.BulkCreateResult, error)
+	GetUserTransactions(userID string, search domain.TransactionSearch) (*domain.TransactionPage, error)
+	GetTransaction(transactionID, userID string) (*domain.PersonalTransaction, error)
+	UpdateTransaction(transaction *domain.PersonalTransaction) error
+	DeleteTransaction(transactionID, userID string) error
+	GetTransactionSummary(userID string, startDate, endDate time.Time) (*domain.TransactionSummary, error)
 	GetTransactionSummaryByCategory(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByCategorySummary, error)
+	GetTransactionSummaryByTag(userID string, startDate, endDate time.Time, transactionType string) ([]domain.TransactionByTagSummary, error)
+	GetTransactionComparison(userID string, options domain.TransactionComparisonOptions) (*domain.TransactionComparison, error)
+	GetPaymentSourceHistory(userID string, paymentSourceID int, startDate, endDate time.Time) ([]domain.PaymentSourceHistoryEntry, error)
 }
 
 type PersonalTransactionHandler struct {
@@ -67,6 +73,10 @@ func (h *PersonalTransactionHandler) CreateTransaction(w http.ResponseWriter, r
 
 	transaction.UserID = userID
 	if err := h.service.CreateTransaction(&transaction); err != nil {
+		if errors.Is(err, financeErrors.ErrExternalIDTaken) {
+			h.respondError(w, http.StatusConflict, "Transaction with this external ID already exists")
+			return
+		}
 		if financeErrors.IsValidationError(err) {
 			h.respondError(w, http.StatusBadRequest, err.Error())
 			return
@@ -83,6 +93,10 @@ func (h *PersonalTransactionHandler) CreateTransaction(w http.ResponseWriter, r
 	})
 }
 
+// CreateTransactionsBulk creates all transactions of the request or none of them, with "mode=partial" the valid ones
+// are created and the errors of the others are reported in the result of their index. The "duplicates" query
+// parameter (skip, the default, overwrite or import) decides what happens to transactions which already exist, with
+// "dry_run=true" nothing is saved and the response only reports what would happen to every transaction.
 func (h *PersonalTransactionHandler) CreateTransactionsBulk(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok {
@@ -101,7 +115,17 @@ func (h *PersonalTransactionHandler) CreateTransactionsBulk(w http.ResponseWrite
 		return
 	}
 
-	if err := h.service.CreateTransactionsBulk(req.Transactions, userID); err != nil {
+	options, ok := h.bulkCreateOptions(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.CreateTransactionsBulk(req.Transactions, userID, options)
+	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if financeErrors.IsValidationErrors(err) {
 			var validationErrors *financeErrors.ValidationErrors
 			errors.As(err, &validationErrors)
@@ -116,13 +140,42 @@ func (h *PersonalTransactionHandler) CreateTransactionsBulk(w http.ResponseWrite
 		h.respondError(w, http.StatusInternalServerError, "Failed to create transaction")
 		return
 	}
+	if options.DryRun {
+		h.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  "success",
+			"message": "Dry run completed, no transactions were saved.",
+			"data":    result,
+		})
+		return
+	}
 	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"status":  "success",
 		"message": "Transactions successfully created.",
-		"data":    req.Transactions,
+		"data":    result,
 	})
 }
 
+func (h *PersonalTransactionHandler) bulkCreateOptions(w http.ResponseWriter, r *http.Request) (domain.BulkCreateOptions, bool) {
+	options := domain.BulkCreateOptions{DuplicatePolicy: domain.DuplicatePolicy(r.URL.Query().Get("duplicates"))}
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid dry_run value")
+			return options, false
+		}
+		options.DryRun = dryRun
+	}
+	switch r.URL.Query().Get("mode") {
+	case "", "all":
+	case "partial":
+		options.Partial = true
+	default:
+		h.respondError(w, http.StatusBadRequest, "Invalid mode value, expected 'all' or 'partial'")
+		return options, false
+	}
+	return options, true
+}
+
 func (h *PersonalTransactionHandler) GetUserTransactions(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok {
@@ -184,19 +237,51 @@ func (h *PersonalTransactionHandler) GetUserTransactions(w http.ResponseWriter,
 		page = 1
 	}
 
-	transactions, err := h.service.GetUserTransactions(userID, transactionType, startDate, endDate, limit, page)
+	search := domain.TransactionSearch{
+		Type:      transactionType,
+		StartDate: startDate,
+		EndDate:   endDate,
+		SortBy:    domain.TransactionSortField(r.URL.Query().Get("sort")),
+		Limit:     limit,
+		Page:      page,
+		Cursor:    r.URL.Query().Get("cursor"),
+	}
+
+	if invalidParam := searchFiltersFromQuery(r, &search); invalidParam != "" {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s value", invalidParam))
+		return
+	}
+
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		search.Ascending = true
+	default:
+		h.respondError(w, http.StatusBadRequest, "Invalid order value")
+		return
+	}
+
+	result, err := h.service.GetUserTransactions(userID, search)
 	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve transactions")
 		return
 	}
 
 	h.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Transactions retrieved successfully.",
-		"data":    transactions,
+		"status":      "success",
+		"message":     "Transactions retrieved successfully.",
+		"data":        result.Transactions,
+		"total_count": result.TotalCount,
+		"next_cursor": result.NextCursor,
 	})
 }
 
+// GetTransactionSummary returns the income and expense totals per year, month and week between ?start_date= and
+// ?end_date=, from the start of the current year until today by default.
 func (h *PersonalTransactionHandler) GetTransactionSummary(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok {
@@ -206,12 +291,11 @@ func (h *PersonalTransactionHandler) GetTransactionSummary(w http.ResponseWriter
 	startDateStr := r.URL.Query().Get("start_date")
 	endDateStr := r.URL.Query().Get("end_date")
 
+	// zero dates are defaulted by the service, in the time zone of the user
 	var startDate, endDate time.Time
 	var err error
 
-	if startDateStr == "" {
-		startDate = time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	} else {
+	if startDateStr != "" {
 		startDate, err = time.Parse("2006-01-02", startDateStr)
 		if err != nil {
 			h.respondError(w, http.StatusBadRequest, "Invalid start date format")
@@ -219,9 +303,7 @@ func (h *PersonalTransactionHandler) GetTransactionSummary(w http.ResponseWriter
 		}
 	}
 
-	if endDateStr == "" {
-		endDate = time.Now()
-	} else {
+	if endDateStr != "" {
 		endDate, err = time.Parse("2006-01-02", endDateStr)
 		if err != nil {
 			h.respondError(w, http.StatusBadRequest, "Invalid end date format")
@@ -230,6 +312,10 @@ func (h *PersonalTransactionHandler) GetTransactionSummary(w http.ResponseWriter
 	}
 	summary, err := h.service.GetTransactionSummary(userID, startDate, endDate)
 	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve transaction summary")
 		return
 	}
@@ -293,3 +379,348 @@ func (h *PersonalTransactionHandler) GetTransactionSummaryByCategory(w http.Resp
 		"data":    summary,
 	})
 }
+
+// GetTransactionSummaryByTag sums up the transactions per tag, filtered by ?type= and the date range like the category summary.
+func (h *PersonalTransactionHandler) GetTransactionSummaryByTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionType := r.URL.Query().Get("type")
+	if !domain.IsValidTransactionType(transactionType) {
+		h.respondError(w, http.StatusBadRequest, "Invalid transaction type")
+		return
+	}
+	startDate, endDate, ok := h.dateRangeFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.GetTransactionSummaryByTag(userID, startDate, endDate, transactionType)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve tag summary")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tag summary retrieved successfully.",
+		"data":    summary,
+	})
+}
+
+// searchFiltersFromQuery reads the ID lists, the amount range and the text of the search from the query, shared by
+// the transactions list and the export. It returns the name of the first invalid parameter, empty if all are valid.
+func searchFiltersFromQuery(r *http.Request, search *domain.TransactionSearch) string {
+	search.Text = r.URL.Query().Get("q")
+
+	var err error
+	for _, param := range []struct {
+		name string
+		ids  *[]int
+	}{
+		{"predefined_category_ids", &search.PredefinedCategoryIDs},
+		{"user_category_ids", &search.UserCategoryIDs},
+		{"payment_method_ids", &search.PaymentMethodIDs},
+		{"payment_source_ids", &search.PaymentSourceIDs},
+		{"tags", &search.TagIDs},
+	} {
+		if *param.ids, err = idsFromQuery(r, param.name); err != nil {
+			return param.name
+		}
+	}
+
+	for _, param := range []struct {
+		name   string
+		amount **money.Decimal
+	}{
+		{"min_amount", &search.MinAmount},
+		{"max_amount", &search.MaxAmount},
+	} {
+		if value := r.URL.Query().Get(param.name); value != "" {
+			parsed, err := money.Parse(value)
+			if err != nil {
+				return param.name
+			}
+			*param.amount = &parsed
+		}
+	}
+	return ""
+}
+
+// idsFromQuery parses a comma separated list of IDs, e.g. tags=3,7.
+func idsFromQuery(r *http.Request, param string) ([]int, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// GetPaymentSourceHistory lists the transactions of a single payment source, transfers included.
+func (h *PersonalTransactionHandler) GetPaymentSourceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sourceID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || sourceID <= 0 {
+		h.respondError(w, http.StatusNotFound, "Payment source not found")
+		return
+	}
+	startDate, endDate, ok := h.dateRangeFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.GetPaymentSourceHistory(userID, sourceID, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, financeErrors.ErrInvalidPaymentSource) {
+			h.respondError(w, http.StatusNotFound, "Payment source not found")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve payment source history")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Payment source history retrieved successfully.",
+		"data":    history,
+	})
+}
+
+// GetTransactionComparison compares the totals per category and per payment method of the period from ?start_date= to
+// ?end_date= with the period from ?compare_start_date= to ?compare_end_date= and with the monthly average of the
+// ?average_months= months before it. The service defaults missing periods to the current month up to today and the
+// same days of the previous month.
+func (h *PersonalTransactionHandler) GetTransactionComparison(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	options := domain.TransactionComparisonOptions{Type: query.Get("type")}
+	for _, param := range []struct {
+		name    string
+		date    *time.Time
+		message string
+	}{
+		{"start_date", &options.StartDate, "Invalid start date format"},
+		{"end_date", &options.EndDate, "Invalid end date format"},
+		{"compare_start_date", &options.CompareStartDate, "Invalid compare start date format"},
+		{"compare_end_date", &options.CompareEndDate, "Invalid compare end date format"},
+	} {
+		if value := query.Get(param.name); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				h.respondError(w, http.StatusBadRequest, param.message)
+				return
+			}
+			*param.date = date
+		}
+	}
+	if value := query.Get("average_months"); value != "" {
+		months, err := strconv.Atoi(value)
+		if err != nil || months <= 0 {
+			h.respondError(w, http.StatusBadRequest, "Average months must be a positive number")
+			return
+		}
+		options.AverageMonths = months
+	}
+
+	comparison, err := h.service.GetTransactionComparison(userID, options)
+	if err != nil {
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve transaction comparison")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Transaction comparison retrieved successfully.",
+		"data":    comparison,
+	})
+}
+
+// dateRangeFromQuery reads the start_date and end_date query parameters, defaulting to the current year up to now.
+func (h *PersonalTransactionHandler) dateRangeFromQuery(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	startDate := time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Now()
+	var err error
+	if value := r.URL.Query().Get("start_date"); value != "" {
+		if startDate, err = time.Parse("2006-01-02", value); err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid start date format")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if value := r.URL.Query().Get("end_date"); value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid end date format")
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return startDate, endDate, true
+}
+
+// transactionIDFromPath returns the {id} path value if it is a valid UUID, otherwise responds with 404.
+func (h *PersonalTransactionHandler) transactionIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	transactionID := r.PathValue("id")
+	if _, err := uuid.Parse(transactionID); err != nil {
+		h.respondError(w, http.StatusNotFound, "Transaction not found")
+		return "", false
+	}
+	return transactionID, true
+}
+
+func (h *PersonalTransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.service.GetTransaction(transactionID, userID)
+	if err != nil {
+		if errors.Is(err, financeErrors.ErrTransactionNotFound) {
+			h.respondError(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Transaction retrieved successfully.",
+		"data":    transaction,
+	})
+}
+
+// UpdateTransaction replaces every editable field of the transaction (PUT).
+func (h *PersonalTransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var transaction domain.PersonalTransaction
+	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	transaction.ID = transactionID
+	transaction.UserID = userID
+
+	h.saveTransactionUpdate(w, &transaction)
+}
+
+// PatchTransaction updates only the fields present in the request body (PATCH).
+func (h *PersonalTransactionHandler) PatchTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.service.GetTransaction(transactionID, userID)
+	if err != nil {
+		if errors.Is(err, financeErrors.ErrTransactionNotFound) {
+			h.respondError(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve transaction")
+		return
+	}
+
+	// decoding on top of the stored transaction keeps every field that is missing in the body
+	if err := json.NewDecoder(r.Body).Decode(transaction); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	transaction.ID = transactionID
+	transaction.UserID = userID
+
+	h.saveTransactionUpdate(w, transaction)
+}
+
+func (h *PersonalTransactionHandler) saveTransactionUpdate(w http.ResponseWriter, transaction *domain.PersonalTransaction) {
+	if err := h.service.UpdateTransaction(transaction); err != nil {
+		if errors.Is(err, financeErrors.ErrTransactionNotFound) {
+			h.respondError(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		if errors.Is(err, financeErrors.ErrTransactionReconciled) {
+			h.respondError(w, http.StatusConflict, "Transaction is reconciled and can't be edited")
+			return
+		}
+		if errors.Is(err, financeErrors.ErrExternalIDTaken) {
+			h.respondError(w, http.StatusConflict, "Transaction with this external ID already exists")
+			return
+		}
+		if financeErrors.IsValidationError(err) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		fmt.Println("Error during transaction update:", err.Error())
+		h.respondError(w, http.StatusInternalServerError, "Failed to update transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Transaction successfully updated.",
+		"data":    transaction,
+	})
+}
+
+func (h *PersonalTransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	transactionID, ok := h.transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTransaction(transactionID, userID); err != nil {
+		if errors.Is(err, financeErrors.ErrTransactionNotFound) {
+			h.respondError(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to delete transaction")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Transaction deleted successfully.",
+	})
+}
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/sebuszqo/FinanceManager/internal/finance/domain"
+	financeErrors "github.com/sebuszqo/FinanceManager/internal/finance/errors"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestCreateTransactionsBulk_WithValidationError(t *testing.T) {
@@ -22,11 +25,11 @@ func TestCreateTransactionsBulk_WithValidationError(t *testing.T) {
 
 	body, err := json.Marshal(map[string]interface{}{
 		"transactions": []domain.PersonalTransaction{
-			{Name: "Transaction number1", Amount: -10, Type: "income"},                          // Invalid Amount
-			{Name: "Transaction number2", Amount: 100, Type: "income", PredefinedCategoryID: 0}, // Invalid Category ID
-			{Name: "Transaction number3", Amount: 50, Type: "income", PredefinedCategoryID: 3},  // Missing PaymentMethodID
-			{Name: "Transaction number4", Amount: 60, Type: "invalid_type"},
-			{Amount: 10, Type: "income"},
+			{Name: "Transaction number1", Amount: money.MustParse("-10"), Type: "income"},                          // Invalid Amount
+			{Name: "Transaction number2", Amount: money.MustParse("100"), Type: "income", PredefinedCategoryID: 0}, // Invalid Category ID
+			{Name: "Transaction number3", Amount: money.MustParse("50"), Type: "income", PredefinedCategoryID: 3},  // Missing PaymentMethodID
+			{Name: "Transaction number4", Amount: money.MustParse("60"), Type: "invalid_type"},
+			{Amount: money.MustParse("10"), Type: "income"},
 		},
 	})
 	assert.NoError(t, err)
@@ -53,7 +56,7 @@ func TestCreateTransactionsBulk_WithValidationError(t *testing.T) {
 			"Validation error at transaction 1: Amount must be greater than zero",
 			"Validation error at transaction 2: PredefinedCategoryID must be provided and must be greater than zero",
 			"Validation error at transaction 3: PaymentMethodID must be provided and must be greater than zero",
-			"Validation error at transaction 4: Type must be either 'income' or 'expense'",
+			"Validation error at transaction 4: Type must be either 'income', 'expense' or 'transfer'",
 			"Validation error at transaction 5: Name should be between 0 and 50",
 		}
 
@@ -93,7 +96,7 @@ func TestCreateTransactionsBulk_InvalidRequestBody(t *testing.T) {
 
 	body, err := json.Marshal(map[string]interface{}{
 		"wrongKey": []domain.PersonalTransaction{
-			{Amount: 100, Type: "income", PredefinedCategoryID: 2, UserCategoryID: nil},
+			{Amount: money.MustParse("100"), Type: "income", PredefinedCategoryID: 2, UserCategoryID: nil},
 		},
 	})
 	assert.NoError(t, err)
@@ -137,8 +140,8 @@ func TestCreateTransactionsBulk_InvalidRequestBody(t *testing.T) {
 
 	body, err = json.Marshal(map[string]interface{}{
 		"transactions": []domain.PersonalTransaction{
-			{Amount: -100, Type: "income", PredefinedCategoryID: 2, UserCategoryID: nil}, // Invalid Amount
-			{Amount: 50, Type: "expense", PredefinedCategoryID: 0, UserCategoryID: nil},  // Invalid CategoryID
+			{Amount: money.MustParse("-100"), Type: "income", PredefinedCategoryID: 2, UserCategoryID: nil}, // Invalid Amount
+			{Amount: money.MustParse("50"), Type: "expense", PredefinedCategoryID: 0, UserCategoryID: nil},  // Invalid CategoryID
 		},
 	})
 	assert.NoError(t, err)
@@ -204,7 +207,7 @@ func TestGetUserTransactions(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		mockService.On("GetUserTransactions", "valid-user-id", "income").Return([]domain.PersonalTransaction{
-			{ID: strconv.Itoa(1), Amount: 100, Type: "income"},
+			{ID: strconv.Itoa(1), Amount: money.MustParse("100"), Type: "income"},
 		}, nil)
 
 		handler.GetUserTransactions(w, req)
@@ -254,8 +257,8 @@ func TestGetTransactionSummaryByCategory_ValidRequest(t *testing.T) {
 
 	mockService.On("GetTransactionSummaryByCategory", "valid-user-id", mock.Anything, mock.Anything, "income").
 		Return([]domain.TransactionByCategorySummary{
-			{CategoryID: 1, CategoryName: "Food", TotalAmount: 100.0},
-			{CategoryID: 2, CategoryName: "Transport", TotalAmount: 50.0},
+			{CategoryID: 1, CategoryName: "Food", TotalAmount: money.MustParse("100.0")},
+			{CategoryID: 2, CategoryName: "Transport", TotalAmount: money.MustParse("50.0")},
 		}, nil)
 
 	handler.GetTransactionSummaryByCategory(w, req)
@@ -270,6 +273,44 @@ func TestGetTransactionSummaryByCategory_ValidRequest(t *testing.T) {
 	assert.Equal(t, "Category summary retrieved successfully.", response["message"])
 }
 
+func TestGetTransactionComparison_PassesOptions(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions/summary/comparison?type=income&start_date=2026-10-01&end_date=2026-10-31&average_months=6", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	mockService.On("GetTransactionComparison", "valid-user-id", domain.TransactionComparisonOptions{
+		Type:          "income",
+		StartDate:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2026, time.October, 31, 0, 0, 0, 0, time.UTC),
+		AverageMonths: 6,
+	}).Return(&domain.TransactionComparison{Type: "income"}, nil)
+
+	handler.GetTransactionComparison(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTransactionComparison_BadRequest(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+	mockService.On("GetTransactionComparison", "valid-user-id", mock.Anything).
+		Return(nil, financeErrors.NewValidationError("Type must be income or expense"))
+
+	for _, query := range []string{"compare_start_date=2026-13-01", "average_months=-1", "average_months=twelve", "type=transfer"} {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/summary/comparison?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.GetTransactionComparison(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
 func TestGetTransactionSummaryByCategory_InvalidTransactionType(t *testing.T) {
 
 	mockService := &MockTransactionService{}
@@ -291,3 +332,126 @@ func TestGetTransactionSummaryByCategory_InvalidTransactionType(t *testing.T) {
 
 	assert.Equal(t, "Invalid transaction type", response["message"])
 }
+
+func TestGetTransaction(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+	transactionID := "6f1c2a44-0f3e-4c55-9a57-0d5b8a7c9e11"
+
+	t.Run("Not found if id is not a UUID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/abc", nil)
+		req.SetPathValue("id", "abc")
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		handler.GetTransaction(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Not found if transaction belongs to someone else", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/"+transactionID, nil)
+		req.SetPathValue("id", transactionID)
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "other-user-id"))
+		w := httptest.NewRecorder()
+
+		mockService.On("GetTransaction", transactionID, "other-user-id").Return(nil, financeErrors.ErrTransactionNotFound)
+
+		handler.GetTransaction(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Returns transaction of the owner", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/"+transactionID, nil)
+		req.SetPathValue("id", transactionID)
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+		w := httptest.NewRecorder()
+
+		mockService.On("GetTransaction", transactionID, "valid-user-id").Return(&domain.PersonalTransaction{
+			ID: transactionID, UserID: "valid-user-id", Name: "Lunch", Amount: money.MustParse("25"), Type: "expense",
+		}, nil)
+
+		handler.GetTransaction(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var response map[string]interface{}
+		json.NewDecoder(res.Body).Decode(&response)
+		assert.Equal(t, "Transaction retrieved successfully.", response["message"])
+	})
+}
+
+func TestPatchTransaction_KeepsFieldsMissingInBody(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+	transactionID := "6f1c2a44-0f3e-4c55-9a57-0d5b8a7c9e11"
+
+	mockService.On("GetTransaction", transactionID, "valid-user-id").Return(&domain.PersonalTransaction{
+		ID: transactionID, UserID: "valid-user-id", Name: "Lunch", Amount: money.MustParse("25"), Type: "expense", PredefinedCategoryID: 3, PaymentMethodID: 1,
+	}, nil)
+	mockService.On("UpdateTransaction", mock.MatchedBy(func(transaction *domain.PersonalTransaction) bool {
+		return transaction.Amount.Equal(money.FromInt(52)) && transaction.Name == "Lunch" && transaction.PredefinedCategoryID == 3 && transaction.UserID == "valid-user-id"
+	})).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/transactions/"+transactionID, bytes.NewBufferString(`{"amount": 52}`))
+	req.SetPathValue("id", transactionID)
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	handler.PatchTransaction(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateTransaction_ValidationError(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+	transactionID := "6f1c2a44-0f3e-4c55-9a57-0d5b8a7c9e11"
+
+	mockService.On("UpdateTransaction", mock.Anything).Return(financeErrors.ErrInvalidPaymentMethod)
+
+	req := httptest.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBufferString(`{"name": "Lunch", "amount": 25, "type": "expense"}`))
+	req.SetPathValue("id", transactionID)
+	req = req.WithContext(context.WithValue(req.Context(), "userID", "valid-user-id"))
+	w := httptest.NewRecorder()
+
+	handler.UpdateTransaction(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	var response map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&response)
+	assert.Equal(t, financeErrors.ErrInvalidPaymentMethod.Error(), response["message"])
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	mockService := &MockTransactionService{}
+	handler := NewPersonalTransactionHandler(mockService, respondJSON, respondError)
+	transactionID := "6f1c2a44-0f3e-4c55-9a57-0d5b8a7c9e11"
+
+	mockService.On("DeleteTransaction", transactionID, "valid-user-id").Return(nil)
+	mockService.On("DeleteTransaction", transactionID, "other-user-id").Return(financeErrors.ErrTransactionNotFound)
+
+	for userID, expectedStatus := range map[string]int{"valid-user-id": http.StatusOK, "other-user-id": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/transactions/"+transactionID, nil)
+		req.SetPathValue("id", transactionID)
+		req = req.WithContext(context.WithValue(req.Context(), "userID", userID))
+		w := httptest.NewRecorder()
+
+		handler.DeleteTransaction(w, req)
+
+		res := w.Result()
+		res.Body.Close()
+		assert.Equal(t, expectedStatus, res.StatusCode)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/sebuszqo/FinanceManager/internal/investment/models"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"log"
 	"time"
 )
@@ -16,20 +17,20 @@ type Asset struct {
 	Name                 string
 	Ticker               string
 	AssetTypeID          int
-	CouponRate           float64
+	CouponRate           money.Decimal
 	MaturityDate         *time.Time
-	FaceValue            float64
-	DividendYield        float64
+	FaceValue            money.Decimal
+	DividendYield        money.Decimal
 	Accumulation         bool
-	TotalQuantity        float64
-	AveragePurchasePrice float64
-	TotalInvested        float64
-	CurrentValue         float64
-	UnrealizedGainLoss   float64
+	TotalQuantity        money.Decimal
+	AveragePurchasePrice money.Decimal
+	TotalInvested        money.Decimal
+	CurrentValue         money.Decimal
+	UnrealizedGainLoss   money.Decimal
 	Currency             string
 	Exchange             string
-	InterestAccrued      float64
-	//RealizedGainLoss float64
+	InterestAccrued      money.Decimal
+	//RealizedGainLoss money.Decimal
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -153,9 +154,9 @@ func (a *assetRepository) findAllByPortfolioID(ctx context.Context, portfolioID
 }
 
 func (a *assetRepository) getAssetByID(ctx context.Context, assetID uuid.UUID) (*Asset, error) {
-	query := `SELECT name, ticker, asset_type_id, coupon_rate, maturity_date, face_value, dividend_yield, accumulation, interest_accrued, created_at, updated_at  from assets WHERE id = $1`
+	query := `SELECT name, ticker, asset_type_id, coupon_rate, maturity_date, face_value, dividend_yield, accumulation, interest_accrued, COALESCE(currency, ''), created_at, updated_at  from assets WHERE id = $1`
 	asset := &Asset{}
-	err := a.db.QueryRowContext(ctx, query, assetID).Scan(&asset.Name, &asset.Ticker, &asset.AssetTypeID, &asset.CouponRate, &asset.MaturityDate, &asset.FaceValue, &asset.DividendYield, &asset.Accumulation, &asset.InterestAccrued, &asset.CreatedAt, &asset.UpdatedAt)
+	err := a.db.QueryRowContext(ctx, query, assetID).Scan(&asset.Name, &asset.Ticker, &asset.AssetTypeID, &asset.CouponRate, &asset.MaturityDate, &asset.FaceValue, &asset.DividendYield, &asset.Accumulation, &asset.InterestAccrued, &asset.Currency, &asset.CreatedAt, &asset.UpdatedAt)
 	return asset, err
 }
 
@@ -263,7 +264,7 @@ func (a *assetRepository) getAllAssets(ctx context.Context) ([]Asset, error) {
         SELECT id, portfolio_id, name, ticker, asset_type_id, coupon_rate, maturity_date,
                face_value, dividend_yield, accumulation, created_at, updated_at,
                total_quantity, average_purchase_price, total_invested, unrealized_gain_loss,
-               current_value, interest_accrued
+               current_value, interest_accrued, COALESCE(currency, '')
         FROM assets
     `)
 	if err != nil {
@@ -293,6 +294,7 @@ func (a *assetRepository) getAllAssets(ctx context.Context) ([]Asset, error) {
 			&a.UnrealizedGainLoss,
 			&a.CurrentValue,
 			&a.InterestAccrued,
+			&a.Currency,
 		); err != nil {
 			return nil, err
 		}
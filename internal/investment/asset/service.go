@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/sebuszqo/FinanceManager/internal/investment/models"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"log"
 	"strings"
 	"sync"
@@ -45,7 +46,7 @@ type TransactionService interface {
 
 type InstrumentService interface {
 	GetTickerWithPriceInstruments(ctx context.Context) ([]models.InstrumentPriceWithSymbol, error)
-	GetInstrumentPrice(ctx context.Context, symbol string) (float64, error)
+	GetInstrumentPrice(ctx context.Context, symbol string) (money.Decimal, error)
 }
 
 type AssetType struct {
@@ -245,7 +246,7 @@ func (s *service) UpdateAssetAggregates(ctx context.Context, assetID uuid.UUID)
 	}
 
 	// Step 3: Initialize variables
-	var totalQuantity, totalInvested, realizedGainLoss float64
+	totalQuantity, totalInvested, realizedGainLoss := money.Zero, money.Zero, money.Zero
 	assetType := s.assetTypeCache[asset.AssetTypeID]
 	// Step 4: Loop through transactions and calculate aggregates
 	for _, t := range transactions {
@@ -253,44 +254,42 @@ func (s *service) UpdateAssetAggregates(ctx context.Context, assetID uuid.UUID)
 		// Buy
 		case 1:
 			if assetType == "ETF" || assetType == "Stock" || assetType == "Cryptocurrency" {
-				totalQuantity += t.Quantity
-				totalInvested += t.Quantity * t.Price
+				totalQuantity = totalQuantity.Add(t.Quantity)
+				totalInvested = totalInvested.Add(t.Quantity.Mul(t.Price))
 			} else if assetType == "Bond" {
-				totalQuantity += t.Quantity
-				totalInvested += t.Quantity * asset.FaceValue
+				totalQuantity = totalQuantity.Add(t.Quantity)
+				totalInvested = totalInvested.Add(t.Quantity.Mul(asset.FaceValue))
 			} else {
-				totalQuantity += t.Quantity
-				totalInvested += t.Quantity * t.Price
+				totalQuantity = totalQuantity.Add(t.Quantity)
+				totalInvested = totalInvested.Add(t.Quantity.Mul(t.Price))
 			}
 		// Sell
 		case 2:
-			if totalQuantity >= t.Quantity {
+			if !totalQuantity.LessThan(t.Quantity) {
 				// Calculate average purchase price before this sale
-				var averagePurchasePrice float64
-				if totalQuantity > 0 {
-					averagePurchasePrice = totalInvested / totalQuantity
-				} else {
-					averagePurchasePrice = 0
+				averagePurchasePrice := money.Zero
+				if totalQuantity.IsPositive() {
+					averagePurchasePrice = totalInvested.Div(totalQuantity)
 				}
 
-				totalQuantity -= t.Quantity
+				totalQuantity = totalQuantity.Sub(t.Quantity)
 
 				switch assetType {
 				case "ETF", "Stock", "Cryptocurrency":
-					totalInvested -= averagePurchasePrice * t.Quantity
+					totalInvested = totalInvested.Sub(averagePurchasePrice.Mul(t.Quantity))
 
-					gainLoss := (t.Price - averagePurchasePrice) * t.Quantity
-					realizedGainLoss += gainLoss
+					gainLoss := t.Price.Sub(averagePurchasePrice).Mul(t.Quantity)
+					realizedGainLoss = realizedGainLoss.Add(gainLoss)
 
 				case "Bond":
-					totalInvested -= asset.FaceValue * t.Quantity
-					gainLoss := (t.Price - asset.FaceValue) * t.Quantity
-					realizedGainLoss += gainLoss
+					totalInvested = totalInvested.Sub(asset.FaceValue.Mul(t.Quantity))
+					gainLoss := t.Price.Sub(asset.FaceValue).Mul(t.Quantity)
+					realizedGainLoss = realizedGainLoss.Add(gainLoss)
 
 				default:
-					totalInvested -= averagePurchasePrice * t.Quantity
-					gainLoss := (t.Price - averagePurchasePrice) * t.Quantity
-					realizedGainLoss += gainLoss
+					totalInvested = totalInvested.Sub(averagePurchasePrice.Mul(t.Quantity))
+					gainLoss := t.Price.Sub(averagePurchasePrice).Mul(t.Quantity)
+					realizedGainLoss = realizedGainLoss.Add(gainLoss)
 				}
 			} else {
 				// Handle selling more than owned, possibly return an error
@@ -304,16 +303,15 @@ func (s *service) UpdateAssetAggregates(ctx context.Context, assetID uuid.UUID)
 	}
 
 	// Step 5: Calculate average purchase price
-	var averagePurchasePrice float64
-	if totalQuantity > 0 {
-		averagePurchasePrice = totalInvested / totalQuantity
+	averagePurchasePrice := money.Zero
+	if totalQuantity.IsPositive() {
+		averagePurchasePrice = totalInvested.Div(totalQuantity)
 	} else {
-		averagePurchasePrice = 0
-		totalInvested = 0
+		totalInvested = money.Zero
 	}
 
 	// Step 6: Calculate current value and unrealized gain/loss
-	var currentValue, unrealizedGainLoss float64
+	var currentValue, unrealizedGainLoss money.Decimal
 
 	if assetType == "ETF" || assetType == "Stock" || assetType == "Cryptocurrency" {
 		// Fetch current market price
@@ -321,23 +319,24 @@ func (s *service) UpdateAssetAggregates(ctx context.Context, assetID uuid.UUID)
 		if err != nil {
 			return err
 		}
-		currentValue = totalQuantity * currentMarketPrice
-		unrealizedGainLoss = currentValue - totalInvested
+		currentValue = totalQuantity.Mul(currentMarketPrice)
 	} else if assetType == "Bond" {
 		// For bonds, use face value and accrued interest
-		currentValue = totalQuantity*asset.FaceValue + asset.InterestAccrued
-		unrealizedGainLoss = currentValue - totalInvested
+		currentValue = totalQuantity.Mul(asset.FaceValue).Add(asset.InterestAccrued)
 	} else {
 		// For other assets, implement appropriate logic
-		currentValue = totalQuantity * averagePurchasePrice
-		unrealizedGainLoss = currentValue - totalInvested
+		currentValue = totalQuantity.Mul(averagePurchasePrice)
 	}
+	// amounts are rounded once, so the stored gain/loss is exactly the difference of the stored amounts
+	totalInvested = totalInvested.RoundToCurrency(asset.Currency)
+	currentValue = currentValue.RoundToCurrency(asset.Currency)
+	unrealizedGainLoss = currentValue.Sub(totalInvested)
 
 	// Step 7: Update the asset record
 	updatedAsset := &Asset{
 		ID:                   assetID,
 		TotalQuantity:        totalQuantity,
-		AveragePurchasePrice: averagePurchasePrice,
+		AveragePurchasePrice: averagePurchasePrice.Round(4),
 		TotalInvested:        totalInvested,
 		CurrentValue:         currentValue,
 		UnrealizedGainLoss:   unrealizedGainLoss,
@@ -369,7 +368,7 @@ func (s *service) UpdateAssetPricing(ctx context.Context) error {
 		return err
 	}
 
-	priceMap := make(map[string]float64, len(instruments))
+	priceMap := make(map[string]money.Decimal, len(instruments))
 	for _, instrument := range instruments {
 		priceMap[strings.ToUpper(instrument.Symbol)] = instrument.Price
 	}
@@ -387,6 +386,12 @@ func (s *service) UpdateAssetPricing(ctx context.Context) error {
 		go func(a Asset) {
 			defer wg.Done()
 			defer func() { <-sem }()
+			// a single asset must not take the scheduler down with it
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Failed to update pricing of asset %s: %v", a.ID, r)
+				}
+			}()
 
 			assetType, exists := s.assetTypeCache[a.AssetTypeID]
 			if !exists {
@@ -396,12 +401,11 @@ func (s *service) UpdateAssetPricing(ctx context.Context) error {
 
 			switch assetType {
 			case "Bond":
-				annualCouponRate := a.CouponRate
-				dailyInterestRate := annualCouponRate / 100 / 365
-				interestAccrued := a.FaceValue * dailyInterestRate * a.TotalQuantity
-				a.InterestAccrued += interestAccrued
-				a.CurrentValue = a.FaceValue*a.TotalQuantity + a.InterestAccrued
-				a.UnrealizedGainLoss = a.CurrentValue - a.TotalInvested
+				// coupon rate is a yearly percentage, dividing once keeps the daily interest exact to Scale digits
+				interestAccrued := a.FaceValue.Mul(a.CouponRate).Mul(a.TotalQuantity).Div(money.FromInt(100 * 365))
+				a.InterestAccrued = a.InterestAccrued.Add(interestAccrued).RoundToCurrency(a.Currency)
+				a.CurrentValue = a.FaceValue.Mul(a.TotalQuantity).Add(a.InterestAccrued).RoundToCurrency(a.Currency)
+				a.UnrealizedGainLoss = a.CurrentValue.Sub(a.TotalInvested)
 				mu.Lock()
 				updatedAssets = append(updatedAssets, a)
 				mu.Unlock()
@@ -412,8 +416,8 @@ func (s *service) UpdateAssetPricing(ctx context.Context) error {
 					return
 				}
 
-				currentValue := a.TotalQuantity * updatedPrice
-				unrealizedGainLoss := currentValue - a.TotalInvested
+				currentValue := a.TotalQuantity.Mul(updatedPrice).RoundToCurrency(a.Currency)
+				unrealizedGainLoss := currentValue.Sub(a.TotalInvested)
 
 				a.CurrentValue = currentValue
 				a.UnrealizedGainLoss = unrealizedGainLoss
@@ -9,6 +9,7 @@ import (
 	"github.com/sebuszqo/FinanceManager/internal/investment/models"
 	portfolios "github.com/sebuszqo/FinanceManager/internal/investment/portfolio"
 	transactions "github.com/sebuszqo/FinanceManager/internal/investment/transaction"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"net/http"
 	"time"
 )
@@ -58,12 +59,12 @@ type createAssetRequest struct {
 	AssetTypeID int    `json:"asset_type_id"`
 	Currency    string `json:"currency"`
 	// Add other optional fields (for bonds, stocks, ETFs, etc.)
-	CouponRate    *float64 `json:"coupon_rate,omitempty"`
-	MaturityDate  *string  `json:"maturity_date,omitempty"`
-	FaceValue     *float64 `json:"face_value,omitempty"`
-	DividendYield *float64 `json:"dividend_yield,omitempty"`
-	Accumulation  *bool    `json:"accumulation,omitempty"`
-	Exchange      *string  `json:"exchange,omitempty"`
+	CouponRate    *money.Decimal `json:"coupon_rate,omitempty"`
+	MaturityDate  *string        `json:"maturity_date,omitempty"`
+	FaceValue     *money.Decimal `json:"face_value,omitempty"`
+	DividendYield *money.Decimal `json:"dividend_yield,omitempty"`
+	Accumulation  *bool          `json:"accumulation,omitempty"`
+	Exchange      *string        `json:"exchange,omitempty"`
 }
 
 func (h *InvestmentHandler) getUserIDReq(w http.ResponseWriter, r *http.Request) string {
@@ -295,6 +296,17 @@ func (h *InvestmentHandler) CreateAsset(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// coupon rate and dividend yield are NUMERIC(5, 2) percentages, the face value NUMERIC(15, 2)
+	if assetRequest.CouponRate != nil && !assetRequest.CouponRate.FitsNumeric(5, 2) ||
+		assetRequest.DividendYield != nil && !assetRequest.DividendYield.FitsNumeric(5, 2) {
+		h.respondError(w, http.StatusBadRequest, "CouponRate and DividendYield must be less than 1000")
+		return
+	}
+	if assetRequest.FaceValue != nil && !assetRequest.FaceValue.FitsNumeric(15, 2) {
+		h.respondError(w, http.StatusBadRequest, "FaceValue must be less than 10000000000000")
+		return
+	}
+
 	// Check if the asset already exists in the portfolio
 	exists, err := h.assetService.DoesAssetExist(r.Context(), portfolioID, assetRequest.Name, assetRequest.Ticker)
 	if err != nil {
@@ -326,11 +338,11 @@ func (h *InvestmentHandler) CreateAsset(w http.ResponseWriter, r *http.Request)
 		}
 		asset.DividendYield = *assetRequest.DividendYield
 		asset.Exchange = *assetRequest.Exchange
-		asset.CouponRate = 0       // not applicable for stocks
-		asset.MaturityDate = nil   // not applicable for stocks
-		asset.FaceValue = 0        // not applicable for stocks
-		asset.Accumulation = false // not applicable for stocks
-		asset.InterestAccrued = 0
+		asset.CouponRate = money.Zero // not applicable for stocks
+		asset.MaturityDate = nil      // not applicable for stocks
+		asset.FaceValue = money.Zero  // not applicable for stocks
+		asset.Accumulation = false    // not applicable for stocks
+		asset.InterestAccrued = money.Zero
 
 	case 2: // Bond
 		if assetRequest.CouponRate == nil || assetRequest.MaturityDate == nil || assetRequest.FaceValue == nil {
@@ -346,9 +358,9 @@ func (h *InvestmentHandler) CreateAsset(w http.ResponseWriter, r *http.Request)
 		asset.CouponRate = *assetRequest.CouponRate
 		asset.MaturityDate = &maturityDate
 		asset.FaceValue = *assetRequest.FaceValue
-		asset.DividendYield = 0    // not applicable for bonds
-		asset.Accumulation = false // not applicable for bonds
-		asset.InterestAccrued = 0
+		asset.DividendYield = money.Zero // not applicable for bonds
+		asset.Accumulation = false       // not applicable for bonds
+		asset.InterestAccrued = money.Zero
 
 	case 3: // ETF
 		if assetRequest.Accumulation == nil {
@@ -361,36 +373,36 @@ func (h *InvestmentHandler) CreateAsset(w http.ResponseWriter, r *http.Request)
 		}
 		asset.Accumulation = *assetRequest.Accumulation
 		asset.Exchange = *assetRequest.Exchange
-		asset.CouponRate = 0     // not applicable for ETFs
-		asset.MaturityDate = nil // not applicable for ETFs
-		asset.FaceValue = 0      // not applicable for ETFs
-		asset.DividendYield = 0  // not applicable for ETFs
-		asset.InterestAccrued = 0
+		asset.CouponRate = money.Zero    // not applicable for ETFs
+		asset.MaturityDate = nil         // not applicable for ETFs
+		asset.FaceValue = money.Zero     // not applicable for ETFs
+		asset.DividendYield = money.Zero // not applicable for ETFs
+		asset.InterestAccrued = money.Zero
 
 	case 4: // Cryptocurrency
 		// No specific fields required, so leave everything else as 0 or nil
-		asset.CouponRate = 0
+		asset.CouponRate = money.Zero
 		asset.MaturityDate = nil
-		asset.FaceValue = 0
-		asset.DividendYield = 0
+		asset.FaceValue = money.Zero
+		asset.DividendYield = money.Zero
 		asset.Accumulation = false
-		asset.InterestAccrued = 0
+		asset.InterestAccrued = money.Zero
 	case 5: // Savings Accounts
 		// No specific fields required, similar to Cryptocurrency
-		asset.CouponRate = 0
+		asset.CouponRate = money.Zero
 		asset.MaturityDate = nil
-		asset.FaceValue = 0
-		asset.DividendYield = 0
+		asset.FaceValue = money.Zero
+		asset.DividendYield = money.Zero
 		asset.Accumulation = false
-		asset.InterestAccrued = 0
+		asset.InterestAccrued = money.Zero
 	case 6: // Cash
 		// No specific fields required, similar to Cryptocurrency and Savings Accounts
-		asset.CouponRate = 0
+		asset.CouponRate = money.Zero
 		asset.MaturityDate = nil
-		asset.FaceValue = 0
-		asset.DividendYield = 0
+		asset.FaceValue = money.Zero
+		asset.DividendYield = money.Zero
 		asset.Accumulation = false
-		asset.InterestAccrued = 0
+		asset.InterestAccrued = money.Zero
 	default:
 		h.respondError(w, http.StatusBadRequest, "Unsupported asset type")
 		return
@@ -484,12 +496,12 @@ func (h *InvestmentHandler) GetAllAssets(w http.ResponseWriter, r *http.Request)
 
 // Transaction handler
 type createTransactionRequest struct {
-	TransactionTypeID int      `json:"transaction_type_id"`
-	Quantity          float64  `json:"quantity"`
-	Price             float64  `json:"price"`
-	TransactionDate   string   `json:"transaction_date"` // Could be ISO8601 format
-	DividendAmount    *float64 `json:"dividend_amount,omitempty"`
-	CouponAmount      *float64 `json:"coupon_amount,omitempty"`
+	TransactionTypeID int            `json:"transaction_type_id"`
+	Quantity          money.Decimal  `json:"quantity"`
+	Price             money.Decimal  `json:"price"`
+	TransactionDate   string         `json:"transaction_date"` // Could be ISO8601 format
+	DividendAmount    *money.Decimal `json:"dividend_amount,omitempty"`
+	CouponAmount      *money.Decimal `json:"coupon_amount,omitempty"`
 }
 
 func (h *InvestmentHandler) GetTransactionTypes(w http.ResponseWriter, r *http.Request) {
@@ -580,6 +592,16 @@ func (h *InvestmentHandler) CreateTransaction(w http.ResponseWriter, r *http.Req
 }
 
 func (h *InvestmentHandler) validateTransactionForAssetType(assetTypeName string, req createTransactionRequest) error {
+	// amounts beyond the NUMERIC columns of transactions would only fail when stored
+	if !req.Quantity.FitsNumeric(15, 4) {
+		return fmt.Errorf("quantity must be less than 100000000000")
+	}
+	if !req.Price.FitsNumeric(15, 2) ||
+		req.DividendAmount != nil && !req.DividendAmount.FitsNumeric(15, 2) ||
+		req.CouponAmount != nil && !req.CouponAmount.FitsNumeric(15, 2) {
+		return fmt.Errorf("price, dividendAmount and couponAmount must be less than 10000000000000")
+	}
+
 	switch assetTypeName {
 	case "Stock":
 		return h.validateStockTransaction(req)
@@ -601,15 +623,15 @@ func (h *InvestmentHandler) validateTransactionForAssetType(assetTypeName string
 func (h *InvestmentHandler) validateStockTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Buy
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and price must be greater than 0 for stock Buy transactions")
 		}
 	case 2: // Sell
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and price must be greater than 0 for stock Sell transactions")
 		}
 	case 3: // Dividend
-		if req.DividendAmount == nil || *req.DividendAmount <= 0 {
+		if req.DividendAmount == nil || !req.DividendAmount.IsPositive() {
 			return fmt.Errorf("dividendAmount must be greater than 0 for stock Dividend transactions")
 		}
 	default:
@@ -621,15 +643,15 @@ func (h *InvestmentHandler) validateStockTransaction(req createTransactionReques
 func (h *InvestmentHandler) validateBondTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Buy
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for bond Buy transactions")
 		}
 	case 2: // Sell
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for bond Sell transactions")
 		}
 	case 4: // Coupon Payment
-		if req.CouponAmount == nil || *req.CouponAmount <= 0 {
+		if req.CouponAmount == nil || !req.CouponAmount.IsPositive() {
 			return fmt.Errorf("couponAmount must be greater than 0 for bond Coupon Payment transactions")
 		}
 	default:
@@ -641,11 +663,11 @@ func (h *InvestmentHandler) validateBondTransaction(req createTransactionRequest
 func (h *InvestmentHandler) validateETFTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Buy
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for ETF Buy transactions")
 		}
 	case 2: // Sell
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for ETF Sell transactions")
 		}
 	default:
@@ -657,11 +679,11 @@ func (h *InvestmentHandler) validateETFTransaction(req createTransactionRequest)
 func (h *InvestmentHandler) validateCryptocurrencyTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Buy
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for cryptocurrency Buy transactions")
 		}
 	case 2: // Sell
-		if req.Quantity <= 0 || req.Price <= 0 {
+		if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
 			return fmt.Errorf("quantity and Price must be greater than 0 for cryptocurrency Sell transactions")
 		}
 	default:
@@ -673,11 +695,11 @@ func (h *InvestmentHandler) validateCryptocurrencyTransaction(req createTransact
 func (h *InvestmentHandler) validateSavingsAccountTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Deposit
-		if req.Quantity <= 0 {
+		if !req.Quantity.IsPositive() {
 			return fmt.Errorf("quantity must be greater than 0 for savings account Deposit transactions")
 		}
 	case 2: // Withdrawal
-		if req.Quantity <= 0 {
+		if !req.Quantity.IsPositive() {
 			return fmt.Errorf("quantity must be greater than 0 for savings account Withdrawal transactions")
 		}
 	default:
@@ -689,11 +711,11 @@ func (h *InvestmentHandler) validateSavingsAccountTransaction(req createTransact
 func (h *InvestmentHandler) validateCashTransaction(req createTransactionRequest) error {
 	switch req.TransactionTypeID {
 	case 1: // Deposit
-		if req.Quantity <= 0 {
+		if !req.Quantity.IsPositive() {
 			return fmt.Errorf("quantity must be greater than 0 for cash Deposit transactions")
 		}
 	case 2: // Withdrawal
-		if req.Quantity <= 0 {
+		if !req.Quantity.IsPositive() {
 			return fmt.Errorf("quantity must be greater than 0 for cash Withdrawal transactions")
 		}
 	default:
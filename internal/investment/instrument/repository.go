@@ -4,13 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"github.com/sebuszqo/FinanceManager/internal/investment/models"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"log"
 	"time"
 )
 
 type Repository interface {
 	bulkInsertOrUpdate(ctx context.Context, instruments *[]models.Instrument) error
-	getPriceBySymbol(ctx context.Context, symbol string) (float64, error)
+	getPriceBySymbol(ctx context.Context, symbol string) (money.Decimal, error)
 	searchByNameOrSymbol(ctx context.Context, query string, assetTypeID int, limit int) (*[]models.Instrument, error)
 	getAllSymbols(ctx context.Context) ([]string, error)
 	getLastUpdatedAt(ctx context.Context) (time.Time, error)
@@ -91,13 +92,13 @@ func safeRollback(tx *sql.Tx) {
 	}
 }
 
-func (r *instrumentRepository) getPriceBySymbol(ctx context.Context, symbol string) (float64, error) {
-	var price float64
+func (r *instrumentRepository) getPriceBySymbol(ctx context.Context, symbol string) (money.Decimal, error) {
+	var price money.Decimal
 	err := r.db.QueryRowContext(ctx, `
         SELECT price FROM instruments WHERE symbol = $1
     `, symbol).Scan(&price)
 	if err != nil {
-		return 0, err
+		return money.Zero, err
 	}
 	return price, nil
 }
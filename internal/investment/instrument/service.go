@@ -6,13 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/sebuszqo/FinanceManager/internal/investment/models"
+	"github.com/sebuszqo/FinanceManager/internal/money"
 	"time"
 )
 
 type Service interface {
 	ImportInstruments(ctx context.Context) error
 	UpdateInstruments(ctx context.Context) error
-	GetInstrumentPrice(ctx context.Context, symbol string) (float64, error)
+	GetInstrumentPrice(ctx context.Context, symbol string) (money.Decimal, error)
 	SearchInstruments(ctx context.Context, query string, assetTypeID int, limit int) (*[]models.Instrument, error)
 	NeedsUpdate(ctx context.Context) (bool, error)
 	GetTickerWithPriceInstruments(ctx context.Context) ([]models.InstrumentPriceWithSymbol, error)
@@ -45,7 +46,7 @@ func (s *service) UpdateInstruments(ctx context.Context) error {
 	return nil
 }
 
-func (s *service) GetInstrumentPrice(ctx context.Context, symbol string) (float64, error) {
+func (s *service) GetInstrumentPrice(ctx context.Context, symbol string) (money.Decimal, error) {
 	return s.instrumentRepo.getPriceBySymbol(ctx, symbol)
 }
 
@@ -113,6 +114,6 @@ func NewInstrumentService(repo Repository, marketDataSvc APIService) Service {
 	return &service{instrumentRepo: repo, marketDataSvc: marketDataSvc}
 }
 
-func (s *service) GetCurrentInstrumentPrice(ticker string) (float64, error) {
-	return 2.33, nil
+func (s *service) GetCurrentInstrumentPrice(ticker string) (money.Decimal, error) {
+	return money.MustParse("2.33"), nil
 }
@@ -116,3 +116,38 @@ func (c *FinancialModelingPrepClient) FetchAllInstruments() (*[]models.Instrumen
 
 	return &results, nil
 }
+
+// GetExchangeRate returns the latest price of one unit of the from currency in the to currency.
+func (c *FinancialModelingPrepClient) GetExchangeRate(from, to string) (float64, error) {
+	baseURL := "https://financialmodelingprep.com/api/v3/quote/"
+	params := fmt.Sprintf("%s%s?apikey=%s", url.PathEscape(from), url.PathEscape(to), c.apiKey)
+	parsedURL, err := url.Parse(baseURL + params)
+	if err != nil {
+		return 0, errors.New("invalid URL")
+	}
+	resp, err := c.httpClient.Get(parsedURL.String())
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error querying API: %s", resp.Status)
+	}
+
+	var results []struct {
+		Symbol string  `json:"symbol"`
+		Price  float64 `json:"price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 || results[0].Price <= 0 {
+		return 0, fmt.Errorf("exchange rate %s/%s not found", from, to)
+	}
+	return results[0].Price, nil
+}
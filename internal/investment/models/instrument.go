@@ -1,5 +1,7 @@
 package models
 
+import "github.com/sebuszqo/FinanceManager/internal/money"
+
 type Instrument struct {
 	ID            int
 	Symbol        string
@@ -7,21 +9,21 @@ type Instrument struct {
 	Exchange      string
 	ExchangeShort string
 	AssetTypeID   int
-	Price         float64
+	Price         money.Decimal
 	Currency      string
 }
 
 type InstrumentDTO struct {
-	Symbol        string  `json:"symbol"`
-	Name          string  `json:"name"`
-	Exchange      string  `json:"exchange"`
-	ExchangeShort string  `json:"exchangeShortName"`
-	Type          string  `json:"type"`
-	Price         float64 `json:"price"`
-	Currency      string  `json:"currency"`
+	Symbol        string        `json:"symbol"`
+	Name          string        `json:"name"`
+	Exchange      string        `json:"exchange"`
+	ExchangeShort string        `json:"exchangeShortName"`
+	Type          string        `json:"type"`
+	Price         money.Decimal `json:"price"`
+	Currency      string        `json:"currency"`
 }
 
 type InstrumentPriceWithSymbol struct {
-	Symbol string  `json:"symbol"`
-	Price  float64 `json:"price"`
+	Symbol string        `json:"symbol"`
+	Price  money.Decimal `json:"price"`
 }
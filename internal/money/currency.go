@@ -0,0 +1,21 @@
+package money
+
+import "strings"
+
+// DefaultCurrency is the currency of personal finance amounts, which don't store a currency of their own.
+const DefaultCurrency = "PLN"
+
+// currencyMinorUnits lists the ISO 4217 currencies that don't use two decimal places.
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns the number of decimal places of the currency, two for unknown codes.
+func MinorUnits(currency string) int {
+	if places, ok := currencyMinorUnits[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return 2
+}
@@ -0,0 +1,392 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of fractional digits a Decimal keeps, enough for crypto quantities and unit prices.
+const Scale = 8
+
+const unitsPerOne = 100_000_000
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal number")
+	ErrOutOfRange     = errors.New("decimal number out of range")
+	ErrDivisionByZero = errors.New("decimal division by zero")
+)
+
+var (
+	minUnits  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxUnits  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	wordMask  = new(big.Int).SetUint64(math.MaxUint64)
+	wordRange = new(big.Int).Lsh(big.NewInt(1), 128)
+)
+
+// Decimal is an exact decimal number stored as a 128-bit two's complement count of 10^-8, so sums of amounts never
+// drift the way float64 does. It covers values up to about ±1.7e30, far more than any NUMERIC column holds, so every
+// stored amount and the product of any two of them fit. Add, Sub, Mul and Div panic beyond that range like a division
+// by zero does, the Checked variants return ErrOutOfRange instead. The zero value is 0.
+type Decimal struct {
+	hi int64
+	lo uint64
+}
+
+var Zero = Decimal{}
+
+func FromInt(value int64) Decimal {
+	return mustFit(new(big.Int).Mul(big.NewInt(value), big.NewInt(unitsPerOne)))
+}
+
+// FromMinorUnits converts an integer count of minor units, e.g. FromMinorUnits(1999, 2) is 19.99.
+func FromMinorUnits(minorUnits int64, places int) Decimal {
+	return mustFit(new(big.Int).Mul(big.NewInt(minorUnits), pow10(Scale-places)))
+}
+
+// FromFloat converts the shortest decimal representation of value, which is what a price returned by an external
+// API as a float64 meant. It should only be used at such boundaries.
+func FromFloat(value float64) Decimal {
+	decimal, err := Parse(strconv.FormatFloat(value, 'g', -1, 64))
+	if err != nil {
+		panic(fmt.Sprintf("money: can't convert %v to a decimal: %v", value, err))
+	}
+	return decimal
+}
+
+// Parse reads a decimal written with a dot, such as 1234.56, -0.5 or 1e3. Digits beyond Scale are rounded half
+// away from zero.
+func Parse(text string) (Decimal, error) {
+	text = strings.TrimSpace(text)
+	// big.Rat would also accept fractions and base prefixes such as 1/3 or 0x1p4
+	if text == "" || strings.TrimLeft(text, "0123456789.+-eE") != "" {
+		return Zero, ErrInvalidDecimal
+	}
+	// a huge exponent would make big.Rat allocate the whole number before the range is checked
+	if exponent := strings.IndexAny(text, "eE"); exponent >= 0 {
+		value, err := strconv.Atoi(text[exponent+1:])
+		if err != nil {
+			return Zero, ErrInvalidDecimal
+		}
+		if value > 64 || value < -64 {
+			return Zero, ErrOutOfRange
+		}
+	}
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return Zero, ErrInvalidDecimal
+	}
+	value.Mul(value, new(big.Rat).SetInt64(unitsPerOne))
+	decimal, ok := fromUnits(roundQuotient(value.Num(), value.Denom()))
+	if !ok {
+		return Zero, ErrOutOfRange
+	}
+	return decimal, nil
+}
+
+func MustParse(text string) Decimal {
+	decimal, err := Parse(text)
+	if err != nil {
+		panic(fmt.Sprintf("money: can't parse %q: %v", text, err))
+	}
+	return decimal
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return mustNotOverflow(d.CheckedAdd(other))
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return mustNotOverflow(d.CheckedSub(other))
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return mustNotOverflow(d.CheckedMul(other))
+}
+
+// Div rounds the quotient to Scale digits, half away from zero. It panics if other is zero.
+func (d Decimal) Div(other Decimal) Decimal {
+	if other.IsZero() {
+		panic("money: division by zero")
+	}
+	return mustNotOverflow(d.CheckedDiv(other))
+}
+
+func (d Decimal) CheckedAdd(other Decimal) (Decimal, error) {
+	lo, carry := bits.Add64(d.lo, other.lo, 0)
+	hi, _ := bits.Add64(uint64(d.hi), uint64(other.hi), carry)
+	sum := Decimal{hi: int64(hi), lo: lo}
+	// the sum of two numbers of the same sign can only overflow into the other sign
+	if (d.hi < 0) == (other.hi < 0) && (sum.hi < 0) != (d.hi < 0) {
+		return Zero, ErrOutOfRange
+	}
+	return sum, nil
+}
+
+func (d Decimal) CheckedSub(other Decimal) (Decimal, error) {
+	lo, borrow := bits.Sub64(d.lo, other.lo, 0)
+	hi, _ := bits.Sub64(uint64(d.hi), uint64(other.hi), borrow)
+	difference := Decimal{hi: int64(hi), lo: lo}
+	if (d.hi < 0) != (other.hi < 0) && (difference.hi < 0) != (d.hi < 0) {
+		return Zero, ErrOutOfRange
+	}
+	return difference, nil
+}
+
+// CheckedMul rounds the product to Scale digits, half away from zero.
+func (d Decimal) CheckedMul(other Decimal) (Decimal, error) {
+	product := new(big.Int).Mul(d.units(), other.units())
+	decimal, ok := fromUnits(roundQuotient(product, big.NewInt(unitsPerOne)))
+	if !ok {
+		return Zero, ErrOutOfRange
+	}
+	return decimal, nil
+}
+
+// CheckedDiv rounds the quotient to Scale digits, half away from zero. It returns ErrDivisionByZero if other is zero.
+func (d Decimal) CheckedDiv(other Decimal) (Decimal, error) {
+	if other.IsZero() {
+		return Zero, ErrDivisionByZero
+	}
+	dividend := new(big.Int).Mul(d.units(), big.NewInt(unitsPerOne))
+	decimal, ok := fromUnits(roundQuotient(dividend, other.units()))
+	if !ok {
+		return Zero, ErrOutOfRange
+	}
+	return decimal, nil
+}
+
+func (d Decimal) Neg() Decimal {
+	return Zero.Sub(d)
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.IsNegative() {
+		return d.Neg()
+	}
+	return d
+}
+
+// FitsNumeric reports whether the decimal, rounded to scale fractional digits, fits a NUMERIC(precision, scale)
+// column, e.g. FitsNumeric(10, 2) is true up to 99999999.99.
+func (d Decimal) FitsNumeric(precision, scale int) bool {
+	limit := pow10(precision + Scale - scale)
+	magnitude := new(big.Int).Abs(d.Round(scale).units())
+	return magnitude.Cmp(limit) < 0
+}
+
+// Round rounds to the given number of fractional digits, half away from zero.
+func (d Decimal) Round(places int) Decimal {
+	if places >= Scale {
+		return d
+	}
+	factor := pow10(Scale - places)
+	return mustFit(new(big.Int).Mul(roundQuotient(d.units(), factor), factor))
+}
+
+// RoundToCurrency rounds to the minor units of the currency, e.g. cents for PLN and whole yen for JPY.
+func (d Decimal) RoundToCurrency(currency string) Decimal {
+	return d.Round(MinorUnits(currency))
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.hi < other.hi || d.hi == other.hi && d.lo < other.lo:
+		return -1
+	case d.hi > other.hi || d.hi == other.hi && d.lo > other.lo:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d == other
+}
+
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+func (d Decimal) Sign() int {
+	return d.Cmp(Zero)
+}
+
+func (d Decimal) IsZero() bool {
+	return d == Zero
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.Sign() > 0
+}
+
+func (d Decimal) IsNegative() bool {
+	return d.hi < 0
+}
+
+// Float64 is meant for ratios and charts only, never for arithmetic on amounts.
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+// String writes at least two fractional digits and drops trailing zeros beyond them, e.g. 12.50 or 0.00012345.
+func (d Decimal) String() string {
+	text := d.format(Scale)
+	trimmed := strings.TrimRight(text, "0")
+	if dot := strings.IndexByte(text, '.'); len(trimmed) < dot+3 {
+		return text[:dot+3]
+	}
+	return trimmed
+}
+
+// StringFixed rounds to the given number of fractional digits and writes all of them.
+func (d Decimal) StringFixed(places int) string {
+	if places > Scale {
+		places = Scale
+	}
+	if places < 0 {
+		places = 0
+	}
+	return d.Round(places).format(places)
+}
+
+func (d Decimal) format(places int) string {
+	sign := ""
+	magnitude := d.units()
+	if magnitude.Sign() < 0 {
+		sign = "-"
+		magnitude.Neg(magnitude)
+	}
+	integer, fraction := new(big.Int).QuoRem(magnitude, big.NewInt(unitsPerOne), new(big.Int))
+	if places == 0 {
+		return sign + integer.String()
+	}
+	digits := fmt.Sprintf("%08d", fraction.Int64())[:places]
+	return sign + integer.String() + "." + digits
+}
+
+// MarshalJSON writes the decimal as a JSON number, so clients reading amounts as numbers keep working.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one. The literal is parsed directly, without going
+// through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	value, err := Parse(text)
+	if err != nil {
+		return fmt.Errorf("money: can't unmarshal %s: %w", data, err)
+	}
+	*d = value
+	return nil
+}
+
+// Value stores the decimal as text, which PostgreSQL converts to NUMERIC without loss.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads NUMERIC columns, which drivers return as text. NULL, e.g. SUM over no rows, is read as zero, use
+// *Decimal to tell the two apart.
+func (d *Decimal) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case []byte:
+		return d.parseScanned(string(value))
+	case string:
+		return d.parseScanned(value)
+	case int64:
+		*d = FromInt(value)
+		return nil
+	case float64:
+		*d = FromFloat(value)
+		return nil
+	}
+	return fmt.Errorf("money: can't scan %T into a decimal", src)
+}
+
+func (d *Decimal) parseScanned(text string) error {
+	value, err := Parse(text)
+	if err != nil {
+		return fmt.Errorf("money: can't scan %q: %w", text, err)
+	}
+	*d = value
+	return nil
+}
+
+// roundQuotient divides and rounds half away from zero.
+func roundQuotient(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() != 0 {
+		doubled := new(big.Int).Abs(remainder)
+		doubled.Lsh(doubled, 1)
+		if doubled.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+			if numerator.Sign()*denominator.Sign() < 0 {
+				quotient.Sub(quotient, big.NewInt(1))
+			} else {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+	return quotient
+}
+
+// units returns the count of 10^-8 the decimal stores.
+func (d Decimal) units() *big.Int {
+	units := new(big.Int).Lsh(big.NewInt(d.hi), 64)
+	return units.Add(units, new(big.Int).SetUint64(d.lo))
+}
+
+// fromUnits stores a count of 10^-8, reporting whether it fits into 128 bits.
+func fromUnits(units *big.Int) (Decimal, bool) {
+	if units.Cmp(minUnits) < 0 || units.Cmp(maxUnits) > 0 {
+		return Zero, false
+	}
+	twosComplement := new(big.Int).Set(units)
+	if twosComplement.Sign() < 0 {
+		twosComplement.Add(twosComplement, wordRange)
+	}
+	lo := new(big.Int).And(twosComplement, wordMask).Uint64()
+	hi := new(big.Int).Rsh(twosComplement, 64).Uint64()
+	return Decimal{hi: int64(hi), lo: lo}, true
+}
+
+func mustFit(units *big.Int) Decimal {
+	decimal, ok := fromUnits(units)
+	if !ok {
+		panic(ErrOutOfRange)
+	}
+	return decimal
+}
+
+func mustNotOverflow(decimal Decimal, err error) Decimal {
+	if err != nil {
+		panic(err)
+	}
+	return decimal
+}
+
+func pow10(exponent int) *big.Int {
+	if exponent < 0 {
+		panic("money: more fractional digits than Scale")
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
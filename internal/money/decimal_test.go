@@ -0,0 +1,146 @@
+package money
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1234.56", "1234.56"},
+		{"-0.5", "-0.50"},
+		{"7", "7.00"},
+		{"0.00012345", "0.00012345"},
+		{"1e3", "1000.00"},
+		{"0.123456789", "0.12345679"},
+		{"-0.000000005", "-0.00000001"},
+		{"+010.5", "10.50"},
+		{".25", "0.25"},
+	}
+	for _, test := range tests {
+		value, err := Parse(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, value.String(), test.input)
+	}
+
+	for _, input := range []string{"", "abc", "1/3", "1_000", "0x10", "NaN", "1e31", "1e999999999", "1e", "1.2.3"} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestSumHasNoFloatingPointDrift(t *testing.T) {
+	sum := Zero
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(MustParse("0.10"))
+	}
+	assert.True(t, sum.Equal(FromInt(1)))
+	assert.Equal(t, "0.30", MustParse("0.1").Add(MustParse("0.2")).String())
+}
+
+func TestMulDivAndRound(t *testing.T) {
+	assert.Equal(t, "3.70", MustParse("1.85").Mul(FromInt(2)).String())
+	assert.Equal(t, "0.33333333", FromInt(1).Div(FromInt(3)).String())
+	assert.Equal(t, "-0.66666667", FromInt(-2).Div(FromInt(3)).String())
+	assert.Panics(t, func() { FromInt(1).Div(Zero) })
+
+	assert.Equal(t, "2.68", MustParse("2.675").Round(2).String())
+	assert.Equal(t, "-2.68", MustParse("-2.675").Round(2).String())
+	assert.Equal(t, "1235.00", MustParse("1234.5").RoundToCurrency("JPY").String())
+	assert.Equal(t, "1.235", MustParse("1.2345").RoundToCurrency("KWD").String())
+	assert.Equal(t, "1.23", MustParse("1.2345").RoundToCurrency("pln").String())
+	assert.Equal(t, "19.990", FromMinorUnits(1999, 2).StringFixed(3))
+	assert.Equal(t, "20", MustParse("19.5").StringFixed(0))
+}
+
+func TestFromFloat(t *testing.T) {
+	assert.Equal(t, "0.10", FromFloat(0.1).String())
+	assert.Equal(t, "182.52", FromFloat(182.52).String())
+	assert.Equal(t, 182.52, MustParse("182.52").Float64())
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Amount   Decimal  `json:"amount"`
+		Price    Decimal  `json:"price"`
+		Optional *Decimal `json:"optional"`
+	}
+	err := json.Unmarshal([]byte(`{"amount": 10.10, "price": "0.00012345", "optional": null}`), &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.10", payload.Amount.String())
+	assert.Equal(t, "0.00012345", payload.Price.String())
+	assert.Nil(t, payload.Optional)
+
+	encoded, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 10.10, "price": 0.00012345, "optional": null}`, string(encoded))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": true}`), &payload))
+}
+
+func TestScanAndValue(t *testing.T) {
+	var value Decimal
+	assert.NoError(t, value.Scan([]byte("1234.5600")))
+	assert.Equal(t, "1234.56", value.String())
+	assert.NoError(t, value.Scan(int64(3)))
+	assert.Equal(t, "3.00", value.String())
+	assert.NoError(t, value.Scan(nil))
+	assert.True(t, value.IsZero())
+	assert.Error(t, value.Scan(true))
+
+	stored, err := MustParse("-12.30").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-12.30", stored)
+}
+
+func TestRange(t *testing.T) {
+	// the largest NUMERIC(15, 4) and NUMERIC(15, 2) values and their product
+	quantity := MustParse("99999999999.9999")
+	price := MustParse("9999999999999.99")
+	assert.Equal(t, "999999999999998000000000.000001", quantity.Mul(price).String())
+
+	var scanned Decimal
+	assert.NoError(t, scanned.Scan([]byte("9999999999999.99")))
+	assert.True(t, scanned.Equal(price))
+
+	// carries between the two words of the count
+	large := MustParse("184467440737.09551616")
+	assert.Equal(t, "0.00", large.Sub(large).String())
+	assert.Equal(t, "-184467440737.09551616", Zero.Sub(large).String())
+	assert.Equal(t, "368934881474.19103232", large.Add(large).String())
+	assert.Equal(t, "184467440737.09551615", large.Sub(MustParse("0.00000001")).String())
+	assert.True(t, large.Neg().LessThan(MustParse("-1")))
+	assert.True(t, large.GreaterThan(MustParse("184467440737.09551615")))
+	assert.True(t, large.Neg().IsNegative())
+
+	maximum := MustParse("1e30")
+	_, err := maximum.CheckedMul(maximum)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = maximum.CheckedAdd(maximum)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = maximum.Neg().CheckedSub(maximum)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = maximum.CheckedDiv(Zero)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+	_, err = maximum.CheckedDiv(MustParse("0.01"))
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.Panics(t, func() { maximum.Mul(maximum) })
+
+	product, err := MustParse("-2.5").CheckedMul(MustParse("1.5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "-3.75", product.String())
+}
+
+func TestFitsNumeric(t *testing.T) {
+	assert.True(t, MustParse("99999999.99").FitsNumeric(10, 2))
+	assert.True(t, MustParse("-99999999.99").FitsNumeric(10, 2))
+	assert.False(t, MustParse("100000000").FitsNumeric(10, 2))
+	// rounded to cents like the column does
+	assert.False(t, MustParse("99999999.995").FitsNumeric(10, 2))
+	assert.True(t, MustParse("9999999999.12345678").FitsNumeric(18, 8))
+	assert.False(t, MustParse("10000000000").FitsNumeric(18, 8))
+}
@@ -261,4 +261,265 @@ CREATE TABLE personal_transactions (
 
 
 
--- delete from personal_transactions where '1' = '1'
\ No newline at end of file
+-- delete from personal_transactions where '1' = '1'
+
+ALTER TABLE user_categories
+    ADD COLUMN parent_category_id INT REFERENCES predefined_categories(id),
+    ADD COLUMN type VARCHAR(10) CHECK (type IN ('income', 'expense')) NOT NULL DEFAULT 'expense',
+    ADD COLUMN color VARCHAR(7),
+    ADD COLUMN icon VARCHAR(50);
+
+ALTER TABLE payment_sources
+    ADD COLUMN archived_at TIMESTAMP;
+
+CREATE TABLE recurring_rules (
+                                 id UUID PRIMARY KEY,
+                                 user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                                 frequency VARCHAR(10) CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')) NOT NULL,
+                                 interval_count INT NOT NULL DEFAULT 1,
+                                 start_date DATE NOT NULL,
+                                 end_date DATE,
+                                 occurrence_count INT,
+                                 generated_until DATE,
+                                 name VARCHAR(50) NOT NULL,
+                                 amount DECIMAL(10, 2) NOT NULL,
+                                 type VARCHAR(10) CHECK (type IN ('income', 'expense')) NOT NULL,
+                                 description TEXT,
+                                 predefined_category_id INT REFERENCES predefined_categories(id) NOT NULL,
+                                 user_category_id INT REFERENCES user_categories(id) ON DELETE SET NULL,
+                                 payment_method_id INT REFERENCES payment_methods(id) NOT NULL,
+                                 payment_source_id INT REFERENCES payment_sources(id),
+                                 created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE recurring_rule_exceptions (
+                                           rule_id UUID REFERENCES recurring_rules(id) ON DELETE CASCADE,
+                                           occurrence_date DATE NOT NULL,
+                                           skipped BOOLEAN NOT NULL DEFAULT FALSE,
+                                           name VARCHAR(50),
+                                           amount DECIMAL(10, 2),
+                                           description TEXT,
+                                           PRIMARY KEY (rule_id, occurrence_date)
+);
+
+ALTER TABLE personal_transactions
+    ADD COLUMN recurring_rule_id UUID REFERENCES recurring_rules(id) ON DELETE SET NULL,
+    ADD COLUMN recurring_occurrence_date DATE,
+    ADD CONSTRAINT unique_recurring_occurrence UNIQUE (recurring_rule_id, recurring_occurrence_date);
+
+CREATE TABLE budgets (
+                         id SERIAL PRIMARY KEY,
+                         user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                         predefined_category_id INT REFERENCES predefined_categories(id),
+                         user_category_id INT REFERENCES user_categories(id) ON DELETE CASCADE,
+                         period VARCHAR(10) CHECK (period IN ('weekly', 'monthly', 'yearly')) NOT NULL,
+                         amount DECIMAL(10, 2) NOT NULL,
+                         rollover BOOLEAN NOT NULL DEFAULT FALSE,
+                         start_date DATE NOT NULL,
+                         CHECK ((predefined_category_id IS NULL) <> (user_category_id IS NULL))
+);
+
+CREATE TABLE budget_alerts (
+                               budget_id INT REFERENCES budgets(id) ON DELETE CASCADE,
+                               period_start DATE NOT NULL,
+                               threshold INT NOT NULL,
+                               sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                               PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+CREATE TABLE import_profiles (
+                                 id SERIAL PRIMARY KEY,
+                                 user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                                 name VARCHAR(50) NOT NULL,
+                                 delimiter VARCHAR(1) NOT NULL,
+                                 decimal_separator VARCHAR(1) NOT NULL,
+                                 date_format VARCHAR(30) NOT NULL,
+                                 has_header BOOLEAN NOT NULL DEFAULT TRUE,
+                                 skip_rows INT NOT NULL DEFAULT 0,
+                                 columns JSONB NOT NULL,
+                                 sign_convention VARCHAR(30) CHECK (sign_convention IN ('negative_expense', 'negative_income', 'debit_credit_columns')) NOT NULL,
+                                 expense_category_id INT REFERENCES predefined_categories(id) NOT NULL,
+                                 income_category_id INT REFERENCES predefined_categories(id) NOT NULL,
+                                 payment_method_id INT REFERENCES payment_methods(id) NOT NULL,
+                                 payment_source_id INT REFERENCES payment_sources(id),
+                                 UNIQUE (name, user_id)
+);
+
+CREATE TABLE personal_transaction_splits (
+                                             id SERIAL PRIMARY KEY,
+                                             transaction_id UUID REFERENCES personal_transactions(id) ON DELETE CASCADE NOT NULL,
+                                             predefined_category_id INT REFERENCES predefined_categories(id) NOT NULL,
+                                             user_category_id INT REFERENCES user_categories(id),
+                                             amount DECIMAL(10, 2) NOT NULL,
+                                             description TEXT
+);
+
+CREATE INDEX idx_personal_transaction_splits_transaction_id ON personal_transaction_splits (transaction_id);
+
+ALTER TABLE personal_transactions
+    DROP CONSTRAINT personal_transactions_type_check,
+    DROP CONSTRAINT personal_transactions_check,
+    ADD CONSTRAINT personal_transactions_type_check CHECK (type IN ('income', 'expense', 'transfer')),
+    ADD COLUMN destination_payment_source_id INT REFERENCES payment_sources(id),
+    ADD COLUMN fx_rate DECIMAL(18, 8),
+    ADD CONSTRAINT personal_transactions_transfer_check CHECK (
+        CASE WHEN type = 'transfer'
+            THEN predefined_category_id IS NULL AND payment_source_id IS NOT NULL AND destination_payment_source_id IS NOT NULL
+            ELSE predefined_category_id IS NOT NULL AND destination_payment_source_id IS NULL AND fx_rate IS NULL
+        END
+    );
+
+CREATE INDEX idx_personal_transactions_destination_payment_source_id ON personal_transactions (destination_payment_source_id);
+
+ALTER TABLE payment_sources
+    ADD COLUMN opening_balance DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE payment_source_reconciliations (
+                                                id SERIAL PRIMARY KEY,
+                                                payment_source_id INT REFERENCES payment_sources(id) ON DELETE CASCADE NOT NULL,
+                                                statement_date DATE NOT NULL,
+                                                statement_balance DECIMAL(12, 2) NOT NULL,
+                                                created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE personal_transactions
+    ADD COLUMN reconciliation_id INT REFERENCES payment_source_reconciliations(id) ON DELETE SET NULL;
+
+CREATE TABLE categorization_rules (
+                                      id SERIAL PRIMARY KEY,
+                                      user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                                      name VARCHAR(50) NOT NULL,
+                                      priority INT NOT NULL DEFAULT 0,
+                                      match_field VARCHAR(20) CHECK (match_field IN ('name', 'description', 'name_or_description')) NOT NULL,
+                                      match_type VARCHAR(10) CHECK (match_type IN ('contains', 'regex')) NOT NULL,
+                                      pattern VARCHAR(200) NOT NULL DEFAULT '',
+                                      min_amount DECIMAL(12, 2),
+                                      max_amount DECIMAL(12, 2),
+                                      transaction_type VARCHAR(10) CHECK (transaction_type IN ('income', 'expense')),
+                                      predefined_category_id INT REFERENCES predefined_categories(id),
+                                      user_category_id INT REFERENCES user_categories(id) ON DELETE SET NULL,
+                                      payment_method_id INT REFERENCES payment_methods(id)
+);
+
+CREATE INDEX idx_categorization_rules_user_id ON categorization_rules (user_id, priority);
+
+CREATE TABLE tags (
+                      id SERIAL PRIMARY KEY,
+                      user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                      name VARCHAR(50) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_tags_user_id_name ON tags (user_id, LOWER(name));
+
+CREATE TABLE personal_transaction_tags (
+                                           transaction_id UUID REFERENCES personal_transactions(id) ON DELETE CASCADE,
+                                           tag_id INT REFERENCES tags(id) ON DELETE CASCADE,
+                                           PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX idx_personal_transaction_tags_tag_id ON personal_transaction_tags (tag_id);
+
+CREATE TABLE categorization_rule_tags (
+                                          rule_id INT REFERENCES categorization_rules(id) ON DELETE CASCADE,
+                                          tag_id INT REFERENCES tags(id) ON DELETE CASCADE,
+                                          PRIMARY KEY (rule_id, tag_id)
+);
+
+ALTER TABLE personal_transactions
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', name || ' ' || COALESCE(description, ''))) STORED;
+
+CREATE INDEX idx_personal_transactions_search_vector ON personal_transactions USING GIN (search_vector);
+CREATE INDEX idx_personal_transactions_user_id_date ON personal_transactions (user_id, date, id);
+CREATE INDEX idx_personal_transactions_user_id_amount ON personal_transactions (user_id, amount, id);
+
+ALTER TABLE personal_transactions
+    ADD COLUMN external_id VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_personal_transactions_user_id_external_id ON personal_transactions (user_id, external_id) WHERE external_id IS NOT NULL;
+
+CREATE TABLE personal_transaction_attachments (
+                                                  id UUID PRIMARY KEY,
+                                                  transaction_id UUID REFERENCES personal_transactions(id) ON DELETE CASCADE NOT NULL,
+                                                  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                                                  file_name VARCHAR(255) NOT NULL,
+                                                  content_type VARCHAR(100) NOT NULL,
+                                                  size BIGINT NOT NULL,
+                                                  storage_key VARCHAR(255) NOT NULL,
+                                                  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_personal_transaction_attachments_transaction_id ON personal_transaction_attachments (transaction_id);
+
+CREATE TABLE savings_goals (
+                               id SERIAL PRIMARY KEY,
+                               user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                               name VARCHAR(100) NOT NULL,
+                               target_amount DECIMAL(15, 2) NOT NULL,
+                               target_date DATE NOT NULL,
+                               funding VARCHAR(20) CHECK (funding IN ('category', 'payment_source', 'portfolio')) NOT NULL,
+                               payment_source_id INT REFERENCES payment_sources(id) ON DELETE CASCADE,
+                               portfolio_id UUID REFERENCES portfolios(id) ON DELETE CASCADE,
+                               start_date DATE NOT NULL,
+                               starting_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+                               last_progress_email DATE
+);
+
+CREATE INDEX idx_savings_goals_user_id ON savings_goals (user_id);
+
+CREATE TABLE loans (
+                       id SERIAL PRIMARY KEY,
+                       user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+                       name VARCHAR(100) NOT NULL,
+                       principal DECIMAL(15, 2) NOT NULL,
+                       annual_rate DECIMAL(7, 4) NOT NULL,
+                       rate_type VARCHAR(10) CHECK (rate_type IN ('fixed', 'variable')) NOT NULL,
+                       term_months INT NOT NULL,
+                       method VARCHAR(10) CHECK (method IN ('equal', 'decreasing')) NOT NULL,
+                       first_installment_date DATE NOT NULL,
+                       predefined_category_id INT REFERENCES predefined_categories(id) NOT NULL,
+                       payment_source_id INT REFERENCES payment_sources(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_loans_user_id ON loans (user_id);
+
+CREATE TABLE loan_rate_changes (
+                                   loan_id INT REFERENCES loans(id) ON DELETE CASCADE,
+                                   effective_date DATE NOT NULL,
+                                   annual_rate DECIMAL(7, 4) NOT NULL,
+                                   PRIMARY KEY (loan_id, effective_date)
+);
+
+CREATE TABLE finance_settings (
+                                  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                  base_currency CHAR(3) NOT NULL DEFAULT 'PLN'
+);
+
+CREATE TABLE net_worth_snapshots (
+                                     user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+                                     snapshot_date DATE NOT NULL,
+                                     base_currency CHAR(3) NOT NULL,
+                                     assets DECIMAL(15, 2) NOT NULL,
+                                     liabilities DECIMAL(15, 2) NOT NULL,
+                                     net_worth DECIMAL(15, 2) NOT NULL,
+                                     breakdown JSONB NOT NULL,
+                                     PRIMARY KEY (user_id, snapshot_date)
+);
+
+ALTER TABLE finance_settings
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN week_start VARCHAR(9) NOT NULL DEFAULT 'monday';
+
+ALTER TABLE personal_transactions
+    ADD COLUMN destination_reconciliation_id INT REFERENCES payment_source_reconciliations(id) ON DELETE SET NULL;
+
+ALTER TABLE recurring_rules
+    DROP CONSTRAINT recurring_rules_type_check,
+    ADD CONSTRAINT recurring_rules_type_check CHECK (type IN ('income', 'expense', 'transfer')),
+    ALTER COLUMN predefined_category_id DROP NOT NULL,
+    ADD COLUMN destination_payment_source_id INT REFERENCES payment_sources(id);
+
+ALTER TABLE payment_sources
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'PLN';
+
+ALTER TABLE loans
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'PLN';